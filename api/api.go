@@ -234,6 +234,7 @@ func NewServerRoutes(s Server) *http.ServeMux {
 	m := http.NewServeMux()
 
 	m.Handle("/health", s.middleware(HealthCheckHandler))
+	m.Handle("/events", s.middleware(s.EventsHandler))
 	m.Handle("/ipfs/", s.middleware(s.HandleIPFSPath))
 	m.Handle("/ipns/", s.middleware(s.HandleIPNSPath))
 
@@ -269,6 +270,8 @@ func NewServerRoutes(s Server) *http.ServeMux {
 	m.Handle("/rename", s.middleware(dsh.RenameHandler))
 	m.Handle("/export/", s.middleware(dsh.ZipDatasetHandler))
 	m.Handle("/diff", s.middleware(dsh.DiffHandler))
+	m.Handle("/apply", s.middleware(dsh.ApplyHandler))
+	m.Handle("/apply/", s.middleware(dsh.ApplyHandler))
 	m.Handle("/body/", s.middleware(dsh.BodyHandler))
 	m.Handle("/stats/", s.middleware(dsh.StatsHandler))
 	m.Handle("/unpack/", s.middleware(dsh.UnpackHandler))
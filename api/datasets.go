@@ -10,6 +10,7 @@ import (
 	"os"
 	"path"
 	"path/filepath"
+	"strconv"
 	"strings"
 
 	util "github.com/qri-io/apiutil"
@@ -110,6 +111,22 @@ func (h *DatasetHandlers) DiffHandler(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// ApplyHandler is an endpoint for running a transform without saving
+func (h *DatasetHandlers) ApplyHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "OPTIONS":
+		util.EmptyOkHandler(w, r)
+	case "POST":
+		if h.ReadOnly {
+			readOnlyResponse(w, "/apply")
+			return
+		}
+		h.applyHandler(w, r)
+	default:
+		util.NotFoundHandler(w, r)
+	}
+}
+
 // PeerListHandler is a dataset list endpoint
 func (h *DatasetHandlers) PeerListHandler(w http.ResponseWriter, r *http.Request) {
 	switch r.Method {
@@ -339,6 +356,65 @@ func (h *DatasetHandlers) diffHandler(w http.ResponseWriter, r *http.Request) {
 	util.WritePageResponse(w, res, r, util.Page{})
 }
 
+func (h *DatasetHandlers) applyHandler(w http.ResponseWriter, r *http.Request) {
+	p := &lib.ApplyParams{}
+	if r.Header.Get("Content-Type") == "application/json" {
+		if err := json.NewDecoder(r.Body).Decode(p); err != nil {
+			util.WriteErrResponse(w, http.StatusBadRequest, fmt.Errorf("error decoding body into params: %s", err.Error()))
+			return
+		}
+	} else {
+		p.ReplayHTTP = r.FormValue("replay_http") == "true"
+		if r.FormValue("secrets") != "" {
+			if err := json.Unmarshal([]byte(r.FormValue("secrets")), &p.Secrets); err != nil {
+				util.WriteErrResponse(w, http.StatusBadRequest, fmt.Errorf("parsing secrets: %s", err))
+				return
+			}
+		}
+		if limit := r.FormValue("preview_limit"); limit != "" {
+			n, err := strconv.Atoi(limit)
+			if err != nil {
+				util.WriteErrResponse(w, http.StatusBadRequest, fmt.Errorf("invalid preview_limit: %s", err))
+				return
+			}
+			p.PreviewLimit = n
+		}
+
+		tfFile, _, err := r.FormFile("transform")
+		if err != nil && err != http.ErrMissingFile {
+			util.WriteErrResponse(w, http.StatusBadRequest, fmt.Errorf("error opening transform file: %s", err))
+			return
+		}
+		if tfFile != nil {
+			p.Transform = &dataset.Transform{}
+			if p.Transform.ScriptBytes, err = ioutil.ReadAll(tfFile); err != nil {
+				util.WriteErrResponse(w, http.StatusBadRequest, err)
+				return
+			}
+		}
+	}
+
+	if strings.HasPrefix(r.URL.Path, "/apply/") {
+		args, err := DatasetRefFromPath(r.URL.Path[len("/apply/"):])
+		if err != nil && err != repo.ErrEmptyRef {
+			util.WriteErrResponse(w, http.StatusBadRequest, err)
+			return
+		}
+		if args.Name != "" {
+			p.Ref = args.AliasString()
+		}
+	}
+	// scripts must be sent with the request, don't read files on the server
+	p.ScriptPath = ""
+
+	res := &lib.ApplyResult{}
+	if err := h.Apply(p, res); err != nil {
+		util.WriteErrResponse(w, http.StatusInternalServerError, err)
+		return
+	}
+	util.WriteResponse(w, res)
+}
+
 func (h *DatasetHandlers) peerListHandler(w http.ResponseWriter, r *http.Request) {
 	log.Info(r.URL.Path)
 	p := lib.ListParamsFromRequest(r)
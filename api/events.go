@@ -0,0 +1,145 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	util "github.com/qri-io/apiutil"
+	"github.com/qri-io/qri/event"
+	"nhooyr.io/websocket"
+	"nhooyr.io/websocket/wsjson"
+)
+
+// EventsHandler streams events from the instance event bus to clients. Clients
+// that request a websocket upgrade receive events as JSON websocket messages,
+// all other clients receive a stream of Server-Sent Events. Subscriptions can
+// be narrowed with one or more "topic" query params, either repeated or
+// comma-separated. No topic params subscribes to all events
+func (s Server) EventsHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "OPTIONS":
+		util.EmptyOkHandler(w, r)
+		return
+	case "GET":
+		// continue
+	default:
+		util.NotFoundHandler(w, r)
+		return
+	}
+
+	bus := s.Instance.Bus()
+	if bus == nil {
+		util.WriteErrResponse(w, http.StatusInternalServerError, fmt.Errorf("no event bus available"))
+		return
+	}
+
+	var events <-chan event.Event
+	if topics := topicsFromRequest(r); len(topics) > 0 {
+		events = bus.Subscribe(topics...)
+	} else {
+		events = bus.SubscribeAll()
+	}
+	defer bus.Unsubscribe(events)
+
+	if strings.EqualFold(r.Header.Get("Upgrade"), "websocket") {
+		s.serveEventsWebsocket(w, r, events)
+		return
+	}
+	serveEventsSSE(w, r, events)
+}
+
+func (s Server) serveEventsWebsocket(w http.ResponseWriter, r *http.Request, events <-chan event.Event) {
+	c, err := websocket.Accept(w, r, &websocket.AcceptOptions{
+		Subprotocols:       []string{qriWebsocketProtocol},
+		InsecureSkipVerify: s.originAllowed(r),
+	})
+	if err != nil {
+		log.Debugf("events websocket accept error: %s", err)
+		return
+	}
+	defer c.Close(websocket.StatusNormalClosure, "")
+
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	// clients aren't expected to send messages, but reading is required to
+	// process control frames & detect closed connections
+	go func() {
+		defer cancel()
+		for {
+			if _, _, err := c.Reader(ctx); err != nil {
+				return
+			}
+		}
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case e := <-events:
+			if err := wsjson.Write(ctx, c, e); err != nil {
+				log.Debugf("events websocket write error: %s", err)
+				return
+			}
+		}
+	}
+}
+
+func serveEventsSSE(w http.ResponseWriter, r *http.Request, events <-chan event.Event) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		util.WriteErrResponse(w, http.StatusInternalServerError, fmt.Errorf("streaming is not supported"))
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	ctx := r.Context()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case e := <-events:
+			data, err := json.Marshal(e)
+			if err != nil {
+				log.Debugf("encoding event %s: %s", e.Topic, err)
+				continue
+			}
+			if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", e.Topic, data); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+// originAllowed reports whether a request origin is in the list of configured
+// CORS origins
+func (s Server) originAllowed(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	for _, o := range s.Config().API.AllowedOrigins {
+		if origin == o {
+			return true
+		}
+	}
+	return false
+}
+
+func topicsFromRequest(r *http.Request) (topics []event.Topic) {
+	for _, v := range r.URL.Query()["topic"] {
+		for _, t := range strings.Split(v, ",") {
+			if t = strings.TrimSpace(t); t != "" {
+				topics = append(topics, event.Topic(t))
+			}
+		}
+	}
+	return topics
+}
@@ -0,0 +1,88 @@
+package api
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/qri-io/qri/dsref"
+	"github.com/qri-io/qri/event"
+)
+
+func TestEventsHandlerSSE(t *testing.T) {
+	node, teardown := newTestNode(t)
+	defer teardown()
+
+	inst := newTestInstanceWithProfileFromNode(node)
+	s := New(inst)
+
+	srv := httptest.NewServer(http.HandlerFunc(s.EventsHandler))
+	defer srv.Close()
+
+	res, err := http.Get(srv.URL + "/events?topic=" + string(event.ETDatasetSaveEvent))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+
+	if ct := res.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("expected content type text/event-stream, got %q", ct)
+	}
+
+	// publish until the subscription is registered & an event is received
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			case <-time.After(time.Millisecond * 10):
+				inst.Bus().Publish(event.ETDatasetRenameEvent, event.DatasetRenameEvent{})
+				inst.Bus().Publish(event.ETDatasetSaveEvent, event.DatasetSaveEvent{
+					Ref: dsref.Ref{Username: "peer", Name: "movies"},
+				})
+			}
+		}
+	}()
+
+	lines := make(chan string)
+	go func() {
+		sc := bufio.NewScanner(res.Body)
+		for sc.Scan() {
+			lines <- sc.Text()
+		}
+	}()
+
+	select {
+	case line := <-lines:
+		expect := "event: " + string(event.ETDatasetSaveEvent)
+		if line != expect {
+			t.Errorf("event line mismatch. expected: %q, got: %q", expect, line)
+		}
+	case <-time.After(time.Second * 2):
+		t.Fatal("timed out waiting for event")
+	}
+
+	line := <-lines
+	if !strings.HasPrefix(line, "data: ") || !strings.Contains(line, `"name":"movies"`) {
+		t.Errorf("unexpected data line: %q", line)
+	}
+}
+
+func TestTopicsFromRequest(t *testing.T) {
+	r := httptest.NewRequest("GET", "/events?topic=a,b&topic=c", nil)
+	got := topicsFromRequest(r)
+	expect := []event.Topic{"a", "b", "c"}
+	if len(got) != len(expect) {
+		t.Fatalf("length mismatch. expected: %v, got: %v", expect, got)
+	}
+	for i := range expect {
+		if got[i] != expect[i] {
+			t.Errorf("index %d mismatch. expected: %q, got: %q", i, expect[i], got[i])
+		}
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/qri-io/qri/base/component"
+	"github.com/qri-io/qri/config"
 	"github.com/qri-io/qri/event"
 	"github.com/qri-io/qri/p2p"
 	"github.com/qri-io/qri/watchfs"
@@ -16,19 +17,24 @@ import (
 	"nhooyr.io/websocket/wsjson"
 )
 
-const (
-	// TODO(dlong): Move to cfg
-	websocketPort        = 2506
-	qriWebsocketProtocol = "qri-websocket"
-)
+const qriWebsocketProtocol = "qri-websocket"
 
 // TODO(dlong): This file has a tight coupling between Websocket and Watchfs that makes sense
 // for now, as they're two pieces working together on the same task, but will start to make
 // less sense once more Websocket messages are being delivered, and as the event.Bus is used
 // more places. Reconsider in the future how to better integrate these two pieces.
 
-// ServeWebsocket creates a websocket that clients can connect to in order to get realtime events
+// ServeWebsocket creates a websocket that clients can connect to in order to get realtime
+// filesystem events. The listener port is set by cfg.API.WebsocketPort. Events from the
+// instance bus are served on the API port by EventsHandler
 func (s Server) ServeWebsocket(ctx context.Context) {
+	websocketPort := s.Config().API.WebsocketPort
+	if websocketPort < 0 {
+		return
+	} else if websocketPort == 0 {
+		websocketPort = config.DefaultWebsocketPort
+	}
+
 	// Watch the filesystem. Events will be sent to websocket connections.
 	node := s.Node()
 	fsmessages, err := s.startFilesysWatcher(ctx, node)
@@ -0,0 +1,397 @@
+// Package bundle reads & writes dataset bundles. A bundle is a single file
+// that holds everything needed to move a dataset and its history to another
+// repo without a network connection: a manifest, the signed logbook for the
+// dataset, and every block of each bundled version.
+//
+// Bundles are a sequence of sections, each prefixed with its length as a
+// uvarint. The first section is the JSON-encoded manifest, the second is the
+// log flatbuffer. Remaining sections are blocks, laid out CAR-style as the
+// uvarint-prefixed CID bytes of the block followed by block data
+package bundle
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"time"
+
+	cid "github.com/ipfs/go-cid"
+	ipld "github.com/ipfs/go-ipld-format"
+	golog "github.com/ipfs/go-log"
+	coreiface "github.com/ipfs/interface-go-ipfs-core"
+	"github.com/ipfs/interface-go-ipfs-core/options"
+	"github.com/ipfs/interface-go-ipfs-core/path"
+	crypto "github.com/libp2p/go-libp2p-core/crypto"
+	"github.com/qri-io/dag"
+	"github.com/qri-io/dag/dsync"
+	"github.com/qri-io/qri/dsref"
+	"github.com/qri-io/qri/identity"
+	"github.com/qri-io/qri/logbook"
+	"github.com/qri-io/qri/logbook/oplog"
+)
+
+var log = golog.Logger("bundle")
+
+const (
+	// FormatVersion is the version of the bundle format this package writes
+	FormatVersion = 1
+	// maxSectionSize caps the size of a single section read from a bundle
+	maxSectionSize = 1 << 28
+)
+
+// ErrInvalidBundle indicates data that isn't a readable bundle
+var ErrInvalidBundle = fmt.Errorf("invalid bundle")
+
+// Manifest describes the contents of a bundle
+type Manifest struct {
+	// FormatVersion is the version of the bundle format
+	FormatVersion int `json:"formatVersion"`
+	// Ref is the bundled dataset, Path is the latest bundled version
+	Ref dsref.Ref `json:"ref"`
+	// Versions lists the paths of all bundled versions, newest first
+	Versions []string `json:"versions"`
+	// Blocks is the number of blocks in the bundle
+	Blocks int `json:"blocks"`
+	// Size is the total size of all blocks in bytes
+	Size uint64 `json:"size"`
+	// AuthorPubKey is the base64-encoded public key of the log author. It
+	// identifies the author for the reader, importers verify log signatures
+	// against keys they already trust
+	AuthorPubKey string `json:"authorPubKey"`
+	// Created is the time the bundle was written
+	Created time.Time `json:"created"`
+}
+
+// Write creates a bundle for a dataset, writing the dataset log and every
+// block of the given versions to w. Logs are signed with the book's key when
+// the book wrote them, logs from other authors keep their signatures & keys
+// resolves the author's public key
+func Write(ctx context.Context, w io.Writer, book *logbook.Book, capi coreiface.CoreAPI, ref dsref.Ref, versions []string, keys logbook.KeyResolver) (*Manifest, error) {
+	if len(versions) == 0 {
+		return nil, fmt.Errorf("no versions to bundle")
+	}
+
+	lg, err := book.UserDatasetRef(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+	logData, err := book.LogBytes(lg)
+	if err != nil {
+		return nil, err
+	}
+	pub, err := authorPubKey(book, lg, keys)
+	if err != nil {
+		return nil, err
+	}
+	pubData, err := pub.Bytes()
+	if err != nil {
+		return nil, err
+	}
+
+	ng, err := dsync.NewLocalNodeGetter(capi)
+	if err != nil {
+		return nil, err
+	}
+
+	mf := &Manifest{
+		FormatVersion: FormatVersion,
+		Ref:           ref,
+		Versions:      versions,
+		AuthorPubKey:  base64.StdEncoding.EncodeToString(pubData),
+		Created:       time.Now().UTC(),
+	}
+	mf.Ref.Path = versions[0]
+
+	// collect the blocks of all versions, versions share most of their blocks
+	ids := []string{}
+	added := map[string]bool{}
+	for _, v := range versions {
+		id, err := cid.Parse(v)
+		if err != nil {
+			return nil, fmt.Errorf("parsing version path %q: %w", v, err)
+		}
+		info, err := dag.NewInfo(ctx, ng, id)
+		if err != nil {
+			return nil, fmt.Errorf("reading blocks of version %s: %w", v, err)
+		}
+		for i, node := range info.Manifest.Nodes {
+			if added[node] {
+				continue
+			}
+			added[node] = true
+			ids = append(ids, node)
+			if i < len(info.Sizes) {
+				mf.Size += info.Sizes[i]
+			}
+		}
+	}
+	mf.Blocks = len(ids)
+
+	header, err := json.Marshal(mf)
+	if err != nil {
+		return nil, err
+	}
+	if err := writeSection(w, header); err != nil {
+		return nil, err
+	}
+	if err := writeSection(w, logData); err != nil {
+		return nil, err
+	}
+
+	for _, id := range ids {
+		c, err := cid.Parse(id)
+		if err != nil {
+			return nil, err
+		}
+		r, err := capi.Block().Get(ctx, path.IpfsPath(c))
+		if err != nil {
+			return nil, fmt.Errorf("reading block %s: %w", id, err)
+		}
+		data, err := ioutil.ReadAll(r)
+		if err != nil {
+			return nil, err
+		}
+		if err := writeSection(w, blockSection(c, data)); err != nil {
+			return nil, err
+		}
+	}
+
+	return mf, nil
+}
+
+// authorPubKey finds the public key of the author of a log
+func authorPubKey(book *logbook.Book, lg *oplog.Log, keys logbook.KeyResolver) (crypto.PubKey, error) {
+	if len(lg.Ops) == 0 {
+		return nil, fmt.Errorf("log has no operations")
+	}
+	keyID := lg.Ops[0].AuthorID
+	if own, err := identity.KeyIDFromPub(book.AuthorPubKey()); err == nil && own == keyID {
+		return book.AuthorPubKey(), nil
+	}
+	if keys != nil {
+		if pub, err := keys(keyID); err == nil {
+			return pub, nil
+		}
+	}
+	return nil, fmt.Errorf("no public key for log author %q, bundles can't be verified without one", keyID)
+}
+
+// Import reads a bundle, verifying log signatures & block hashes. Logs are
+// verified against a key the book trusts for the log author: the book's own
+// key or one its key resolver provides, never a key carried in the bundle.
+// Blocks are added to the store and imported versions the log references are
+// pinned before the log is merged into the book, so history never references
+// missing data. The returned manifest describes what was imported: Ref is
+// read from the verified log & Versions lists the pinned versions, newest
+// first
+func Import(ctx context.Context, r io.Reader, book *logbook.Book, capi coreiface.CoreAPI) (*Manifest, error) {
+	br := bufio.NewReader(r)
+
+	header, err := readSection(br)
+	if err != nil {
+		return nil, fmt.Errorf("%w: reading manifest: %s", ErrInvalidBundle, err)
+	}
+	mf := &Manifest{}
+	if err := json.Unmarshal(header, mf); err != nil {
+		return nil, fmt.Errorf("%w: decoding manifest: %s", ErrInvalidBundle, err)
+	}
+	if mf.FormatVersion != FormatVersion {
+		return nil, fmt.Errorf("%w: unsupported format version %d", ErrInvalidBundle, mf.FormatVersion)
+	}
+
+	logData, err := readSection(br)
+	if err != nil {
+		return nil, fmt.Errorf("%w: reading log: %s", ErrInvalidBundle, err)
+	}
+	lg := &oplog.Log{}
+	if err := lg.UnmarshalFlatbufferBytes(logData); err != nil {
+		return nil, fmt.Errorf("%w: decoding log: %s", ErrInvalidBundle, err)
+	}
+
+	author, err := verifyLog(book, lg)
+	if err != nil {
+		return nil, err
+	}
+	ref, logVersions, err := logHistory(lg)
+	if err != nil {
+		return nil, err
+	}
+
+	imported := map[string]bool{}
+	for {
+		data, err := readSection(br)
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("%w: reading block: %s", ErrInvalidBundle, err)
+		}
+		id, err := putBlock(ctx, capi, data)
+		if err != nil {
+			return nil, err
+		}
+		imported[id.String()] = true
+	}
+	if len(imported) != mf.Blocks {
+		return nil, fmt.Errorf("%w: expected %d blocks, bundle has %d", ErrInvalidBundle, mf.Blocks, len(imported))
+	}
+	log.Debugf("imported %d blocks", len(imported))
+
+	ng, err := dsync.NewLocalNodeGetter(capi)
+	if err != nil {
+		return nil, err
+	}
+	versions := []string{}
+	for _, v := range logVersions {
+		if !importedVersion(ctx, ng, imported, v) {
+			continue
+		}
+		if err := capi.Pin().Add(ctx, path.New(v)); err != nil {
+			return nil, fmt.Errorf("pinning version %s: %w", v, err)
+		}
+		versions = append(versions, v)
+	}
+	if len(versions) == 0 {
+		return nil, fmt.Errorf("%w: bundle has no complete versions of %s", ErrInvalidBundle, ref.Alias())
+	}
+
+	if err := book.MergeLog(ctx, author, lg); err != nil {
+		return nil, err
+	}
+
+	mf.Ref = ref
+	mf.Ref.Path = versions[0]
+	mf.Versions = versions
+	return mf, nil
+}
+
+// verifyLog checks log signatures against the key book trusts for the log's
+// author
+func verifyLog(book *logbook.Book, lg *oplog.Log) (identity.Author, error) {
+	if len(lg.Ops) == 0 {
+		return nil, fmt.Errorf("%w: log has no operations", ErrInvalidBundle)
+	}
+	keyID := lg.Ops[0].AuthorID
+	pub, err := book.AuthorKey(keyID)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := func(id string) (crypto.PubKey, error) {
+		if id == keyID {
+			return pub, nil
+		}
+		return nil, logbook.ErrNotFound
+	}
+	if err := logbook.VerifyLog(lg, keys).Err(); err != nil {
+		return nil, err
+	}
+	return identity.NewAuthor(keyID, pub), nil
+}
+
+// logHistory reads the dataset reference & version paths of the default
+// branch from a verified log, newest first
+func logHistory(lg *oplog.Log) (dsref.Ref, []string, error) {
+	ref, err := logbook.DsrefAliasForLog(lg)
+	if err != nil {
+		return ref, nil, fmt.Errorf("%w: %s", ErrInvalidBundle, err)
+	}
+	dsLog := lg.Logs[0]
+	if len(dsLog.Logs) == 0 {
+		return ref, nil, fmt.Errorf("%w: log has no history", ErrInvalidBundle)
+	}
+	items := logbook.ConvertLogsToItems(dsLog.Logs[0], ref)
+	versions := make([]string, len(items))
+	for i, item := range items {
+		versions[i] = item.Path
+	}
+	return ref, versions, nil
+}
+
+// importedVersion returns true if the root block of version v was imported &
+// every block of the version is stored locally
+func importedVersion(ctx context.Context, ng ipld.NodeGetter, imported map[string]bool, v string) bool {
+	id, err := cid.Parse(v)
+	if err != nil || !imported[id.String()] {
+		return false
+	}
+	_, err = dag.NewManifest(ctx, ng, id)
+	return err == nil
+}
+
+// putBlock adds a block section to the store, checking the block hashes to
+// the CID it's stored with
+func putBlock(ctx context.Context, capi coreiface.CoreAPI, section []byte) (cid.Cid, error) {
+	size, n := binary.Uvarint(section)
+	if n <= 0 || uint64(len(section)-n) < size {
+		return cid.Undef, fmt.Errorf("%w: malformed block section", ErrInvalidBundle)
+	}
+	id, err := cid.Cast(section[n : n+int(size)])
+	if err != nil {
+		return cid.Undef, fmt.Errorf("%w: decoding block CID: %s", ErrInvalidBundle, err)
+	}
+	data := section[n+int(size):]
+
+	opts := []options.BlockPutOption{}
+	if pre := id.Prefix(); pre.Version != 0 {
+		opts = append(opts,
+			options.Block.Format(cid.CodecToStr[pre.Codec]),
+			options.Block.Hash(pre.MhType, pre.MhLength),
+		)
+	}
+
+	stat, err := capi.Block().Put(ctx, bytes.NewReader(data), opts...)
+	if err != nil {
+		return cid.Undef, fmt.Errorf("storing block %s: %w", id, err)
+	}
+	if !stat.Path().Cid().Equals(id) {
+		return cid.Undef, fmt.Errorf("%w: block %s doesn't match its hash", ErrInvalidBundle, id)
+	}
+	return id, nil
+}
+
+// blockSection lays out a block as its length-prefixed CID & data
+func blockSection(id cid.Cid, data []byte) []byte {
+	idBytes := id.Bytes()
+	prefix := make([]byte, binary.MaxVarintLen64)
+	n := binary.PutUvarint(prefix, uint64(len(idBytes)))
+	section := make([]byte, 0, n+len(idBytes)+len(data))
+	section = append(section, prefix[:n]...)
+	section = append(section, idBytes...)
+	return append(section, data...)
+}
+
+func writeSection(w io.Writer, data []byte) error {
+	prefix := make([]byte, binary.MaxVarintLen64)
+	n := binary.PutUvarint(prefix, uint64(len(data)))
+	if _, err := w.Write(prefix[:n]); err != nil {
+		return err
+	}
+	_, err := w.Write(data)
+	return err
+}
+
+// readSection reads a single section, returning io.EOF when no sections are
+// left
+func readSection(br *bufio.Reader) ([]byte, error) {
+	size, err := binary.ReadUvarint(br)
+	if err != nil {
+		return nil, err
+	}
+	if size > maxSectionSize {
+		return nil, fmt.Errorf("section of %d bytes exceeds maximum size", size)
+	}
+	data := make([]byte, size)
+	if _, err := io.ReadFull(br, data); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	return data, nil
+}
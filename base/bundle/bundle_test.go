@@ -0,0 +1,201 @@
+package bundle
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	datastore "github.com/ipfs/go-datastore"
+	crypto "github.com/libp2p/go-libp2p-core/crypto"
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/qfs"
+	ipfs "github.com/qri-io/qfs/cafs/ipfs"
+	"github.com/qri-io/qri/base/dsfs"
+	"github.com/qri-io/qri/config"
+	cfgtest "github.com/qri-io/qri/config/test"
+	"github.com/qri-io/qri/dsref"
+	"github.com/qri-io/qri/logbook"
+	"github.com/qri-io/qri/repo/storage"
+)
+
+func TestWriteImport(t *testing.T) {
+	ctx := context.Background()
+	tr := newTestRunner(ctx, t)
+
+	ref, versions := tr.writeHistory(ctx, t)
+
+	buf := &bytes.Buffer{}
+	mf, err := Write(ctx, buf, tr.ABook, tr.AStore.IPFSCoreAPI(), ref, versions, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mf.Ref.Path != versions[0] {
+		t.Errorf("expected manifest ref path to be the latest version. want: %s got: %s", versions[0], mf.Ref.Path)
+	}
+	if mf.Blocks == 0 || mf.Size == 0 {
+		t.Errorf("expected manifest to count blocks & size. got: %d blocks, %d bytes", mf.Blocks, mf.Size)
+	}
+	data := buf.Bytes()
+
+	// B can't verify logs from an author it has no key for, the key in the
+	// manifest isn't trusted
+	if _, err := Import(ctx, bytes.NewReader(data), tr.BBook, tr.BStore.IPFSCoreAPI()); !errors.Is(err, logbook.ErrUnverifiedAuthor) {
+		t.Errorf("expected bundle from an unknown author to return ErrUnverifiedAuthor. got: %v", err)
+	}
+	tr.trustA()
+
+	// a corrupted block is rejected before any logs are merged
+	tampered := append([]byte{}, data...)
+	tampered[len(tampered)-1] ^= 0xff
+	if _, err := Import(ctx, bytes.NewReader(tampered), tr.BBook, tr.BStore.IPFSCoreAPI()); !errors.Is(err, ErrInvalidBundle) {
+		t.Errorf("expected tampered block to return ErrInvalidBundle. got: %v", err)
+	}
+	if _, err := tr.BBook.Items(ctx, ref, 0, -1); err == nil {
+		t.Errorf("expected failed import to leave the logbook untouched")
+	}
+
+	if _, err := Import(ctx, bytes.NewReader([]byte("not a bundle")), tr.BBook, tr.BStore.IPFSCoreAPI()); !errors.Is(err, ErrInvalidBundle) {
+		t.Errorf("expected invalid data to return ErrInvalidBundle. got: %v", err)
+	}
+	if _, err := Import(ctx, bytes.NewReader(data[:len(data)/2]), tr.BBook, tr.BStore.IPFSCoreAPI()); !errors.Is(err, ErrInvalidBundle) {
+		t.Errorf("expected truncated bundle to return ErrInvalidBundle. got: %v", err)
+	}
+
+	got, err := Import(ctx, bytes.NewReader(data), tr.BBook, tr.BStore.IPFSCoreAPI())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Blocks != mf.Blocks {
+		t.Errorf("block count mismatch. want: %d got: %d", mf.Blocks, got.Blocks)
+	}
+	if got.Ref.Alias() != ref.Alias() || got.Ref.Path != versions[0] {
+		t.Errorf("expected imported ref to come from the log. want: %s@%s got: %s@%s", ref.Alias(), versions[0], got.Ref.Alias(), got.Ref.Path)
+	}
+	if len(got.Versions) != len(versions) {
+		t.Errorf("expected %d pinned versions. got: %v", len(versions), got.Versions)
+	}
+
+	for _, v := range versions {
+		if _, err := dsfs.LoadDataset(ctx, tr.BStore, v); err != nil {
+			t.Errorf("loading imported version %s: %s", v, err)
+		}
+	}
+
+	expect, err := tr.ABook.Items(ctx, ref, 0, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	items, err := tr.BBook.Items(ctx, ref, 0, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != len(expect) {
+		t.Errorf("expected imported history to have %d versions. got: %d", len(expect), len(items))
+	}
+}
+
+func TestWriteErrors(t *testing.T) {
+	ctx := context.Background()
+	tr := newTestRunner(ctx, t)
+	ref, versions := tr.writeHistory(ctx, t)
+
+	if _, err := Write(ctx, &bytes.Buffer{}, tr.ABook, tr.AStore.IPFSCoreAPI(), ref, nil, nil); err == nil {
+		t.Error("expected bundling no versions to error")
+	}
+
+	// B has A's log, but no key to verify it with
+	tr.trustA()
+	buf := &bytes.Buffer{}
+	if _, err := Write(ctx, buf, tr.ABook, tr.AStore.IPFSCoreAPI(), ref, versions, nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Import(ctx, buf, tr.BBook, tr.BStore.IPFSCoreAPI()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Write(ctx, &bytes.Buffer{}, tr.BBook, tr.BStore.IPFSCoreAPI(), ref, versions, nil); err == nil {
+		t.Error("expected bundling a log without the author's key to error")
+	}
+	keys := func(string) (crypto.PubKey, error) { return tr.ABook.AuthorPubKey(), nil }
+	if _, err := Write(ctx, &bytes.Buffer{}, tr.BBook, tr.BStore.IPFSCoreAPI(), ref, versions, keys); err != nil {
+		t.Errorf("expected bundling with a resolved author key to succeed. got: %s", err)
+	}
+}
+
+type testRunner struct {
+	ABook, BBook   *logbook.Book
+	AStore, BStore *ipfs.Filestore
+}
+
+func newTestRunner(ctx context.Context, t *testing.T) *testRunner {
+	tr := &testRunner{}
+	var err error
+	if tr.AStore, err = storage.NewFilestore(ctx, datastore.NewMapDatastore(), testP2P(0)); err != nil {
+		t.Fatal(err)
+	}
+	if tr.BStore, err = storage.NewFilestore(ctx, datastore.NewMapDatastore(), testP2P(1)); err != nil {
+		t.Fatal(err)
+	}
+	if tr.ABook, err = logbook.NewJournal(cfgtest.GetTestPeerInfo(0).PrivKey, "peer_a", qfs.NewMemFS(), "/mem/logbook"); err != nil {
+		t.Fatal(err)
+	}
+	if tr.BBook, err = logbook.NewJournal(cfgtest.GetTestPeerInfo(1).PrivKey, "peer_b", qfs.NewMemFS(), "/mem/logbook"); err != nil {
+		t.Fatal(err)
+	}
+	return tr
+}
+
+// trustA lets B resolve A's public key
+func (tr *testRunner) trustA() {
+	tr.BBook.SetKeyResolver(func(string) (crypto.PubKey, error) { return tr.ABook.AuthorPubKey(), nil })
+}
+
+// writeHistory saves two versions of a dataset to A, returning version paths
+// newest first
+func (tr *testRunner) writeHistory(ctx context.Context, t *testing.T) (dsref.Ref, []string) {
+	ref := dsref.Ref{Username: "peer_a", Name: "numbers"}
+	initID, err := tr.ABook.WriteDatasetInit(ctx, ref.Name)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var prev *dataset.Dataset
+	versions := []string{}
+	for i, body := range []string{`[1,2,3]`, `[1,2,3,4]`} {
+		ds := &dataset.Dataset{
+			Commit: &dataset.Commit{Title: "commit", Timestamp: time.Date(2001, 1, i+1, 0, 0, 0, 0, time.UTC)},
+			Meta:   &dataset.Meta{Title: "numbers"},
+			Structure: &dataset.Structure{
+				Format: "json",
+				Schema: dataset.BaseSchemaArray,
+			},
+		}
+		ds.SetBodyFile(qfs.NewMemfileBytes("body.json", []byte(body)))
+		if prev != nil {
+			ds.PreviousPath = prev.Path
+		}
+
+		path, err := dsfs.CreateDataset(ctx, tr.AStore, ds, prev, cfgtest.GetTestPeerInfo(0).PrivKey, dsfs.SaveSwitches{Pin: true, ForceIfNoChanges: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if prev, err = dsfs.LoadDataset(ctx, tr.AStore, path); err != nil {
+			t.Fatal(err)
+		}
+		prev.Path = path
+		if err := tr.ABook.WriteVersionSave(ctx, initID, prev); err != nil {
+			t.Fatal(err)
+		}
+		versions = append([]string{path}, versions...)
+	}
+	return ref, versions
+}
+
+func testP2P(i int) *config.P2P {
+	pi := cfgtest.GetTestPeerInfo(i)
+	cfg := config.DefaultP2PForTesting()
+	cfg.PeerID = pi.PeerID.Pretty()
+	cfg.PrivKey = pi.EncodedPrivKey
+	return cfg
+}
@@ -58,7 +58,7 @@ func ConvertDatasetToComponents(ds *dataset.Dataset, qfilesys qfs.Filesystem) Co
 	}
 	if ds.Transform != nil {
 		dc.Subcomponents["transform"] = &TransformComponent{
-			BaseComponent: BaseComponent{Format: "star"},
+			BaseComponent: BaseComponent{Format: TransformFormat(ds.Transform)},
 			Resolver:      qfilesys,
 			Value:         ds.Transform,
 		}
@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/qri-io/dataset"
+	"github.com/qri-io/qfs"
 )
 
 func TestConvertDatasetToComponents(t *testing.T) {
@@ -76,3 +77,24 @@ func TestToDataset(t *testing.T) {
 		t.Errorf("expected commit.message \"%s\", got \"%s\"", "test", ds.Commit.Message)
 	}
 }
+
+func TestTransformFormat(t *testing.T) {
+	sqlScript := &dataset.Transform{}
+	sqlScript.SetScriptFile(qfs.NewMemfileBytes("transform.sql", []byte("SELECT 1 AS one")))
+
+	cases := []struct {
+		tf     *dataset.Transform
+		expect string
+	}{
+		{nil, "star"},
+		{&dataset.Transform{Syntax: "starlark"}, "star"},
+		{&dataset.Transform{Syntax: "sql"}, "sql"},
+		{&dataset.Transform{ScriptPath: "/path/to/transform.sql"}, "sql"},
+		{sqlScript, "sql"},
+	}
+	for i, c := range cases {
+		if got := TransformFormat(c.tf); got != c.expect {
+			t.Errorf("case %d: expected %q. got: %q", i, c.expect, got)
+		}
+	}
+}
@@ -662,6 +662,34 @@ type TransformComponent struct {
 	Value    *dataset.Transform
 }
 
+// sqlSyntax is the syntax of transforms written in SQL. it matches
+// startf.SQLSyntax, which component can't import without depending on the
+// transform runtime
+const sqlSyntax = "sql"
+
+// TransformFormat returns the file format of a transform script, "sql" for
+// transforms written in SQL, "star" for starlark. Transforms without a syntax
+// are SQL if their script file name ends in .sql
+func TransformFormat(tf *dataset.Transform) string {
+	if tf == nil {
+		return "star"
+	}
+	if tf.Syntax != "" {
+		if tf.Syntax == sqlSyntax {
+			return sqlSyntax
+		}
+		return "star"
+	}
+	name := tf.ScriptPath
+	if f := tf.ScriptFile(); f != nil {
+		name = f.FileName()
+	}
+	if filepath.Ext(name) == "."+sqlSyntax {
+		return sqlSyntax
+	}
+	return "star"
+}
+
 // Compare compares to another component
 func (tc *TransformComponent) Compare(compare Component) (bool, error) {
 	other, ok := compare.(*TransformComponent)
@@ -724,6 +752,9 @@ func (tc *TransformComponent) LoadAndFill(ds *dataset.Dataset) error {
 		if err := fill.Struct(fields, tc.Value); err != nil {
 			return err
 		}
+		if tc.Format == sqlSyntax {
+			tc.Value.Syntax = sqlSyntax
+		}
 	}
 	tc.Base().IsLoaded = true
 
@@ -781,7 +812,7 @@ func (bc *BaseComponent) LoadFile() (map[string]interface{}, error) {
 			return nil, err
 		}
 		return fields, nil
-	case "html", "md", "star":
+	case "html", "md", "star", "sql":
 		fields["ScriptBytes"] = data
 		return fields, nil
 	}
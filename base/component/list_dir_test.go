@@ -2,10 +2,14 @@ package component
 
 import (
 	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"sort"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/qri-io/dataset"
 )
 
 func TestListDirectoryComponents(t *testing.T) {
@@ -66,6 +70,7 @@ func TestIsKnownFilename(t *testing.T) {
 		{"meta json file", "meta.json"},
 		{"readme file", "readme.md"},
 		{"transform file", "transform.star"},
+		{"sql transform file", "transform.sql"},
 		{"dataset json", "dataset.json"},
 		{"dataset yaml", "dataset.yaml"},
 	}
@@ -104,3 +109,46 @@ func getComponentNames(comp Component) []string {
 	sort.Strings(names)
 	return names
 }
+
+func TestSQLTransformComponent(t *testing.T) {
+	dir, err := ioutil.TempDir("", "sql_transform_component")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	query := "SELECT * FROM me/movies AS m\n"
+	tc := &TransformComponent{
+		BaseComponent: BaseComponent{Format: TransformFormat(&dataset.Transform{Syntax: "sql"})},
+		Value:         &dataset.Transform{Syntax: "sql", ScriptBytes: []byte(query)},
+	}
+	tc.IsLoaded = true
+	if _, err := tc.WriteTo(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	components, err := ListDirectoryComponents(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	read, ok := components.Base().GetSubcomponent("transform").(*TransformComponent)
+	if !ok {
+		t.Fatalf("expected transform.sql to be read as a transform component")
+	}
+	if err := read.LoadAndFill(nil); err != nil {
+		t.Fatal(err)
+	}
+	if read.Value.Syntax != "sql" {
+		t.Errorf("expected sql syntax. got: %q", read.Value.Syntax)
+	}
+	if diff := cmp.Diff(query, string(read.Value.ScriptBytes)); diff != "" {
+		t.Errorf("script (-want +got):\n%s", diff)
+	}
+
+	if err := tc.RemoveFrom(dir); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "transform.sql")); !os.IsNotExist(err) {
+		t.Errorf("expected transform.sql to be removed. got: %v", err)
+	}
+}
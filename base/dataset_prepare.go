@@ -14,6 +14,7 @@ import (
 	"github.com/qri-io/qfs"
 	"github.com/qri-io/qri/base/dsfs"
 	"github.com/qri-io/qri/dsref"
+	"github.com/qri-io/qri/logbook"
 	"github.com/qri-io/qri/repo"
 	"github.com/qri-io/qri/repo/profile"
 	reporef "github.com/qri-io/qri/repo/ref"
@@ -36,7 +37,33 @@ func PrepareHeadDatasetVersion(ctx context.Context, r repo.Repo, peername, name
 
 	currPath = lookup.Path
 	log.Debugf("loading currPath: %s. lookup result: %v", currPath, lookup)
+	curr, mutable, err = loadHeadDatasetVersion(ctx, r, currPath)
+	return
+}
+
+// PrepareBranchHeadDatasetVersion is PrepareHeadDatasetVersion for a named branch of dataset
+// history. Branch heads are read from logbook, an empty branch name or the default branch
+// prepares the head of the dataset
+func PrepareBranchHeadDatasetVersion(ctx context.Context, r repo.Repo, peername, name, branch string) (curr, mutable *dataset.Dataset, currPath string, err error) {
+	if branch == "" || branch == logbook.DefaultBranchName {
+		return PrepareHeadDatasetVersion(ctx, r, peername, name)
+	}
+
+	items, err := r.Logbook().Items(ctx, dsref.Ref{Username: peername, Name: name, Branch: branch}, 0, 1)
+	if err != nil {
+		return nil, nil, "", err
+	}
+	if len(items) == 0 {
+		return &dataset.Dataset{}, &dataset.Dataset{}, "", nil
+	}
+
+	currPath = items[0].Path
+	log.Debugf("loading currPath: %s from branch: %s", currPath, branch)
+	curr, mutable, err = loadHeadDatasetVersion(ctx, r, currPath)
+	return
+}
 
+func loadHeadDatasetVersion(ctx context.Context, r repo.Repo, currPath string) (curr, mutable *dataset.Dataset, err error) {
 	if curr, err = dsfs.LoadDataset(ctx, r.Store(), currPath); err != nil {
 		return
 	}
@@ -0,0 +1,146 @@
+package base
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/qri/base/dsfs"
+	"github.com/qri-io/qri/repo"
+)
+
+// ErrDependencyCycle indicates datasets that depend on each other, which
+// can't be ordered for re-running
+var ErrDependencyCycle = fmt.Errorf("dependency cycle")
+
+// Dependency is a dataset version loaded by a transform
+type Dependency struct {
+	// Ref is the "peername/name" alias of the dataset
+	Ref string `json:"ref"`
+	// Path is the version that was loaded
+	Path string `json:"path"`
+}
+
+// DependencyGraph records the datasets each local dataset's transform loaded
+// when its head version was created
+type DependencyGraph struct {
+	// Heads maps dataset alias to head version path
+	Heads map[string]string
+	// Upstream maps dataset alias to the dependencies of its head version
+	Upstream map[string][]Dependency
+}
+
+// TransformDependencies lists the datasets a transform loaded, read from
+// transform resources
+func TransformDependencies(tf *dataset.Transform) []Dependency {
+	if tf == nil {
+		return nil
+	}
+	deps := []Dependency{}
+	for path, res := range tf.Resources {
+		ref, err := repo.ParseDatasetRef(res.Path)
+		if err != nil {
+			log.Debugf("parsing transform resource %q: %s", res.Path, err)
+			continue
+		}
+		if ref.Path == "" {
+			ref.Path = path
+		}
+		deps = append(deps, Dependency{Ref: ref.AliasString(), Path: ref.Path})
+	}
+	sort.Slice(deps, func(i, j int) bool { return deps[i].Ref < deps[j].Ref })
+	return deps
+}
+
+// LoadDependencyGraph builds the dependency graph of all datasets in a repo
+func LoadDependencyGraph(ctx context.Context, r repo.Repo) (*DependencyGraph, error) {
+	num, err := r.RefCount()
+	if err != nil {
+		return nil, err
+	}
+	refs, err := r.References(0, num)
+	if err != nil {
+		return nil, fmt.Errorf("error getting dataset list: %s", err.Error())
+	}
+
+	g := &DependencyGraph{
+		Heads:    map[string]string{},
+		Upstream: map[string][]Dependency{},
+	}
+	for _, ref := range refs {
+		if ref.Path == "" {
+			continue
+		}
+		alias := ref.AliasString()
+		g.Heads[alias] = ref.Path
+
+		ds, err := dsfs.LoadDataset(ctx, r.Store(), ref.Path)
+		if err != nil {
+			log.Debugf("loading %s: %s", alias, err)
+			continue
+		}
+		if deps := TransformDependencies(ds.Transform); len(deps) > 0 {
+			g.Upstream[alias] = deps
+		}
+	}
+	return g, nil
+}
+
+// Downstream lists the datasets whose head version loaded ref
+func (g *DependencyGraph) Downstream(ref string) []Dependency {
+	deps := []Dependency{}
+	for alias, up := range g.Upstream {
+		for _, d := range up {
+			if d.Ref == ref {
+				deps = append(deps, Dependency{Ref: alias, Path: g.Heads[alias]})
+				break
+			}
+		}
+	}
+	sort.Slice(deps, func(i, j int) bool { return deps[i].Ref < deps[j].Ref })
+	return deps
+}
+
+// CascadeOrder lists every dataset downstream of ref, directly or through
+// other datasets, ordered so each dataset comes after all of its upstream
+// dependencies
+func (g *DependencyGraph) CascadeOrder(ref string) ([]string, error) {
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	state := map[string]int{}
+	order := []string{}
+
+	// depth-first search of downstream datasets, appending a dataset once all
+	// of its downstream datasets are added produces reverse topological order
+	var visit func(alias string) error
+	visit = func(alias string) error {
+		switch state[alias] {
+		case visiting:
+			return fmt.Errorf("%w involving %s", ErrDependencyCycle, alias)
+		case visited:
+			return nil
+		}
+		state[alias] = visiting
+		for _, d := range g.Downstream(alias) {
+			if err := visit(d.Ref); err != nil {
+				return err
+			}
+		}
+		state[alias] = visited
+		order = append(order, alias)
+		return nil
+	}
+	if err := visit(ref); err != nil {
+		return nil, err
+	}
+
+	// reverse, dropping ref itself from the end
+	cascade := make([]string, 0, len(order)-1)
+	for i := len(order) - 2; i >= 0; i-- {
+		cascade = append(cascade, order[i])
+	}
+	return cascade, nil
+}
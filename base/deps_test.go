@@ -0,0 +1,82 @@
+package base
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/qri-io/dataset"
+)
+
+func TestTransformDependencies(t *testing.T) {
+	tf := &dataset.Transform{
+		Resources: map[string]*dataset.TransformResource{
+			"/map/QmMovies": {Path: "peer/movies@QmProfile/map/QmMovies"},
+			"/map/QmCities": {Path: "peer/cities@QmProfile/map/QmCities"},
+		},
+	}
+	expect := []Dependency{
+		{Ref: "peer/cities", Path: "/map/QmCities"},
+		{Ref: "peer/movies", Path: "/map/QmMovies"},
+	}
+	if diff := cmp.Diff(expect, TransformDependencies(tf)); diff != "" {
+		t.Errorf("result mismatch (-want +got):\n%s", diff)
+	}
+	if deps := TransformDependencies(nil); deps != nil {
+		t.Errorf("expected nil transform to have no dependencies. got: %v", deps)
+	}
+}
+
+func TestCascadeOrder(t *testing.T) {
+	// a -> b -> d
+	// a -> c -> d
+	// c -> e
+	g := &DependencyGraph{
+		Heads: map[string]string{
+			"peer/a": "/map/a", "peer/b": "/map/b", "peer/c": "/map/c",
+			"peer/d": "/map/d", "peer/e": "/map/e",
+		},
+		Upstream: map[string][]Dependency{
+			"peer/b": {{Ref: "peer/a", Path: "/map/a"}},
+			"peer/c": {{Ref: "peer/a", Path: "/map/a"}},
+			"peer/d": {{Ref: "peer/b", Path: "/map/b"}, {Ref: "peer/c", Path: "/map/c"}},
+			"peer/e": {{Ref: "peer/c", Path: "/map/c"}},
+		},
+	}
+
+	expect := []Dependency{{Ref: "peer/b", Path: "/map/b"}, {Ref: "peer/c", Path: "/map/c"}}
+	if diff := cmp.Diff(expect, g.Downstream("peer/a")); diff != "" {
+		t.Errorf("downstream mismatch (-want +got):\n%s", diff)
+	}
+
+	order, err := g.CascadeOrder("peer/a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(order) != 4 {
+		t.Fatalf("expected 4 downstream datasets. got: %v", order)
+	}
+	pos := map[string]int{}
+	for i, ref := range order {
+		pos[ref] = i
+	}
+	for ds, deps := range g.Upstream {
+		for _, d := range deps {
+			if d.Ref == "peer/a" {
+				continue
+			}
+			if pos[d.Ref] > pos[ds] {
+				t.Errorf("expected %s to come before %s. got: %v", d.Ref, ds, order)
+			}
+		}
+	}
+
+	if order, err = g.CascadeOrder("peer/e"); err != nil || len(order) != 0 {
+		t.Errorf("expected no downstream datasets of peer/e. got: %v, %v", order, err)
+	}
+
+	g.Upstream["peer/a"] = []Dependency{{Ref: "peer/d", Path: "/map/d"}}
+	if _, err = g.CascadeOrder("peer/a"); !errors.Is(err, ErrDependencyCycle) {
+		t.Errorf("expected cycle error. got: %v", err)
+	}
+}
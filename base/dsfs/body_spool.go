@@ -0,0 +1,110 @@
+package dsfs
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"github.com/qri-io/qfs"
+)
+
+// bodySpool accumulates body bytes in memory up to a limit, spilling to a temp
+// file past it, so large bodies aren't held in memory while being saved
+type bodySpool struct {
+	limit int
+	buf   bytes.Buffer
+	file  *os.File
+	size  int
+}
+
+func newBodySpool(limit int) *bodySpool {
+	return &bodySpool{limit: limit}
+}
+
+// Write implements the io.Writer interface
+func (s *bodySpool) Write(p []byte) (int, error) {
+	if s.file == nil && s.buf.Len()+len(p) >= s.limit {
+		f, err := ioutil.TempFile("", "qri_body_spool")
+		if err != nil {
+			return 0, err
+		}
+		s.file = f
+		if _, err := s.buf.WriteTo(f); err != nil {
+			return 0, err
+		}
+	}
+
+	var (
+		n   int
+		err error
+	)
+	if s.file != nil {
+		n, err = s.file.Write(p)
+	} else {
+		n, err = s.buf.Write(p)
+	}
+	s.size += n
+	return n, err
+}
+
+// Len is the number of bytes written
+func (s *bodySpool) Len() int {
+	return s.size
+}
+
+// Spilled returns true if the spool has moved its contents to disk
+func (s *bodySpool) Spilled() bool {
+	return s.file != nil
+}
+
+// Bytes returns spooled bytes, only valid if the spool hasn't spilled to disk
+func (s *bodySpool) Bytes() []byte {
+	return s.buf.Bytes()
+}
+
+// File returns the spooled contents as a file. spilled temp files are removed
+// once read to the end or closed
+func (s *bodySpool) File(path string) (qfs.File, error) {
+	if s.file == nil {
+		return qfs.NewMemfileBytes(path, s.buf.Bytes()), nil
+	}
+	if _, err := s.file.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	return qfs.NewMemfileReader(path, &spoolReader{file: s.file}), nil
+}
+
+// Remove drops any spilled temp file
+func (s *bodySpool) Remove() {
+	if s.file != nil {
+		s.file.Close()
+		os.Remove(s.file.Name())
+	}
+}
+
+// spoolReader reads a temp file, removing it at EOF or when closed
+type spoolReader struct {
+	file *os.File
+	done bool
+}
+
+func (r *spoolReader) Read(p []byte) (int, error) {
+	if r.done {
+		return 0, io.EOF
+	}
+	n, err := r.file.Read(p)
+	if err == io.EOF {
+		r.Close()
+	}
+	return n, err
+}
+
+func (r *spoolReader) Close() error {
+	if r.done {
+		return nil
+	}
+	r.done = true
+	r.file.Close()
+	return os.Remove(r.file.Name())
+}
@@ -0,0 +1,45 @@
+package dsfs
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestBodySpool(t *testing.T) {
+	s := newBodySpool(10)
+	if _, err := s.Write([]byte("12345")); err != nil {
+		t.Fatal(err)
+	}
+	if s.Spilled() {
+		t.Fatal("expected spool under limit to stay in memory")
+	}
+	if _, err := s.Write([]byte("6789012345")); err != nil {
+		t.Fatal(err)
+	}
+	if !s.Spilled() {
+		t.Fatal("expected spool over limit to spill to disk")
+	}
+	if s.Len() != 15 {
+		t.Errorf("length mismatch. expected: 15, got: %d", s.Len())
+	}
+
+	name := s.file.Name()
+	f, err := s.File("body.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := ioutil.ReadAll(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "123456789012345" {
+		t.Errorf("data mismatch. got: %q", string(data))
+	}
+	if _, err := os.Stat(name); !os.IsNotExist(err) {
+		t.Errorf("expected temp file to be removed after reading. got: %v", err)
+	}
+	if err := f.Close(); err != nil {
+		t.Errorf("expected closing a read file not to error. got: %s", err)
+	}
+}
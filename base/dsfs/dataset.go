@@ -1,8 +1,8 @@
 package dsfs
 
 import (
-	"bytes"
 	"context"
+	"crypto/sha256"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
@@ -38,6 +38,9 @@ var (
 	// files unresolved.
 	// TODO (b5) - allow -1 duration as a sentinel value for no timeout
 	OpenFileTimeoutDuration = time.Millisecond * 700
+	// ErrNoChanges is returned when saving a dataset that is identical to its
+	// previous version without forcing the save
+	ErrNoChanges = fmt.Errorf("no changes")
 )
 
 // If a user has a dataset larger than the above limit, then instead of diffing we compare the
@@ -241,6 +244,9 @@ type SaveSwitches struct {
 	FileHint string
 	// Drop is a string of components to remove before saving
 	Drop string
+	// Branch is the name of the branch of dataset history to save to. An empty
+	// string saves to the default branch
+	Branch string
 }
 
 // CreateDataset places a dataset into the store.
@@ -278,6 +284,9 @@ func CreateDataset(ctx context.Context, store cafs.Filestore, ds, dsPrev *datase
 		log.Debug(err.Error())
 		return "", err
 	}
+	// close the prepared body, dropping any spooled temp file if writing fails
+	// before the body is read
+	defer ds.BodyFile().Close()
 
 	path, err := WriteDataset(ctx, store, ds, sw.Pin)
 	if err != nil {
@@ -313,8 +322,8 @@ func prepareDataset(store cafs.Filestore, ds, dsPrev *dataset.Dataset, privKey c
 		err error
 		// lock for parallel edits to ds pointer
 		mu sync.Mutex
-		// accumulate reader into a buffer for shasum calculation & passing out another qfs.File
-		buf     bytes.Buffer
+		// accumulate reader into a spool for passing out another qfs.File
+		spool   = newBodySpool(BodySizeSmallEnoughToDiff)
 		bf      = ds.BodyFile()
 		bodyAct = BodyDefault
 		bfPrev  qfs.File
@@ -346,7 +355,7 @@ func prepareDataset(store cafs.Filestore, ds, dsPrev *dataset.Dataset, privKey c
 
 	go setErrCount(ds, qfs.NewMemfileReader(bf.FileName(), errR), &mu, done, valChan)
 	go setDepthAndEntryCount(ds, qfs.NewMemfileReader(bf.FileName(), entryR), &mu, done)
-	go setChecksumAndLength(ds, qfs.NewMemfileReader(bf.FileName(), hashR), &buf, &mu, done)
+	go setChecksumAndLength(ds, qfs.NewMemfileReader(bf.FileName(), hashR), spool, &mu, done)
 
 	go func() {
 		// pipes must be manually closed to trigger EOF
@@ -368,12 +377,14 @@ func prepareDataset(store cafs.Filestore, ds, dsPrev *dataset.Dataset, privKey c
 	// Join the outstanding tasks, wait until all are cmoplete.
 	for i := 0; i < tasks; i++ {
 		if err := <-done; err != nil {
+			spool.Remove()
 			return err
 		}
 	}
 
 	// If in strict mode, fail if there were any errors.
 	if ds.Structure.Strict && ds.Structure.ErrCount > 0 {
+		spool.Remove()
 		fmt.Fprintf(os.Stderr, "\nShowing errors at each /row/column of the dataset body:\n")
 		for i, v := range validationErrors {
 			fmt.Fprintf(os.Stderr, "%d) %v\n", i, v)
@@ -382,8 +393,8 @@ func prepareDataset(store cafs.Filestore, ds, dsPrev *dataset.Dataset, privKey c
 	}
 
 	// If the body exists and is small enough, deserialize it and assign it
-	if len(buf.Bytes()) < BodySizeSmallEnoughToDiff {
-		file := qfs.NewMemfileBytes("body."+ds.Structure.Format, buf.Bytes())
+	if !spool.Spilled() {
+		file := qfs.NewMemfileBytes("body."+ds.Structure.Format, spool.Bytes())
 		reader, err := dsio.NewEntryReader(ds.Structure, file)
 		if err == nil {
 			dsBody, err := readAllEntries(reader)
@@ -396,10 +407,16 @@ func prepareDataset(store cafs.Filestore, ds, dsPrev *dataset.Dataset, privKey c
 	}
 
 	if err = generateCommit(store, dsPrev, ds, privKey, bodyAct, sw.FileHint, sw.ForceIfNoChanges); err != nil {
+		spool.Remove()
 		return err
 	}
 
-	ds.SetBodyFile(qfs.NewMemfileBytes("body."+ds.Structure.Format, buf.Bytes()))
+	spooled, err := spool.File("body." + ds.Structure.Format)
+	if err != nil {
+		spool.Remove()
+		return err
+	}
+	ds.SetBodyFile(spooled)
 
 	if sw.ShouldRender && ds.Viz != nil && ds.Viz.ScriptFile() != nil {
 		// render the viz
@@ -419,7 +436,7 @@ func generateCommit(store cafs.Filestore, prev, ds *dataset.Dataset, privKey cry
 	shortTitle, longMessage, err := generateCommitDescriptions(store, prev, ds, bodyAct, forceIfNoChanges)
 	if err != nil {
 		log.Debug(fmt.Errorf("error saving: %s", err))
-		return fmt.Errorf("error saving: %s", err)
+		return fmt.Errorf("error saving: %w", err)
 	}
 
 	if shortTitle == defaultCreatedDescription && fileHint != "" {
@@ -538,15 +555,16 @@ func getDepth(x interface{}) (depth int) {
 }
 
 // setChecksumAndLength
-func setChecksumAndLength(ds *dataset.Dataset, data qfs.File, buf *bytes.Buffer, mu *sync.Mutex, done chan error) {
+func setChecksumAndLength(ds *dataset.Dataset, data qfs.File, spool *bodySpool, mu *sync.Mutex, done chan error) {
 	defer data.Close()
 
-	if _, err := io.Copy(buf, data); err != nil {
+	hash := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(spool, hash), data); err != nil {
 		done <- err
 		return
 	}
 
-	shasum, err := multihash.Sum(buf.Bytes(), multihash.SHA2_256, -1)
+	shasum, err := multihash.Encode(hash.Sum(nil), multihash.SHA2_256)
 	if err != nil {
 		log.Debug(err.Error())
 		done <- fmt.Errorf("error calculating hash: %s", err.Error())
@@ -554,8 +572,8 @@ func setChecksumAndLength(ds *dataset.Dataset, data qfs.File, buf *bytes.Buffer,
 	}
 
 	mu.Lock()
-	ds.Structure.Checksum = shasum.B58String()
-	ds.Structure.Length = len(buf.Bytes())
+	ds.Structure.Checksum = multihash.Multihash(shasum).B58String()
+	ds.Structure.Length = spool.Len()
 	mu.Unlock()
 
 	done <- nil
@@ -756,7 +774,7 @@ func generateCommitDescriptions(store cafs.Filestore, prev, ds *dataset.Dataset,
 		if forceIfNoChanges {
 			return "forced update", "forced update", nil
 		}
-		return "", "", fmt.Errorf("no changes")
+		return "", "", ErrNoChanges
 	}
 
 	return shortTitle, longMessage, nil
@@ -31,25 +31,7 @@ func DatasetLog(ctx context.Context, r repo.Repo, ref reporef.DatasetRef, limit,
 			if len(items) == 0 {
 				return nil, repo.ErrNoHistory
 			}
-			// Logbook doesn't store the CommitMessage and CommitTitle
-			// (see infoFromOp in logbook/logbook.go), so we need to load
-			// each dataset, and assign the CommitMessage and CommitTitle field.
-			for i, item := range items {
-				if item.Path != "" {
-					local, err := r.Store().Has(ctx, item.Path)
-					if err != nil {
-						continue
-					}
-					if local {
-						if ds, err := dsfs.LoadDataset(ctx, r.Store(), item.Path); err == nil {
-							if ds.Commit != nil {
-								items[i].CommitMessage = ds.Commit.Message
-							}
-						}
-					}
-					items[i].Foreign = !local
-				}
-			}
+			addLocalCommitInfo(ctx, r, items)
 			return items, nil
 		}
 	}
@@ -75,6 +57,47 @@ func DatasetLog(ctx context.Context, r repo.Repo, ref reporef.DatasetRef, limit,
 	return items, err
 }
 
+// DatasetBranchLog fetches the change version history of a named branch of a
+// dataset. Branches are only recorded in logbook, there is no fallback to
+// dataset history
+func DatasetBranchLog(ctx context.Context, r repo.Repo, ref reporef.DatasetRef, branch string, limit, offset int) ([]DatasetLogItem, error) {
+	book := r.Logbook()
+	if book == nil {
+		return nil, logbook.ErrNoLogbook
+	}
+	dr := reporef.ConvertToDsref(ref)
+	dr.Branch = branch
+	items, err := book.Items(ctx, dr, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	addLocalCommitInfo(ctx, r, items)
+	return items, nil
+}
+
+// addLocalCommitInfo fills in log items with details from locally stored
+// datasets. Logbook doesn't store the CommitMessage and CommitTitle
+// (see infoFromOp in logbook/logbook.go), so we need to load
+// each dataset, and assign the CommitMessage and CommitTitle field.
+func addLocalCommitInfo(ctx context.Context, r repo.Repo, items []DatasetLogItem) {
+	for i, item := range items {
+		if item.Path != "" {
+			local, err := r.Store().Has(ctx, item.Path)
+			if err != nil {
+				continue
+			}
+			if local {
+				if ds, err := dsfs.LoadDataset(ctx, r.Store(), item.Path); err == nil {
+					if ds.Commit != nil {
+						items[i].CommitMessage = ds.Commit.Message
+					}
+				}
+			}
+			items[i].Foreign = !local
+		}
+	}
+}
+
 // DatasetLogFromHistory fetches the history of changes to a dataset by walking
 // backwards through dataset commits. if loadDatasets is true, dataset
 // information will be populated
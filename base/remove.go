@@ -161,7 +161,7 @@ func RemoveNVersionsFromStore(ctx context.Context, r repo.Repo, curr dsref.Ref,
 		// we're just trying to remove it. Return successfully.
 		return info, nil
 	}
-	err = r.Logbook().WriteVersionDelete(ctx, initID, n)
+	err = r.Logbook().WriteBranchVersionDelete(ctx, initID, curr.Branch, n)
 	if err == logbook.ErrNoLogbook {
 		err = nil
 	}
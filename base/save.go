@@ -21,8 +21,9 @@ import (
 // SaveSwitches is an alias for the switches that control how saves happen
 type SaveSwitches = dsfs.SaveSwitches
 
-// SaveDataset initializes a dataset from a dataset pointer and data file
-func SaveDataset(ctx context.Context, r repo.Repo, str ioes.IOStreams, changes *dataset.Dataset, secrets map[string]string, scriptOut io.Writer, sw SaveSwitches) (ref reporef.DatasetRef, err error) {
+// SaveDataset initializes a dataset from a dataset pointer and data file.
+// transformOpts configure transform script execution
+func SaveDataset(ctx context.Context, r repo.Repo, str ioes.IOStreams, changes *dataset.Dataset, secrets map[string]string, scriptOut io.Writer, sw SaveSwitches, transformOpts ...func(*startf.ExecOpts)) (ref reporef.DatasetRef, err error) {
 	var (
 		prevPath string
 		pro      *profile.Profile
@@ -45,7 +46,11 @@ func SaveDataset(ctx context.Context, r repo.Repo, str ioes.IOStreams, changes *
 		dsName = inferredName
 	}
 
-	prev, mutable, prevPath, err := PrepareHeadDatasetVersion(ctx, r, peername, dsName)
+	if isBranchSave(sw) && (sw.NewName || inferredName != "") {
+		return ref, fmt.Errorf("saving to branch %q requires an existing dataset", sw.Branch)
+	}
+
+	prev, mutable, prevPath, err := PrepareBranchHeadDatasetVersion(ctx, r, peername, dsName, sw.Branch)
 	if err != nil {
 		log.Errorf("preparing dataset: %s", err)
 		return
@@ -89,7 +94,9 @@ func SaveDataset(ctx context.Context, r repo.Repo, str ioes.IOStreams, changes *
 		}
 	}
 
-	if changes.Transform != nil {
+	var deps map[string]*dataset.TransformResource
+	ranTransform := changes.Transform != nil
+	if ranTransform {
 		// create a check func from a record of all the parts that the datasetPod is changing,
 		// the startf package will use this function to ensure the same components aren't modified
 		mutateCheck := startf.MutatedComponentsFunc(changes)
@@ -100,10 +107,18 @@ func SaveDataset(ctx context.Context, r repo.Repo, str ioes.IOStreams, changes *
 			startf.SetErrWriter(scriptOut),
 			startf.SetSecrets(secrets),
 		}
+		opts = append(opts, transformOpts...)
 
-		if err = startf.ExecScript(ctx, changes, prev, opts...); err != nil {
+		exec := startf.ExecScript
+		if startf.IsSQLTransform(changes.Transform) {
+			// sql transforms run their query with the sql service provided in
+			// transformOpts
+			exec = startf.ExecSQL
+		}
+		if err = exec(ctx, changes, prev, opts...); err != nil {
 			return
 		}
+		deps = changes.Transform.Resources
 
 		str.PrintErr("✅ transform complete\n")
 	}
@@ -134,6 +149,10 @@ func SaveDataset(ctx context.Context, r repo.Repo, str ioes.IOStreams, changes *
 		// Treat the changes as a set of patches applied to the previous dataset
 		mutable.Assign(changes)
 		changes = mutable
+		if ranTransform {
+			// assign merges resources, keep only the dependencies this run loaded
+			changes.Transform.Resources = deps
+		}
 	}
 
 	// infer missing values
@@ -185,7 +204,7 @@ func CreateDataset(ctx context.Context, r repo.Repo, streams ioes.IOStreams, ds,
 		log.Debugf("dsfs.CreateDataset: %s", err)
 		return
 	}
-	if ds.PreviousPath != "" && ds.PreviousPath != "/" {
+	if ds.PreviousPath != "" && ds.PreviousPath != "/" && !isBranchSave(sw) {
 		prev := reporef.DatasetRef{
 			ProfileID: pro.ID,
 			Peername:  pro.Peername,
@@ -207,7 +226,17 @@ func CreateDataset(ctx context.Context, r repo.Repo, streams ioes.IOStreams, ds,
 		Path:      path,
 	}
 
-	if !sw.DryRun {
+	if !sw.DryRun && isBranchSave(sw) {
+		// saves to a named branch only advance that branch's log. The refstore
+		// tracks the head of the default branch
+		initID, err := r.Logbook().RefToInitID(dsref.Ref{Username: pro.Peername, Name: dsName})
+		if err != nil {
+			return ref, err
+		}
+		if err = r.Logbook().WriteBranchVersionSave(ctx, initID, sw.Branch, ds); err != nil {
+			return ref, err
+		}
+	} else if !sw.DryRun {
 		if err = r.PutRef(ref); err != nil {
 			log.Debugf("r.PutRef: %s", err)
 			return
@@ -256,6 +285,12 @@ func CreateDataset(ctx context.Context, r repo.Repo, streams ioes.IOStreams, ds,
 	return
 }
 
+// isBranchSave returns true if switches direct a save to a branch other than
+// the default branch
+func isBranchSave(sw SaveSwitches) bool {
+	return sw.Branch != "" && sw.Branch != logbook.DefaultBranchName
+}
+
 // GenerateAvailableName creates a name for the dataset that is not currently in use
 func GenerateAvailableName(r repo.Repo, peername, prefix string) string {
 	counter := 0
@@ -8,8 +8,10 @@ import (
 	"github.com/qri-io/ioes"
 	"github.com/qri-io/qfs"
 	"github.com/qri-io/qfs/cafs"
+	"github.com/qri-io/qri/dsref"
 	"github.com/qri-io/qri/repo"
 	"github.com/qri-io/qri/repo/profile"
+	reporef "github.com/qri-io/qri/repo/ref"
 )
 
 func TestSaveDataset(t *testing.T) {
@@ -221,6 +223,64 @@ func TestSaveDatasetReplace(t *testing.T) {
 	}
 }
 
+func TestSaveDatasetToBranch(t *testing.T) {
+	ctx := context.Background()
+	r := newTestRepo(t)
+
+	ds := &dataset.Dataset{
+		Name:      "test_save",
+		Meta:      &dataset.Meta{Title: "main title"},
+		Structure: &dataset.Structure{Format: "json", Schema: map[string]interface{}{"type": "array"}},
+	}
+	ds.SetBodyFile(qfs.NewMemfileBytes("body.json", []byte("[]")))
+	mainRef, err := SaveDataset(ctx, r, devNull, ds, nil, nil, SaveSwitches{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	book := r.Logbook()
+	initID, err := book.RefToInitID(dsref.Ref{Username: mainRef.Peername, Name: mainRef.Name})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := book.WriteBranchInit(ctx, initID, "experimental", ""); err != nil {
+		t.Fatal(err)
+	}
+
+	ds = &dataset.Dataset{
+		Name: "test_save",
+		Meta: &dataset.Meta{Title: "experimental title"},
+	}
+	branchRef, err := SaveDataset(ctx, r, devNull, ds, nil, nil, SaveSwitches{Branch: "experimental"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if branchRef.Dataset.PreviousPath != mainRef.Path {
+		t.Errorf("expected branch save to follow the branch head %q, got: %q", mainRef.Path, branchRef.Dataset.PreviousPath)
+	}
+
+	// the refstore head shouldn't move
+	head := reporef.DatasetRef{Peername: mainRef.Peername, Name: mainRef.Name}
+	if err := repo.CanonicalizeDatasetRef(r, &head); err != nil {
+		t.Fatal(err)
+	}
+	if head.Path != mainRef.Path {
+		t.Errorf("expected dataset head to remain %q, got: %q", mainRef.Path, head.Path)
+	}
+
+	items, err := book.Items(ctx, dsref.Ref{Username: mainRef.Peername, Name: mainRef.Name, Branch: "experimental"}, 0, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 2 || items[0].Path != branchRef.Path {
+		t.Errorf("expected branch head to be %q with 2 versions, got: %v", branchRef.Path, items)
+	}
+
+	if _, err := SaveDataset(ctx, r, devNull, ds, nil, nil, SaveSwitches{Branch: "missing"}); err == nil {
+		t.Error("expected saving to a missing branch to error")
+	}
+}
+
 func TestCreateDataset(t *testing.T) {
 	ctx := context.Background()
 	streams := ioes.NewDiscardIOStreams()
@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/qri-io/ioes"
+	"github.com/qri-io/qri/event"
 	"github.com/qri-io/qri/lib"
 	reporef "github.com/qri-io/qri/repo/ref"
 	"github.com/spf13/cobra"
@@ -45,6 +46,8 @@ type AddOptions struct {
 	LinkDir        string
 	LogsOnly       bool
 	DatasetMethods *lib.DatasetMethods
+
+	bus event.Bus
 }
 
 // Complete adds any missing configuration that can only be added just before calling Run
@@ -52,6 +55,9 @@ func (o *AddOptions) Complete(f Factory) (err error) {
 	if o.DatasetMethods, err = f.DatasetMethods(); err != nil {
 		return
 	}
+	if inst := f.Instance(); inst != nil {
+		o.bus = inst.Bus()
+	}
 	return nil
 }
 
@@ -59,6 +65,8 @@ func (o *AddOptions) Complete(f Factory) (err error) {
 func (o *AddOptions) Run(args []string) error {
 	o.StartSpinner()
 	defer o.StopSpinner()
+	stop := printTransferProgress(o.ErrOut, o.bus, o.StopSpinner)
+	defer stop()
 
 	if len(args) == 0 {
 		return fmt.Errorf("nothing to add")
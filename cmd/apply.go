@@ -0,0 +1,133 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/qri-io/ioes"
+	"github.com/qri-io/qri/errors"
+	"github.com/qri-io/qri/lib"
+	"github.com/qri-io/qri/repo"
+	"github.com/qri-io/qri/startf"
+	"github.com/spf13/cobra"
+)
+
+// NewApplyCommand creates a new `qri apply` cobra command for running a
+// transform without saving the result
+func NewApplyCommand(f Factory, ioStreams ioes.IOStreams) *cobra.Command {
+	o := &ApplyOptions{IOStreams: ioStreams}
+	cmd := &cobra.Command{
+		Use:   "apply [DATASET]",
+		Short: "run a transform without saving the result",
+		Long: `Apply runs a transform script against the latest version of a dataset &
+shows what the transform would change: meta & structure changes, a preview
+of the resulting body, a diff against the current body, the output of the
+script and how long it took. Nothing is written to the repo.
+
+Without a --file, apply re-runs the transform saved with the dataset.`,
+		Example: `  # Preview the result of a transform script:
+  $ qri apply --file transform.star me/annual_pop
+
+  # Re-run the saved transform of a dataset offline, using recorded http:
+  $ qri apply --replay-http me/annual_pop`,
+		Annotations: map[string]string{
+			"group": "dataset",
+		},
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Complete(f, args); err != nil {
+				return err
+			}
+			if err := o.Validate(); err != nil {
+				return err
+			}
+			return o.Run()
+		},
+	}
+
+	cmd.Flags().StringVarP(&o.FilePath, "file", "f", "", "transform script file to apply")
+	cmd.MarkFlagFilename("file", "star")
+	cmd.Flags().StringSliceVar(&o.Secrets, "secrets", nil, "transform secrets as comma separated key,value,key,value,... sequence")
+	cmd.Flags().IntVar(&o.PreviewLimit, "preview", lib.DefaultApplyPreviewLimit, "number of body entries to preview")
+	cmd.Flags().StringVar(&o.Format, "format", "pretty", "output format. one of [json,pretty]")
+	cmd.Flags().BoolVar(&o.ReplayHTTP, "replay-http", false, "serve transform http requests from the recorded fixture instead of the network")
+	cmd.Flags().Uint64Var(&o.TransformLimits.MaxSteps, "max-steps", 0, "maximum number of transform execution steps, 0 for no limit")
+	cmd.Flags().DurationVar(&o.TransformLimits.Timeout, "timeout", 0, "maximum transform run time, eg: 30s, 5m. 0 for no limit")
+	cmd.Flags().Int64Var(&o.TransformLimits.MaxDownloadBytes, "max-download", 0, "maximum bytes a transform can download, 0 for no limit")
+	cmd.Flags().Int64Var(&o.TransformLimits.MaxBodySize, "max-body-size", 0, "maximum size in bytes of a transform-produced body, 0 for no limit")
+
+	return cmd
+}
+
+// ApplyOptions encapsulates state for the apply command
+type ApplyOptions struct {
+	ioes.IOStreams
+
+	Refs         *RefSelect
+	FilePath     string
+	Secrets      []string
+	PreviewLimit int
+	Format       string
+	ReplayHTTP   bool
+
+	TransformLimits startf.Limits
+
+	DatasetMethods *lib.DatasetMethods
+}
+
+// Complete adds any missing configuration that can only be added just before calling Run
+func (o *ApplyOptions) Complete(f Factory, args []string) (err error) {
+	if o.DatasetMethods, err = f.DatasetMethods(); err != nil {
+		return
+	}
+	if o.Refs, err = GetCurrentRefSelect(f, args, 1, nil); err != nil {
+		// an empty reference is fine when applying a script file
+		if err != repo.ErrEmptyRef || o.FilePath == "" {
+			return err
+		}
+		o.Refs = NewEmptyRefSelect()
+	}
+	return nil
+}
+
+// Validate checks that any user input is valid
+func (o *ApplyOptions) Validate() error {
+	if o.Format != "pretty" && o.Format != "json" {
+		return errors.New(lib.ErrBadArgs, "format must be one of [json,pretty]")
+	}
+	return nil
+}
+
+// Run executes the apply command
+func (o *ApplyOptions) Run() (err error) {
+	printRefSelect(o.ErrOut, o.Refs)
+
+	p := &lib.ApplyParams{
+		Ref:             o.Refs.Ref(),
+		ScriptPath:      o.FilePath,
+		TransformLimits: o.TransformLimits,
+		ReplayHTTP:      o.ReplayHTTP,
+		PreviewLimit:    o.PreviewLimit,
+	}
+	if o.Secrets != nil {
+		if p.Secrets, err = parseSecrets(o.Secrets...); err != nil {
+			return err
+		}
+	}
+
+	res := &lib.ApplyResult{}
+	if err = o.DatasetMethods.Apply(p, res); err != nil {
+		if res.Output != "" {
+			fmt.Fprint(o.ErrOut, res.Output)
+		}
+		return err
+	}
+
+	if o.Format == "json" {
+		return json.NewEncoder(o.Out).Encode(res)
+	}
+	fmt.Fprint(o.ErrOut, res.Output)
+	printInfo(o.Out, "dry run, nothing was saved")
+	fmt.Fprint(o.Out, applyResultStringer(*res).String())
+	return nil
+}
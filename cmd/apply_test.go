@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/qri-io/qri/lib"
+)
+
+func TestApply(t *testing.T) {
+	run := NewTestRunner(t, "test_peer", "qri_test_apply")
+	defer run.Delete()
+
+	run.MustExec(t, "qri save --body testdata/movies/body_ten.csv test_peer/my_ds")
+	before := run.MustExec(t, "qri log test_peer/my_ds")
+
+	output := run.MustExec(t, "qri apply --format json --file testdata/movies/tf_one_movie.star test_peer/my_ds")
+	res := lib.ApplyResult{}
+	if err := json.Unmarshal([]byte(output), &res); err != nil {
+		t.Fatalf("decoding apply result: %s\n%s", err, output)
+	}
+	if !res.BodyChanged {
+		t.Errorf("expected transform to change the body")
+	}
+	preview, ok := res.BodyPreview.([]interface{})
+	if !ok || len(preview) != 1 {
+		t.Errorf("expected a one entry body preview. got: %v", res.BodyPreview)
+	}
+	if res.BodyStat == nil || res.BodyStat.Deletes == 0 {
+		t.Errorf("expected body diff against head to have deletes. got: %v", res.BodyStat)
+	}
+
+	if after := run.MustExec(t, "qri log test_peer/my_ds"); after != before {
+		t.Errorf("expected apply not to add a version. log before:\n%s\nafter:\n%s", before, after)
+	}
+
+	if err := run.ExecCommand("qri apply test_peer/my_ds"); err == nil {
+		t.Errorf("expected applying a dataset without a transform to error")
+	}
+}
@@ -0,0 +1,154 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/qri-io/ioes"
+	"github.com/qri-io/qri/errors"
+	"github.com/qri-io/qri/lib"
+	"github.com/qri-io/qri/repo"
+	"github.com/spf13/cobra"
+)
+
+// NewBranchCommand creates a `qri branch` subcommand for working with named
+// branches of dataset history
+func NewBranchCommand(f Factory, ioStreams ioes.IOStreams) *cobra.Command {
+	o := &BranchOptions{IOStreams: ioStreams}
+	cmd := &cobra.Command{
+		Use:   "branch",
+		Short: "create, list, and delete dataset branches",
+		Long: `Branches are named, parallel lines of history for a dataset. Every dataset
+starts with a single branch named "main". A new branch starts with a copy of
+the history of the branch it's created from, and saves to a branch only add
+versions to that branch.
+
+Refer to a branch by adding "#" and the branch name to a dataset reference.
+Commands that accept branch references include save, log, and publish.`,
+		Example: `  # Create a branch named experimental from the main branch of me/annual_pop:
+  $ qri branch create me/annual_pop experimental
+
+  # Save a new version to the experimental branch:
+  $ qri save --body /path/to/data.csv me/annual_pop#experimental
+
+  # Show the history of the experimental branch:
+  $ qri log me/annual_pop#experimental
+
+  # List branches of me/annual_pop:
+  $ qri branch list me/annual_pop`,
+		Annotations: map[string]string{
+			"group": "dataset",
+		},
+	}
+
+	create := &cobra.Command{
+		Use:   "create DATASET NAME",
+		Short: "create a new branch",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Complete(f, args); err != nil {
+				return err
+			}
+			return o.Create()
+		},
+	}
+	create.Flags().StringVar(&o.From, "from", "", "branch to start the new branch from, default main")
+
+	list := &cobra.Command{
+		Use:   "list [DATASET]",
+		Short: "list branches of a dataset",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Complete(f, args); err != nil {
+				return err
+			}
+			return o.List()
+		},
+	}
+
+	del := &cobra.Command{
+		Use:   "delete DATASET NAME",
+		Short: "delete a branch",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Complete(f, args); err != nil {
+				return err
+			}
+			return o.Delete()
+		},
+	}
+
+	cmd.AddCommand(create, list, del)
+	return cmd
+}
+
+// BranchOptions encapsulates state for the branch command
+type BranchOptions struct {
+	ioes.IOStreams
+
+	Refs *RefSelect
+	Name string
+	From string
+
+	BranchMethods *lib.BranchMethods
+}
+
+// Complete adds any missing configuration that can only be added just before calling Run
+func (o *BranchOptions) Complete(f Factory, args []string) (err error) {
+	if len(args) > 1 {
+		o.Name = args[1]
+		args = args[:1]
+	}
+	if o.Refs, err = GetCurrentRefSelect(f, args, 1, nil); err != nil {
+		if err == repo.ErrEmptyRef {
+			return errors.New(err, "please provide a dataset reference")
+		}
+		return err
+	}
+	o.BranchMethods, err = f.BranchMethods()
+	return
+}
+
+// Create executes the branch create command
+func (o *BranchOptions) Create() error {
+	p := &lib.BranchParams{
+		Ref:  o.Refs.Ref(),
+		Name: o.Name,
+		From: o.From,
+	}
+	res := lib.BranchInfo{}
+	if err := o.BranchMethods.Create(p, &res); err != nil {
+		return err
+	}
+	printSuccess(o.Out, "created branch %s from %s", res.Name, res.From)
+	return nil
+}
+
+// List executes the branch list command
+func (o *BranchOptions) List() error {
+	printRefSelect(o.ErrOut, o.Refs)
+
+	res := []lib.BranchInfo{}
+	if err := o.BranchMethods.List(&lib.BranchParams{Ref: o.Refs.Ref()}, &res); err != nil {
+		return err
+	}
+
+	items := make([]fmt.Stringer, len(res))
+	for i, b := range res {
+		items[i] = branchInfoStringer(b)
+	}
+	return printItems(o.Out, items, 0)
+}
+
+// Delete executes the branch delete command
+func (o *BranchOptions) Delete() error {
+	p := &lib.BranchParams{
+		Ref:  o.Refs.Ref(),
+		Name: o.Name,
+	}
+	res := false
+	if err := o.BranchMethods.Delete(p, &res); err != nil {
+		return err
+	}
+	printSuccess(o.Out, "deleted branch %s", o.Name)
+	return nil
+}
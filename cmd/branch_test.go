@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBranchCommands(t *testing.T) {
+	run := NewTestRunner(t, "test_peer", "qri_test_branch")
+	defer run.Delete()
+
+	run.MustExec(t, "qri save --body=testdata/movies/body_ten.csv me/test_movies")
+
+	output := run.MustExec(t, "qri branch create me/test_movies experimental")
+	if !strings.Contains(output, "created branch experimental from main") {
+		t.Errorf("unexpected create output: %q", output)
+	}
+	if err := run.ExecCommand("qri branch create me/test_movies experimental"); err == nil {
+		t.Error("expected creating a duplicate branch to error")
+	}
+
+	run.MustExec(t, "qri save --body=testdata/movies/body_thirty.csv me/test_movies#experimental")
+
+	output = run.MustExec(t, "qri log me/test_movies#experimental")
+	if !strings.Contains(output, "body changed") {
+		t.Errorf("expected branch log to contain the branch save, got: %q", output)
+	}
+	output = run.MustExec(t, "qri log me/test_movies")
+	if strings.Contains(output, "body changed") {
+		t.Errorf("expected main log to not contain the branch save, got: %q", output)
+	}
+
+	output = run.MustExec(t, "qri branch list me/test_movies")
+	for _, expect := range []string{"main", "experimental", "Versions: 2"} {
+		if !strings.Contains(output, expect) {
+			t.Errorf("expected branch list to contain %q, got: %q", expect, output)
+		}
+	}
+
+	if err := run.ExecCommand("qri branch delete me/test_movies main"); err == nil {
+		t.Error("expected deleting the default branch to error")
+	}
+	run.MustExec(t, "qri branch delete me/test_movies experimental")
+	output = run.MustExec(t, "qri branch list me/test_movies")
+	if strings.Contains(output, "experimental") {
+		t.Errorf("expected deleted branch to be removed from list, got: %q", output)
+	}
+}
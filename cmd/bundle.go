@@ -0,0 +1,148 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/dustin/go-humanize"
+	"github.com/qri-io/ioes"
+	"github.com/qri-io/qri/base/bundle"
+	"github.com/qri-io/qri/errors"
+	"github.com/qri-io/qri/lib"
+	"github.com/qri-io/qri/repo"
+	"github.com/spf13/cobra"
+)
+
+// NewBundleCommand creates a `qri bundle` subcommand for moving datasets
+// between repos without a network connection
+func NewBundleCommand(f Factory, ioStreams ioes.IOStreams) *cobra.Command {
+	o := &BundleOptions{IOStreams: ioStreams}
+	cmd := &cobra.Command{
+		Use:   "bundle",
+		Short: "move datasets between repos with a single file",
+		Long: `Bundles hold a dataset, its signed history, and the data of one or more
+versions in a single file. Bundles move datasets to and from machines that
+can't connect to a remote, like air-gapped networks.
+
+Importing a bundle verifies the signatures of the bundled history and the
+hashes of all bundled data. Once imported, the dataset and its history show up
+exactly as if they were pulled from a remote.`,
+		Example: `  # Bundle all versions of me/annual_pop:
+  $ qri bundle create me/annual_pop --versions all
+
+  # Bundle the latest version to a chosen file:
+  $ qri bundle create me/annual_pop --output /media/usb/annual_pop.qribundle
+
+  # Import a bundle:
+  $ qri bundle import /media/usb/annual_pop.qribundle`,
+		Annotations: map[string]string{
+			"group": "dataset",
+		},
+	}
+
+	create := &cobra.Command{
+		Use:   "create DATASET",
+		Short: "write a dataset to a bundle file",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.CompleteCreate(f, args); err != nil {
+				return err
+			}
+			return o.Create()
+		},
+	}
+	create.Flags().StringVar(&o.Versions, "versions", "1", `number of versions to bundle, or "all"`)
+	create.Flags().StringVarP(&o.Output, "output", "o", "", "path of the bundle file to write, default DATASET_NAME.qribundle")
+
+	imp := &cobra.Command{
+		Use:   "import FILE",
+		Short: "add a bundled dataset to your repo",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.CompleteImport(f, args); err != nil {
+				return err
+			}
+			return o.Import()
+		},
+	}
+
+	cmd.AddCommand(create, imp)
+	return cmd
+}
+
+// BundleOptions encapsulates state for the bundle command
+type BundleOptions struct {
+	ioes.IOStreams
+
+	Refs     *RefSelect
+	Versions string
+	Output   string
+	Path     string
+
+	DatasetMethods *lib.DatasetMethods
+}
+
+// CompleteCreate adds any missing configuration that can only be added just
+// before calling Create
+func (o *BundleOptions) CompleteCreate(f Factory, args []string) (err error) {
+	if o.Refs, err = GetCurrentRefSelect(f, args, 1, nil); err != nil {
+		if err == repo.ErrEmptyRef {
+			return errors.New(err, "please provide a dataset reference")
+		}
+		return err
+	}
+	if o.Output == "" {
+		ref, err := repo.ParseDatasetRef(o.Refs.Ref())
+		if err != nil {
+			return err
+		}
+		o.Output = ref.Name + ".qribundle"
+	}
+	o.DatasetMethods, err = f.DatasetMethods()
+	return
+}
+
+// CompleteImport adds any missing configuration that can only be added just
+// before calling Import
+func (o *BundleOptions) CompleteImport(f Factory, args []string) (err error) {
+	if o.Path = args[0]; o.Path == "" {
+		return errors.New(lib.ErrBadArgs, "please provide the path to a bundle file")
+	}
+	o.DatasetMethods, err = f.DatasetMethods()
+	return
+}
+
+// Create executes the bundle create command
+func (o *BundleOptions) Create() error {
+	printRefSelect(o.ErrOut, o.Refs)
+
+	versions, err := parseDepth(o.Versions)
+	if err != nil {
+		return fmt.Errorf(`invalid versions %q, must be a positive number or "all"`, o.Versions)
+	}
+
+	p := &lib.CreateBundleParams{
+		Ref:      o.Refs.Ref(),
+		Versions: versions,
+		Output:   o.Output,
+	}
+	res := bundle.Manifest{}
+	if err := o.DatasetMethods.CreateBundle(p, &res); err != nil {
+		return err
+	}
+	printInfo(o.Out, "%d version(s), %d blocks, %s", len(res.Versions), res.Blocks, humanize.Bytes(res.Size))
+	printSuccess(o.Out, "wrote bundle of %s to %s", res.Ref.Alias(), o.Output)
+	return nil
+}
+
+// Import executes the bundle import command
+func (o *BundleOptions) Import() error {
+	res := bundle.Manifest{}
+	if err := o.DatasetMethods.ImportBundle(&lib.ImportBundleParams{Path: o.Path}, &res); err != nil {
+		return err
+	}
+	for _, v := range res.Versions {
+		printInfo(o.Out, "imported version %s", v)
+	}
+	printSuccess(o.Out, "imported %d version(s) of %s", len(res.Versions), res.Ref.Alias())
+	return nil
+}
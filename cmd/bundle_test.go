@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBundleCreateImport(t *testing.T) {
+	a := NewTestRunner(t, "peer_a", "qri_test_bundle_a")
+	defer a.Delete()
+	b := NewTestRunner(t, "peer_b", "qri_test_bundle_b")
+	defer b.Delete()
+
+	a.MustExec(t, "qri save --body=testdata/movies/body_ten.csv me/test_movies")
+	a.MustExec(t, "qri save --body=testdata/movies/body_thirty.csv me/test_movies")
+
+	file := filepath.Join(a.MakeTmpDir(t, "bundle"), "movies.qribundle")
+	output := a.MustExec(t, "qri bundle create me/test_movies --versions all --output "+file)
+	if !strings.Contains(output, "2 version(s)") {
+		t.Errorf("expected bundle of 2 versions, got: %q", output)
+	}
+
+	if err := b.ExecCommand("qri bundle import " + filepath.Join(filepath.Dir(file), "missing.qribundle")); err == nil {
+		t.Error("expected importing a missing file to fail")
+	}
+
+	output = b.MustExec(t, "qri bundle import "+file)
+	if !strings.Contains(output, "imported 2 version(s) of peer_a/test_movies") {
+		t.Errorf("expected import to report 2 versions, got: %q", output)
+	}
+
+	expect := a.MustExec(t, "qri log peer_a/test_movies")
+	got := b.MustExec(t, "qri log peer_a/test_movies")
+	if expect != got {
+		t.Errorf("expected imported log to match the original.\nwant: %s\ngot:  %s", expect, got)
+	}
+	if strings.Contains(got, "Storage: remote") {
+		t.Errorf("expected all imported versions to be local, got: %s", got)
+	}
+
+	b.MustExec(t, "qri get body peer_a/test_movies")
+
+	// importing again is a no-op
+	b.MustExec(t, "qri bundle import "+file)
+}
@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/qri-io/ioes"
+	"github.com/qri-io/qri/errors"
+	"github.com/qri-io/qri/lib"
+	"github.com/qri-io/qri/repo"
+	"github.com/spf13/cobra"
+)
+
+// NewDepsCommand creates a `qri deps` subcommand for showing the datasets a
+// dataset's transform depends on
+func NewDepsCommand(f Factory, ioStreams ioes.IOStreams) *cobra.Command {
+	o := &DepsOptions{IOStreams: ioStreams}
+	cmd := &cobra.Command{
+		Use:   "deps [DATASET]",
+		Short: "show dataset dependencies",
+		Long: `Deps shows how a dataset is connected to other datasets by transforms.
+Every time a transform runs, qri records the datasets & versions it loads
+with load_dataset or the qri module. Upstream datasets are the ones the
+latest version of a dataset loaded, downstream datasets are local datasets
+whose latest version loaded it.
+
+A dependency is stale when it was recorded against a version that is no
+longer the latest. Use ` + "`qri save --cascade`" + ` to re-run downstream
+transforms when saving a new version.`,
+		Example: `  # Show dependencies of me/annual_pop:
+  $ qri deps me/annual_pop`,
+		Annotations: map[string]string{
+			"group": "dataset",
+		},
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Complete(f, args); err != nil {
+				return err
+			}
+			return o.Run()
+		},
+	}
+
+	return cmd
+}
+
+// DepsOptions encapsulates state for the deps command
+type DepsOptions struct {
+	ioes.IOStreams
+
+	Refs *RefSelect
+
+	DatasetMethods *lib.DatasetMethods
+}
+
+// Complete adds any missing configuration that can only be added just before calling Run
+func (o *DepsOptions) Complete(f Factory, args []string) (err error) {
+	if o.Refs, err = GetCurrentRefSelect(f, args, 1, nil); err != nil {
+		if err == repo.ErrEmptyRef {
+			return errors.New(err, "please provide a dataset reference")
+		}
+		return err
+	}
+	o.DatasetMethods, err = f.DatasetMethods()
+	return
+}
+
+// Run executes the deps command
+func (o *DepsOptions) Run() error {
+	printRefSelect(o.ErrOut, o.Refs)
+
+	ref := o.Refs.Ref()
+	res := lib.DepsResult{}
+	if err := o.DatasetMethods.Deps(&ref, &res); err != nil {
+		return err
+	}
+	fmt.Fprint(o.Out, depsStringer(res).String())
+	return nil
+}
@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDepsAndCascade(t *testing.T) {
+	run := NewTestRunner(t, "test_peer", "qri_test_deps")
+	defer run.Delete()
+
+	run.MustExec(t, "qri save --body=testdata/movies/body_ten.csv me/test_movies")
+	run.MustExec(t, "qri save --file=testdata/movies/tf_count_movies.star me/movie_count")
+
+	output := run.MustExec(t, "qri deps me/movie_count")
+	if !strings.Contains(output, "test_peer/test_movies") {
+		t.Errorf("expected upstream to list test_peer/test_movies, got: %q", output)
+	}
+	output = run.MustExec(t, "qri deps me/test_movies")
+	if !strings.Contains(output, "test_peer/movie_count") {
+		t.Errorf("expected downstream to list test_peer/movie_count, got: %q", output)
+	}
+
+	// saving without cascade leaves the downstream dependency stale
+	run.MustExec(t, "qri save --body=testdata/movies/body_twenty.csv me/test_movies")
+	output = run.MustExec(t, "qri deps me/movie_count")
+	if !strings.Contains(output, "stale") {
+		t.Errorf("expected stale upstream dependency, got: %q", output)
+	}
+
+	output = run.MustExecCombinedOutErr(t, "qri save --body=testdata/movies/body_thirty.csv --cascade me/test_movies")
+	if !strings.Contains(output, "test_peer/movie_count saved") {
+		t.Errorf("expected cascade to save test_peer/movie_count, got: %q", output)
+	}
+	output = run.MustExec(t, "qri deps me/movie_count")
+	if strings.Contains(output, "stale") {
+		t.Errorf("expected cascade to update dependency, got: %q", output)
+	}
+
+	if err := run.ExecCommand("qri save --body=testdata/movies/body_ten.csv --cascade --dry-run me/test_movies"); err == nil {
+		t.Error("expected --cascade with --dry-run to error")
+	}
+}
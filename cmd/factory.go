@@ -36,6 +36,9 @@ type Factory interface {
 	SearchMethods() (*lib.SearchMethods, error)
 	SQLMethods() (*lib.SQLMethods, error)
 	FSIMethods() (*lib.FSIMethods, error)
+	WebhookMethods() (*lib.WebhookMethods, error)
+	BranchMethods() (*lib.BranchMethods, error)
+	ScheduleMethods() (*lib.ScheduleMethods, error)
 
 	// TODO (b5) - these should be deprecated:
 	ExportRequests() (*lib.ExportRequests, error)
@@ -191,6 +191,21 @@ func (t TestFactory) FSIMethods() (*lib.FSIMethods, error) {
 	return lib.NewFSIMethods(t.inst), nil
 }
 
+// WebhookMethods generates a lib.WebhookMethods from internal state
+func (t TestFactory) WebhookMethods() (*lib.WebhookMethods, error) {
+	return lib.NewWebhookMethods(t.inst), nil
+}
+
+// BranchMethods generates a lib.BranchMethods from internal state
+func (t TestFactory) BranchMethods() (*lib.BranchMethods, error) {
+	return lib.NewBranchMethods(t.inst), nil
+}
+
+// ScheduleMethods generates a lib.ScheduleMethods from internal state
+func (t TestFactory) ScheduleMethods() (*lib.ScheduleMethods, error) {
+	return lib.NewScheduleMethods(t.inst), nil
+}
+
 // SearchMethods generates a lib.SearchMethods from internal state
 func (t TestFactory) SearchMethods() (*lib.SearchMethods, error) {
 	return lib.NewSearchMethods(t.inst), nil
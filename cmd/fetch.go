@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	util "github.com/qri-io/apiutil"
+	"github.com/qri-io/ioes"
+	"github.com/qri-io/qri/dsref"
+	"github.com/qri-io/qri/errors"
+	"github.com/qri-io/qri/lib"
+	"github.com/spf13/cobra"
+)
+
+// NewFetchCommand creates a `qri fetch` cobra command
+func NewFetchCommand(f Factory, ioStreams ioes.IOStreams) *cobra.Command {
+	o := &FetchOptions{IOStreams: ioStreams}
+	cmd := &cobra.Command{
+		Use:   "fetch [DATASET | USERNAME]",
+		Short: "get logbook history from a remote",
+		Long: "`qri fetch`" + ` downloads logbook history from a remote without
+downloading dataset versions. Fetching a dataset shows the versions the remote
+knows about.
+
+With --all, fetch syncs the logbook for every dataset a user has on the remote
+into your logbook. Only logs that differ from your logbook are transferred,
+in a single request, which is much faster than fetching datasets one at a time.`,
+		Example: `  # Show the versions of b5/precip on the registry:
+  $ qri fetch b5/precip
+
+  # Sync logbooks for every dataset b5 has on a remote named "archive":
+  $ qri fetch --all b5 --remote archive`,
+		Annotations: map[string]string{
+			"group": "network",
+		},
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Complete(f, args); err != nil {
+				return err
+			}
+			if o.All {
+				return o.FetchAll()
+			}
+			return o.Run()
+		},
+	}
+
+	cmd.Flags().StringVar(&o.RemoteName, "remote", "", "name of remote to fetch from, defaults to the registry")
+	cmd.Flags().BoolVar(&o.All, "all", false, "fetch logs for all datasets a user has on the remote")
+
+	return cmd
+}
+
+// FetchOptions encapsulates state for the fetch command
+type FetchOptions struct {
+	ioes.IOStreams
+
+	Ref        string
+	RemoteName string
+	All        bool
+
+	RemoteMethods *lib.RemoteMethods
+}
+
+// Complete adds any missing configuration that can only be added just before calling Run
+func (o *FetchOptions) Complete(f Factory, args []string) (err error) {
+	if o.Ref = args[0]; o.Ref == "" {
+		return errors.New(lib.ErrBadArgs, "please provide a dataset reference or username")
+	}
+	o.RemoteMethods, err = f.RemoteMethods()
+	return
+}
+
+// Run fetches the history of a single dataset
+func (o *FetchOptions) Run() error {
+	p := lib.FetchParams{
+		Ref:        o.Ref,
+		RemoteName: o.RemoteName,
+	}
+	res := []DatasetLogItem{}
+	if err := o.RemoteMethods.Fetch(&p, &res); err != nil {
+		return err
+	}
+
+	makeItemsAndPrint(res, o.Out, util.NewPage(1, len(res)))
+	return nil
+}
+
+// FetchAll syncs logs for all of a user's datasets
+func (o *FetchOptions) FetchAll() error {
+	p := &lib.FetchAllParams{
+		Username:   o.Ref,
+		RemoteName: o.RemoteName,
+	}
+	res := []dsref.Ref{}
+	if err := o.RemoteMethods.FetchAll(p, &res); err != nil {
+		return err
+	}
+
+	if len(res) == 0 {
+		printSuccess(o.Out, "logs for %s are up to date", o.Ref)
+		return nil
+	}
+
+	items := make([]string, len(res))
+	for i, ref := range res {
+		items[i] = ref.Alias()
+	}
+	printlnStringItems(o.Out, items)
+	printSuccess(o.Out, "fetched logs for %d datasets", len(res))
+	return nil
+}
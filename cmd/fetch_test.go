@@ -7,12 +7,15 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"regexp"
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/qri-io/qri/api"
 	"github.com/qri-io/qri/config"
 	"github.com/qri-io/qri/lib"
+	"github.com/qri-io/qri/registry/regserver"
+	repotest "github.com/qri-io/qri/repo/test"
 )
 
 func TestFetchCommand(t *testing.T) {
@@ -211,3 +214,54 @@ func APICall(method, reqURL string, params map[string]string, hf http.HandlerFun
 	}
 	return statusCode, string(bodyBytes)
 }
+
+func TestFetchAll(t *testing.T) {
+	reg, cleanup, err := regserver.NewTempRegistry("temp_registry", "", repotest.NewTestCrypto())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+
+	_, httpServer := regserver.NewMockServerRegistry(*reg)
+	defer httpServer.Close()
+
+	a := NewTestRunner(t, "peer_a", "qri_test_fetch_all_a")
+	defer a.Delete()
+	b := NewTestRunner(t, "peer_b", "qri_test_fetch_all_b")
+	defer b.Delete()
+
+	for _, run := range []*TestRunner{a, b} {
+		cfg := run.RepoRoot.GetConfig()
+		cfg.Registry.Location = httpServer.URL
+		cfg.Remotes = &config.Remotes{"my_remote": httpServer.URL}
+		if err := run.RepoRoot.WriteConfigFile(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	a.MustExec(t, "qri save me/movies --body testdata/movies/body_ten.csv")
+	a.MustExec(t, "qri save me/more_movies --body testdata/movies/body_thirty.csv")
+	a.MustExec(t, "qri publish me/movies")
+	a.MustExec(t, "qri publish me/more_movies")
+
+	// logs are kept under the username of the author that wrote them
+	username := "peer_a"
+	output := b.MustExec(t, "qri fetch --all --remote my_remote "+username)
+	for _, name := range []string{"movies", "more_movies"} {
+		if !strings.Contains(output, username+"/"+name+"\n") {
+			t.Errorf("expected fetch to list %s/%s, got: %q", username, name, output)
+		}
+	}
+	if !strings.Contains(output, "fetched logs for 2 datasets") {
+		t.Errorf("expected fetch to report 2 datasets, got: %q", output)
+	}
+
+	output = b.MustExec(t, "qri fetch --all --remote my_remote "+username)
+	if !strings.Contains(output, "up to date") {
+		t.Errorf("expected second fetch to be up to date, got: %q", output)
+	}
+
+	if err := b.ExecCommand("qri fetch --all --remote my_remote unknown_user"); err == nil {
+		t.Error("expected fetching an unknown user to fail")
+	}
+}
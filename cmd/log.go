@@ -37,6 +37,9 @@ on the network at a remote.
 		Example: `  # Show log for the local dataset b5/precip:
   $ qri log b5/precip
 
+  # Show log for the experimental branch of b5/precip:
+  $ qri log b5/precip#experimental
+
   # Show log for a dataset on the Qri Cloud registry called ramfox/league_stats
   $ qri log ramfox/league_stats
 	
@@ -216,6 +219,53 @@ The logbook command shows entries for a dataset, from newest to oldest.`,
 	cmd.Flags().IntVar(&o.Page, "page", 1, "page number of results, default 1")
 	cmd.Flags().BoolVar(&o.Raw, "raw", false, "full logbook in raw JSON format. overrides all other flags")
 
+	verify := &cobra.Command{
+		Use:   "verify [DATASET]",
+		Short: "check logbook signatures and history",
+		Long: `Verify checks every log for a dataset against the public key of the author
+that wrote it. Verification confirms logs are signed by their author, that
+operations are attributed to the author of the log they're in, and that each
+version links to the version before it. Without a dataset reference all logs
+in the logbook are checked.
+
+Logs written by you don't need signatures, they're signed when sent to others.
+Public keys for other authors are looked up from connected peers.`,
+		Example: `  # Verify logs for the dataset bob/precip:
+  $ qri logbook verify bob/precip
+
+  # Verify the entire logbook:
+  $ qri logbook verify`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.CompleteVerify(f, args); err != nil {
+				return err
+			}
+			return o.Verify()
+		},
+	}
+	compact := &cobra.Command{
+		Use:   "compact",
+		Short: "shrink the logbook",
+		Long: `Compact reduces the size of your logbook. Logs for deleted datasets and
+branches are reduced to a record of their creation and removal, and runs of
+amendments like repeated renames are reduced to the latest amendment. Only logs
+you've written are compacted, logs from other users are left as-is so their
+signatures can still be verified.
+
+Saving the logbook appends changes to segment files, compacting also folds
+those segments back into a single file.`,
+		Example: `  # Compact the logbook:
+  $ qri logbook compact`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			if o.LogMethods, err = f.LogMethods(); err != nil {
+				return err
+			}
+			return o.Compact()
+		},
+	}
+	cmd.AddCommand(verify, compact)
+
 	return cmd
 }
 
@@ -228,6 +278,8 @@ type LogbookOptions struct {
 	Refs     *RefSelect
 	Raw      bool
 
+	VerifyRef string
+
 	LogMethods *lib.LogMethods
 }
 
@@ -247,6 +299,15 @@ func (o *LogbookOptions) Complete(f Factory, args []string) (err error) {
 	return
 }
 
+// CompleteVerify adds any missing configuration for the verify subcommand
+func (o *LogbookOptions) CompleteVerify(f Factory, args []string) (err error) {
+	if len(args) > 0 {
+		o.VerifyRef = args[0]
+	}
+	o.LogMethods, err = f.LogMethods()
+	return
+}
+
 // Logbook executes the Logbook command
 func (o *LogbookOptions) Logbook() error {
 	printRefSelect(o.ErrOut, o.Refs)
@@ -295,3 +356,32 @@ func (o *LogbookOptions) RawLogs() error {
 	printToPager(o.Out, bytes.NewBuffer(data))
 	return nil
 }
+
+// Verify executes the logbook verify subcommand, returning an error if any
+// problems are found
+func (o *LogbookOptions) Verify() error {
+	res := lib.VerifyReport{}
+	if err := o.LogMethods.VerifyLogs(&lib.VerifyLogsParams{Ref: o.VerifyRef}, &res); err != nil {
+		return err
+	}
+
+	if !res.OK() {
+		for _, p := range res.Problems {
+			printWarning(o.Out, p.String())
+		}
+		return fmt.Errorf("%d problem(s) found in %d logs", len(res.Problems), res.LogsChecked)
+	}
+
+	printSuccess(o.Out, "verified %d logs, %d operations", res.LogsChecked, res.OpsChecked)
+	return nil
+}
+
+// Compact executes the logbook compact subcommand
+func (o *LogbookOptions) Compact() error {
+	res := lib.CompactResult{}
+	if err := o.LogMethods.CompactLogs(&lib.CompactLogsParams{}, &res); err != nil {
+		return err
+	}
+	printSuccess(o.Out, "compacted logbook from %d to %d operations", res.OpsBefore, res.OpsAfter)
+	return nil
+}
@@ -3,6 +3,7 @@ package cmd
 import (
 	"fmt"
 	"regexp"
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -40,3 +41,46 @@ func TestLogbookCommand(t *testing.T) {
 		t.Errorf("unexpected (-want +got):\n%s", diff)
 	}
 }
+
+func TestLogbookVerifyCommand(t *testing.T) {
+	r := NewTestRunner(t, "test_peer", "qri_test_logbook_verify")
+	defer r.Delete()
+
+	r.MustExec(t, "qri save --body=testdata/movies/body_ten.csv me/test_movies")
+	r.MustExec(t, "qri save --body=testdata/movies/body_thirty.csv me/test_movies")
+
+	for _, cmdText := range []string{
+		"qri logbook verify me/test_movies",
+		"qri logbook verify",
+	} {
+		output := r.MustExec(t, cmdText)
+		if !strings.Contains(output, "verified") {
+			t.Errorf("%q: expected output to report verified logs. got: %q", cmdText, output)
+		}
+	}
+
+	if err := r.ExecCommand("qri logbook verify me/not_a_dataset"); err == nil {
+		t.Error("expected verifying an unknown dataset to error")
+	}
+}
+
+func TestLogbookCompactCommand(t *testing.T) {
+	r := NewTestRunner(t, "test_peer", "qri_test_logbook_compact")
+	defer r.Delete()
+
+	r.MustExec(t, "qri save --body=testdata/movies/body_ten.csv me/test_movies")
+	r.MustExec(t, "qri rename me/test_movies me/movies")
+	r.MustExec(t, "qri rename me/movies me/test_movies")
+
+	output := r.MustExec(t, "qri logbook compact")
+	if !strings.Contains(output, "compacted logbook") {
+		t.Errorf("expected output to report compaction. got: %q", output)
+	}
+
+	// logbook is still usable after compacting
+	output = r.MustExec(t, "qri log me/test_movies")
+	if !strings.Contains(output, "created dataset") {
+		t.Errorf("expected log to list the dataset version. got: %q", output)
+	}
+	r.MustExec(t, "qri logbook verify me/test_movies")
+}
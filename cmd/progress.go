@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	humanize "github.com/dustin/go-humanize"
+	"github.com/qri-io/qri/event"
+)
+
+const progressBarWidth = 30
+
+// printTransferProgress draws a progress bar to w for each dataset transfer
+// progress event published on bus. onStart is called before the first bar is
+// drawn. Calling the returned function stops drawing
+func printTransferProgress(w io.Writer, bus event.Bus, onStart func()) (stop func()) {
+	if bus == nil {
+		return func() {}
+	}
+
+	events := bus.Subscribe(event.ETRemoteTransferProgressEvent)
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		var last *event.TransferProgressEvent
+		finished := map[string]bool{}
+		for {
+			select {
+			case e := <-events:
+				p, ok := e.Payload.(event.TransferProgressEvent)
+				if !ok {
+					continue
+				}
+				// the bus doesn't guarantee delivery order, skip stale updates
+				key := p.Direction + p.Ref.Path
+				if finished[key] || (last != nil && !last.Complete && p.BlocksDone < last.BlocksDone) {
+					continue
+				}
+				if last == nil && onStart != nil {
+					onStart()
+				}
+				last = &p
+				fmt.Fprintf(w, "\r%s", progressBar(p))
+				if p.Complete {
+					finished[key] = true
+					fmt.Fprintln(w, "")
+				}
+			case <-done:
+				if last != nil && !last.Complete {
+					fmt.Fprintln(w, "")
+				}
+				return
+			}
+		}
+	}()
+
+	return func() {
+		bus.Unsubscribe(events)
+		close(done)
+		<-stopped
+	}
+}
+
+func progressBar(p event.TransferProgressEvent) string {
+	filled := 0
+	if p.BlocksTotal > 0 {
+		filled = progressBarWidth * p.BlocksDone / p.BlocksTotal
+	}
+	bar := strings.Repeat("=", filled) + strings.Repeat(" ", progressBarWidth-filled)
+
+	line := fmt.Sprintf("%sing [%s] %d/%d blocks %s/%s", p.Direction, bar, p.BlocksDone, p.BlocksTotal, humanize.Bytes(p.BytesDone), humanize.Bytes(p.BytesTotal))
+	if p.Rate > 0 {
+		line += fmt.Sprintf(" %s/s", humanize.Bytes(uint64(p.Rate)))
+	}
+	if p.ETA > 0 && !p.Complete {
+		line += fmt.Sprintf(" eta %s", p.ETA.Round(time.Second))
+	}
+	if p.Resumed > 0 {
+		line += fmt.Sprintf(" (resumed at %d)", p.Resumed)
+	}
+	return line
+}
@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/qri-io/qri/dsref"
+	"github.com/qri-io/qri/event"
+)
+
+func TestPrintTransferProgress(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	bus := event.NewBus(ctx)
+
+	buf := &bytes.Buffer{}
+	started := make(chan struct{})
+	stop := printTransferProgress(buf, bus, func() { close(started) })
+
+	ref := dsref.Ref{Username: "peer", Name: "ds", Path: "/ipfs/QmFoo"}
+	bus.Publish(event.ETRemoteTransferProgressEvent, event.TransferProgressEvent{
+		Ref:         ref,
+		Direction:   "push",
+		BlocksDone:  4,
+		BlocksTotal: 4,
+		BytesDone:   2048,
+		BytesTotal:  2048,
+		Complete:    true,
+	})
+
+	select {
+	case <-started:
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for progress to draw")
+	}
+	stop()
+
+	got := buf.String()
+	expect := "pushing [" + strings.Repeat("=", progressBarWidth) + "] 4/4 blocks 2.0 kB/2.0 kB\n"
+	if !strings.HasSuffix(got, expect) {
+		t.Errorf("progress output mismatch.\nexpected suffix: %q\ngot: %q", expect, got)
+	}
+}
+
+func TestProgressBar(t *testing.T) {
+	got := progressBar(event.TransferProgressEvent{
+		Direction:   "pull",
+		BlocksDone:  1,
+		BlocksTotal: 3,
+		BytesDone:   1000,
+		BytesTotal:  3000,
+		Rate:        500,
+		ETA:         4 * time.Second,
+		Resumed:     1,
+	})
+	expect := "pulling [" + strings.Repeat("=", 10) + strings.Repeat(" ", 20) + "] 1/3 blocks 1.0 kB/3.0 kB 500 B/s eta 4s (resumed at 1)"
+	if got != expect {
+		t.Errorf("progress bar mismatch.\nexpected: %q\ngot:      %q", expect, got)
+	}
+}
@@ -1,8 +1,11 @@
 package cmd
 
 import (
+	"fmt"
+
 	"github.com/qri-io/ioes"
 	"github.com/qri-io/qri/dsref"
+	"github.com/qri-io/qri/event"
 	"github.com/qri-io/qri/lib"
 	"github.com/spf13/cobra"
 )
@@ -27,6 +30,11 @@ be explicitly published to be made public.
 
 Use the --unpublish option to make a dataset private and remove it from a
 registry.
+
+Use the --dry-run option to see what publishing would do without sending
+anything: how many blocks & bytes would be transferred, which versions the
+remote already has, whether the remote's history of the dataset has diverged
+from yours & whether the remote would accept the push.
 `,
 		Example: `  # Publish a dataset:
   $ qri publish me/dataset
@@ -37,6 +45,9 @@ registry.
   # Unpublish a dataset:
   $ qri publish --unpublish me/dataset
 
+  # Check what publishing would transfer:
+  $ qri publish --dry-run me/dataset
+
   # Publish a few dataset on p2p only:
   $ qri publish --no-registry me/dataset_2`,
 		Annotations: map[string]string{
@@ -55,6 +66,7 @@ registry.
 	cmd.Flags().BoolVarP(&o.NoRegistry, "no-registry", "", false, "don't publish to registry")
 	cmd.Flags().BoolVarP(&o.NoPin, "no-pin", "", false, "don't pin dataset to registry")
 	cmd.Flags().StringVarP(&o.RemoteName, "remote", "", "", "name of remote to publish to")
+	cmd.Flags().BoolVar(&o.DryRun, "dry-run", false, "preview publishing without sending data")
 
 	return cmd
 }
@@ -68,9 +80,12 @@ type PublishOptions struct {
 	NoRegistry bool
 	NoPin      bool
 	RemoteName string
+	DryRun     bool
 
 	DatasetMethods *lib.DatasetMethods
 	RemoteMethods  *lib.RemoteMethods
+
+	bus event.Bus
 }
 
 // Complete adds any missing configuration that can only be added just before calling Run
@@ -84,6 +99,10 @@ func (o *PublishOptions) Complete(f Factory, args []string) (err error) {
 		return err
 	}
 
+	if inst := f.Instance(); inst != nil {
+		o.bus = inst.Bus()
+	}
+
 	o.RemoteMethods, err = f.RemoteMethods()
 	return
 }
@@ -96,6 +115,19 @@ func (o *PublishOptions) Run() error {
 		Ref:        o.Refs.Ref(),
 		RemoteName: o.RemoteName,
 	}
+	if o.DryRun {
+		if o.Unpublish {
+			return fmt.Errorf("--dry-run can't be combined with --unpublish")
+		}
+		res := lib.PublishPreview{}
+		if err := o.RemoteMethods.PreviewPublish(&p, &res); err != nil {
+			return err
+		}
+		printInfo(o.Out, "dry run, nothing was published")
+		fmt.Fprint(o.Out, publishPreviewStringer(res).String())
+		return nil
+	}
+
 	var res dsref.Ref
 	if o.Unpublish {
 		if err := o.RemoteMethods.Unpublish(&p, &res); err != nil {
@@ -103,7 +135,10 @@ func (o *PublishOptions) Run() error {
 		}
 		printInfo(o.Out, "unpublished dataset %s", res)
 	} else {
-		if err := o.RemoteMethods.Publish(&p, &res); err != nil {
+		stop := printTransferProgress(o.ErrOut, o.bus, nil)
+		err := o.RemoteMethods.Publish(&p, &res)
+		stop()
+		if err != nil {
 			return err
 		}
 		printInfo(o.Out, "published dataset %s", res)
@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/qri-io/qri/registry"
@@ -51,3 +52,50 @@ func TestPublish(t *testing.T) {
 		t.Errorf("expected: dataset named \"one_ds\", got %q", results[0].Name)
 	}
 }
+
+func TestPublishDryRun(t *testing.T) {
+	run := NewTestRunner(t, "test_peer", "qri_test_publish_dry_run")
+	defer run.Delete()
+
+	reg, cleanup, err := regserver.NewTempRegistry("temp_registry", "", repotest.NewTestCrypto())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+
+	_, httpServer := regserver.NewMockServerRegistry(*reg)
+	defer httpServer.Close()
+	run.RepoRoot.GetConfig().Registry.Location = httpServer.URL
+	if err := run.RepoRoot.WriteConfigFile(); err != nil {
+		t.Fatal(err)
+	}
+
+	run.MustExec(t, "qri save me/one_ds --body testdata/movies/body_ten.csv")
+
+	output := run.MustExec(t, "qri publish --dry-run me/one_ds")
+	for _, expect := range []string{"nothing was published", "0 of 1 already on remote", "new to remote", "Accepted: yes"} {
+		if !strings.Contains(output, expect) {
+			t.Errorf("expected dry run output to contain %q, got: %q", expect, output)
+		}
+	}
+	results, err := reg.Search.Search(registry.SearchParams{Limit: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 0 {
+		t.Errorf("expected dry run to publish nothing, got %d results", len(results))
+	}
+
+	run.MustExec(t, "qri publish me/one_ds")
+
+	output = run.MustExec(t, "qri publish --dry-run me/one_ds")
+	for _, expect := range []string{"Transfer: 0 of", "1 of 1 already on remote", "is in local history"} {
+		if !strings.Contains(output, expect) {
+			t.Errorf("expected dry run output to contain %q, got: %q", expect, output)
+		}
+	}
+
+	if err := run.ExecCommand("qri publish --dry-run --unpublish me/one_ds"); err == nil {
+		t.Error("expected combining --dry-run & --unpublish to fail")
+	}
+}
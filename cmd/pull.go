@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/qri-io/ioes"
+	"github.com/qri-io/qri/event"
+	"github.com/qri-io/qri/lib"
+	"github.com/spf13/cobra"
+)
+
+// NewPullCommand creates a `qri pull` subcommand for fetching dataset versions
+// from a remote
+func NewPullCommand(f Factory, ioStreams ioes.IOStreams) *cobra.Command {
+	o := &PullOptions{IOStreams: ioStreams}
+	cmd := &cobra.Command{
+		Use:   "pull DATASET",
+		Short: "fetch dataset versions from a remote",
+		Long: `Pull fetches the history of a dataset from a remote, then fetches the data for
+versions in that history so they're available offline. By default only the
+latest version is pulled. Use --depth to pull older versions as well.
+
+Use --components to limit what's fetched for versions older than the latest,
+for example pulling meta & structure while skipping bodies. The dataset &
+commit of each version are always fetched.`,
+		Example: `  # Pull the latest version of a dataset from the registry:
+  $ qri pull b5/world_bank_population
+
+  # Pull the five most recent versions:
+  $ qri pull --depth 5 b5/world_bank_population
+
+  # Pull all versions, skipping bodies of older versions:
+  $ qri pull --depth all --components meta,structure b5/world_bank_population`,
+		Annotations: map[string]string{
+			"group": "network",
+		},
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Complete(f, args); err != nil {
+				return err
+			}
+			return o.Run()
+		},
+	}
+
+	cmd.Flags().StringVar(&o.Depth, "depth", "1", `number of versions to pull, or "all"`)
+	cmd.Flags().StringSliceVar(&o.Components, "components", nil, "components to pull for older versions, comma separated")
+	cmd.Flags().StringVar(&o.RemoteName, "remote", "", "name of remote to pull from")
+
+	return cmd
+}
+
+// PullOptions encapsulates state for the pull command
+type PullOptions struct {
+	ioes.IOStreams
+
+	Ref        string
+	Depth      string
+	Components []string
+	RemoteName string
+
+	RemoteMethods *lib.RemoteMethods
+
+	bus event.Bus
+}
+
+// Complete adds any missing configuration that can only be added just before calling Run
+func (o *PullOptions) Complete(f Factory, args []string) (err error) {
+	if len(args) > 0 {
+		o.Ref = args[0]
+	}
+	if inst := f.Instance(); inst != nil {
+		o.bus = inst.Bus()
+	}
+	o.RemoteMethods, err = f.RemoteMethods()
+	return
+}
+
+// Run executes the pull command
+func (o *PullOptions) Run() error {
+	depth, err := parseDepth(o.Depth)
+	if err != nil {
+		return err
+	}
+
+	p := &lib.PullParams{
+		Ref:        o.Ref,
+		RemoteName: o.RemoteName,
+		Depth:      depth,
+		Components: o.Components,
+	}
+	res := []lib.DatasetLogItem{}
+	stop := printTransferProgress(o.ErrOut, o.bus, nil)
+	err = o.RemoteMethods.PullDataset(p, &res)
+	stop()
+	if err != nil {
+		return err
+	}
+
+	for _, item := range res {
+		printInfo(o.Out, "pulled version %s", item.Path)
+	}
+	printSuccess(o.Out, "pulled %d version(s) of %s", len(res), o.Ref)
+	return nil
+}
+
+// parseDepth converts a --depth flag value into a pull depth, where -1 means
+// all versions
+func parseDepth(s string) (int, error) {
+	if s == "" {
+		return 0, nil
+	}
+	if s == "all" {
+		return -1, nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf(`invalid depth %q, must be a positive number or "all"`, s)
+	}
+	return n, nil
+}
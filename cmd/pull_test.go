@@ -0,0 +1,31 @@
+package cmd
+
+import "testing"
+
+func TestParseDepth(t *testing.T) {
+	good := []struct {
+		in     string
+		expect int
+	}{
+		{"", 0},
+		{"1", 1},
+		{"12", 12},
+		{"all", -1},
+	}
+	for _, c := range good {
+		got, err := parseDepth(c.in)
+		if err != nil {
+			t.Errorf("%q: unexpected error: %s", c.in, err)
+			continue
+		}
+		if got != c.expect {
+			t.Errorf("%q: expected %d, got %d", c.in, c.expect, got)
+		}
+	}
+
+	for _, in := range []string{"0", "-1", "some", "1.5"} {
+		if _, err := parseDepth(in); err == nil {
+			t.Errorf("%q: expected error", in)
+		}
+	}
+}
@@ -41,13 +41,18 @@ https://github.com/qri-io/qri/issues`,
 
 	cmd.AddCommand(
 		NewAddCommand(opt, ioStreams),
+		NewApplyCommand(opt, ioStreams),
 		NewAutocompleteCommand(opt, ioStreams),
+		NewBranchCommand(opt, ioStreams),
+		NewBundleCommand(opt, ioStreams),
 		NewCheckoutCommand(opt, ioStreams),
 		NewConfigCommand(opt, ioStreams),
 		NewConnectCommand(opt, ioStreams),
 		NewDAGCommand(opt, ioStreams),
+		NewDepsCommand(opt, ioStreams),
 		NewDiffCommand(opt, ioStreams),
 		NewExportCommand(opt, ioStreams),
+		NewFetchCommand(opt, ioStreams),
 		NewFSICommand(opt, ioStreams),
 		NewGetCommand(opt, ioStreams),
 		NewInitCommand(opt, ioStreams),
@@ -55,21 +60,29 @@ https://github.com/qri-io/qri/issues`,
 		NewLogCommand(opt, ioStreams),
 		NewLogbookCommand(opt, ioStreams),
 		NewPublishCommand(opt, ioStreams),
+		NewPullCommand(opt, ioStreams),
 		NewPeersCommand(opt, ioStreams),
 		NewRegistryCommand(opt, ioStreams),
+		NewRemoteCommand(opt, ioStreams),
 		NewRemoveCommand(opt, ioStreams),
 		NewRenameCommand(opt, ioStreams),
 		NewRenderCommand(opt, ioStreams),
 		NewRestoreCommand(opt, ioStreams),
 		NewSaveCommand(opt, ioStreams),
+		NewScheduleCommand(opt, ioStreams),
 		NewSearchCommand(opt, ioStreams),
 		NewSetupCommand(opt, ioStreams),
 		NewStatsCommand(opt, ioStreams),
 		NewStatusCommand(opt, ioStreams),
+		NewStoreCommand(opt, ioStreams),
+		NewTestCommand(opt, ioStreams),
 		NewSQLCommand(opt, ioStreams),
+		NewSubscribeCommand(opt, ioStreams),
+		NewUnsubscribeCommand(opt, ioStreams),
 		NewUseCommand(opt, ioStreams),
 		NewValidateCommand(opt, ioStreams),
 		NewVersionCommand(opt, ioStreams),
+		NewWebhooksCommand(opt, ioStreams),
 		NewWhatChangedCommand(opt, ioStreams),
 	)
 
@@ -292,3 +305,30 @@ func (o *QriOptions) FSIMethods() (m *lib.FSIMethods, err error) {
 
 	return lib.NewFSIMethods(o.inst), nil
 }
+
+// WebhookMethods generates a lib.WebhookMethods from internal state
+func (o *QriOptions) WebhookMethods() (m *lib.WebhookMethods, err error) {
+	if err = o.Init(); err != nil {
+		return
+	}
+
+	return lib.NewWebhookMethods(o.inst), nil
+}
+
+// BranchMethods generates a lib.BranchMethods from internal state
+func (o *QriOptions) BranchMethods() (m *lib.BranchMethods, err error) {
+	if err = o.Init(); err != nil {
+		return
+	}
+
+	return lib.NewBranchMethods(o.inst), nil
+}
+
+// ScheduleMethods generates a lib.ScheduleMethods from internal state
+func (o *QriOptions) ScheduleMethods() (m *lib.ScheduleMethods, err error) {
+	if err = o.Init(); err != nil {
+		return
+	}
+
+	return lib.NewScheduleMethods(o.inst), nil
+}
@@ -0,0 +1,226 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/qri-io/ioes"
+	"github.com/qri-io/qri/config"
+	"github.com/qri-io/qri/lib"
+	"github.com/qri-io/qri/remote"
+	"github.com/spf13/cobra"
+)
+
+// NewRemoteCommand creates a `qri remote` subcommand for managing this node's
+// remote
+func NewRemoteCommand(f Factory, ioStreams ioes.IOStreams) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "remote",
+		Short: "manage this node's remote",
+		Long: `A qri node with remote mode enabled accepts datasets pushed by other peers,
+serving them to anyone who pulls. Remote commands configure how this node
+behaves as a remote.`,
+		Annotations: map[string]string{
+			"group": "network",
+		},
+	}
+
+	cmd.AddCommand(
+		NewRemoteMirrorCommand(f, ioStreams),
+		NewRemoteUsageCommand(f, ioStreams),
+	)
+	return cmd
+}
+
+// NewRemoteUsageCommand creates a `qri remote usage` subcommand for reporting
+// storage used on this node's remote
+func NewRemoteUsageCommand(f Factory, ioStreams ioes.IOStreams) *cobra.Command {
+	o := &RemoteUsageOptions{IOStreams: ioStreams}
+	cmd := &cobra.Command{
+		Use:   "usage",
+		Short: "show storage used by each profile that pushes to this remote",
+		Long: `Usage reports the number of datasets, versions & bytes this remote stores for
+each profile that has pushed to it, along with the profile's quota.
+
+Quotas are set with remote.profilequota, and can be overridden for individual
+profiles in remote.quotas, keyed by profile ID. Pushes that would take a
+profile over its quota are rejected. Versions unpinned by the retention policy
+in remote.retention no longer count toward a profile's usage.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Complete(f, args); err != nil {
+				return err
+			}
+			return o.Run()
+		},
+	}
+	return cmd
+}
+
+// RemoteUsageOptions encapsulates state for the remote usage command
+type RemoteUsageOptions struct {
+	ioes.IOStreams
+
+	RemoteMethods *lib.RemoteMethods
+}
+
+// Complete adds any missing configuration that can only be added just before calling Run
+func (o *RemoteUsageOptions) Complete(f Factory, args []string) (err error) {
+	o.RemoteMethods, err = f.RemoteMethods()
+	return
+}
+
+// Run executes the remote usage command
+func (o *RemoteUsageOptions) Run() error {
+	res := []remote.ProfileUsage{}
+	if err := o.RemoteMethods.Usage(nil, &res); err != nil {
+		return err
+	}
+	if len(res) == 0 {
+		printInfo(o.Out, "no datasets stored")
+		return nil
+	}
+
+	items := make([]fmt.Stringer, len(res))
+	for i, u := range res {
+		items[i] = profileUsageStringer(u)
+	}
+	return printItems(o.Out, items, 0)
+}
+
+// NewRemoteMirrorCommand creates a `qri remote mirror` subcommand for
+// replicating datasets from upstream remotes
+func NewRemoteMirrorCommand(f Factory, ioStreams ioes.IOStreams) *cobra.Command {
+	o := &RemoteMirrorOptions{IOStreams: ioStreams}
+	cmd := &cobra.Command{
+		Use:   "mirror",
+		Short: "replicate datasets from upstream remotes",
+		Long: `Mirrors keep this remote in sync with an upstream remote. While connected, a
+remote with mirrors checks each upstream on a schedule, fetching logs & data for
+new versions of datasets that match the mirror's patterns. Mirrored datasets
+are pinned & published, so they're served to this remote's clients.
+
+Patterns are "peername/name" strings where "*" matches any part of a peername
+or name, eg: "nasa/*". Upstreams are names from the remotes config, or remote
+addresses. Mirrors only run when remote.enabled is true.`,
+	}
+
+	add := &cobra.Command{
+		Use:   "add UPSTREAM PATTERN [PATTERN...]",
+		Short: "add datasets to replicate from an upstream remote",
+		Example: `  # Replicate all datasets published by nasa from the "primary" remote:
+  $ qri remote mirror add primary "nasa/*"
+
+  # Check an upstream address for new versions every hour:
+  $ qri remote mirror add --interval 1h https://registry.qri.cloud b5/world_bank_population`,
+		Args: cobra.MinimumNArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Complete(f, args); err != nil {
+				return err
+			}
+			return o.Add(args)
+		},
+	}
+	add.Flags().StringVar(&o.Interval, "interval", "", fmt.Sprintf("time between replication checks, default %s", config.DefaultMirrorInterval))
+
+	list := &cobra.Command{
+		Use:   "list",
+		Short: "list upstream remotes this remote replicates from",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Complete(f, args); err != nil {
+				return err
+			}
+			return o.List()
+		},
+	}
+
+	status := &cobra.Command{
+		Use:   "status",
+		Short: "show replication status of mirrored datasets",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Complete(f, args); err != nil {
+				return err
+			}
+			return o.Status()
+		},
+	}
+
+	cmd.AddCommand(add, list, status)
+	return cmd
+}
+
+// RemoteMirrorOptions encapsulates state for the remote mirror command
+type RemoteMirrorOptions struct {
+	ioes.IOStreams
+
+	Interval string
+
+	RemoteMethods *lib.RemoteMethods
+	remoteEnabled bool
+}
+
+// Complete adds any missing configuration that can only be added just before calling Run
+func (o *RemoteMirrorOptions) Complete(f Factory, args []string) (err error) {
+	if inst := f.Instance(); inst != nil {
+		if cfg := inst.Config(); cfg != nil && cfg.Remote != nil {
+			o.remoteEnabled = cfg.Remote.Enabled
+		}
+	}
+	o.RemoteMethods, err = f.RemoteMethods()
+	return
+}
+
+// Add executes the remote mirror add command
+func (o *RemoteMirrorOptions) Add(args []string) error {
+	p := &lib.MirrorParams{
+		Upstream: args[0],
+		Patterns: args[1:],
+		Interval: o.Interval,
+	}
+	res := config.Mirror{}
+	if err := o.RemoteMethods.AddMirror(p, &res); err != nil {
+		return err
+	}
+	printSuccess(o.Out, "mirroring %d pattern(s) from %s", len(res.Patterns), res.Upstream)
+	if !o.remoteEnabled {
+		printWarning(o.ErrOut, "remote mode isn't enabled, mirrors won't run until remote.enabled is true")
+	}
+	return nil
+}
+
+// List executes the remote mirror list command
+func (o *RemoteMirrorOptions) List() error {
+	res := []*config.Mirror{}
+	if err := o.RemoteMethods.ListMirrors(nil, &res); err != nil {
+		return err
+	}
+	if len(res) == 0 {
+		printInfo(o.Out, "no mirrors configured")
+		return nil
+	}
+
+	items := make([]fmt.Stringer, len(res))
+	for i, m := range res {
+		items[i] = mirrorStringer(*m)
+	}
+	return printItems(o.Out, items, 0)
+}
+
+// Status executes the remote mirror status command
+func (o *RemoteMirrorOptions) Status() error {
+	res := []*remote.MirrorStatus{}
+	if err := o.RemoteMethods.MirrorStatus(nil, &res); err != nil {
+		return err
+	}
+	if len(res) == 0 {
+		printInfo(o.Out, "no mirrored datasets")
+		return nil
+	}
+
+	items := make([]fmt.Stringer, len(res))
+	for i, s := range res {
+		items[i] = mirrorStatusStringer(*s)
+	}
+	return printItems(o.Out, items, 0)
+}
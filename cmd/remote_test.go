@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/qri-io/qri/remote"
+)
+
+func TestRemoteMirror(t *testing.T) {
+	run := NewTestRunner(t, "test_peer", "qri_test_remote_mirror")
+	defer run.Delete()
+
+	output := run.MustExec(t, "qri remote mirror list")
+	if !strings.Contains(output, "no mirrors configured") {
+		t.Errorf("expected empty list message, got: %q", output)
+	}
+
+	output = run.MustExec(t, "qri remote mirror add --interval 1h http://localhost:2503 nasa/* b5/world_bank_population")
+	if !strings.Contains(output, "mirroring 2 pattern(s) from http://localhost:2503") {
+		t.Errorf("expected add success message, got: %q", output)
+	}
+
+	output = run.MustExec(t, "qri remote mirror list")
+	for _, expect := range []string{"http://localhost:2503", "nasa/*, b5/world_bank_population", "Interval: 1h"} {
+		if !strings.Contains(output, expect) {
+			t.Errorf("expected output to contain %q, got: %q", expect, output)
+		}
+	}
+
+	output = run.MustExec(t, "qri remote mirror status")
+	if !strings.Contains(output, "no mirrored datasets") {
+		t.Errorf("expected empty status message, got: %q", output)
+	}
+
+	if err := run.ExecCommand("qri remote mirror add unknown_remote nasa/*"); err == nil {
+		t.Error("expected adding a mirror for an unknown remote to fail")
+	}
+}
+
+func TestRemoteUsage(t *testing.T) {
+	run := NewTestRunner(t, "test_peer", "qri_test_remote_usage")
+	defer run.Delete()
+
+	output := run.MustExec(t, "qri remote usage")
+	if !strings.Contains(output, "no datasets stored") {
+		t.Errorf("expected empty usage message, got: %q", output)
+	}
+
+	usage, err := remote.NewUsage(filepath.Join(run.RepoRoot.QriPath, "remote"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	versions := []*remote.StoredVersion{
+		{ProfileID: "QmPusher", Ref: "pusher/one", Path: "/ipfs/QmOne", Size: 2000},
+		{ProfileID: "QmPusher", Ref: "pusher/two", Path: "/ipfs/QmTwo", Size: 3000},
+	}
+	for _, v := range versions {
+		if err := usage.Add(v); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	output = run.MustExec(t, "qri remote usage")
+	for _, expect := range []string{"QmPusher", "Datasets: 2", "Versions: 2", "5.0 kB", "Quota:    none"} {
+		if !strings.Contains(output, expect) {
+			t.Errorf("expected output to contain %q, got: %q", expect, output)
+		}
+	}
+}
@@ -6,9 +6,11 @@ import (
 
 	"github.com/qri-io/ioes"
 	"github.com/qri-io/qfs"
+	"github.com/qri-io/qri/errors"
 	"github.com/qri-io/qri/lib"
 	"github.com/qri-io/qri/repo"
 	reporef "github.com/qri-io/qri/repo/ref"
+	"github.com/qri-io/qri/startf"
 	"github.com/spf13/cobra"
 )
 
@@ -42,7 +44,17 @@ commit message and title to the save.`,
   $ qri save --file /path/to/dataset.yaml me/annual_pop
   
   # Re-execute a dataset that has a transform:
-  $ qri save me/tf_dataset`,
+  $ qri save me/tf_dataset
+
+  # Save the results of a SQL query, re-running the query on each save:
+  $ qri save --file transform.sql me/long_movies
+
+  # Record the http requests a transform makes, then re-run it offline:
+  $ qri save --file transform.star --record-http me/tf_dataset
+  $ qri save --recall tf --replay-http me/tf_dataset
+
+  # Save new data, then re-run transforms of datasets that load me/annual_pop:
+  $ qri save --body /path/to/data.csv --cascade me/annual_pop`,
 		Annotations: map[string]string{
 			"group": "dataset",
 		},
@@ -76,6 +88,13 @@ commit message and title to the save.`,
 	cmd.Flags().BoolVarP(&o.NewName, "new", "n", false, "save a new dataset only, using an available name")
 	cmd.Flags().BoolVarP(&o.UseDscache, "use-dscache", "", false, "experimental: build and use dscache if none exists")
 	cmd.Flags().StringVar(&o.Drop, "drop", "", "comma-separated list of components to remove")
+	cmd.Flags().Uint64Var(&o.TransformLimits.MaxSteps, "max-steps", 0, "maximum number of transform execution steps, 0 for no limit")
+	cmd.Flags().DurationVar(&o.TransformLimits.Timeout, "timeout", 0, "maximum transform run time, eg: 30s, 5m. 0 for no limit")
+	cmd.Flags().Int64Var(&o.TransformLimits.MaxDownloadBytes, "max-download", 0, "maximum bytes a transform can download, 0 for no limit")
+	cmd.Flags().Int64Var(&o.TransformLimits.MaxBodySize, "max-body-size", 0, "maximum size in bytes of a transform-produced body, 0 for no limit")
+	cmd.Flags().BoolVar(&o.RecordHTTP, "record-http", false, "record transform http requests into a fixture stored with the transform")
+	cmd.Flags().BoolVar(&o.ReplayHTTP, "replay-http", false, "serve transform http requests from the recorded fixture instead of the network")
+	cmd.Flags().BoolVar(&o.Cascade, "cascade", false, "re-run transforms of downstream datasets after saving")
 
 	return cmd
 }
@@ -104,6 +123,11 @@ type SaveOptions struct {
 	NewName        bool
 	UseDscache     bool
 
+	TransformLimits startf.Limits
+	RecordHTTP      bool
+	ReplayHTTP      bool
+	Cascade         bool
+
 	DatasetMethods *lib.DatasetMethods
 	FSIMethods     *lib.FSIMethods
 }
@@ -139,6 +163,12 @@ func (o *SaveOptions) Complete(f Factory, args []string) (err error) {
 
 // Validate checks that all user input is valid
 func (o *SaveOptions) Validate() error {
+	if o.RecordHTTP && o.ReplayHTTP {
+		return errors.New(lib.ErrBadArgs, "cannot use both --record-http and --replay-http")
+	}
+	if o.Cascade && o.DryRun {
+		return errors.New(lib.ErrBadArgs, "cannot use both --cascade and --dry-run")
+	}
 	return nil
 }
 
@@ -168,6 +198,9 @@ func (o *SaveOptions) Run() (err error) {
 		ShouldRender:        !o.NoRender,
 		NewName:             o.NewName,
 		UseDscache:          o.UseDscache,
+		TransformLimits:     o.TransformLimits,
+		RecordHTTP:          o.RecordHTTP,
+		ReplayHTTP:          o.ReplayHTTP,
 	}
 
 	if o.Secrets != nil {
@@ -206,5 +239,42 @@ continue?`, true) {
 		fmt.Fprint(o.Out, string(data))
 	}
 
+	if o.Cascade {
+		return o.cascade(p.Secrets, res.AliasString())
+	}
+	return nil
+}
+
+// cascade re-runs the transforms of datasets downstream of a saved dataset
+func (o *SaveOptions) cascade(secrets map[string]string, ref string) error {
+	p := &lib.CascadeParams{
+		Ref:          ref,
+		Secrets:      secrets,
+		ScriptOutput: o.ErrOut,
+	}
+	res := []lib.CascadeResult{}
+	if err := o.DatasetMethods.Cascade(p, &res); err != nil {
+		return err
+	}
+	if len(res) == 0 {
+		printInfo(o.ErrOut, "no downstream datasets to update")
+		return nil
+	}
+
+	failed := 0
+	for _, r := range res {
+		switch r.Status {
+		case lib.CascadeSaved:
+			printSuccess(o.ErrOut, "%s saved: %s", r.Ref, r.Path)
+		case lib.CascadeFailed:
+			failed++
+			printErr(o.ErrOut, fmt.Errorf("%s failed: %s", r.Ref, r.Error))
+		default:
+			printInfo(o.ErrOut, "%s %s", r.Ref, r.Status)
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d downstream transforms failed", failed)
+	}
 	return nil
 }
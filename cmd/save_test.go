@@ -879,6 +879,25 @@ func TestSaveTwiceWithTransform(t *testing.T) {
 	}
 }
 
+func TestSaveTransformLimits(t *testing.T) {
+	run := NewTestRunner(t, "test_peer", "qri_test_save_transform_limits")
+	defer run.Delete()
+
+	err := run.ExecCommand("qri save --file testdata/movies/tf_loop.star --max-steps 100 test_peer/my_ds")
+	if err == nil || !strings.Contains(err.Error(), "transform exceeded step limit of 100 steps") {
+		t.Errorf("expected step limit error, got: %v", err)
+	}
+
+	err = run.ExecCommand("qri save --file testdata/movies/tf_loop.star --max-body-size 4 test_peer/my_ds")
+	if err == nil || !strings.Contains(err.Error(), "transform exceeded body size limit of 4 bytes") {
+		t.Errorf("expected body size limit error, got: %v", err)
+	}
+
+	// the interpreter counts each instruction as a step, tf_loop.star takes
+	// about 10,000 steps
+	run.MustExec(t, "qri save --file testdata/movies/tf_loop.star --max-steps 50000 --timeout 1m --max-body-size 100 test_peer/my_ds")
+}
+
 // TODO(dustmop): Test that if the result has a different shape than the previous version,
 // the error message should be reasonable and understandable
 //func TestSaveWithBadShape(t *testing.T) {
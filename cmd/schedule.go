@@ -0,0 +1,200 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	util "github.com/qri-io/apiutil"
+	"github.com/qri-io/ioes"
+	"github.com/qri-io/qri/cron"
+	"github.com/qri-io/qri/errors"
+	"github.com/qri-io/qri/lib"
+	"github.com/qri-io/qri/repo"
+	"github.com/spf13/cobra"
+)
+
+// NewScheduleCommand creates a `qri schedule` subcommand for running dataset
+// transforms on a schedule
+func NewScheduleCommand(f Factory, ioStreams ioes.IOStreams) *cobra.Command {
+	o := &ScheduleOptions{IOStreams: ioStreams}
+	cmd := &cobra.Command{
+		Use:   "schedule",
+		Short: "run dataset transforms on a schedule",
+		Long: `Schedule re-runs the transform of a dataset at regular times, saving a new
+version whenever the transform produces a different body or meta. Runs that
+produce no changes aren't saved. Scheduled transforms only run while qri is
+connected (see ` + "`qri connect`" + `).
+
+Schedules are crontab expressions with five fields (minute, hour, day of
+month, month, day of week), one of the descriptors @yearly, @monthly,
+@weekly, @daily, @hourly, or an interval like "@every 6h".
+
+Each run is recorded with its output & any error. Use ` + "`qri schedule logs`" + `
+to show the history of runs.`,
+		Example: `  # Run the transform of me/scraped_prices every morning at 6:30:
+  $ qri schedule add me/scraped_prices "30 6 * * *"
+
+  # Run the transform of me/feed every 15 minutes:
+  $ qri schedule add me/feed "@every 15m"
+
+  # List scheduled datasets:
+  $ qri schedule list
+
+  # Show the history of runs of me/feed:
+  $ qri schedule logs me/feed
+
+  # Stop running the transform of me/feed:
+  $ qri schedule remove me/feed`,
+		Annotations: map[string]string{
+			"group": "dataset",
+		},
+	}
+
+	add := &cobra.Command{
+		Use:   "add DATASET SCHEDULE",
+		Short: "schedule a dataset transform",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Complete(f, args); err != nil {
+				return err
+			}
+			return o.Add()
+		},
+	}
+
+	list := &cobra.Command{
+		Use:   "list",
+		Short: "list scheduled datasets",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Complete(f, args); err != nil {
+				return err
+			}
+			return o.List()
+		},
+	}
+
+	remove := &cobra.Command{
+		Use:   "remove DATASET",
+		Short: "unschedule a dataset transform",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Complete(f, args); err != nil {
+				return err
+			}
+			return o.Remove()
+		},
+	}
+
+	logs := &cobra.Command{
+		Use:   "logs [DATASET]",
+		Short: "show the history of scheduled runs",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Complete(f, args); err != nil {
+				return err
+			}
+			return o.Logs()
+		},
+	}
+	logs.Flags().IntVar(&o.PageSize, "page-size", 25, "page size of results, default 25")
+	logs.Flags().IntVar(&o.Page, "page", 1, "page number of results, default 1")
+
+	cmd.AddCommand(add, list, remove, logs)
+	return cmd
+}
+
+// ScheduleOptions encapsulates state for the schedule command
+type ScheduleOptions struct {
+	ioes.IOStreams
+
+	Ref      string
+	Schedule string
+
+	Page     int
+	PageSize int
+
+	ScheduleMethods *lib.ScheduleMethods
+}
+
+// Complete adds any missing configuration that can only be added just before calling Run
+func (o *ScheduleOptions) Complete(f Factory, args []string) (err error) {
+	if len(args) > 0 {
+		o.Ref = args[0]
+	}
+	if len(args) > 1 {
+		o.Schedule = args[1]
+	}
+	o.ScheduleMethods, err = f.ScheduleMethods()
+	return
+}
+
+// Add executes the schedule add command
+func (o *ScheduleOptions) Add() error {
+	p := &lib.ScheduleParams{
+		Ref:      o.Ref,
+		Schedule: o.Schedule,
+	}
+	res := cron.Job{}
+	if err := o.ScheduleMethods.Add(p, &res); err != nil {
+		if err == repo.ErrEmptyRef {
+			return errors.New(err, "please provide a dataset reference")
+		}
+		return err
+	}
+	printSuccess(o.Out, "scheduled %s, next run %s", res.Ref, res.NextRun.In(StringerLocation).Format(time.UnixDate))
+	return nil
+}
+
+// List executes the schedule list command
+func (o *ScheduleOptions) List() error {
+	res := []*cron.Job{}
+	if err := o.ScheduleMethods.List(&lib.ListParams{}, &res); err != nil {
+		return err
+	}
+	if len(res) == 0 {
+		printInfo(o.Out, "no scheduled datasets")
+		return nil
+	}
+
+	items := make([]fmt.Stringer, len(res))
+	for i, j := range res {
+		items[i] = jobStringer(*j)
+	}
+	return printItems(o.Out, items, 0)
+}
+
+// Remove executes the schedule remove command
+func (o *ScheduleOptions) Remove() error {
+	res := false
+	if err := o.ScheduleMethods.Remove(&o.Ref, &res); err != nil {
+		return err
+	}
+	printSuccess(o.Out, "unscheduled %s", o.Ref)
+	return nil
+}
+
+// Logs executes the schedule logs command
+func (o *ScheduleOptions) Logs() error {
+	// convert Page and PageSize to Limit and Offset
+	page := util.NewPage(o.Page, o.PageSize)
+	p := &lib.ScheduleLogsParams{
+		Ref:    o.Ref,
+		Limit:  page.Limit(),
+		Offset: page.Offset(),
+	}
+	res := []*cron.Run{}
+	if err := o.ScheduleMethods.Logs(p, &res); err != nil {
+		return err
+	}
+	if len(res) == 0 {
+		printInfo(o.Out, "no scheduled runs")
+		return nil
+	}
+
+	items := make([]fmt.Stringer, len(res))
+	for i, r := range res {
+		items[i] = runStringer(*r)
+	}
+	return printItems(o.Out, items, page.Offset())
+}
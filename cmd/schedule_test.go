@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestScheduleCommands(t *testing.T) {
+	run := NewTestRunner(t, "test_peer", "qri_test_schedule")
+	defer run.Delete()
+
+	run.MustExec(t, "qri save --body=testdata/movies/body_ten.csv me/test_movies")
+	if err := run.ExecCommand("qri schedule add me/test_movies @daily"); err == nil {
+		t.Error("expected scheduling a dataset without a transform to error")
+	}
+
+	run.MustExec(t, "qri save --file=testdata/movies/tf_one_movie.star me/tf_movies")
+	if err := run.ExecCommand("qri schedule add me/tf_movies \"not a schedule\""); err == nil {
+		t.Error("expected an invalid schedule to error")
+	}
+
+	output := run.MustExec(t, "qri schedule add me/tf_movies @hourly")
+	if !strings.Contains(output, "scheduled test_peer/tf_movies") {
+		t.Errorf("unexpected add output: %q", output)
+	}
+
+	output = run.MustExec(t, "qri schedule list")
+	for _, expect := range []string{"test_peer/tf_movies", "@hourly"} {
+		if !strings.Contains(output, expect) {
+			t.Errorf("expected schedule list to contain %q, got: %q", expect, output)
+		}
+	}
+
+	output = run.MustExec(t, "qri schedule logs me/tf_movies")
+	if !strings.Contains(output, "no scheduled runs") {
+		t.Errorf("expected no runs before the scheduler runs, got: %q", output)
+	}
+
+	run.MustExec(t, "qri schedule remove me/tf_movies")
+	output = run.MustExec(t, "qri schedule list")
+	if strings.Contains(output, "tf_movies") {
+		t.Errorf("expected removed schedule to be dropped from list, got: %q", output)
+	}
+	if err := run.ExecCommand("qri schedule remove me/tf_movies"); err == nil {
+		t.Error("expected removing an unscheduled dataset to error")
+	}
+}
@@ -4,10 +4,12 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"time"
 
 	util "github.com/qri-io/apiutil"
 	"github.com/qri-io/dataset"
 	"github.com/qri-io/ioes"
+	"github.com/qri-io/qri/dsref"
 	"github.com/qri-io/qri/errors"
 	"github.com/qri-io/qri/lib"
 	"github.com/spf13/cobra"
@@ -21,9 +23,17 @@ func NewSearchCommand(f Factory, ioStreams ioes.IOStreams) *cobra.Command {
 		Short: "search the registry for datasets",
 		Long: `Search datasets & peers that match your query. Search pings the qri registry. 
 
-Any dataset that has been published to the registry is available for search.`,
+Any dataset that has been published to the registry is available for search.
+
+Use --remote to search the datasets a remote holds instead. Remote searches
+match names, usernames, meta & structure fields, and can be filtered by
+--username, --body-format & --since. Run a remote search without a query to
+browse everything the remote holds.`,
 		Example: `  # Search for datasets featuring "annual population":
-  $ qri search "annual population"`,
+  $ qri search "annual population"
+
+  # Search a remote for csv datasets updated this year:
+  $ qri search --remote my_remote --body-format csv --since 2020-01-01 population`,
 		Annotations: map[string]string{
 			"group": "network",
 		},
@@ -42,6 +52,10 @@ Any dataset that has been published to the registry is available for search.`,
 	cmd.Flags().StringVarP(&o.Format, "format", "f", "", "set output format [json|simple]")
 	cmd.Flags().IntVar(&o.PageSize, "page-size", 25, "page size of results, default 25")
 	cmd.Flags().IntVar(&o.Page, "page", 1, "page number of results, default 1")
+	cmd.Flags().StringVar(&o.RemoteName, "remote", "", "name of remote to search")
+	cmd.Flags().StringVar(&o.Username, "username", "", "only show datasets owned by username, requires --remote")
+	cmd.Flags().StringVar(&o.BodyFormat, "body-format", "", "only show datasets with a body format, requires --remote")
+	cmd.Flags().StringVar(&o.Since, "since", "", "only show datasets updated since a date (YYYY-MM-DD), requires --remote")
 
 	return cmd
 }
@@ -56,7 +70,13 @@ type SearchOptions struct {
 	Page     int
 	// Reindex bool
 
+	RemoteName string
+	Username   string
+	BodyFormat string
+	Since      string
+
 	SearchMethods *lib.SearchMethods
+	RemoteMethods *lib.RemoteMethods
 }
 
 // Complete adds any missing configuration that can only be added just before calling Run
@@ -64,13 +84,19 @@ func (o *SearchOptions) Complete(f Factory, args []string) (err error) {
 	if len(args) != 0 {
 		o.Query = args[0]
 	}
-	o.SearchMethods, err = f.SearchMethods()
+	if o.SearchMethods, err = f.SearchMethods(); err != nil {
+		return
+	}
+	o.RemoteMethods, err = f.RemoteMethods()
 	return
 }
 
 // Validate checks that any user inputs are valid
 func (o *SearchOptions) Validate() error {
-	if o.Query == "" {
+	if o.RemoteName == "" && (o.Username != "" || o.BodyFormat != "" || o.Since != "") {
+		return errors.New(lib.ErrBadArgs, "--username, --body-format & --since filters require --remote")
+	}
+	if o.Query == "" && o.RemoteName == "" {
 		return errors.New(lib.ErrBadArgs, "please provide search parameters, for example:\n    $ qri search census\n    $ qri search 'census 2018'\nsee `qri search --help` for more information")
 	}
 	return nil
@@ -86,6 +112,10 @@ func (o *SearchOptions) Run() (err error) {
 	// convert Page and PageSize to Limit and Offset
 	page := util.NewPage(o.Page, o.PageSize)
 
+	if o.RemoteName != "" {
+		return o.searchRemote(page)
+	}
+
 	p := &lib.SearchParams{
 		QueryString: o.Query,
 		Limit:       page.Limit(),
@@ -153,3 +183,53 @@ func (o *SearchOptions) Run() (err error) {
 // 	ref.Name = id[1]
 // 	return ref, nil
 // }
+
+// searchRemote queries the datasets a remote holds
+func (o *SearchOptions) searchRemote(page util.Page) error {
+	p := &lib.RemoteSearchParams{
+		RemoteName: o.RemoteName,
+		Query:      o.Query,
+		Username:   o.Username,
+		Format:     o.BodyFormat,
+		Limit:      page.Limit(),
+		Offset:     page.Offset(),
+	}
+	if o.Since != "" {
+		since, err := time.Parse("2006-01-02", o.Since)
+		if err != nil {
+			return errors.New(lib.ErrBadArgs, fmt.Sprintf("invalid --since date %q, expected YYYY-MM-DD", o.Since))
+		}
+		p.UpdatedSince = since
+	}
+
+	results := []dsref.VersionInfo{}
+	if err := o.RemoteMethods.Search(p, &results); err != nil {
+		return err
+	}
+	o.StopSpinner()
+
+	switch o.Format {
+	case "":
+		fmt.Fprintf(o.Out, "showing %d results for '%s' on %s\n", len(results), o.Query, o.RemoteName)
+		items := make([]fmt.Stringer, len(results))
+		for i, result := range results {
+			items[i] = versionInfoStringer(result)
+		}
+		printItems(o.Out, items, page.Offset())
+	case "simple":
+		items := make([]string, len(results))
+		for i, r := range results {
+			items[i] = r.Alias()
+		}
+		printlnStringItems(o.Out, items)
+	case dataset.JSONDataFormat.String():
+		data, err := json.MarshalIndent(results, "", "  ")
+		if err != nil {
+			return err
+		}
+		printToPager(o.Out, bytes.NewBuffer(data))
+	default:
+		return fmt.Errorf("unrecognized format: %s", o.Format)
+	}
+	return nil
+}
@@ -6,12 +6,16 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/qri-io/ioes"
+	"github.com/qri-io/qri/config"
 	"github.com/qri-io/qri/errors"
 	"github.com/qri-io/qri/lib"
 	"github.com/qri-io/qri/registry/regclient"
+	"github.com/qri-io/qri/registry/regserver"
+	repotest "github.com/qri-io/qri/repo/test"
 )
 
 func TestSearchComplete(t *testing.T) {
@@ -283,3 +287,60 @@ var jsonSearchResponse = `[
     }
   }
 ]`
+
+func TestSearchValidateRemoteFilters(t *testing.T) {
+	opt := &SearchOptions{RemoteName: "my_remote"}
+	if err := opt.Validate(); err != nil {
+		t.Errorf("expected remote search without a query to be valid, got: %s", err)
+	}
+
+	opt = &SearchOptions{Query: "population", BodyFormat: "csv"}
+	if err := opt.Validate(); err == nil {
+		t.Error("expected filters without --remote to be invalid")
+	}
+}
+
+func TestSearchRemote(t *testing.T) {
+	run := NewTestRunner(t, "test_peer", "qri_test_search_remote")
+	defer run.Delete()
+
+	reg, cleanup, err := regserver.NewTempRegistry("temp_registry", "", repotest.NewTestCrypto())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+
+	_, httpServer := regserver.NewMockServerRegistry(*reg)
+	defer httpServer.Close()
+	cfg := run.RepoRoot.GetConfig()
+	cfg.Registry.Location = httpServer.URL
+	cfg.Remotes = &config.Remotes{"my_remote": httpServer.URL}
+	if err := run.RepoRoot.WriteConfigFile(); err != nil {
+		t.Fatal(err)
+	}
+
+	run.MustExec(t, "qri save me/movies --body testdata/movies/body_ten.csv")
+	run.MustExec(t, "qri publish me/movies")
+
+	output := run.MustExec(t, "qri search --remote my_remote --format simple movie")
+	if !strings.HasSuffix(output, "/movies\n") {
+		t.Fatalf("expected search to find published dataset, got: %q", output)
+	}
+	// the remote may list the dataset under a canonicalized username
+	alias := output
+	username := strings.Split(output, "/")[0]
+
+	output = run.MustExec(t, "qri search --remote my_remote --format simple --body-format json")
+	if output != "" {
+		t.Errorf("expected format filter to exclude csv dataset, got: %q", output)
+	}
+
+	output = run.MustExec(t, "qri search --remote my_remote --format simple --since 2000-01-01 --username "+username)
+	if output != alias {
+		t.Errorf("expected filters to match published dataset, got: %q", output)
+	}
+
+	if err := run.ExecCommand("qri search --remote my_remote --since yesterday"); err == nil {
+		t.Error("expected invalid --since date to fail")
+	}
+}
@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestSaveSQLTransform(t *testing.T) {
+	run := NewTestRunner(t, "test_peer", "qri_test_sql_transform")
+	defer run.Delete()
+
+	run.MustExec(t, "qri save --body=testdata/movies/body_ten.csv me/test_movies")
+	run.MustExec(t, "qri save --file=testdata/movies/tf_long_movies.sql me/long_movies")
+
+	output := run.MustExec(t, "qri get body --format json me/long_movies")
+	rows := []map[string]interface{}{}
+	if err := json.Unmarshal([]byte(output), &rows); err != nil {
+		t.Fatalf("decoding body: %s\n%s", err, output)
+	}
+	if len(rows) != 1 {
+		t.Fatalf("expected one movie longer than 170 minutes. got: %v", rows)
+	}
+	for _, title := range rows[0] {
+		if s, ok := title.(string); !ok || !strings.HasPrefix(s, "Avatar") {
+			t.Errorf("expected body to be the query results. got: %v", rows)
+		}
+	}
+
+	output = run.MustExec(t, "qri get transform.syntax me/long_movies")
+	if !strings.Contains(output, "sql") {
+		t.Errorf("expected sql transform syntax. got: %q", output)
+	}
+
+	output = run.MustExec(t, "qri deps me/long_movies")
+	if !strings.Contains(output, "test_peer/test_movies") {
+		t.Errorf("expected upstream to list test_peer/test_movies, got: %q", output)
+	}
+}
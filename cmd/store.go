@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/qri-io/ioes"
+	"github.com/qri-io/qri/lib"
+	"github.com/qri-io/qri/repo/storage"
+	"github.com/spf13/cobra"
+)
+
+// NewStoreCommand creates a `qri store` command for working with the store
+// dataset versions are kept in
+func NewStoreCommand(f Factory, ioStreams ioes.IOStreams) *cobra.Command {
+	o := &StoreOptions{IOStreams: ioStreams}
+	cmd := &cobra.Command{
+		Use:   "store",
+		Short: "manage the store dataset versions are kept in",
+		Long: `Store commands work with the content-addressed store qri keeps dataset
+versions in. The store is set with the store section of the qri config:
+
+  ipfs       an IPFS repo, the default
+  ipfs_http  an IPFS node accessed over its HTTP API
+  fs         a plain directory tree, kept at store.path
+  s3         an S3-compatible object store, configured with store.options`,
+		Annotations: map[string]string{
+			"group": "other",
+		},
+	}
+
+	migrate := &cobra.Command{
+		Use:   "migrate",
+		Short: "copy all data into a new store & switch to it",
+		Long: `Migrate copies every block & pin in the configured store into a new store,
+then updates your config to use the new store. Data is not removed from the
+original store. Migrations that are interrupted can be safely re-run, blocks
+that have already been copied are skipped.
+
+s3 stores accept the options endpoint, bucket, region, prefix, accessKeyID &
+secretAccessKey. Credentials default to the AWS_ACCESS_KEY_ID &
+AWS_SECRET_ACCESS_KEY environment variables.`,
+		Example: `  # Move the store into a plain directory:
+  $ qri store migrate --type fs --path ~/qri_store
+
+  # Move the store into a MinIO bucket:
+  $ qri store migrate --type s3 --option endpoint=http://localhost:9000 \
+    --option bucket=qri`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Complete(f); err != nil {
+				return err
+			}
+			return o.Migrate()
+		},
+	}
+
+	migrate.Flags().StringVar(&o.Type, "type", "", "type of store to migrate to, one of fs or s3")
+	migrate.MarkFlagRequired("type")
+	migrate.Flags().StringVar(&o.Path, "path", "", "location of the new store")
+	migrate.Flags().StringSliceVar(&o.Options, "option", nil, "store option as key=value, can be repeated")
+
+	cmd.AddCommand(migrate)
+	return cmd
+}
+
+// StoreOptions encapsulates state for the store command
+type StoreOptions struct {
+	ioes.IOStreams
+
+	Type    string
+	Path    string
+	Options []string
+
+	ConfigMethods *lib.ConfigMethods
+}
+
+// Complete adds any missing configuration that can only be added just before calling Run
+func (o *StoreOptions) Complete(f Factory) (err error) {
+	o.ConfigMethods, err = f.ConfigMethods()
+	return
+}
+
+// Migrate copies the configured store into a new store
+func (o *StoreOptions) Migrate() error {
+	p := &lib.MigrateStoreParams{
+		Type:    o.Type,
+		Options: map[string]interface{}{},
+	}
+	if o.Path != "" {
+		path, err := filepath.Abs(o.Path)
+		if err != nil {
+			return err
+		}
+		p.Path = path
+	}
+	for _, opt := range o.Options {
+		kv := strings.SplitN(opt, "=", 2)
+		if len(kv) != 2 {
+			return fmt.Errorf("invalid store option %q, expected key=value", opt)
+		}
+		p.Options[kv[0]] = kv[1]
+	}
+
+	printInfo(o.ErrOut, "migrating store to %s...", o.Type)
+	res := storage.MigrateResult{}
+	if err := o.ConfigMethods.MigrateStore(p, &res); err != nil {
+		return err
+	}
+
+	printSuccess(o.Out, "copied %d blocks (%d already present) & %d pins. qri will use the %s store from now on", res.Blocks, res.Skipped, res.Pins, o.Type)
+	return nil
+}
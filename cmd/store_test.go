@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestStoreMigrate(t *testing.T) {
+	run := NewTestRunner(t, "test_peer", "qri_test_store_migrate")
+	defer run.Delete()
+
+	run.MustExec(t, "qri save --body=testdata/movies/body_ten.csv me/test_movies")
+	expectBody := run.MustExec(t, "qri get body me/test_movies")
+
+	dir := filepath.Join(run.MakeTmpDir(t, "store_migrate"), "store")
+	output := run.MustExec(t, "qri store migrate --type fs --path "+dir)
+	if !strings.Contains(output, "qri will use the fs store") {
+		t.Errorf("expected success message, got: %q", output)
+	}
+	if !run.FileExists(filepath.Join(dir, "blocks")) {
+		t.Error("expected blocks to be written to the new store")
+	}
+
+	output = run.MustExec(t, "qri config get store.type")
+	if !strings.Contains(output, "fs") {
+		t.Errorf("expected config store type to be fs, got: %q", output)
+	}
+
+	// dataset versions are read from the new store
+	if body := run.MustExec(t, "qri get body me/test_movies"); body != expectBody {
+		t.Errorf("body mismatch after migration. want:\n%s\ngot:\n%s", expectBody, body)
+	}
+
+	if err := run.ExecCommand("qri store migrate --type fs --path " + dir); err == nil {
+		t.Error("expected migrating into the configured store to fail")
+	}
+}
@@ -2,16 +2,22 @@ package cmd
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"strings"
 	"time"
 
 	"github.com/dustin/go-humanize"
 	"github.com/fatih/color"
+	"github.com/qri-io/deepdiff"
 	"github.com/qri-io/qri/config"
+	"github.com/qri-io/qri/cron"
 	"github.com/qri-io/qri/dsref"
 	"github.com/qri-io/qri/lib"
+	"github.com/qri-io/qri/remote"
 	reporef "github.com/qri-io/qri/repo/ref"
+	"github.com/qri-io/qri/startf"
+	"github.com/qri-io/qri/webhook"
 )
 
 type peerStringer config.ProfilePod
@@ -263,3 +269,310 @@ func (s dslogItemStringer) String() string {
 
 	return msg
 }
+
+type webhookLogEntryStringer webhook.LogEntry
+
+func (s webhookLogEntryStringer) String() string {
+	faint := color.New(color.Faint).SprintFunc()
+
+	var status string
+	switch s.Status {
+	case webhook.StatusDelivered:
+		status = color.New(color.FgGreen).Sprint(s.Status)
+	case webhook.StatusRetrying:
+		status = color.New(color.FgYellow).Sprint(s.Status)
+	default:
+		status = color.New(color.FgRed).Sprint(s.Status)
+	}
+
+	w := &bytes.Buffer{}
+	fmt.Fprintf(w, "%s (attempt %d)\n", status, s.Attempt)
+	fmt.Fprintf(w, "%s%s\n", faint("Event:    "), s.Topic)
+	fmt.Fprintf(w, "%s%s\n", faint("URL:      "), s.URL)
+	fmt.Fprintf(w, "%s%s\n", faint("Date:     "), s.Timestamp.In(StringerLocation).Format(time.UnixDate))
+	fmt.Fprintf(w, "%s%s\n", faint("Delivery: "), s.DeliveryID)
+	if s.StatusCode != 0 {
+		fmt.Fprintf(w, "%s%d\n", faint("Response: "), s.StatusCode)
+	}
+	if s.Error != "" {
+		fmt.Fprintf(w, "%s%s\n", faint("Error:    "), s.Error)
+	}
+	if s.Status == webhook.StatusRetrying {
+		fmt.Fprintf(w, "%s%s\n", faint("Retry at: "), s.NextAttempt.In(StringerLocation).Format(time.UnixDate))
+	}
+	fmt.Fprintln(w, "")
+	return w.String()
+}
+
+type branchInfoStringer lib.BranchInfo
+
+func (s branchInfoStringer) String() string {
+	name := color.New(color.FgGreen, color.Bold).SprintFunc()
+	faint := color.New(color.Faint).SprintFunc()
+
+	w := &bytes.Buffer{}
+	fmt.Fprintf(w, "%s\n", name(s.Name))
+	if s.From != "" {
+		fmt.Fprintf(w, "%s%s\n", faint("From:     "), s.From)
+	}
+	fmt.Fprintf(w, "%s%s\n", faint("Head:     "), s.Path)
+	fmt.Fprintf(w, "%s%d\n", faint("Versions: "), s.NumVersions)
+	if !s.CommitTime.IsZero() {
+		fmt.Fprintf(w, "%s%s\n", faint("Date:     "), s.CommitTime.In(StringerLocation).Format(time.UnixDate))
+	}
+	fmt.Fprintln(w, "")
+	return w.String()
+}
+
+type mirrorStringer config.Mirror
+
+func (s mirrorStringer) String() string {
+	name := color.New(color.FgGreen, color.Bold).SprintFunc()
+	faint := color.New(color.Faint).SprintFunc()
+
+	interval := s.Interval
+	if interval == "" {
+		interval = config.DefaultMirrorInterval.String()
+	}
+
+	w := &bytes.Buffer{}
+	fmt.Fprintf(w, "%s\n", name(s.Upstream))
+	fmt.Fprintf(w, "%s%s\n", faint("Patterns: "), strings.Join(s.Patterns, ", "))
+	fmt.Fprintf(w, "%s%s\n", faint("Interval: "), interval)
+	fmt.Fprintln(w, "")
+	return w.String()
+}
+
+type mirrorStatusStringer remote.MirrorStatus
+
+func (s mirrorStatusStringer) String() string {
+	name := color.New(color.FgGreen, color.Bold).SprintFunc()
+	faint := color.New(color.Faint).SprintFunc()
+
+	w := &bytes.Buffer{}
+	fmt.Fprintf(w, "%s\n", name(s.Ref))
+	fmt.Fprintf(w, "%s%s\n", faint("Upstream: "), s.Upstream)
+	if s.Path != "" {
+		fmt.Fprintf(w, "%s%s\n", faint("Path:     "), s.Path)
+	}
+	fmt.Fprintf(w, "%s%s\n", faint("Checked:  "), s.LastCheck.In(StringerLocation).Format(time.UnixDate))
+	if !s.LastSync.IsZero() {
+		fmt.Fprintf(w, "%s%s\n", faint("Synced:   "), s.LastSync.In(StringerLocation).Format(time.UnixDate))
+	}
+	if s.Error != "" {
+		fmt.Fprintf(w, "%s%s\n", faint("Error:    "), color.New(color.FgRed).Sprint(s.Error))
+	}
+	fmt.Fprintln(w, "")
+	return w.String()
+}
+
+type profileUsageStringer remote.ProfileUsage
+
+func (s profileUsageStringer) String() string {
+	name := color.New(color.FgGreen, color.Bold).SprintFunc()
+	faint := color.New(color.Faint).SprintFunc()
+
+	quota := "none"
+	if s.Quota > 0 {
+		quota = fmt.Sprintf("%s (%d%% used)", humanize.Bytes(uint64(s.Quota)), s.Bytes*100/uint64(s.Quota))
+	}
+
+	w := &bytes.Buffer{}
+	fmt.Fprintf(w, "%s\n", name(s.ProfileID))
+	fmt.Fprintf(w, "%s%d\n", faint("Datasets: "), s.Datasets)
+	fmt.Fprintf(w, "%s%d\n", faint("Versions: "), s.Versions)
+	fmt.Fprintf(w, "%s%s\n", faint("Size:     "), humanize.Bytes(s.Bytes))
+	fmt.Fprintf(w, "%s%s\n", faint("Quota:    "), quota)
+	fmt.Fprintln(w, "")
+	return w.String()
+}
+
+type subscriptionStringer remote.Subscription
+
+func (s subscriptionStringer) String() string {
+	name := color.New(color.FgGreen, color.Bold).SprintFunc()
+	faint := color.New(color.Faint).SprintFunc()
+
+	remoteName := s.RemoteName
+	if remoteName == "" {
+		remoteName = "registry"
+	}
+
+	w := &bytes.Buffer{}
+	fmt.Fprintf(w, "%s\n", name(s.Ref))
+	fmt.Fprintf(w, "%s%s (%s)\n", faint("Remote:     "), remoteName, s.RemoteAddr)
+	fmt.Fprintf(w, "%s%t\n", faint("Auto pull:  "), s.AutoPull)
+	fmt.Fprintf(w, "%s%s\n", faint("Subscribed: "), s.Subscribed.In(StringerLocation).Format(time.UnixDate))
+	fmt.Fprintln(w, "")
+	return w.String()
+}
+
+type publishPreviewStringer lib.PublishPreview
+
+func (p publishPreviewStringer) String() string {
+	name := color.New(color.FgGreen, color.Bold).SprintFunc()
+	faint := color.New(color.Faint).SprintFunc()
+	warn := color.New(color.FgYellow).SprintFunc()
+
+	w := &bytes.Buffer{}
+	fmt.Fprintf(w, "%s\n", name(p.Ref.Alias()))
+	fmt.Fprintf(w, "%s%s\n", faint("Remote:   "), p.RemoteAddr)
+	fmt.Fprintf(w, "%s%d of %d blocks, %s of %s\n", faint("Transfer: "), p.Blocks, p.TotalBlocks, humanize.Bytes(p.Bytes), humanize.Bytes(p.TotalBytes))
+	fmt.Fprintf(w, "%s%d of %d already on remote\n", faint("Versions: "), len(p.HasVersions), p.Versions)
+	if p.Diverged {
+		fmt.Fprintf(w, "%s%s\n", faint("History:  "), warn(fmt.Sprintf("diverged, remote head is %s", p.RemoteHead)))
+	} else if p.RemoteHead != "" {
+		fmt.Fprintf(w, "%sremote head %s is in local history\n", faint("History:  "), p.RemoteHead)
+	} else {
+		fmt.Fprintf(w, "%snew to remote\n", faint("History:  "))
+	}
+	if p.Rejection != "" {
+		fmt.Fprintf(w, "%s%s\n", faint("Accepted: "), warn("no, "+p.Rejection))
+	} else {
+		fmt.Fprintf(w, "%syes\n", faint("Accepted: "))
+	}
+	return w.String()
+}
+
+type applyResultStringer lib.ApplyResult
+
+func (r applyResultStringer) String() string {
+	title := color.New(color.Bold).SprintFunc()
+	faint := color.New(color.Faint).SprintFunc()
+
+	w := &bytes.Buffer{}
+	fmt.Fprintf(w, "%s%s\n", faint("Duration: "), r.Duration)
+	for _, name := range []string{"meta", "structure"} {
+		deltas, ok := r.Changes[name]
+		if !ok {
+			continue
+		}
+		fmt.Fprintf(w, "\n%s\n", title(name+" changes"))
+		deepdiff.FormatPretty(w, deltas, !color.NoColor)
+	}
+
+	if !r.BodyChanged {
+		fmt.Fprintf(w, "\n%s\n", faint("transform didn't set a body"))
+		return w.String()
+	}
+	fmt.Fprintf(w, "\n%s\n", title("body preview"))
+	preview, err := json.MarshalIndent(r.BodyPreview, "", "  ")
+	if err != nil {
+		fmt.Fprintf(w, "error encoding preview: %s\n", err)
+	} else {
+		fmt.Fprintf(w, "%s\n", preview)
+	}
+
+	fmt.Fprintf(w, "\n%s\n", title("body changes"))
+	if r.BodyStat != nil {
+		deepdiff.FormatPrettyStats(w, r.BodyStat, !color.NoColor)
+		w.WriteByte('\n')
+	}
+	deepdiff.FormatPretty(w, r.BodyDiff, !color.NoColor)
+	return w.String()
+}
+
+type jobStringer cron.Job
+
+func (s jobStringer) String() string {
+	name := color.New(color.FgGreen, color.Bold).SprintFunc()
+	faint := color.New(color.Faint).SprintFunc()
+
+	w := &bytes.Buffer{}
+	fmt.Fprintf(w, "%s\n", name(s.Ref))
+	fmt.Fprintf(w, "%s%s\n", faint("Schedule: "), s.Schedule)
+	if !s.LastRun.IsZero() {
+		fmt.Fprintf(w, "%s%s\n", faint("Last run: "), s.LastRun.In(StringerLocation).Format(time.UnixDate))
+	}
+	fmt.Fprintf(w, "%s%s\n", faint("Next run: "), s.NextRun.In(StringerLocation).Format(time.UnixDate))
+	fmt.Fprintln(w, "")
+	return w.String()
+}
+
+type runStringer cron.Run
+
+func (s runStringer) String() string {
+	name := color.New(color.FgGreen, color.Bold).SprintFunc()
+	faint := color.New(color.Faint).SprintFunc()
+	warn := color.New(color.FgYellow).SprintFunc()
+
+	status := string(s.Status)
+	if s.Status == cron.RunStatusFailed {
+		status = warn(status)
+	}
+
+	w := &bytes.Buffer{}
+	fmt.Fprintf(w, "%s\n", name(s.Ref))
+	fmt.Fprintf(w, "%s%s\n", faint("Status:   "), status)
+	fmt.Fprintf(w, "%s%s\n", faint("Date:     "), s.Start.In(StringerLocation).Format(time.UnixDate))
+	fmt.Fprintf(w, "%s%s\n", faint("Duration: "), s.Duration.Round(time.Millisecond))
+	if s.Path != "" {
+		fmt.Fprintf(w, "%s%s\n", faint("Path:     "), s.Path)
+	}
+	if s.Error != "" {
+		fmt.Fprintf(w, "%s%s\n", faint("Error:    "), s.Error)
+	}
+	if s.Log != "" {
+		fmt.Fprintf(w, "%s\n%s\n", faint("Log:"), strings.TrimRight(s.Log, "\n"))
+	}
+	fmt.Fprintln(w, "")
+	return w.String()
+}
+
+type depsStringer lib.DepsResult
+
+func (s depsStringer) String() string {
+	name := color.New(color.FgGreen, color.Bold).SprintFunc()
+	faint := color.New(color.Faint).SprintFunc()
+	warn := color.New(color.FgYellow).SprintFunc()
+
+	w := &bytes.Buffer{}
+	fmt.Fprintf(w, "%s\n", name(s.Ref))
+	fmt.Fprintf(w, "%s%s\n\n", faint("Path: "), s.Path)
+
+	list := func(title string, deps []lib.DepInfo) {
+		fmt.Fprintf(w, "%s\n", title)
+		if len(deps) == 0 {
+			fmt.Fprintf(w, "  %s\n", faint("none"))
+		}
+		for _, d := range deps {
+			fmt.Fprintf(w, "  %s %s", d.Ref, faint(d.Path))
+			if d.Stale {
+				fmt.Fprintf(w, " %s", warn("stale"))
+			}
+			fmt.Fprintln(w, "")
+		}
+	}
+	list("Upstream:", s.Upstream)
+	fmt.Fprintln(w, "")
+	list("Downstream:", s.Downstream)
+	return w.String()
+}
+
+type testReportStringer startf.TestReport
+
+func (r testReportStringer) String() string {
+	pass := color.New(color.FgGreen, color.Bold).SprintFunc()
+	fail := color.New(color.FgRed, color.Bold).SprintFunc()
+	faint := color.New(color.Faint).SprintFunc()
+
+	w := &bytes.Buffer{}
+	for _, t := range r.Tests {
+		if t.Passed {
+			fmt.Fprintf(w, "%s %s %s\n", pass("PASS"), t.Name, faint(t.Duration))
+			continue
+		}
+		fmt.Fprintf(w, "%s %s %s\n", fail("FAIL"), t.Name, faint(t.Duration))
+		for _, line := range strings.Split(strings.TrimSpace(t.Error), "\n") {
+			fmt.Fprintf(w, "    %s\n", line)
+		}
+		if t.Output != "" {
+			fmt.Fprintf(w, "    %s\n", faint("output:"))
+			for _, line := range strings.Split(strings.TrimSpace(t.Output), "\n") {
+				fmt.Fprintf(w, "    %s\n", line)
+			}
+		}
+	}
+	fmt.Fprintf(w, "\n%d passed, %d failed %s\n", r.Passed, r.Failed, faint(r.Duration))
+	return w.String()
+}
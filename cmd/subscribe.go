@@ -0,0 +1,157 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/qri-io/ioes"
+	"github.com/qri-io/qri/lib"
+	"github.com/qri-io/qri/remote"
+	"github.com/spf13/cobra"
+)
+
+// NewSubscribeCommand creates a `qri subscribe` subcommand for receiving
+// notifications of new dataset versions from a remote
+func NewSubscribeCommand(f Factory, ioStreams ioes.IOStreams) *cobra.Command {
+	o := &SubscribeOptions{IOStreams: ioStreams}
+	cmd := &cobra.Command{
+		Use:   "subscribe [DATASET]",
+		Short: "get notified when new versions of a dataset are pushed to a remote",
+		Long: `Subscribe asks a remote to notify you when new versions of a dataset are
+pushed to it. While qri is connected, notifications arrive over p2p & a
+notification stream from the remote. With --pull, new versions are pulled as
+soon as the remote announces them.
+
+Run without arguments to list your subscriptions.`,
+		Example: `  # Subscribe to a dataset on the registry:
+  $ qri subscribe b5/world_bank_population
+
+  # Pull new versions automatically:
+  $ qri subscribe --pull b5/world_bank_population
+
+  # List subscriptions:
+  $ qri subscribe`,
+		Annotations: map[string]string{
+			"group": "network",
+		},
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Complete(f, args); err != nil {
+				return err
+			}
+			return o.Run()
+		},
+	}
+
+	cmd.Flags().StringVar(&o.RemoteName, "remote", "", "name of remote to subscribe on")
+	cmd.Flags().BoolVar(&o.AutoPull, "pull", false, "pull new versions when notified")
+
+	return cmd
+}
+
+// SubscribeOptions encapsulates state for the subscribe command
+type SubscribeOptions struct {
+	ioes.IOStreams
+
+	Ref        string
+	RemoteName string
+	AutoPull   bool
+
+	RemoteMethods *lib.RemoteMethods
+}
+
+// Complete adds any missing configuration that can only be added just before calling Run
+func (o *SubscribeOptions) Complete(f Factory, args []string) (err error) {
+	if len(args) > 0 {
+		o.Ref = args[0]
+	}
+	o.RemoteMethods, err = f.RemoteMethods()
+	return
+}
+
+// Run executes the subscribe command
+func (o *SubscribeOptions) Run() error {
+	if o.Ref == "" {
+		res := []*remote.Subscription{}
+		if err := o.RemoteMethods.Subscriptions(nil, &res); err != nil {
+			return err
+		}
+		if len(res) == 0 {
+			printInfo(o.Out, "no subscriptions")
+			return nil
+		}
+		items := make([]fmt.Stringer, len(res))
+		for i, sub := range res {
+			items[i] = subscriptionStringer(*sub)
+		}
+		return printItems(o.Out, items, 0)
+	}
+
+	p := &lib.SubscribeParams{
+		Ref:        o.Ref,
+		RemoteName: o.RemoteName,
+		AutoPull:   o.AutoPull,
+	}
+	res := &remote.Subscription{}
+	if err := o.RemoteMethods.Subscribe(p, res); err != nil {
+		return err
+	}
+	printSuccess(o.Out, "subscribed to %s", res.Ref)
+	return nil
+}
+
+// NewUnsubscribeCommand creates a `qri unsubscribe` subcommand for stopping
+// dataset notifications
+func NewUnsubscribeCommand(f Factory, ioStreams ioes.IOStreams) *cobra.Command {
+	o := &UnsubscribeOptions{IOStreams: ioStreams}
+	cmd := &cobra.Command{
+		Use:   "unsubscribe DATASET",
+		Short: "stop notifications for a dataset",
+		Example: `  # Unsubscribe from a dataset:
+  $ qri unsubscribe b5/world_bank_population`,
+		Annotations: map[string]string{
+			"group": "network",
+		},
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Complete(f, args); err != nil {
+				return err
+			}
+			return o.Run()
+		},
+	}
+
+	cmd.Flags().StringVar(&o.RemoteName, "remote", "", "name of remote to unsubscribe on")
+
+	return cmd
+}
+
+// UnsubscribeOptions encapsulates state for the unsubscribe command
+type UnsubscribeOptions struct {
+	ioes.IOStreams
+
+	Ref        string
+	RemoteName string
+
+	RemoteMethods *lib.RemoteMethods
+}
+
+// Complete adds any missing configuration that can only be added just before calling Run
+func (o *UnsubscribeOptions) Complete(f Factory, args []string) (err error) {
+	o.Ref = args[0]
+	o.RemoteMethods, err = f.RemoteMethods()
+	return
+}
+
+// Run executes the unsubscribe command
+func (o *UnsubscribeOptions) Run() error {
+	p := &lib.SubscribeParams{
+		Ref:        o.Ref,
+		RemoteName: o.RemoteName,
+	}
+	res := false
+	if err := o.RemoteMethods.Unsubscribe(p, &res); err != nil {
+		return err
+	}
+	printSuccess(o.Out, "unsubscribed from %s", o.Ref)
+	return nil
+}
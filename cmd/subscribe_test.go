@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/qri-io/qri/remote"
+)
+
+func TestSubscribeList(t *testing.T) {
+	run := NewTestRunner(t, "test_peer", "qri_test_subscribe_list")
+	defer run.Delete()
+
+	output := run.MustExec(t, "qri subscribe")
+	if !strings.Contains(output, "no subscriptions") {
+		t.Errorf("expected empty list message, got: %q", output)
+	}
+
+	subs, err := remote.NewSubscriptions(filepath.Join(run.RepoRoot.QriPath, "subscriptions"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sub := &remote.Subscription{
+		Ref:        "b5/world_bank_population",
+		RemoteAddr: "http://localhost:2503",
+		AutoPull:   true,
+	}
+	if err := subs.Put(sub); err != nil {
+		t.Fatal(err)
+	}
+
+	output = run.MustExec(t, "qri subscribe")
+	for _, expect := range []string{"b5/world_bank_population", "registry (http://localhost:2503)", "Auto pull:  true"} {
+		if !strings.Contains(output, expect) {
+			t.Errorf("expected output to contain %q, got: %q", expect, output)
+		}
+	}
+
+	if err := run.ExecCommand("qri unsubscribe me/not_subscribed"); err == nil {
+		t.Error("expected unsubscribing from an unknown dataset to fail")
+	}
+}
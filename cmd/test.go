@@ -0,0 +1,120 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/qri-io/ioes"
+	"github.com/qri-io/qri/errors"
+	"github.com/qri-io/qri/lib"
+	"github.com/qri-io/qri/startf"
+	"github.com/spf13/cobra"
+)
+
+// NewTestCommand creates a `qri test` subcommand for running transform tests
+func NewTestCommand(f Factory, ioStreams ioes.IOStreams) *cobra.Command {
+	o := &TestOptions{IOStreams: ioStreams}
+	cmd := &cobra.Command{
+		Use:   "test SCRIPT",
+		Short: "run the tests of a transform script",
+		Long: `Test runs unit tests for a transform script without saving a dataset.
+Tests are functions named test_* in a companion test script: tests for
+transform.star go in transform_test.star. Test scripts can call any
+function the transform script defines, and have these builtins:
+
+  assert.eq(a, b), assert.ne(a, b), assert.true(x), assert.false(x),
+  assert.contains(container, x), assert.fails(fn, pattern)
+      assertions. all take an optional failure message as a last argument,
+      except fails
+  fixture(path)
+      load a dataset from a JSON dataset document, for use as a previous
+      version. paths are relative to the test script
+  mock_ctx(config, secrets, download)
+      create a transform context with config, secrets & a download result
+  run_transform(prev, ctx)
+      call the transform function, returning the resulting dataset
+
+Network access is disabled while tests run. Test exits with an error if any
+test fails. Use --format json for machine-readable results.`,
+		Example: `  # Run the tests in transform_test.star:
+  $ qri test transform.star
+
+  # Report results as JSON for CI:
+  $ qri test --format json transform.star`,
+		Annotations: map[string]string{
+			"group": "dataset",
+		},
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Complete(f, args); err != nil {
+				return err
+			}
+			if err := o.Validate(); err != nil {
+				return err
+			}
+			return o.Run()
+		},
+	}
+
+	cmd.Flags().StringVar(&o.TestPath, "tests", "", "path to the test script. defaults to SCRIPT with a _test suffix")
+	cmd.MarkFlagFilename("tests", "star")
+	cmd.Flags().StringVar(&o.Format, "format", "pretty", "output format. one of [json,pretty]")
+	cmd.Flags().Uint64Var(&o.TransformLimits.MaxSteps, "max-steps", 0, "maximum number of execution steps, 0 for no limit")
+	cmd.Flags().DurationVar(&o.TransformLimits.Timeout, "timeout", 0, "maximum run time of all tests, eg: 30s, 5m. 0 for no limit")
+
+	return cmd
+}
+
+// TestOptions encapsulates state for the test command
+type TestOptions struct {
+	ioes.IOStreams
+
+	ScriptPath string
+	TestPath   string
+	Format     string
+
+	TransformLimits startf.Limits
+
+	DatasetMethods *lib.DatasetMethods
+}
+
+// Complete adds any missing configuration that can only be added just before calling Run
+func (o *TestOptions) Complete(f Factory, args []string) (err error) {
+	o.ScriptPath = args[0]
+	o.DatasetMethods, err = f.DatasetMethods()
+	return
+}
+
+// Validate checks that any user input is valid
+func (o *TestOptions) Validate() error {
+	if o.Format != "pretty" && o.Format != "json" {
+		return errors.New(lib.ErrBadArgs, "format must be one of [json,pretty]")
+	}
+	return nil
+}
+
+// Run executes the test command
+func (o *TestOptions) Run() error {
+	p := &lib.TestTransformParams{
+		ScriptPath:      o.ScriptPath,
+		TestPath:        o.TestPath,
+		TransformLimits: o.TransformLimits,
+	}
+	res := &startf.TestReport{}
+	if err := o.DatasetMethods.TestTransform(p, res); err != nil {
+		return err
+	}
+
+	if o.Format == "json" {
+		if err := json.NewEncoder(o.Out).Encode(res); err != nil {
+			return err
+		}
+	} else {
+		fmt.Fprint(o.Out, testReportStringer(*res).String())
+	}
+
+	if res.Failed > 0 {
+		return fmt.Errorf("%d of %d tests failed", res.Failed, len(res.Tests))
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+package cmd
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/qri-io/qri/startf"
+)
+
+func TestTransformTests(t *testing.T) {
+	run := NewTestRunner(t, "test_peer", "qri_test_transform_tests")
+	defer run.Delete()
+
+	output := run.MustExec(t, "qri test --format json testdata/movies/tf_one_movie.star")
+	res := startf.TestReport{}
+	if err := json.Unmarshal([]byte(output), &res); err != nil {
+		t.Fatalf("decoding test report: %s\n%s", err, output)
+	}
+	if res.Passed != 1 || res.Failed != 0 {
+		t.Errorf("expected 1 passing test. got: %d passed, %d failed", res.Passed, res.Failed)
+	}
+
+	err := run.ExecCommand("qri test --tests testdata/movies/tf_one_movie_failing_test.star testdata/movies/tf_one_movie.star")
+	if err == nil {
+		t.Fatal("expected failing tests to error")
+	}
+	output = run.GetCommandOutput()
+	if !strings.Contains(output, "FAIL test_two_movies") || !strings.Contains(output, "movie count") {
+		t.Errorf("expected output to report the failing test. got:\n%s", output)
+	}
+}
@@ -0,0 +1 @@
+SELECT m.movie_title FROM me/test_movies AS m WHERE m.duration > 170
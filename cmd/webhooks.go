@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"fmt"
+
+	util "github.com/qri-io/apiutil"
+	"github.com/qri-io/ioes"
+	"github.com/qri-io/qri/lib"
+	"github.com/qri-io/qri/webhook"
+	"github.com/spf13/cobra"
+)
+
+// NewWebhooksCommand creates a `qri webhooks` subcommand for inspecting
+// outbound webhook deliveries
+func NewWebhooksCommand(f Factory, ioStreams ioes.IOStreams) *cobra.Command {
+	o := &WebhooksOptions{IOStreams: ioStreams}
+	cmd := &cobra.Command{
+		Use:   "webhooks",
+		Short: "inspect outbound webhook deliveries",
+		Long: `Webhooks notify other services when events happen on this qri node, like a
+new dataset version being saved or pushed by a peer. Each webhook is an HTTP
+endpoint that receives a signed JSON POST request for every matching event.
+
+Webhooks are configured in the "webhooks" section of qri configuration, and are
+delivered while qri is connected. Deliveries that fail are retried with
+exponential backoff until they succeed or reach webhooks.maxattempts.`,
+		Annotations: map[string]string{
+			"group": "network",
+		},
+	}
+
+	log := &cobra.Command{
+		Use:   "log",
+		Short: "show webhook delivery attempts",
+		Long:  `Log lists webhook delivery attempts, starting with the most recent.`,
+		Example: `  # Show recent webhook deliveries:
+  $ qri webhooks log
+
+  # Show the second page of deliveries:
+  $ qri webhooks log --page 2`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Complete(f, args); err != nil {
+				return err
+			}
+			return o.Log()
+		},
+	}
+
+	log.Flags().IntVar(&o.PageSize, "page-size", 25, "page size of results, default 25")
+	log.Flags().IntVar(&o.Page, "page", 1, "page number of results, default 1")
+
+	cmd.AddCommand(log)
+	return cmd
+}
+
+// WebhooksOptions encapsulates state for the webhooks command
+type WebhooksOptions struct {
+	ioes.IOStreams
+
+	PageSize int
+	Page     int
+
+	WebhookMethods *lib.WebhookMethods
+}
+
+// Complete adds any missing configuration that can only be added just before calling Run
+func (o *WebhooksOptions) Complete(f Factory, args []string) (err error) {
+	o.WebhookMethods, err = f.WebhookMethods()
+	return
+}
+
+// Log executes the webhooks log command
+func (o *WebhooksOptions) Log() error {
+	page := util.NewPage(o.Page, o.PageSize)
+	p := &lib.ListParams{
+		Limit:  page.Limit(),
+		Offset: page.Offset(),
+	}
+
+	res := []webhook.LogEntry{}
+	if err := o.WebhookMethods.Log(p, &res); err != nil {
+		return err
+	}
+	if len(res) == 0 {
+		printInfo(o.Out, "no webhook deliveries")
+		return nil
+	}
+
+	items := make([]fmt.Stringer, len(res))
+	for i, e := range res {
+		items[i] = webhookLogEntryStringer(e)
+	}
+	return printItems(o.Out, items, page.Offset())
+}
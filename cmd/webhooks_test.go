@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/qri-io/qri/config"
+	"github.com/qri-io/qri/event"
+	"github.com/qri-io/qri/webhook"
+)
+
+func TestWebhooksLog(t *testing.T) {
+	run := NewTestRunner(t, "test_peer", "qri_test_webhooks_log")
+	defer run.Delete()
+
+	output := run.MustExec(t, "qri webhooks log")
+	if !strings.Contains(output, "no webhook deliveries") {
+		t.Errorf("expected empty log message, got: %q", output)
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	cfg := &config.Webhooks{
+		Enabled: true,
+		Hooks:   []*config.Webhook{{URL: srv.URL}},
+	}
+	d, err := webhook.NewDispatcher(cfg, filepath.Join(run.RepoRoot.QriPath, "webhooks"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Enqueue(event.ETDatasetSaveEvent, event.DatasetSaveEvent{}); err != nil {
+		t.Fatal(err)
+	}
+	d.DeliverDue(context.Background())
+
+	output = run.MustExec(t, "qri webhooks log")
+	for _, expect := range []string{"retrying (attempt 1)", string(event.ETDatasetSaveEvent), srv.URL, "503"} {
+		if !strings.Contains(output, expect) {
+			t.Errorf("expected output to contain %q, got: %q", expect, output)
+		}
+	}
+}
@@ -11,11 +11,19 @@ import (
 // DefaultAPIPort is local the port webapp serves on by default
 var DefaultAPIPort = 2503
 
+// DefaultWebsocketPort is the port the standalone websocket listens on by
+// default
+var DefaultWebsocketPort = 2506
+
 // API holds configuration for the qri JSON api
 type API struct {
 	Enabled bool `json:"enabled"`
 	// APIPort specifies the port to listen for JSON API calls
 	Port int `json:"port"`
+	// WebsocketPort specifies the port for the standalone websocket listener.
+	// Events are always available on the API port at /events, a zero value
+	// uses DefaultWebsocketPort, a negative value disables the listener
+	WebsocketPort int `json:"websocketport,omitempty"`
 	// read-only mode
 	ReadOnly bool `json:"readonly"`
 	// remote mode
@@ -63,6 +71,10 @@ func (a API) Validate() error {
         "description": "The port that listens for JSON API calls",
         "type": "integer"
       },
+      "websocketport": {
+        "description": "The port for the standalone websocket listener. zero uses the default port, a negative value disables the listener",
+        "type": "integer"
+      },
       "readonly": {
         "description": "When true, api port limits the accepted calls to certain GET requests",
         "type": "boolean"
@@ -98,9 +110,10 @@ func (a API) Validate() error {
 // DefaultAPI returns the default configuration details
 func DefaultAPI() *API {
 	return &API{
-		Enabled: true,
-		Port:    DefaultAPIPort,
-		TLS:     false,
+		Enabled:       true,
+		Port:          DefaultAPIPort,
+		WebsocketPort: DefaultWebsocketPort,
+		TLS:           false,
 		AllowedOrigins: []string{
 			"electron://local.qri.io",
 			fmt.Sprintf("http://localhost:%d", DefaultWebappPort),
@@ -115,6 +128,7 @@ func (a *API) Copy() *API {
 	res := &API{
 		Enabled:            a.Enabled,
 		Port:               a.Port,
+		WebsocketPort:      a.WebsocketPort,
 		ReadOnly:           a.ReadOnly,
 		URLRoot:            a.URLRoot,
 		TLS:                a.TLS,
@@ -32,6 +32,7 @@ type Config struct {
 	Registry *Registry
 	Remotes  *Remotes
 	Remote   *Remote
+	Webhooks *Webhooks
 
 	CLI     *CLI
 	API     *API
@@ -221,6 +222,7 @@ func (cfg Config) Validate() error {
 		cfg.Update,
 		cfg.Logging,
 		cfg.Stats,
+		cfg.Webhooks,
 	}
 	for _, val := range validators {
 		// we need to check here because we're potentially calling methods on nil
@@ -291,6 +293,9 @@ func (cfg *Config) Copy() *Config {
 	if cfg.Stats != nil {
 		res.Stats = cfg.Stats.Copy()
 	}
+	if cfg.Webhooks != nil {
+		res.Webhooks = cfg.Webhooks.Copy()
+	}
 
 	return res
 }
@@ -301,6 +306,11 @@ func (cfg *Config) WithoutPrivateValues() *Config {
 
 	res.Profile.PrivKey = ""
 	res.P2P.PrivKey = ""
+	if res.Webhooks != nil {
+		for _, h := range res.Webhooks.Hooks {
+			h.Secret = ""
+		}
+	}
 
 	return res
 }
@@ -312,6 +322,15 @@ func (cfg *Config) WithPrivateValues(p *Config) *Config {
 
 	res.Profile.PrivKey = p.Profile.PrivKey
 	res.P2P.PrivKey = p.P2P.PrivKey
+	if res.Webhooks != nil && p.Webhooks != nil {
+		for _, h := range res.Webhooks.Hooks {
+			for _, ph := range p.Webhooks.Hooks {
+				if h.Secret == "" && h.URL == ph.URL {
+					h.Secret = ph.Secret
+				}
+			}
+		}
+	}
 
 	return res
 }
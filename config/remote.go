@@ -1,6 +1,7 @@
 package config
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/qri-io/jsonschema"
@@ -19,6 +20,91 @@ type Remote struct {
 	RequireAllBlocks bool `json:"requireallblocks"`
 	// allow clients to request unpins for their own pushes
 	AllowRemoves bool `json:"allowremoves"`
+	// reject pushed logs that fail signature & history verification
+	StrictLogs bool `json:"strictlogs"`
+	// upstream remotes to replicate datasets from
+	Mirrors []*Mirror `json:"mirrors"`
+	// maximum number of bytes each profile may store on the remote, summed
+	// across all pushed versions. zero means no limit
+	ProfileQuota int64 `json:"profilequota"`
+	// per-profile quota overrides keyed by profile ID. -1 means no limit
+	Quotas map[string]int64 `json:"quotas"`
+	// retention policy for versions pushed to the remote
+	Retention *Retention `json:"retention"`
+}
+
+// Quota returns the maximum number of bytes a profile may store on the remote.
+// zero means no limit
+func (cfg *Remote) Quota(profileID string) int64 {
+	if q, ok := cfg.Quotas[profileID]; ok {
+		if q < 0 {
+			return 0
+		}
+		return q
+	}
+	return cfg.ProfileQuota
+}
+
+// DefaultRetentionSweepInterval is the time between retention sweeps for a
+// retention policy that doesn't set an interval
+var DefaultRetentionSweepInterval = time.Hour
+
+// Retention configures which pushed versions of each dataset a remote keeps.
+// A version is kept if it satisfies either rule, and the head of a dataset is
+// always kept. Versions that aren't kept are unpinned by a background sweeper
+type Retention struct {
+	// KeepVersions is the number of most recent versions to keep. zero
+	// disables the rule
+	KeepVersions int `json:"keepversions"`
+	// KeepDays keeps versions pushed within this many days. zero disables the
+	// rule
+	KeepDays int `json:"keepdays"`
+	// SweepInterval is the time between sweeps as a duration string, eg: "6h".
+	// empty uses DefaultRetentionSweepInterval
+	SweepInterval string `json:"sweepinterval"`
+}
+
+// Enabled returns true if the policy has at least one rule
+func (r *Retention) Enabled() bool {
+	return r != nil && (r.KeepVersions > 0 || r.KeepDays > 0)
+}
+
+// SweepIntervalDuration parses the sweep interval, returning
+// DefaultRetentionSweepInterval if no interval is set
+func (r *Retention) SweepIntervalDuration() (time.Duration, error) {
+	if r.SweepInterval == "" {
+		return DefaultRetentionSweepInterval, nil
+	}
+	d, err := time.ParseDuration(r.SweepInterval)
+	if err != nil {
+		return 0, fmt.Errorf("invalid retention sweep interval %q: %w", r.SweepInterval, err)
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("invalid retention sweep interval %q: must be positive", r.SweepInterval)
+	}
+	return d, nil
+}
+
+// Copy returns a deep copy of a Retention struct
+func (r *Retention) Copy() *Retention {
+	res := *r
+	return &res
+}
+
+// DefaultMirrorInterval is the time between replication checks for a mirror
+// that doesn't set an interval
+var DefaultMirrorInterval = time.Minute * 10
+
+// Mirror configures scheduled replication of datasets from an upstream remote
+type Mirror struct {
+	// Upstream is a remote name from the remotes config, or a remote address
+	Upstream string `json:"upstream"`
+	// Patterns select datasets to replicate as "peername/name" strings. "*"
+	// matches any sequence of characters within a peername or name, eg: "nasa/*"
+	Patterns []string `json:"patterns"`
+	// Interval is the time between replication checks as a duration string,
+	// eg: "1h". empty uses DefaultMirrorInterval
+	Interval string `json:"interval"`
 }
 
 // Validate validates all fields of render returning all errors found.
@@ -29,6 +115,64 @@ func (cfg Remote) Validate() error {
     "description": "Configure Qri for control over the network",
     "type": "object",
     "properties": {
+      "mirrors": {
+        "description": "Upstream remotes to replicate datasets from",
+        "type": ["array", "null"],
+        "items": {
+          "type": "object",
+          "properties": {
+            "upstream": {
+              "description": "Remote name or address to replicate from",
+              "type": "string"
+            },
+            "patterns": {
+              "description": "peername/name patterns of datasets to replicate",
+              "type": "array",
+              "items": {
+                "type": "string"
+              }
+            },
+            "interval": {
+              "description": "Time between replication checks, eg: 1h",
+              "type": "string"
+            }
+          },
+          "required": ["upstream", "patterns"]
+        }
+      },
+      "profilequota": {
+        "description": "Maximum bytes each profile may store, zero for no limit",
+        "type": "integer",
+        "minimum": 0
+      },
+      "quotas": {
+        "description": "Per-profile quota overrides keyed by profile ID",
+        "type": ["object", "null"],
+        "additionalProperties": {
+          "type": "integer",
+          "minimum": -1
+        }
+      },
+      "retention": {
+        "description": "Retention policy for pushed dataset versions",
+        "type": ["object", "null"],
+        "properties": {
+          "keepversions": {
+            "description": "Number of most recent versions to keep",
+            "type": "integer",
+            "minimum": 0
+          },
+          "keepdays": {
+            "description": "Keep versions pushed within this many days",
+            "type": "integer",
+            "minimum": 0
+          },
+          "sweepinterval": {
+            "description": "Time between retention sweeps, eg: 6h",
+            "type": "string"
+          }
+        }
+      },
       "templateUpdateAddress": {
         "description": "address to check for app updates",
         "type": "string"
@@ -50,7 +194,53 @@ func (cfg *Remote) Copy() *Remote {
 		AcceptTimeoutMs:  cfg.AcceptTimeoutMs,
 		RequireAllBlocks: cfg.RequireAllBlocks,
 		AllowRemoves:     cfg.AllowRemoves,
+		StrictLogs:       cfg.StrictLogs,
+	}
+	if cfg.Mirrors != nil {
+		res.Mirrors = make([]*Mirror, len(cfg.Mirrors))
+		for i, m := range cfg.Mirrors {
+			res.Mirrors[i] = m.Copy()
+		}
+	}
+	res.ProfileQuota = cfg.ProfileQuota
+	if cfg.Quotas != nil {
+		res.Quotas = make(map[string]int64, len(cfg.Quotas))
+		for id, q := range cfg.Quotas {
+			res.Quotas[id] = q
+		}
+	}
+	if cfg.Retention != nil {
+		res.Retention = cfg.Retention.Copy()
 	}
 
 	return res
 }
+
+// Copy returns a deep copy of a Mirror struct
+func (m *Mirror) Copy() *Mirror {
+	res := &Mirror{
+		Upstream: m.Upstream,
+		Interval: m.Interval,
+	}
+	if m.Patterns != nil {
+		res.Patterns = make([]string, len(m.Patterns))
+		copy(res.Patterns, m.Patterns)
+	}
+	return res
+}
+
+// IntervalDuration parses the mirror interval, returning DefaultMirrorInterval
+// if no interval is set
+func (m *Mirror) IntervalDuration() (time.Duration, error) {
+	if m.Interval == "" {
+		return DefaultMirrorInterval, nil
+	}
+	d, err := time.ParseDuration(m.Interval)
+	if err != nil {
+		return 0, fmt.Errorf("invalid mirror interval %q: %w", m.Interval, err)
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("invalid mirror interval %q: must be positive", m.Interval)
+	}
+	return d, nil
+}
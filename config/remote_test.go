@@ -3,6 +3,7 @@ package config
 import (
 	"reflect"
 	"testing"
+	"time"
 )
 
 func TestRemoteValidate(t *testing.T) {
@@ -18,6 +19,9 @@ func TestRemoteCopy(t *testing.T) {
 		remote *Remote
 	}{
 		{&Remote{}},
+		{&Remote{AllowRemoves: true, StrictLogs: true}},
+		{&Remote{Mirrors: []*Mirror{{Upstream: "backup", Patterns: []string{"nasa/*"}, Interval: "1h"}}}},
+		{&Remote{ProfileQuota: 1000, Quotas: map[string]int64{"QmFoo": -1}, Retention: &Retention{KeepVersions: 3, KeepDays: 30}}},
 	}
 	for i, c := range cases {
 		cpy := c.remote.Copy()
@@ -32,3 +36,53 @@ func TestRemoteCopy(t *testing.T) {
 		}
 	}
 }
+
+func TestMirrorIntervalDuration(t *testing.T) {
+	cases := []struct {
+		interval string
+		expect   time.Duration
+		err      bool
+	}{
+		{"", DefaultMirrorInterval, false},
+		{"1h", time.Hour, false},
+		{"0s", 0, true},
+		{"soon", 0, true},
+	}
+	for _, c := range cases {
+		got, err := (&Mirror{Interval: c.interval}).IntervalDuration()
+		if (err != nil) != c.err {
+			t.Errorf("interval %q error mismatch. expected error: %t, got: %v", c.interval, c.err, err)
+			continue
+		}
+		if got != c.expect {
+			t.Errorf("interval %q mismatch. expected: %s, got: %s", c.interval, c.expect, got)
+		}
+	}
+}
+
+func TestRemoteQuota(t *testing.T) {
+	cfg := &Remote{ProfileQuota: 100, Quotas: map[string]int64{"QmBig": 1000, "QmUnlimited": -1}}
+	cases := map[string]int64{
+		"QmOther":     100,
+		"QmBig":       1000,
+		"QmUnlimited": 0,
+	}
+	for id, expect := range cases {
+		if got := cfg.Quota(id); got != expect {
+			t.Errorf("profile %s quota mismatch. expected: %d, got: %d", id, expect, got)
+		}
+	}
+}
+
+func TestRetentionEnabled(t *testing.T) {
+	var nilRetention *Retention
+	if nilRetention.Enabled() {
+		t.Error("expected nil retention policy to be disabled")
+	}
+	if (&Retention{}).Enabled() {
+		t.Error("expected retention policy without rules to be disabled")
+	}
+	if !(&Retention{KeepDays: 7}).Enabled() {
+		t.Error("expected retention policy with a rule to be enabled")
+	}
+}
@@ -2,7 +2,11 @@ package config
 
 import "github.com/qri-io/jsonschema"
 
-// Store configures a qri content addessed file store (cafs)
+// Store configures a qri content addessed file store (cafs). In addition to
+// IPFS-backed stores, Type may be "fs" to keep blocks in a plain directory
+// tree at Path, or "s3" to keep blocks in an S3-compatible object store
+// configured with the "endpoint", "bucket", "region", "prefix", "accessKeyID"
+// & "secretAccessKey" options
 type Store struct {
 	Type    string                 `json:"type"`
 	Options map[string]interface{} `json:"options,omitempty"`
@@ -34,7 +38,9 @@ func (cfg Store) Validate() error {
         "enum": [
 					"ipfs",
 					"ipfs_http",
-					"map"
+					"map",
+					"fs",
+					"s3"
         ]
       }
     }
@@ -45,8 +51,14 @@ func (cfg Store) Validate() error {
 // Copy returns a deep copy of the Store struct
 func (cfg *Store) Copy() *Store {
 	res := &Store{
-		Type:    cfg.Type,
-		Options: cfg.Options,
+		Type: cfg.Type,
+		Path: cfg.Path,
+	}
+	if cfg.Options != nil {
+		res.Options = map[string]interface{}{}
+		for key, val := range cfg.Options {
+			res.Options[key] = val
+		}
 	}
 
 	return res
@@ -0,0 +1,114 @@
+package config
+
+import (
+	"github.com/qri-io/jsonschema"
+)
+
+// DefaultWebhookMaxAttempts is the number of times a webhook delivery is tried
+// before it's dropped from the queue
+var DefaultWebhookMaxAttempts = 8
+
+// Webhooks configures outbound HTTP notifications for qri events
+type Webhooks struct {
+	// Enabled turns webhook delivery on or off
+	Enabled bool `json:"enabled"`
+	// MaxAttempts is the number of delivery attempts for a single event before
+	// giving up. zero uses DefaultWebhookMaxAttempts
+	MaxAttempts int `json:"maxattempts"`
+	// Hooks is the list of webhook destinations
+	Hooks []*Webhook `json:"hooks"`
+}
+
+// Webhook is a single destination for event notifications
+type Webhook struct {
+	// URL to POST event payloads to
+	URL string `json:"url"`
+	// Topics is a list of event topics to deliver. A topic ending in "*" matches
+	// all topics with that prefix, eg: "remote:*". An empty list delivers all
+	// events
+	Topics []string `json:"topics"`
+	// Secret is used to sign payloads with HMAC-SHA256
+	Secret string `json:"secret"`
+}
+
+// DefaultWebhooks creates a new default Webhooks configuration
+func DefaultWebhooks() *Webhooks {
+	return &Webhooks{
+		Enabled:     false,
+		MaxAttempts: DefaultWebhookMaxAttempts,
+	}
+}
+
+// Validate validates all fields of webhooks returning all errors found.
+func (cfg Webhooks) Validate() error {
+	schema := jsonschema.Must(`{
+    "$schema": "http://json-schema.org/draft-06/schema#",
+    "title": "Webhooks",
+    "description": "Outbound HTTP notifications for qri events",
+    "type": "object",
+    "properties": {
+      "enabled": {
+        "description": "When true, events are delivered to configured hooks",
+        "type": "boolean"
+      },
+      "maxattempts": {
+        "description": "Number of delivery attempts before an event is dropped",
+        "type": "integer",
+        "minimum": 0
+      },
+      "hooks": {
+        "description": "Webhook destinations",
+        "type": ["array", "null"],
+        "items": {
+          "type": "object",
+          "required": ["url"],
+          "properties": {
+            "url": {
+              "description": "URL to POST event payloads to",
+              "type": "string",
+              "minLength": 1
+            },
+            "topics": {
+              "description": "Event topics to deliver, empty delivers all events",
+              "type": ["array", "null"],
+              "items": { "type": "string" }
+            },
+            "secret": {
+              "description": "Secret used to sign payloads with HMAC-SHA256",
+              "type": "string"
+            }
+          }
+        }
+      }
+    }
+  }`)
+	return validate(schema, &cfg)
+}
+
+// Copy returns a deep copy of a Webhooks struct
+func (cfg *Webhooks) Copy() *Webhooks {
+	res := &Webhooks{
+		Enabled:     cfg.Enabled,
+		MaxAttempts: cfg.MaxAttempts,
+	}
+	if cfg.Hooks != nil {
+		res.Hooks = make([]*Webhook, len(cfg.Hooks))
+		for i, h := range cfg.Hooks {
+			res.Hooks[i] = h.Copy()
+		}
+	}
+	return res
+}
+
+// Copy returns a deep copy of a Webhook struct
+func (h *Webhook) Copy() *Webhook {
+	res := &Webhook{
+		URL:    h.URL,
+		Secret: h.Secret,
+	}
+	if h.Topics != nil {
+		res.Topics = make([]string, len(h.Topics))
+		copy(res.Topics, h.Topics)
+	}
+	return res
+}
@@ -0,0 +1,80 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestWebhooksValidate(t *testing.T) {
+	if err := DefaultWebhooks().Validate(); err != nil {
+		t.Errorf("error validating default webhooks: %s", err)
+	}
+
+	cfg := &Webhooks{
+		Enabled: true,
+		Hooks: []*Webhook{
+			{URL: "https://example.com/hook", Topics: []string{"remote:*"}, Secret: "shh"},
+		},
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("error validating webhooks: %s", err)
+	}
+
+	cfg.Hooks = append(cfg.Hooks, &Webhook{URL: ""})
+	if err := cfg.Validate(); err == nil {
+		t.Errorf("expected hook with empty url to fail validation")
+	}
+}
+
+func TestWebhooksCopy(t *testing.T) {
+	cases := []struct {
+		webhooks *Webhooks
+	}{
+		{DefaultWebhooks()},
+		{&Webhooks{
+			Enabled: true,
+			Hooks: []*Webhook{
+				{URL: "https://example.com/hook", Topics: []string{"remote:*"}, Secret: "shh"},
+			},
+		}},
+	}
+	for i, c := range cases {
+		cpy := c.webhooks.Copy()
+		if !reflect.DeepEqual(cpy, c.webhooks) {
+			t.Errorf("Webhooks Copy test case %v, webhooks structs are not equal: \ncopy: %v, \noriginal: %v", i, cpy, c.webhooks)
+			continue
+		}
+		cpy.MaxAttempts = 100
+		if reflect.DeepEqual(cpy, c.webhooks) {
+			t.Errorf("Webhooks Copy test case %v, editing one webhooks struct should not affect the other: \ncopy: %v, \noriginal: %v", i, cpy, c.webhooks)
+			continue
+		}
+		if len(cpy.Hooks) > 0 {
+			cpy.Hooks[0].Topics[0] = "changed"
+			if c.webhooks.Hooks[0].Topics[0] == "changed" {
+				t.Errorf("Webhooks Copy test case %v, editing copied topics should not affect the original", i)
+			}
+		}
+	}
+}
+
+func TestWebhooksPrivateValues(t *testing.T) {
+	cfg := DefaultConfigForTesting()
+	cfg.Webhooks = &Webhooks{
+		Enabled: true,
+		Hooks:   []*Webhook{{URL: "https://example.com/hook", Secret: "shh"}},
+	}
+
+	public := cfg.WithoutPrivateValues()
+	if public.Webhooks.Hooks[0].Secret != "" {
+		t.Errorf("expected webhook secret to be removed")
+	}
+	if cfg.Webhooks.Hooks[0].Secret != "shh" {
+		t.Errorf("removing private values should not affect the original")
+	}
+
+	restored := public.WithPrivateValues(cfg)
+	if restored.Webhooks.Hooks[0].Secret != "shh" {
+		t.Errorf("expected webhook secret to be restored")
+	}
+}
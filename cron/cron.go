@@ -0,0 +1,270 @@
+// Package cron runs dataset transforms on a schedule. Jobs pair a dataset
+// reference with a crontab-style schedule & are persisted to a directory
+// alongside a history of runs. A running Cron checks for due jobs at a
+// regular interval, handing each to a RunFunc that re-executes the dataset's
+// transform
+package cron
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	golog "github.com/ipfs/go-log"
+)
+
+var log = golog.Logger("cron")
+
+var (
+	// ErrNoChanges is returned by a RunFunc when a run produced a version
+	// identical to the previous one, which isn't saved
+	ErrNoChanges = errors.New("no changes")
+	// ErrNotScheduled is returned when accessing a job that doesn't exist
+	ErrNotScheduled = errors.New("dataset is not scheduled")
+)
+
+// DefaultCheckInterval is the default time between checks for due jobs
+const DefaultCheckInterval = time.Minute
+
+// RunFunc executes a scheduled job, writing log output to w. RunFunc returns
+// the path of the version it saved, or ErrNoChanges if nothing was saved
+type RunFunc func(ctx context.Context, ref string, w io.Writer) (path string, err error)
+
+// Job is a dataset scheduled to run
+type Job struct {
+	// Ref is the "peername/name" alias of the dataset
+	Ref string `json:"ref"`
+	// Schedule is the schedule string the job was created with
+	Schedule string `json:"schedule"`
+	// Created is when the job was scheduled
+	Created time.Time `json:"created"`
+	// LastRun is the start of the most recent run
+	LastRun time.Time `json:"lastRun,omitempty"`
+	// NextRun is when the job will next run
+	NextRun time.Time `json:"nextRun"`
+}
+
+// RunStatus enumerates the outcomes of a run
+type RunStatus string
+
+const (
+	// RunStatusSaved indicates the run saved a new version
+	RunStatusSaved = RunStatus("saved")
+	// RunStatusUnchanged indicates the run produced no changes
+	RunStatusUnchanged = RunStatus("unchanged")
+	// RunStatusFailed indicates the run errored
+	RunStatusFailed = RunStatus("failed")
+)
+
+// Run records a single execution of a job
+type Run struct {
+	Ref      string        `json:"ref"`
+	Start    time.Time     `json:"start"`
+	Duration time.Duration `json:"duration"`
+	Status   RunStatus     `json:"status"`
+	// Path of the saved version, if any
+	Path string `json:"path,omitempty"`
+	// Log is the output written during the run
+	Log   string `json:"log,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+// Options configures a Cron
+type Options struct {
+	// Clock returns the current time, time.Now if nil
+	Clock func() time.Time
+	// CheckInterval is the time between checks for due jobs
+	CheckInterval time.Duration
+	// MaxRuns is the number of runs kept in history for each job
+	MaxRuns int
+}
+
+// Cron runs scheduled jobs
+type Cron struct {
+	store    *store
+	run      RunFunc
+	now      func() time.Time
+	interval time.Duration
+
+	// runLk is held while running jobs so overlapping checks don't run a job
+	// twice
+	runLk sync.Mutex
+}
+
+// NewCron creates a Cron that persists jobs & run history to dir
+func NewCron(dir string, run RunFunc, opts ...func(o *Options)) (*Cron, error) {
+	o := &Options{
+		Clock:         time.Now,
+		CheckInterval: DefaultCheckInterval,
+		MaxRuns:       50,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if run == nil {
+		return nil, fmt.Errorf("cron requires a run function")
+	}
+
+	s, err := newStore(dir, o.MaxRuns)
+	if err != nil {
+		return nil, err
+	}
+	return &Cron{
+		store:    s,
+		run:      run,
+		now:      o.Clock,
+		interval: o.CheckInterval,
+	}, nil
+}
+
+// Schedule adds a job, or changes the schedule of an existing job for ref
+func (c *Cron) Schedule(ref, schedule string) (*Job, error) {
+	sched, err := ParseSchedule(schedule)
+	if err != nil {
+		return nil, err
+	}
+	now := c.now()
+	next := sched.Next(now)
+	if next.IsZero() {
+		return nil, fmt.Errorf("schedule %q never runs", schedule)
+	}
+
+	var job *Job
+	err = c.store.updateJobs(func(jobs []*Job) ([]*Job, error) {
+		for _, j := range jobs {
+			if j.Ref == ref {
+				job = j
+				break
+			}
+		}
+		if job == nil {
+			job = &Job{Ref: ref, Created: now}
+			jobs = append(jobs, job)
+		}
+		job.Schedule = schedule
+		job.NextRun = next
+		return jobs, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	cpy := *job
+	return &cpy, nil
+}
+
+// Unschedule removes the job for ref. Run history is kept
+func (c *Cron) Unschedule(ref string) error {
+	return c.store.updateJobs(func(jobs []*Job) ([]*Job, error) {
+		for i, j := range jobs {
+			if j.Ref == ref {
+				return append(jobs[:i], jobs[i+1:]...), nil
+			}
+		}
+		return nil, ErrNotScheduled
+	})
+}
+
+// Jobs lists scheduled jobs, ordered by next run
+func (c *Cron) Jobs() ([]*Job, error) {
+	return c.store.jobs()
+}
+
+// Runs lists the run history of ref, most recent first. An empty ref lists
+// runs of all jobs
+func (c *Cron) Runs(ref string, offset, limit int) ([]*Run, error) {
+	return c.store.runs(ref, offset, limit)
+}
+
+// Start checks for due jobs every check interval until ctx is cancelled
+func (c *Cron) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(c.interval)
+		defer ticker.Stop()
+		for {
+			if _, err := c.RunDue(ctx); err != nil {
+				log.Errorf("running scheduled jobs: %s", err)
+			}
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// RunDue runs all jobs whose next run is at or before the current time,
+// returning the runs performed. Each job runs once per call, even if it was
+// due more than once since its last run
+func (c *Cron) RunDue(ctx context.Context) ([]*Run, error) {
+	c.runLk.Lock()
+	defer c.runLk.Unlock()
+
+	jobs, err := c.store.jobs()
+	if err != nil {
+		return nil, err
+	}
+
+	runs := []*Run{}
+	for _, job := range jobs {
+		if err := ctx.Err(); err != nil {
+			return runs, err
+		}
+		if job.NextRun.After(c.now()) {
+			continue
+		}
+		run, err := c.runJob(ctx, job)
+		if err != nil {
+			return runs, err
+		}
+		runs = append(runs, run)
+	}
+	return runs, nil
+}
+
+// runJob runs a single job, recording the run & scheduling the next one
+func (c *Cron) runJob(ctx context.Context, job *Job) (*Run, error) {
+	run := &Run{Ref: job.Ref, Start: c.now()}
+	buf := &bytes.Buffer{}
+
+	path, err := c.run(ctx, job.Ref, buf)
+	run.Duration = c.now().Sub(run.Start)
+	run.Log = buf.String()
+	switch {
+	case errors.Is(err, ErrNoChanges):
+		run.Status = RunStatusUnchanged
+	case err != nil:
+		log.Debugf("running scheduled job %s: %s", job.Ref, err)
+		run.Status = RunStatusFailed
+		run.Error = err.Error()
+	default:
+		run.Status = RunStatusSaved
+		run.Path = path
+	}
+
+	if err := c.store.addRun(run); err != nil {
+		return nil, err
+	}
+
+	// the job may have been changed or removed while running, only update
+	// run times of the job as currently stored
+	err = c.store.updateJobs(func(jobs []*Job) ([]*Job, error) {
+		for _, j := range jobs {
+			if j.Ref != job.Ref {
+				continue
+			}
+			sched, err := ParseSchedule(j.Schedule)
+			if err != nil {
+				return nil, err
+			}
+			j.LastRun = run.Start
+			j.NextRun = sched.Next(c.now())
+		}
+		return jobs, nil
+	})
+	return run, err
+}
@@ -0,0 +1,179 @@
+package cron
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+)
+
+type testClock struct {
+	t time.Time
+}
+
+func (c *testClock) Now() time.Time { return c.t }
+
+func (c *testClock) Advance(d time.Duration) { c.t = c.t.Add(d) }
+
+func TestCron(t *testing.T) {
+	ctx := context.Background()
+	dir, err := ioutil.TempDir("", "qri_test_cron")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	clock := &testClock{t: time.Date(2020, time.April, 15, 10, 30, 0, 0, time.UTC)}
+	results := map[string]error{}
+	calls := 0
+	run := func(ctx context.Context, ref string, w io.Writer) (string, error) {
+		calls++
+		fmt.Fprintf(w, "running %s", ref)
+		if err := results[ref]; err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("/mem/%s/%d", ref, calls), nil
+	}
+	useClock := func(o *Options) { o.Clock = clock.Now }
+
+	c, err := NewCron(dir, run, useClock)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := c.Schedule("peer/hourly", "@hourly"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Schedule("peer/daily", "0 12 * * *"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Schedule("peer/bad", "not a schedule"); err == nil {
+		t.Errorf("expected invalid schedule to error")
+	}
+
+	runs, err := c.RunDue(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(runs) != 0 {
+		t.Errorf("expected no runs before jobs are due. got: %d", len(runs))
+	}
+
+	clock.Advance(time.Minute * 30)
+	results["peer/hourly"] = ErrNoChanges
+	if runs, err = c.RunDue(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if len(runs) != 1 || runs[0].Ref != "peer/hourly" || runs[0].Status != RunStatusUnchanged {
+		t.Errorf("expected an unchanged run of peer/hourly. got: %#v", runs)
+	}
+
+	// a second check at the same time shouldn't run anything
+	if runs, _ = c.RunDue(ctx); len(runs) != 0 {
+		t.Errorf("expected job not to run twice. got: %d runs", len(runs))
+	}
+
+	clock.Advance(time.Hour)
+	results["peer/hourly"] = fmt.Errorf("transform exploded")
+	if runs, err = c.RunDue(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if len(runs) != 2 {
+		t.Fatalf("expected both jobs to run. got: %d runs", len(runs))
+	}
+	for _, r := range runs {
+		switch r.Ref {
+		case "peer/hourly":
+			if r.Status != RunStatusFailed || r.Error != "transform exploded" {
+				t.Errorf("expected failed run to record error. got: %#v", r)
+			}
+		case "peer/daily":
+			if r.Status != RunStatusSaved || r.Path == "" || r.Log != "running peer/daily" {
+				t.Errorf("expected saved run with path & log. got: %#v", r)
+			}
+		}
+	}
+
+	// state survives a restart
+	c, err = NewCron(dir, run, useClock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	jobs, err := c.Jobs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(jobs) != 2 {
+		t.Fatalf("expected 2 jobs. got: %d", len(jobs))
+	}
+	if expect := time.Date(2020, time.April, 15, 13, 0, 0, 0, time.UTC); jobs[0].Ref != "peer/hourly" || !jobs[0].NextRun.Equal(expect) {
+		t.Errorf("expected peer/hourly to run next at %s. got: %s at %s", expect, jobs[0].Ref, jobs[0].NextRun)
+	}
+
+	history, err := c.Runs("peer/hourly", 0, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 2 || history[0].Status != RunStatusFailed || history[1].Status != RunStatusUnchanged {
+		t.Errorf("expected run history, most recent first. got: %#v", history)
+	}
+	if all, _ := c.Runs("", 0, -1); len(all) != 3 {
+		t.Errorf("expected 3 runs in total history. got: %d", len(all))
+	}
+
+	if err := c.Unschedule("peer/hourly"); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Unschedule("peer/hourly"); err != ErrNotScheduled {
+		t.Errorf("expected unscheduling a missing job to error with ErrNotScheduled. got: %v", err)
+	}
+	clock.Advance(time.Hour * 24)
+	if runs, _ = c.RunDue(ctx); len(runs) != 1 || runs[0].Ref != "peer/daily" {
+		t.Errorf("expected only peer/daily to run after unscheduling. got: %#v", runs)
+	}
+}
+
+func TestRunHistoryLimit(t *testing.T) {
+	ctx := context.Background()
+	dir, err := ioutil.TempDir("", "qri_test_cron_history")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	clock := &testClock{t: time.Date(2020, time.April, 15, 10, 30, 0, 0, time.UTC)}
+	run := func(ctx context.Context, ref string, w io.Writer) (string, error) {
+		return "", ErrNoChanges
+	}
+	c, err := NewCron(dir, run, func(o *Options) {
+		o.Clock = clock.Now
+		o.MaxRuns = 3
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Schedule("peer/ds", "@every 1h"); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 5; i++ {
+		clock.Advance(time.Hour)
+		if _, err := c.RunDue(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	runs, err := c.Runs("peer/ds", 0, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(runs) != 3 {
+		t.Errorf("expected history to keep 3 runs. got: %d", len(runs))
+	}
+	if !runs[0].Start.Equal(clock.Now()) {
+		t.Errorf("expected most recent run first. got: %s", runs[0].Start)
+	}
+}
@@ -0,0 +1,193 @@
+package cron
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule determines when a job runs
+type Schedule interface {
+	// Next returns the first time the schedule activates after t
+	Next(t time.Time) time.Time
+}
+
+// descriptors are shorthands for common crontab schedules
+var descriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// ParseSchedule parses a schedule string. Schedules are either standard five
+// field crontab expressions ("minute hour day-of-month month day-of-week"),
+// one of the descriptors @yearly, @monthly, @weekly, @daily or @hourly, or
+// "@every <duration>" for fixed intervals, eg: "@every 90m"
+func ParseSchedule(s string) (Schedule, error) {
+	s = strings.TrimSpace(s)
+	if strings.HasPrefix(s, "@every ") {
+		d, err := time.ParseDuration(strings.TrimSpace(strings.TrimPrefix(s, "@every ")))
+		if err != nil {
+			return nil, fmt.Errorf("invalid schedule %q: %w", s, err)
+		}
+		if d < time.Minute {
+			return nil, fmt.Errorf("invalid schedule %q: interval must be at least one minute", s)
+		}
+		return everySchedule(d), nil
+	}
+	if expr, ok := descriptors[s]; ok {
+		s = expr
+	}
+
+	fields := strings.Fields(s)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("invalid schedule %q: expected 5 fields, got %d", s, len(fields))
+	}
+
+	sched := &cronSchedule{}
+	var err error
+	if sched.minute, err = parseField(fields[0], 0, 59); err != nil {
+		return nil, fmt.Errorf("invalid schedule %q minute: %w", s, err)
+	}
+	if sched.hour, err = parseField(fields[1], 0, 23); err != nil {
+		return nil, fmt.Errorf("invalid schedule %q hour: %w", s, err)
+	}
+	if sched.dom, err = parseField(fields[2], 1, 31); err != nil {
+		return nil, fmt.Errorf("invalid schedule %q day of month: %w", s, err)
+	}
+	if sched.month, err = parseField(fields[3], 1, 12); err != nil {
+		return nil, fmt.Errorf("invalid schedule %q month: %w", s, err)
+	}
+	if sched.dow, err = parseField(fields[4], 0, 7); err != nil {
+		return nil, fmt.Errorf("invalid schedule %q day of week: %w", s, err)
+	}
+	// both 0 & 7 are sunday
+	if sched.dow&(1<<7) != 0 {
+		sched.dow |= 1
+	}
+	sched.domStar = fields[2] == "*"
+	sched.dowStar = fields[4] == "*"
+	return sched, nil
+}
+
+// everySchedule activates at a fixed interval
+type everySchedule time.Duration
+
+// Next implements Schedule, rounding down to the minute
+func (e everySchedule) Next(t time.Time) time.Time {
+	return t.Add(time.Duration(e)).Truncate(time.Minute)
+}
+
+// cronSchedule is a parsed crontab expression. Each field is a bitset of the
+// values it matches
+type cronSchedule struct {
+	minute, hour, dom, month, dow uint64
+	domStar, dowStar              bool
+}
+
+// maxSearch bounds the search for the next activation of schedules that never
+// match, like "0 0 31 2 *"
+const maxSearch = 5 * 366 * 24 * 60
+
+// Next implements Schedule. Times are matched in the location of t
+func (s *cronSchedule) Next(t time.Time) time.Time {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	for i := 0; i < maxSearch; i++ {
+		if !has(s.month, int(t.Month())) {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !s.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !has(s.hour, t.Hour()) {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+			continue
+		}
+		if !has(s.minute, t.Minute()) {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+// dayMatches follows crontab convention: when both day of month & day of week
+// are restricted, a day matching either one matches
+func (s *cronSchedule) dayMatches(t time.Time) bool {
+	dom := has(s.dom, t.Day())
+	dow := has(s.dow, int(t.Weekday()))
+	if s.domStar || s.dowStar {
+		return dom && dow
+	}
+	return dom || dow
+}
+
+func has(set uint64, v int) bool {
+	return set&(1<<uint(v)) != 0
+}
+
+// parseField parses a comma separated list of values, ranges & steps, eg:
+// "*/15", "1-5", "0,30"
+func parseField(field string, min, max int) (uint64, error) {
+	var set uint64
+	for _, part := range strings.Split(field, ",") {
+		step := 1
+		if i := strings.Index(part, "/"); i >= 0 {
+			var err error
+			if step, err = strconv.Atoi(part[i+1:]); err != nil || step < 1 {
+				return 0, fmt.Errorf("invalid step %q", part[i+1:])
+			}
+			part = part[:i]
+		}
+
+		lo, hi := min, max
+		switch {
+		case part == "*":
+		case strings.Contains(part, "-"):
+			bounds := strings.SplitN(part, "-", 2)
+			var err error
+			if lo, err = parseValue(bounds[0], min, max); err != nil {
+				return 0, err
+			}
+			if hi, err = parseValue(bounds[1], min, max); err != nil {
+				return 0, err
+			}
+			if lo > hi {
+				return 0, fmt.Errorf("invalid range %q", part)
+			}
+		default:
+			v, err := parseValue(part, min, max)
+			if err != nil {
+				return 0, err
+			}
+			lo = v
+			if step == 1 {
+				hi = v
+			}
+		}
+
+		for v := lo; v <= hi; v += step {
+			set |= 1 << uint(v)
+		}
+	}
+	return set, nil
+}
+
+func parseValue(s string, min, max int) (int, error) {
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value %q", s)
+	}
+	if v < min || v > max {
+		return 0, fmt.Errorf("value %d out of range [%d-%d]", v, min, max)
+	}
+	return v, nil
+}
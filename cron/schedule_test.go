@@ -0,0 +1,80 @@
+package cron
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseSchedule(t *testing.T) {
+	start := time.Date(2020, time.April, 15, 10, 30, 20, 0, time.UTC) // a wednesday
+
+	cases := []struct {
+		schedule string
+		expect   []time.Time
+	}{
+		{"*/15 * * * *", []time.Time{
+			time.Date(2020, time.April, 15, 10, 45, 0, 0, time.UTC),
+			time.Date(2020, time.April, 15, 11, 0, 0, 0, time.UTC),
+		}},
+		{"0 9-17/4 * * 1-5", []time.Time{
+			time.Date(2020, time.April, 15, 13, 0, 0, 0, time.UTC),
+			time.Date(2020, time.April, 15, 17, 0, 0, 0, time.UTC),
+			time.Date(2020, time.April, 16, 9, 0, 0, 0, time.UTC),
+		}},
+		{"@daily", []time.Time{
+			time.Date(2020, time.April, 16, 0, 0, 0, 0, time.UTC),
+			time.Date(2020, time.April, 17, 0, 0, 0, 0, time.UTC),
+		}},
+		{"@weekly", []time.Time{
+			time.Date(2020, time.April, 19, 0, 0, 0, 0, time.UTC),
+		}},
+		{"0 0 1,15 * 7", []time.Time{
+			time.Date(2020, time.April, 19, 0, 0, 0, 0, time.UTC),
+			time.Date(2020, time.April, 26, 0, 0, 0, 0, time.UTC),
+			time.Date(2020, time.May, 1, 0, 0, 0, 0, time.UTC),
+		}},
+		{"30 6 29 2 *", []time.Time{
+			time.Date(2024, time.February, 29, 6, 30, 0, 0, time.UTC),
+		}},
+		{"@every 90m", []time.Time{
+			time.Date(2020, time.April, 15, 12, 0, 0, 0, time.UTC),
+			time.Date(2020, time.April, 15, 13, 30, 0, 0, time.UTC),
+		}},
+	}
+
+	for _, c := range cases {
+		sched, err := ParseSchedule(c.schedule)
+		if err != nil {
+			t.Errorf("%q: unexpected error: %s", c.schedule, err)
+			continue
+		}
+		got := start
+		for i, expect := range c.expect {
+			if got = sched.Next(got); !got.Equal(expect) {
+				t.Errorf("%q activation %d: expected %s, got %s", c.schedule, i, expect, got)
+				break
+			}
+		}
+	}
+}
+
+func TestParseScheduleErrors(t *testing.T) {
+	bad := []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"*/0 * * * *",
+		"5-1 * * * *",
+		"a * * * *",
+		"@every 10s",
+		"@every soon",
+		"@sometimes",
+	}
+	for _, s := range bad {
+		if _, err := ParseSchedule(s); err == nil {
+			t.Errorf("%q: expected error", s)
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package cron
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+const (
+	jobsFilename = "jobs.json"
+	runsFilename = "runs.json"
+)
+
+// store persists jobs & run history to a directory
+type store struct {
+	dir     string
+	maxRuns int
+	lk      sync.Mutex
+}
+
+func newStore(dir string, maxRuns int) (*store, error) {
+	if dir == "" {
+		return nil, fmt.Errorf("cron storage directory is required")
+	}
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return nil, err
+	}
+	return &store{dir: dir, maxRuns: maxRuns}, nil
+}
+
+// jobs lists stored jobs ordered by next run
+func (s *store) jobs() ([]*Job, error) {
+	s.lk.Lock()
+	defer s.lk.Unlock()
+	return s.readJobs()
+}
+
+// updateJobs replaces stored jobs with the result of update. update is called
+// with a lock held. Jobs are left unchanged if update errors
+func (s *store) updateJobs(update func(jobs []*Job) ([]*Job, error)) error {
+	s.lk.Lock()
+	defer s.lk.Unlock()
+
+	jobs, err := s.readJobs()
+	if err != nil {
+		return err
+	}
+	if jobs, err = update(jobs); err != nil {
+		return err
+	}
+	return s.writeFile(jobsFilename, jobs)
+}
+
+func (s *store) readJobs() ([]*Job, error) {
+	jobs := []*Job{}
+	if err := s.readFile(jobsFilename, &jobs); err != nil {
+		return nil, fmt.Errorf("reading scheduled jobs: %w", err)
+	}
+	sort.SliceStable(jobs, func(i, j int) bool {
+		return jobs[i].NextRun.Before(jobs[j].NextRun)
+	})
+	return jobs, nil
+}
+
+// runs lists run history, most recent first
+func (s *store) runs(ref string, offset, limit int) ([]*Run, error) {
+	s.lk.Lock()
+	defer s.lk.Unlock()
+
+	all := []*Run{}
+	if err := s.readFile(runsFilename, &all); err != nil {
+		return nil, fmt.Errorf("reading run history: %w", err)
+	}
+
+	runs := []*Run{}
+	for i := len(all) - 1; i >= 0; i-- {
+		if ref == "" || all[i].Ref == ref {
+			runs = append(runs, all[i])
+		}
+	}
+	if offset >= len(runs) {
+		return []*Run{}, nil
+	}
+	runs = runs[offset:]
+	if limit >= 0 && limit < len(runs) {
+		runs = runs[:limit]
+	}
+	return runs, nil
+}
+
+// addRun appends a run to history, dropping the oldest runs of the same job
+// beyond the history limit
+func (s *store) addRun(run *Run) error {
+	s.lk.Lock()
+	defer s.lk.Unlock()
+
+	runs := []*Run{}
+	if err := s.readFile(runsFilename, &runs); err != nil {
+		return fmt.Errorf("reading run history: %w", err)
+	}
+	runs = append(runs, run)
+
+	count := 0
+	for i := len(runs) - 1; i >= 0; i-- {
+		if runs[i].Ref != run.Ref {
+			continue
+		}
+		if count++; s.maxRuns > 0 && count > s.maxRuns {
+			runs = append(runs[:i], runs[i+1:]...)
+		}
+	}
+	return s.writeFile(runsFilename, runs)
+}
+
+// writeFile replaces a JSON file, writing to a temp file first so a crash
+// mid-write can't corrupt it
+func (s *store) writeFile(filename string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(s.dir, filename)
+	tmp := path + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// readFile decodes a JSON file into v, leaving v unchanged if the file
+// doesn't exist
+func (s *store) readFile(filename string, v interface{}) error {
+	data, err := ioutil.ReadFile(filepath.Join(s.dir, filename))
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
@@ -129,6 +129,9 @@ func (d *Dscache) VerboseString(showEmpty bool) string {
 		if len(r.FsiPath()) != 0 || showEmpty {
 			fmt.Fprintf(&out, "%sfsiPath       = %s\n", indent, r.FsiPath())
 		}
+		for _, h := range readBranchHeads(&r) {
+			fmt.Fprintf(&out, "%sbranch        = %s topIndex=%d headRef=%s\n", indent, h.Name, h.TopIndex, h.HeadRef)
+		}
 	}
 	return out.String()
 }
@@ -177,7 +180,8 @@ func (d *Dscache) ListRefs() ([]reporef.DatasetRef, error) {
 	return refs, nil
 }
 
-// LookupByName looks up a dataset by dsref and returns the latest VersionInfo if found
+// LookupByName looks up a dataset by dsref and returns the latest VersionInfo if found. If
+// the ref names a branch, the path & number of versions describe the head of that branch
 func (d *Dscache) LookupByName(ref dsref.Ref) (*dsref.VersionInfo, error) {
 	// Convert the username into a profileID
 	for i := 0; i < d.Root.UsersLength(); i++ {
@@ -201,7 +205,17 @@ func (d *Dscache) LookupByName(ref dsref.Ref) (*dsref.VersionInfo, error) {
 		d.Root.Refs(&r, i)
 		if string(r.ProfileID()) == ref.ProfileID && string(r.PrettyName()) == ref.Name {
 			info := convertEntryToVersionInfo(&r)
-			return &info, nil
+			if ref.Branch == "" || ref.Branch == logbook.DefaultBranchName {
+				return &info, nil
+			}
+			for _, h := range readBranchHeads(&r) {
+				if h.Name == ref.Branch {
+					info.Path = h.HeadRef
+					info.NumVersions = h.TopIndex
+					return &info, nil
+				}
+			}
+			return nil, fmt.Errorf("branch not found %s/%s#%s", ref.Username, ref.Name, ref.Branch)
 		}
 	}
 	return nil, fmt.Errorf("dataset ref not found %s/%s", ref.Username, ref.Name)
@@ -218,6 +232,12 @@ func (d *Dscache) update(act *logbook.Action) {
 			log.Error(err)
 		}
 	case logbook.ActionDatasetCommitChange:
+		if act.Branch != "" && act.Branch != logbook.DefaultBranchName {
+			if err := d.updateBranchHead(act); err != nil && err != ErrNoDscache {
+				log.Error(err)
+			}
+			return
+		}
 		if err := d.updateChangeCursor(act); err != nil && err != ErrNoDscache {
 			log.Error(err)
 		}
@@ -227,6 +247,14 @@ func (d *Dscache) update(act *logbook.Action) {
 		}
 	case logbook.ActionDatasetRename:
 		// TODO(dustmop): Handle renames
+	case logbook.ActionBranchInit:
+		if err := d.updateBranchHead(act); err != nil && err != ErrNoDscache {
+			log.Error(err)
+		}
+	case logbook.ActionBranchDelete:
+		if err := d.updateDeleteBranch(act); err != nil && err != ErrNoDscache {
+			log.Error(err)
+		}
 	}
 }
 
@@ -321,6 +349,67 @@ func (d *Dscache) updateChangeCursor(act *logbook.Action) error {
 	return d.save()
 }
 
+// Copy the entire dscache, setting the head of the action's branch in the matching entry. The
+// entry's own head, which tracks the default branch, is left unchanged
+func (d *Dscache) updateBranchHead(act *logbook.Action) error {
+	return d.replaceBranchHeads(act.InitID, func(heads []branchHead) []branchHead {
+		head := branchHead{Name: act.Branch, HeadRef: act.HeadRef, TopIndex: act.TopIndex}
+		for i, h := range heads {
+			if h.Name == act.Branch {
+				heads[i] = head
+				return heads
+			}
+		}
+		return append(heads, head)
+	})
+}
+
+// Copy the entire dscache, removing the action's branch from the matching entry
+func (d *Dscache) updateDeleteBranch(act *logbook.Action) error {
+	return d.replaceBranchHeads(act.InitID, func(heads []branchHead) []branchHead {
+		res := heads[:0]
+		for _, h := range heads {
+			if h.Name != act.Branch {
+				res = append(res, h)
+			}
+		}
+		return res
+	})
+}
+
+func (d *Dscache) replaceBranchHeads(initID string, modify func([]branchHead) []branchHead) error {
+	if d.IsEmpty() {
+		return ErrNoDscache
+	}
+	builder := flatbuffers.NewBuilder(0)
+	users := d.copyUserAssociationList(builder)
+	refs := d.copyReferenceListWithReplacement(
+		builder,
+		func(r *dscachefb.RefEntryInfo) bool {
+			return string(r.InitID()) == initID
+		},
+		func(refStartMutationFunc func(builder *flatbuffers.Builder)) {
+			// The old buffer isn't modified while building, read the current heads from it
+			var heads []branchHead
+			for i := 0; i < d.Root.RefsLength(); i++ {
+				r := dscachefb.RefEntryInfo{}
+				d.Root.Refs(&r, i)
+				if string(r.InitID()) == initID {
+					heads = readBranchHeads(&r)
+					break
+				}
+			}
+			branches := buildBranchHeads(builder, modify(heads))
+			refStartMutationFunc(builder)
+			dscachefb.RefEntryInfoAddBranches(builder, branches)
+		},
+	)
+	root, serialized := d.finishBuilding(builder, users, refs)
+	d.Root = root
+	d.Buffer = serialized
+	return d.save()
+}
+
 // Copy the entire dscache, except leave out the matching entry.
 func (d *Dscache) updateDeleteDataset(act *logbook.Action) error {
 	if d.IsEmpty() {
@@ -11,6 +11,7 @@ import (
 	"github.com/qri-io/qfs/localfs"
 	testPeers "github.com/qri-io/qri/config/test"
 	"github.com/qri-io/qri/dsref"
+	"github.com/qri-io/qri/logbook"
 	"github.com/qri-io/qri/repo/profile"
 )
 
@@ -69,3 +70,67 @@ func TestDscacheAssignSaveAndLoad(t *testing.T) {
 		t.Errorf("expected, 2 refs, got %d refs", loadable.Root.RefsLength())
 	}
 }
+
+func TestBranchHeads(t *testing.T) {
+	peerInfo := testPeers.GetTestPeerInfo(0)
+	profileID := profile.IDFromPeerID(peerInfo.PeerID).String()
+
+	builder := NewBuilder()
+	builder.AddUser("test_user", profileID)
+	builder.AddDsVersionInfo(dsref.VersionInfo{
+		InitID:    "abcd1",
+		ProfileID: profileID,
+		Name:      "my_ds",
+		Path:      "/ipfs/QmMain1",
+	})
+	cache := builder.Build()
+
+	cache.update(&logbook.Action{
+		Type:     logbook.ActionBranchInit,
+		InitID:   "abcd1",
+		Branch:   "experimental",
+		TopIndex: 1,
+		HeadRef:  "/ipfs/QmMain1",
+	})
+	cache.update(&logbook.Action{
+		Type:     logbook.ActionDatasetCommitChange,
+		InitID:   "abcd1",
+		Branch:   "experimental",
+		TopIndex: 2,
+		HeadRef:  "/ipfs/QmExp2",
+	})
+	// saving to the default branch shouldn't drop branch heads
+	cache.update(&logbook.Action{
+		Type:     logbook.ActionDatasetCommitChange,
+		InitID:   "abcd1",
+		Branch:   logbook.DefaultBranchName,
+		TopIndex: 2,
+		HeadRef:  "/ipfs/QmMain2",
+	})
+
+	ref := dsref.Ref{Username: "test_user", Name: "my_ds"}
+	info, err := cache.LookupByName(ref)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Path != "/ipfs/QmMain2" {
+		t.Errorf("default branch path mismatch. expected: %q, got: %q", "/ipfs/QmMain2", info.Path)
+	}
+
+	ref.Branch = "experimental"
+	if info, err = cache.LookupByName(ref); err != nil {
+		t.Fatal(err)
+	}
+	if info.Path != "/ipfs/QmExp2" || info.NumVersions != 2 {
+		t.Errorf("branch head mismatch. expected path %q with 2 versions, got: %q with %d", "/ipfs/QmExp2", info.Path, info.NumVersions)
+	}
+
+	cache.update(&logbook.Action{
+		Type:   logbook.ActionBranchDelete,
+		InitID: "abcd1",
+		Branch: "experimental",
+	})
+	if _, err = cache.LookupByName(ref); err == nil {
+		t.Error("expected looking up a deleted branch to error")
+	}
+}
@@ -0,0 +1,71 @@
+// Code generated by the FlatBuffers compiler. DO NOT EDIT.
+
+package dscachefb
+
+import (
+	flatbuffers "github.com/google/flatbuffers/go"
+)
+
+type BranchHead struct {
+	_tab flatbuffers.Table
+}
+
+func GetRootAsBranchHead(buf []byte, offset flatbuffers.UOffsetT) *BranchHead {
+	n := flatbuffers.GetUOffsetT(buf[offset:])
+	x := &BranchHead{}
+	x.Init(buf, n+offset)
+	return x
+}
+
+func (rcv *BranchHead) Init(buf []byte, i flatbuffers.UOffsetT) {
+	rcv._tab.Bytes = buf
+	rcv._tab.Pos = i
+}
+
+func (rcv *BranchHead) Table() flatbuffers.Table {
+	return rcv._tab
+}
+
+func (rcv *BranchHead) Name() []byte {
+	o := flatbuffers.UOffsetT(rcv._tab.Offset(4))
+	if o != 0 {
+		return rcv._tab.ByteVector(o + rcv._tab.Pos)
+	}
+	return nil
+}
+
+func (rcv *BranchHead) HeadRef() []byte {
+	o := flatbuffers.UOffsetT(rcv._tab.Offset(6))
+	if o != 0 {
+		return rcv._tab.ByteVector(o + rcv._tab.Pos)
+	}
+	return nil
+}
+
+func (rcv *BranchHead) TopIndex() int32 {
+	o := flatbuffers.UOffsetT(rcv._tab.Offset(8))
+	if o != 0 {
+		return rcv._tab.GetInt32(o + rcv._tab.Pos)
+	}
+	return 0
+}
+
+func (rcv *BranchHead) MutateTopIndex(n int32) bool {
+	return rcv._tab.MutateInt32Slot(8, n)
+}
+
+func BranchHeadStart(builder *flatbuffers.Builder) {
+	builder.StartObject(3)
+}
+func BranchHeadAddName(builder *flatbuffers.Builder, name flatbuffers.UOffsetT) {
+	builder.PrependUOffsetTSlot(0, flatbuffers.UOffsetT(name), 0)
+}
+func BranchHeadAddHeadRef(builder *flatbuffers.Builder, headRef flatbuffers.UOffsetT) {
+	builder.PrependUOffsetTSlot(1, flatbuffers.UOffsetT(headRef), 0)
+}
+func BranchHeadAddTopIndex(builder *flatbuffers.Builder, topIndex int32) {
+	builder.PrependInt32Slot(2, topIndex, 0)
+}
+func BranchHeadEnd(builder *flatbuffers.Builder) flatbuffers.UOffsetT {
+	return builder.EndObject()
+}
@@ -88,6 +88,11 @@ func (d *Dscache) copyReference(builder *flatbuffers.Builder, r *dscachefb.RefEn
 	themeList := builder.CreateString(string(r.ThemeList()))
 	hashRef := builder.CreateString(string(r.HeadRef()))
 	fsiPath := builder.CreateString(string(r.FsiPath()))
+	heads := readBranchHeads(r)
+	var branches flatbuffers.UOffsetT
+	if len(heads) > 0 {
+		branches = buildBranchHeads(builder, heads)
+	}
 	dscachefb.RefEntryInfoStart(builder)
 	dscachefb.RefEntryInfoAddInitID(builder, initID)
 	dscachefb.RefEntryInfoAddProfileID(builder, profileID)
@@ -102,4 +107,46 @@ func (d *Dscache) copyReference(builder *flatbuffers.Builder, r *dscachefb.RefEn
 	dscachefb.RefEntryInfoAddNumErrors(builder, int32(r.NumErrors()))
 	dscachefb.RefEntryInfoAddHeadRef(builder, hashRef)
 	dscachefb.RefEntryInfoAddFsiPath(builder, fsiPath)
+	if len(heads) > 0 {
+		dscachefb.RefEntryInfoAddBranches(builder, branches)
+	}
+}
+
+// branchHead is the head of a named branch of a dataset
+type branchHead struct {
+	Name     string
+	HeadRef  string
+	TopIndex int
+}
+
+func readBranchHeads(r *dscachefb.RefEntryInfo) []branchHead {
+	heads := make([]branchHead, 0, r.BranchesLength())
+	for i := 0; i < r.BranchesLength(); i++ {
+		bh := dscachefb.BranchHead{}
+		r.Branches(&bh, i)
+		heads = append(heads, branchHead{
+			Name:     string(bh.Name()),
+			HeadRef:  string(bh.HeadRef()),
+			TopIndex: int(bh.TopIndex()),
+		})
+	}
+	return heads
+}
+
+func buildBranchHeads(builder *flatbuffers.Builder, heads []branchHead) flatbuffers.UOffsetT {
+	headList := make([]flatbuffers.UOffsetT, 0, len(heads))
+	for _, h := range heads {
+		name := builder.CreateString(h.Name)
+		headRef := builder.CreateString(h.HeadRef)
+		dscachefb.BranchHeadStart(builder)
+		dscachefb.BranchHeadAddName(builder, name)
+		dscachefb.BranchHeadAddHeadRef(builder, headRef)
+		dscachefb.BranchHeadAddTopIndex(builder, int32(h.TopIndex))
+		headList = append(headList, dscachefb.BranchHeadEnd(builder))
+	}
+	dscachefb.RefEntryInfoStartBranchesVector(builder, len(headList))
+	for i := len(headList) - 1; i >= 0; i-- {
+		builder.PrependUOffsetT(headList[i])
+	}
+	return builder.EndVector(len(headList))
 }
@@ -7,9 +7,9 @@ import (
 )
 
 // These functions parse a string to create a dsref. We refer to a "human-friendly reference"
-// as one with only a username and dataset name, such as "my_user/my_dataset". A "full reference"
-// can also contain a "concrete reference", which includes an optional profileID plus a network
-// and a "commit hash".
+// as one with only a username and dataset name, such as "my_user/my_dataset", optionally followed
+// by a branch name. A "full reference" can also contain a "concrete reference", which includes an
+// optional profileID plus a network and a "commit hash".
 //
 // Parse will parse a human-friendly reference, or only a concrete reference, or a full reference.
 //
@@ -18,12 +18,13 @@ import (
 // The grammar is here:
 //
 //  <dsref> = <humanFriendlyRef> [ <concreteRef> ] | <concreteRef>
-//  <humanFriendlyRef> = <username> '/' <datasetname>
+//  <humanFriendlyRef> = <username> '/' <datasetname> [ '#' <branch> ]
 //  <concreteRef> = '@' [ <profileID> ] '/' <network> '/' <commitHash>
 //
 // Some examples of valid references:
 //     me/dataset
 //     username/dataset
+//     username/dataset#branch
 //     @/ipfs/QmSome1Commit2Hash3
 //     @QmProfile4ID5/ipfs/QmSome1Commit2Hash3
 //     username/dataset@QmProfile4ID5/ipfs/QmSome1Commit2Hash3
@@ -37,10 +38,11 @@ const (
 )
 
 var (
-	dsNameCheck    = regexp.MustCompile(`^` + alphaNumericDsname + `$`)
-	humanFriendly  = regexp.MustCompile(`^(` + alphaNumeric + `)\/(` + alphaNumericDsname + `)`)
-	concreteRef    = regexp.MustCompile(`^@(` + b58Id + `)?\/(` + alphaNumeric + `)\/(` + b58Id + `)`)
-	b58StrictCheck = regexp.MustCompile(`^Qm[1-9A-HJ-NP-Za-km-z]*$`)
+	dsNameCheck     = regexp.MustCompile(`^` + alphaNumericDsname + `$`)
+	branchNameCheck = regexp.MustCompile(`^` + alphaNumeric + `$`)
+	humanFriendly   = regexp.MustCompile(`^(` + alphaNumeric + `)\/(` + alphaNumericDsname + `)(?:#(` + alphaNumeric + `))?`)
+	concreteRef     = regexp.MustCompile(`^@(` + b58Id + `)?\/(` + alphaNumeric + `)\/(` + b58Id + `)`)
+	b58StrictCheck  = regexp.MustCompile(`^Qm[1-9A-HJ-NP-Za-km-z]*$`)
 
 	// ErrEmptyRef is an error for when a reference is empty
 	ErrEmptyRef = fmt.Errorf("empty reference")
@@ -58,6 +60,8 @@ var (
 	ErrDescribeValidName = fmt.Errorf("dataset name must start with a lower-case letter, and only contain lower-case letters, numbers, dashes, and underscore. Maximum length is 144 characters")
 	// ErrDescribeValidUsername describes valid username
 	ErrDescribeValidUsername = fmt.Errorf("username must start with a lower-case letter, and only contain lower-case letters, numbers, dashes, and underscores")
+	// ErrDescribeValidBranch describes a valid branch name
+	ErrDescribeValidBranch = fmt.Errorf("branch name must start with a letter, and only contain letters, numbers, dashes, and underscores")
 )
 
 // Parse a reference from a string
@@ -73,6 +77,7 @@ func Parse(text string) (Ref, error) {
 		text = remain
 		r.Username = partial.Username
 		r.Name = partial.Name
+		r.Branch = partial.Branch
 	} else if err != ErrParseError {
 		return r, err
 	}
@@ -115,6 +120,7 @@ func ParseHumanFriendly(text string) (Ref, error) {
 		text = remain
 		r.Username = partial.Username
 		r.Name = partial.Name
+		r.Branch = partial.Branch
 	} else if err != ErrParseError {
 		return r, err
 	}
@@ -175,6 +181,14 @@ func EnsureValidName(text string) error {
 	return nil
 }
 
+// EnsureValidBranch returns nil if the branch name is valid, and an error otherwise
+func EnsureValidBranch(text string) error {
+	if !branchNameCheck.MatchString(text) {
+		return ErrDescribeValidBranch
+	}
+	return nil
+}
+
 // EnsureValidUsername is the same as EnsureValidName but returns a different error
 func EnsureValidUsername(text string) error {
 	err := EnsureValidName(text)
@@ -193,12 +207,13 @@ func parseHumanFriendly(text string) (string, Ref, error) {
 	if matches == nil {
 		return text, r, ErrParseError
 	}
-	if len(matches) != 3 {
+	if len(matches) != 4 {
 		return text, r, fmt.Errorf("unexpected number of regex matches %d", len(matches))
 	}
 	matchedLen := len(matches[0])
 	r.Username = matches[1]
 	r.Name = matches[2]
+	r.Branch = matches[3]
 	return text[matchedLen:], r, nil
 }
 
@@ -17,6 +17,8 @@ func TestParse(t *testing.T) {
 		{"long name", "peer/some_name@/map/QmXATayrFgsS3tpCi2ykfpNJ8uiCWT74dttnvJvVo1J7Rn", Ref{Username: "peer", Name: "some_name", Path: "/map/QmXATayrFgsS3tpCi2ykfpNJ8uiCWT74dttnvJvVo1J7Rn"}},
 		{"name-has-dash", "abc/my-dataset", Ref{Username: "abc", Name: "my-dataset"}},
 		{"dash-in-username", "some-user/my_dataset", Ref{Username: "some-user", Name: "my_dataset"}},
+		{"branch", "abc/my_dataset#experimental", Ref{Username: "abc", Name: "my_dataset", Branch: "experimental"}},
+		{"branch with path", "abc/my_dataset#exp-2@/ipfs/QmSecond", Ref{Username: "abc", Name: "my_dataset", Branch: "exp-2", Path: "/ipfs/QmSecond"}},
 	}
 	for i, c := range goodCases {
 		ref, err := Parse(c.text)
@@ -45,6 +47,8 @@ func TestParse(t *testing.T) {
 		{"absolute dirname", "/usr/local/bin", "parsing ref, unexpected character at position 0: '/'"},
 		{"dot in dataset", "abc/data.set", "parsing ref, unexpected character at position 8: '.'"},
 		{"equals in dataset", "abc/my=ds", "parsing ref, unexpected character at position 6: '='"},
+		{"empty branch", "abc/my_dataset#", "parsing ref, unexpected character at position 14: '#'"},
+		{"invalid branch", "abc/my_dataset#_exp", "parsing ref, unexpected character at position 14: '#'"},
 	}
 	for i, c := range badCases {
 		_, err := Parse(c.text)
@@ -73,6 +77,7 @@ func TestParseHumanFriendly(t *testing.T) {
 		expect      Ref
 	}{
 		{"human friendly", "abc/my_dataset", Ref{Username: "abc", Name: "my_dataset"}},
+		{"branch", "abc/my_dataset#experimental", Ref{Username: "abc", Name: "my_dataset", Branch: "experimental"}},
 	}
 	for i, c := range goodCases {
 		ref, err := ParseHumanFriendly(c.text)
@@ -8,6 +8,9 @@ type Ref struct {
 	ProfileID string `json:"profileID,omitempty"`
 	// Unique name reference for this dataset
 	Name string `json:"name,omitempty"`
+	// Branch of dataset history this reference points to. An empty branch
+	// refers to the default branch
+	Branch string `json:"branch,omitempty"`
 	// Content-addressed path for this dataset
 	Path string `json:"path,omitempty"`
 }
@@ -24,6 +27,9 @@ func (r Ref) Alias() (s string) {
 // String implements the Stringer interface for Ref
 func (r Ref) String() (s string) {
 	s = r.Alias()
+	if r.Branch != "" {
+		s += "#" + r.Branch
+	}
 	if r.ProfileID != "" || r.Path != "" {
 		s += "@"
 	}
@@ -38,10 +44,10 @@ func (r Ref) String() (s string) {
 
 // IsEmpty returns whether the reference is empty
 func (r Ref) IsEmpty() bool {
-	return r.Username == "" && r.ProfileID == "" && r.Name == "" && r.Path == ""
+	return r.Username == "" && r.ProfileID == "" && r.Name == "" && r.Branch == "" && r.Path == ""
 }
 
 // Equals returns whether the reference equals another
 func (r Ref) Equals(t Ref) bool {
-	return r.Username == t.Username && r.ProfileID == t.ProfileID && r.Name == t.Name && r.Path == t.Path
+	return r.Username == t.Username && r.ProfileID == t.ProfileID && r.Name == t.Name && r.Branch == t.Branch && r.Path == t.Path
 }
@@ -12,6 +12,7 @@ func TestRefAlias(t *testing.T) {
 		{Ref{}, ""},
 		{Ref{Username: "a", Name: "b"}, "a/b"},
 		{Ref{Username: "a", Name: "b", Path: "foo"}, "a/b"},
+		{Ref{Username: "a", Name: "b", Branch: "c"}, "a/b"},
 	}
 
 	for _, c := range cases {
@@ -31,6 +32,7 @@ func TestRefString(t *testing.T) {
 		{Ref{Username: "a", Name: "b"}, "a/b"},
 		{Ref{Username: "a", Name: "b"}, "a/b"},
 		{Ref{Username: "a", Name: "b", Path: "/foo"}, "a/b@/foo"},
+		{Ref{Username: "a", Name: "b", Branch: "c", Path: "/foo"}, "a/b#c@/foo"},
 	}
 
 	for _, c := range cases {
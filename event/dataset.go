@@ -0,0 +1,33 @@
+package event
+
+import "github.com/qri-io/qri/dsref"
+
+var (
+	// ETDatasetSaveEvent type for when a new dataset version is committed
+	// payload is a DatasetSaveEvent
+	ETDatasetSaveEvent = Topic("dataset:saveEvent")
+	// ETDatasetRemoveEvent type for when one or more versions of a dataset are
+	// removed. payload is a DatasetRemoveEvent
+	ETDatasetRemoveEvent = Topic("dataset:removeEvent")
+	// ETDatasetRenameEvent type for when a dataset is renamed
+	// payload is a DatasetRenameEvent
+	ETDatasetRenameEvent = Topic("dataset:renameEvent")
+)
+
+// DatasetSaveEvent describes a saved dataset version
+type DatasetSaveEvent struct {
+	Ref dsref.Ref
+}
+
+// DatasetRemoveEvent describes a dataset removal. NumDeleted is
+// dsref.AllGenerations when every version has been removed
+type DatasetRemoveEvent struct {
+	Ref        dsref.Ref
+	NumDeleted int
+}
+
+// DatasetRenameEvent describes a change of dataset name
+type DatasetRenameEvent struct {
+	Prev dsref.Ref
+	Next dsref.Ref
+}
@@ -45,6 +45,9 @@ type Bus interface {
 	Publish(t Topic, data interface{})
 	// Subscribe to one or more topics
 	Subscribe(topics ...Topic) <-chan Event
+	// SubscribeAll returns a channel that receives every event published to the
+	// bus, regardless of topic
+	SubscribeAll() <-chan Event
 	// Unsubscribe cleans up a channel that no longer need to receive events
 	Unsubscribe(<-chan Event)
 	// SubscribeOnce to one or more topics. the returned channel will only fire
@@ -61,8 +64,11 @@ type dataChannels []chan Event
 type bus struct {
 	ctx context.Context
 
-	lk   sync.RWMutex
-	subs map[Topic]dataChannels
+	lk      sync.RWMutex
+	subs    map[Topic]dataChannels
+	allSubs dataChannels
+	// closed when a channel is unsubscribed, releasing pending deliveries
+	removed map[<-chan Event]chan struct{}
 
 	onceLk sync.RWMutex
 	onces  []onceSub
@@ -80,8 +86,9 @@ type onceSub struct {
 // TODO (b5) - finish context-closing cleanup
 func NewBus(ctx context.Context) Bus {
 	b := &bus{
-		ctx:  ctx,
-		subs: map[Topic]dataChannels{},
+		ctx:     ctx,
+		subs:    map[Topic]dataChannels{},
+		removed: map[<-chan Event]chan struct{}{},
 	}
 
 	go func(b *bus) {
@@ -103,13 +110,22 @@ func (b *bus) Publish(topic Topic, data interface{}) {
 
 	event := Event{Payload: data, Topic: topic}
 
-	if chans, ok := b.subs[topic]; ok {
-		// slices in this map refer to same array even though they are passed by value
-		// creating a new slice preserves locking correctly
-		channels := append(dataChannels{}, chans...)
+	// slices in this map refer to same array even though they are passed by value
+	// creating a new slice preserves locking correctly
+	channels := append(dataChannels{}, b.subs[topic]...)
+	channels = append(channels, b.allSubs...)
+	if len(channels) > 0 {
+		removed := make([]chan struct{}, len(channels))
+		for i, ch := range channels {
+			removed[i] = b.removed[ch]
+		}
 		go func(e Event, dataChannelSlices dataChannels) {
-			for _, ch := range dataChannelSlices {
-				ch <- e
+			for i, ch := range dataChannelSlices {
+				// drop the event if the subscriber unsubscribes before receiving it
+				select {
+				case ch <- e:
+				case <-removed[i]:
+				}
 			}
 		}(event, channels)
 	}
@@ -139,6 +155,7 @@ func (b *bus) Subscribe(topics ...Topic) <-chan Event {
 	log.Debugf("Subscribe: %v", topics)
 
 	ch := make(chan Event)
+	b.removed[ch] = make(chan struct{})
 
 	for _, topic := range topics {
 		if prev, ok := b.subs[topic]; ok {
@@ -151,10 +168,34 @@ func (b *bus) Subscribe(topics ...Topic) <-chan Event {
 	return ch
 }
 
-// Unsubscribe cleans up a channel that no longer need to receive events
+// SubscribeAll requests every event published to the bus, returning a channel
+// of those events
+func (b *bus) SubscribeAll() <-chan Event {
+	b.lk.Lock()
+	defer b.lk.Unlock()
+	log.Debugf("SubscribeAll")
+
+	ch := make(chan Event)
+	b.removed[ch] = make(chan struct{})
+	b.allSubs = append(b.allSubs, ch)
+	return ch
+}
+
+// Unsubscribe cleans up a channel that no longer need to receive events.
+// Events dispatched to the channel that haven't been received are dropped
 func (b *bus) Unsubscribe(unsub <-chan Event) {
 	b.lk.Lock()
 	defer b.lk.Unlock()
+	if removed, ok := b.removed[unsub]; ok {
+		close(removed)
+		delete(b.removed, unsub)
+	}
+	for i, ch := range b.allSubs {
+		if ch == unsub {
+			b.allSubs = append(b.allSubs[:i:i], b.allSubs[i+1:]...)
+			break
+		}
+	}
 	for topic, channels := range b.subs {
 		var replace dataChannels
 		for i, ch := range channels {
@@ -172,7 +213,7 @@ func (b *bus) Unsubscribe(unsub <-chan Event) {
 func (b *bus) NumSubscribers() int {
 	b.lk.Lock()
 	defer b.lk.Unlock()
-	total := 0
+	total := len(b.allSubs)
 	for _, channels := range b.subs {
 		total += len(channels)
 	}
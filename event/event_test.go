@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"testing"
+	"time"
 )
 
 func Example() {
@@ -77,3 +78,52 @@ func TestSubscribeUnsubscribe(t *testing.T) {
 		t.Errorf("expected 1 subscribers, got %d", b.NumSubscribers())
 	}
 }
+
+func TestSubscribeAll(t *testing.T) {
+	ctx := context.Background()
+	const (
+		topicA = Topic("test:a")
+		topicB = Topic("test:b")
+	)
+
+	b := NewBus(ctx)
+	ch := b.SubscribeAll()
+
+	if b.NumSubscribers() != 1 {
+		t.Errorf("expected 1 subscriber, got %d", b.NumSubscribers())
+	}
+
+	go b.Publish(topicA, "a")
+	if e := <-ch; e.Topic != topicA {
+		t.Errorf("expected topic %q, got %q", topicA, e.Topic)
+	}
+	go b.Publish(topicB, "b")
+	if e := <-ch; e.Topic != topicB {
+		t.Errorf("expected topic %q, got %q", topicB, e.Topic)
+	}
+
+	b.Unsubscribe(ch)
+	if b.NumSubscribers() != 0 {
+		t.Errorf("expected 0 subscribers, got %d", b.NumSubscribers())
+	}
+}
+
+func TestUnsubscribeReleasesPublisher(t *testing.T) {
+	ctx := context.Background()
+	const testTopic = Topic("test_event")
+
+	b := NewBus(ctx)
+	ch := b.Subscribe(testTopic)
+	received := b.Subscribe(testTopic)
+
+	// nothing reads from ch, delivery to received must not block on it once
+	// ch is unsubscribed
+	b.Publish(testTopic, "a")
+	b.Unsubscribe(ch)
+
+	select {
+	case <-received:
+	case <-time.After(time.Second):
+		t.Fatal("publisher blocked on an unsubscribed channel")
+	}
+}
@@ -0,0 +1,17 @@
+package event
+
+var (
+	// ETPeerConnectEvent type for when this node connects to a peer
+	// payload is a PeerEvent
+	ETPeerConnectEvent = Topic("p2p:peerConnectEvent")
+	// ETPeerDisconnectEvent type for when this node explicitly disconnects from
+	// a peer. payload is a PeerEvent
+	ETPeerDisconnectEvent = Topic("p2p:peerDisconnectEvent")
+)
+
+// PeerEvent describes a peer this node has interacted with
+type PeerEvent struct {
+	ProfileID string
+	Peername  string
+	PeerID    string
+}
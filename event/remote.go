@@ -0,0 +1,86 @@
+package event
+
+import (
+	"time"
+
+	"github.com/qri-io/qri/dsref"
+)
+
+var (
+	// ETRemotePublishEvent type for when a dataset is pushed to a remote
+	// payload is a RemoteEvent
+	ETRemotePublishEvent = Topic("remote:publishEvent")
+	// ETRemoteUnpublishEvent type for when a dataset is removed from a remote
+	// payload is a RemoteEvent
+	ETRemoteUnpublishEvent = Topic("remote:unpublishEvent")
+	// ETRemotePullEvent type for when a dataset version is pulled from a remote
+	// payload is a RemoteEvent
+	ETRemotePullEvent = Topic("remote:pullEvent")
+	// ETLogsyncPushEvent type for when logbook data is pushed to a remote
+	// payload is a RemoteEvent
+	ETLogsyncPushEvent = Topic("logsync:pushEvent")
+	// ETLogsyncPullEvent type for when logbook data is pulled from a remote
+	// payload is a RemoteEvent
+	ETLogsyncPullEvent = Topic("logsync:pullEvent")
+	// ETRemoteTransferProgressEvent type for when blocks of a dataset are
+	// transferred while pushing to or pulling from a remote
+	// payload is a TransferProgressEvent
+	ETRemoteTransferProgressEvent = Topic("remote:transferProgressEvent")
+	// ETRemoteDatasetMirroredEvent type for when a new dataset version is
+	// replicated from an upstream remote
+	// payload is a RemoteEvent
+	ETRemoteDatasetMirroredEvent = Topic("remote:datasetMirroredEvent")
+	// ETRemoteDatasetNotificationEvent type for when a remote announces a new
+	// version of a subscribed dataset
+	// payload is a RemoteEvent
+	ETRemoteDatasetNotificationEvent = Topic("remote:datasetNotificationEvent")
+
+	// ETRemoteDatasetPushedEvent type for when a peer finishes pushing a dataset
+	// to this node while acting as a remote
+	// payload is a RemoteHookEvent
+	ETRemoteDatasetPushedEvent = Topic("remote:datasetPushedEvent")
+	// ETRemoteDatasetRemovedEvent type for when a peer removes a dataset from
+	// this node while acting as a remote
+	// payload is a RemoteHookEvent
+	ETRemoteDatasetRemovedEvent = Topic("remote:datasetRemovedEvent")
+	// ETRemoteLogPushedEvent type for when a peer pushes logbook data to this
+	// node while acting as a remote
+	// payload is a RemoteHookEvent
+	ETRemoteLogPushedEvent = Topic("remote:logPushedEvent")
+)
+
+// RemoteEvent describes a syncronization action between this node and a
+// remote
+type RemoteEvent struct {
+	Ref        dsref.Ref
+	RemoteAddr string
+}
+
+// TransferProgressEvent describes the state of a dataset transfer between this
+// node and a remote
+type TransferProgressEvent struct {
+	Ref        dsref.Ref
+	RemoteAddr string
+	// Direction is either "push" or "pull"
+	Direction   string
+	BlocksDone  int
+	BlocksTotal int
+	BytesDone   uint64
+	BytesTotal  uint64
+	// Rate is the transfer speed in bytes per second, not counting blocks that
+	// were present before the transfer began
+	Rate float64
+	// ETA estimates the time remaining, zero when unknown
+	ETA time.Duration
+	// Resumed is the number of blocks an earlier, interrupted transfer had
+	// already moved
+	Resumed  int
+	Complete bool
+}
+
+// RemoteHookEvent describes an action a peer has taken against this node while
+// it's acting as a remote
+type RemoteHookEvent struct {
+	ProfileID string
+	Ref       dsref.Ref
+}
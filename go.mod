@@ -31,6 +31,7 @@ require (
 	github.com/ipfs/go-ipld-format v0.0.2
 	github.com/ipfs/go-log v0.0.1
 	github.com/ipfs/interface-go-ipfs-core v0.2.3
+	github.com/jbenet/goprocess v0.1.3
 	github.com/jinzhu/copier v0.0.0-20180308034124-7e38e58719c3
 	github.com/libp2p/go-libp2p v0.4.0
 	github.com/libp2p/go-libp2p-circuit v0.1.3
@@ -62,9 +63,9 @@ require (
 	github.com/spf13/cobra v0.0.5
 	github.com/theckman/go-flock v0.7.1
 	github.com/ugorji/go/codec v1.1.7
-	go.starlark.net v0.0.0-20200330013621-be5394c419b6
+	go.starlark.net v0.0.0-20200901195727-6e684ef5eeee
 	golang.org/x/crypto v0.0.0-20190926180335-cea2066c6411
-	golang.org/x/sys v0.0.0-20200323222414-85ca7c5b95cd
+	golang.org/x/sys v0.0.0-20200803210538-64077c9b5642
 	golang.org/x/text v0.3.2
 	gonum.org/v1/gonum v0.6.0
 	gopkg.in/yaml.v2 v2.2.8
@@ -0,0 +1,296 @@
+package lib
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"time"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/dataset/dsio"
+	"github.com/qri-io/deepdiff"
+	"github.com/qri-io/qfs"
+	"github.com/qri-io/qri/base"
+	"github.com/qri-io/qri/base/dsfs"
+	"github.com/qri-io/qri/repo"
+	"github.com/qri-io/qri/sql"
+	"github.com/qri-io/qri/startf"
+)
+
+// DefaultApplyPreviewLimit is the number of body entries Apply previews when
+// no limit is given
+const DefaultApplyPreviewLimit = 10
+
+// ApplyParams are parameters for running a transform script without saving
+// the result
+type ApplyParams struct {
+	Ref string
+	// ScriptPath is the path to a transform script to run. If empty, the
+	// transform of the dataset head is run
+	ScriptPath string
+	// Transform is a transform to run with inline script bytes, takes
+	// precedence over ScriptPath
+	Transform *dataset.Transform
+	// secrets for transform execution
+	Secrets map[string]string
+	// resource limits for transform execution, zero values mean no limit
+	TransformLimits startf.Limits
+	// serve transform http requests from the recorded fixture instead of the
+	// network
+	ReplayHTTP bool
+	// number of body entries to preview, DefaultApplyPreviewLimit if zero
+	PreviewLimit int
+}
+
+// ApplyResult describes the outcome of running a transform against a dataset
+// head. Nothing in an ApplyResult has been written to the repo
+type ApplyResult struct {
+	// Dataset is the dataset the transform produced, without a body
+	Dataset *dataset.Dataset `json:"dataset"`
+	// Changes are meta & structure differences from head, keyed by component
+	Changes map[string][]*Delta `json:"changes,omitempty"`
+	// BodyChanged is true if the transform set a body
+	BodyChanged bool `json:"bodyChanged"`
+	// BodyPreview is the first entries of the resulting body
+	BodyPreview interface{} `json:"bodyPreview,omitempty"`
+	// BodyStat & BodyDiff describe differences between the body of head and
+	// the resulting body
+	BodyStat *DiffStat `json:"bodyStat,omitempty"`
+	BodyDiff []*Delta  `json:"bodyDiff,omitempty"`
+	// Output is the "stderr" diagnostic output of the transform script
+	Output string `json:"output,omitempty"`
+	// Duration is the time taken to execute the transform
+	Duration time.Duration `json:"duration"`
+}
+
+// Apply runs a transform script against the head of a dataset, reporting
+// what the transform would change without writing to the repo or logbook
+func (m *DatasetMethods) Apply(p *ApplyParams, res *ApplyResult) error {
+	if p.ScriptPath != "" {
+		// absolutize local paths before a possible trip over RPC to another
+		// local process
+		if err := qfs.AbsPath(&p.ScriptPath); err != nil {
+			return err
+		}
+	}
+	if m.inst.rpc != nil {
+		return checkRPCError(m.inst.rpc.Call("DatasetMethods.Apply", p, res))
+	}
+	ctx := m.inst.Context()
+
+	var prev, mutable *dataset.Dataset
+	if p.Ref != "" {
+		ref, err := repo.ParseDatasetRef(p.Ref)
+		if err != nil {
+			return err
+		}
+		if err = repo.CanonicalizeDatasetRef(m.inst.repo, &ref); err != nil && err != repo.ErrNoHistory && err != repo.ErrNotFound {
+			return err
+		}
+		if prev, mutable, _, err = base.PrepareHeadDatasetVersion(ctx, m.inst.repo, ref.Peername, ref.Name); err != nil {
+			return err
+		}
+	} else {
+		prev, mutable = &dataset.Dataset{}, &dataset.Dataset{}
+	}
+
+	tf, err := m.applyTransform(ctx, p, prev)
+	if err != nil {
+		return err
+	}
+
+	next := &dataset.Dataset{Transform: tf}
+	output := &bytes.Buffer{}
+	opts := []func(*startf.ExecOpts){
+		startf.AddQriRepo(m.inst.repo),
+		startf.AddMutateFieldCheck(startf.MutatedComponentsFunc(next)),
+		startf.SetErrWriter(output),
+		startf.SetSecrets(p.Secrets),
+		startf.SetLimits(p.TransformLimits),
+		startf.SetReplayHTTP(p.ReplayHTTP),
+		startf.SetSQLService(sql.New(m.inst.repo)),
+	}
+
+	start := time.Now()
+	exec := startf.ExecScript
+	if startf.IsSQLTransform(tf) {
+		exec = startf.ExecSQL
+	}
+	err = exec(ctx, next, prev, opts...)
+	res.Duration = time.Since(start)
+	res.Output = output.String()
+	if err != nil {
+		return err
+	}
+
+	// treat transform results as a patch on head, the way save does
+	body := next.BodyFile()
+	mutable.Assign(next)
+	res.Dataset = mutable
+	res.Dataset.Transform.DropTransientValues()
+
+	res.Changes = map[string][]*Delta{}
+	for name, pair := range map[string][2]interface{}{
+		"meta":      {prev.Meta, mutable.Meta},
+		"structure": {prev.Structure, mutable.Structure},
+	} {
+		deltas, err := componentDeltas(ctx, pair[0], pair[1])
+		if err != nil {
+			return fmt.Errorf("comparing %s: %w", name, err)
+		}
+		if len(deltas) > 0 {
+			res.Changes[name] = deltas
+		}
+	}
+
+	if body == nil {
+		return nil
+	}
+	res.BodyChanged = true
+
+	// scripts can consume the body of head, load a fresh copy to compare with
+	var prevBody qfs.File
+	if prev.BodyPath != "" {
+		if prevBody, err = dsfs.LoadBody(ctx, m.inst.repo.Store(), prev); err != nil {
+			return err
+		}
+	}
+	return applyBodyResults(ctx, prev.Structure, prevBody, mutable.Structure, body, p.PreviewLimit, res)
+}
+
+// applyTransform picks the transform Apply runs from params, falling back to
+// the transform of the dataset head
+func (m *DatasetMethods) applyTransform(ctx context.Context, p *ApplyParams, prev *dataset.Dataset) (*dataset.Transform, error) {
+	tf := &dataset.Transform{}
+	switch {
+	case p.Transform != nil:
+		tf.Assign(p.Transform)
+		tf.SetScriptFile(qfs.NewMemfileBytes("transform.star", p.Transform.ScriptBytes))
+	case p.ScriptPath != "":
+		data, err := ioutil.ReadFile(p.ScriptPath)
+		if err != nil {
+			return nil, fmt.Errorf("reading transform script: %w", err)
+		}
+		name := "transform.star"
+		if filepath.Ext(p.ScriptPath) == ".sql" {
+			name = "transform.sql"
+			tf.Syntax = startf.SQLSyntax
+		}
+		tf.SetScriptFile(qfs.NewMemfileBytes(name, data))
+	case prev.Transform != nil && prev.Transform.ScriptPath != "":
+		tf.Assign(prev.Transform)
+		if err := tf.OpenScriptFile(ctx, m.inst.repo.Store()); err != nil {
+			return nil, fmt.Errorf("opening transform script: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("dataset has no transform, provide a transform script to apply")
+	}
+
+	// keep config, including any recorded http fixture, when running a new
+	// script against an existing dataset
+	if tf.Config == nil && prev.Transform != nil {
+		tf.Config = prev.Transform.Config
+	}
+	return tf, nil
+}
+
+// applyBodyResults previews the body a transform produced & compares it to
+// the body of head
+func applyBodyResults(ctx context.Context, prevSt *dataset.Structure, prevFile qfs.File, st *dataset.Structure, body qfs.File, limit int, res *ApplyResult) error {
+	if st == nil {
+		return fmt.Errorf("transform produced a body without a structure")
+	}
+	data, err := ioutil.ReadAll(body)
+	if err != nil {
+		return err
+	}
+	nextBody, err := readBodyEntries(st, data, -1)
+	if err != nil {
+		return err
+	}
+	if limit <= 0 {
+		limit = DefaultApplyPreviewLimit
+	}
+	if res.BodyPreview, err = readBodyEntries(st, data, limit); err != nil {
+		return err
+	}
+
+	var prevBody interface{} = []interface{}{}
+	if prevFile != nil && prevSt != nil {
+		prevData, err := ioutil.ReadAll(prevFile)
+		if err != nil {
+			return err
+		}
+		if prevBody, err = readBodyEntries(prevSt, prevData, -1); err != nil {
+			return err
+		}
+	}
+
+	res.BodyDiff, res.BodyStat, err = deepdiff.New().StatDiff(ctx, prevBody, nextBody)
+	return err
+}
+
+// readBodyEntries reads up to limit entries of body data, -1 reads all
+// entries
+func readBodyEntries(st *dataset.Structure, data []byte, limit int) (interface{}, error) {
+	r, err := dsio.NewEntryReader(st, bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	if limit >= 0 {
+		r = &dsio.PagedReader{Reader: r, Limit: limit}
+	}
+	return base.ReadEntries(r)
+}
+
+// componentDeltas diffs two dataset components, ignoring values derived
+// from the body like checksums & entry counts
+func componentDeltas(ctx context.Context, prev, next interface{}) ([]*Delta, error) {
+	prevVal, err := derivedValuesDropped(prev)
+	if err != nil {
+		return nil, err
+	}
+	nextVal, err := derivedValuesDropped(next)
+	if err != nil {
+		return nil, err
+	}
+	return deepdiff.New().Diff(ctx, prevVal, nextVal)
+}
+
+// derivedValuesDropped converts a component to generic values without
+// derived fields, leaving the component itself unmodified
+func derivedValuesDropped(comp interface{}) (interface{}, error) {
+	data, err := json.Marshal(comp)
+	if err != nil {
+		return nil, err
+	}
+	switch comp.(type) {
+	case *dataset.Meta:
+		md := &dataset.Meta{}
+		if err := json.Unmarshal(data, md); err != nil {
+			return nil, err
+		}
+		md.DropDerivedValues()
+		data, err = json.Marshal(md)
+	case *dataset.Structure:
+		st := &dataset.Structure{}
+		if err := json.Unmarshal(data, st); err != nil {
+			return nil, err
+		}
+		st.DropDerivedValues()
+		data, err = json.Marshal(st)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	val := map[string]interface{}{}
+	if string(data) == "null" {
+		return val, nil
+	}
+	err = json.Unmarshal(data, &val)
+	return val, err
+}
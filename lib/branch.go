@@ -0,0 +1,134 @@
+package lib
+
+import (
+	"fmt"
+
+	"github.com/qri-io/qri/dsref"
+	"github.com/qri-io/qri/logbook"
+	"github.com/qri-io/qri/repo"
+	reporef "github.com/qri-io/qri/repo/ref"
+)
+
+// BranchMethods extends a lib.Instance with business logic for working with
+// named branches of dataset history
+type BranchMethods struct {
+	inst *Instance
+}
+
+// CoreRequestsName implements the Requets interface
+func (m BranchMethods) CoreRequestsName() string { return "branch" }
+
+// NewBranchMethods creates a BranchMethods pointer from a qri instance
+func NewBranchMethods(inst *Instance) *BranchMethods {
+	return &BranchMethods{
+		inst: inst,
+	}
+}
+
+// BranchInfo describes a branch of dataset history
+type BranchInfo = logbook.BranchInfo
+
+// BranchParams defines parameters for branch methods
+type BranchParams struct {
+	// Reference to the dataset to branch
+	Ref string
+	// Name of the branch
+	Name string
+	// Branch to start a new branch from, empty uses the default branch
+	From string
+}
+
+// Create starts a new named branch of a dataset's history
+func (m *BranchMethods) Create(p *BranchParams, res *BranchInfo) error {
+	if m.inst.rpc != nil {
+		return checkRPCError(m.inst.rpc.Call("BranchMethods.Create", p, res))
+	}
+	ctx := m.inst.Context()
+
+	ref, initID, err := m.resolveInitID(p.Ref)
+	if err != nil {
+		return err
+	}
+	book := m.inst.repo.Logbook()
+	if err = book.WriteBranchInit(ctx, initID, p.Name, p.From); err != nil {
+		return err
+	}
+
+	branches, err := book.Branches(ctx, ref)
+	if err != nil {
+		return err
+	}
+	for _, b := range branches {
+		if b.Name == p.Name {
+			*res = b
+			return nil
+		}
+	}
+	return fmt.Errorf("branch %q not found after creation", p.Name)
+}
+
+// List shows the branches of a dataset, starting with the default branch
+func (m *BranchMethods) List(p *BranchParams, res *[]BranchInfo) error {
+	if m.inst.rpc != nil {
+		return checkRPCError(m.inst.rpc.Call("BranchMethods.List", p, res))
+	}
+	ctx := m.inst.Context()
+
+	ref, _, err := m.resolveInitID(p.Ref)
+	if err != nil {
+		return err
+	}
+	*res, err = m.inst.repo.Logbook().Branches(ctx, ref)
+	return err
+}
+
+// Delete removes a named branch from a dataset's history. The default branch
+// cannot be deleted
+func (m *BranchMethods) Delete(p *BranchParams, res *bool) error {
+	if m.inst.rpc != nil {
+		return checkRPCError(m.inst.rpc.Call("BranchMethods.Delete", p, res))
+	}
+	ctx := m.inst.Context()
+
+	_, initID, err := m.resolveInitID(p.Ref)
+	if err != nil {
+		return err
+	}
+	if err = m.inst.repo.Logbook().WriteBranchDelete(ctx, initID, p.Name); err != nil {
+		return err
+	}
+	*res = true
+	return nil
+}
+
+func (m *BranchMethods) resolveInitID(refstr string) (dsref.Ref, string, error) {
+	if refstr == "" {
+		return dsref.Ref{}, "", repo.ErrEmptyRef
+	}
+	ref, err := repo.ParseDatasetRef(refstr)
+	if err != nil {
+		return dsref.Ref{}, "", fmt.Errorf("'%s' is not a valid dataset reference", refstr)
+	}
+	if err = repo.CanonicalizeDatasetRef(m.inst.repo, &ref); err != nil && err != repo.ErrNoHistory {
+		return dsref.Ref{}, "", err
+	}
+	dr := reporef.ConvertToDsref(ref)
+	initID, err := m.inst.repo.Logbook().RefToInitID(dr)
+	if err != nil {
+		return dsref.Ref{}, "", err
+	}
+	return dr, initID, nil
+}
+
+// splitBranch separates a branch name from a dataset reference string,
+// returning the reference without the branch. Reference strings that don't
+// name a branch are returned unchanged
+func splitBranch(refstr string) (string, string) {
+	ref, err := dsref.Parse(refstr)
+	if (err != nil && err != dsref.ErrBadCaseName) || ref.Branch == "" {
+		return refstr, ""
+	}
+	branch := ref.Branch
+	ref.Branch = ""
+	return ref.String(), branch
+}
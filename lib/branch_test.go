@@ -0,0 +1,95 @@
+package lib
+
+import (
+	"testing"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/qri/logbook"
+	reporef "github.com/qri-io/qri/repo/ref"
+)
+
+func TestBranchMethods(t *testing.T) {
+	tr, cleanup := newTestRunner(t)
+	defer cleanup()
+
+	bm := NewBranchMethods(tr.Instance)
+	dm := NewDatasetMethods(tr.Instance)
+	lm := NewLogMethods(tr.Instance)
+
+	all := ListParams{Limit: 100}
+	mainLog := []DatasetLogItem{}
+	if err := lm.Log(&LogParams{Ref: "me/movies", ListParams: all}, &mainLog); err != nil {
+		t.Fatal(err)
+	}
+
+	created := BranchInfo{}
+	if err := bm.Create(&BranchParams{Ref: "me/movies", Name: "experimental"}, &created); err != nil {
+		t.Fatal(err)
+	}
+	if created.From != logbook.DefaultBranchName || created.NumVersions != len(mainLog) {
+		t.Errorf("expected branch from %q with %d versions, got: %#v", logbook.DefaultBranchName, len(mainLog), created)
+	}
+
+	saved := &dataset.Dataset{Meta: &dataset.Meta{Title: "experimental title"}}
+	res := &reporef.DatasetRef{}
+	if err := dm.Save(&SaveParams{Ref: "me/movies#experimental", Dataset: saved}, res); err != nil {
+		t.Fatal(err)
+	}
+
+	branchLog := []DatasetLogItem{}
+	if err := lm.Log(&LogParams{Ref: "me/movies#experimental", ListParams: all}, &branchLog); err != nil {
+		t.Fatal(err)
+	}
+	if len(branchLog) != len(mainLog)+1 || branchLog[0].Path != res.Path {
+		t.Errorf("expected branch log to have %d versions headed by %q, got: %v", len(mainLog)+1, res.Path, branchLog)
+	}
+
+	got := []DatasetLogItem{}
+	if err := lm.Log(&LogParams{Ref: "me/movies", ListParams: all}, &got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != len(mainLog) || got[0].Path != mainLog[0].Path {
+		t.Errorf("expected saving to a branch to leave the default branch unchanged")
+	}
+
+	branches := []BranchInfo{}
+	if err := bm.List(&BranchParams{Ref: "me/movies"}, &branches); err != nil {
+		t.Fatal(err)
+	}
+	if len(branches) != 2 || branches[1].Name != "experimental" || branches[1].Path != res.Path {
+		t.Errorf("unexpected branch list: %v", branches)
+	}
+
+	deleted := false
+	if err := bm.Delete(&BranchParams{Ref: "me/movies", Name: logbook.DefaultBranchName}, &deleted); err == nil {
+		t.Error("expected deleting the default branch to error")
+	}
+	if err := bm.Delete(&BranchParams{Ref: "me/movies", Name: "experimental"}, &deleted); err != nil {
+		t.Fatal(err)
+	}
+	if err := bm.List(&BranchParams{Ref: "me/movies"}, &branches); err != nil {
+		t.Fatal(err)
+	}
+	if len(branches) != 1 {
+		t.Errorf("expected 1 branch after delete, got: %d", len(branches))
+	}
+}
+
+func TestSplitBranch(t *testing.T) {
+	cases := []struct {
+		in, ref, branch string
+	}{
+		{"me/movies", "me/movies", ""},
+		{"me/movies#experimental", "me/movies", "experimental"},
+		{"me/movies#exp@/ipfs/QmFoo", "me/movies@/ipfs/QmFoo", "exp"},
+		// a branch name that also appears elsewhere in the reference
+		{"movies/movies#movies", "movies/movies", "movies"},
+		{"not a ref", "not a ref", ""},
+	}
+	for _, c := range cases {
+		ref, branch := splitBranch(c.in)
+		if ref != c.ref || branch != c.branch {
+			t.Errorf("splitBranch(%q): expected (%q, %q), got (%q, %q)", c.in, c.ref, c.branch, ref, branch)
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package lib
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/qri-io/qri/base"
+	"github.com/qri-io/qri/base/bundle"
+	"github.com/qri-io/qri/base/dsfs"
+	"github.com/qri-io/qri/repo"
+	reporef "github.com/qri-io/qri/repo/ref"
+)
+
+// CreateBundleParams encapsulates parameters for writing a dataset bundle
+type CreateBundleParams struct {
+	Ref string
+	// Versions is the number of versions to bundle, starting with the latest.
+	// Zero bundles only the latest version, -1 bundles all versions
+	Versions int
+	// Output is the path of the bundle file to write
+	Output string
+}
+
+// CreateBundle writes a dataset, its history and the data of the requested
+// versions to a single bundle file that can be imported by a repo without a
+// network connection
+func (m *DatasetMethods) CreateBundle(p *CreateBundleParams, res *bundle.Manifest) error {
+	if m.inst.rpc != nil {
+		return checkRPCError(m.inst.rpc.Call("DatasetMethods.CreateBundle", p, res))
+	}
+	ctx := m.inst.Context()
+
+	if p.Output == "" {
+		return fmt.Errorf("output path is required")
+	}
+	if p.Ref == "" {
+		return repo.ErrEmptyRef
+	}
+	ref, err := repo.ParseDatasetRef(p.Ref)
+	if err != nil {
+		return err
+	}
+	if err = repo.CanonicalizeDatasetRef(m.inst.repo, &ref); err != nil {
+		return err
+	}
+	if m.inst.node == nil {
+		return fmt.Errorf("bundles require a repo with an IPFS store")
+	}
+	capi, err := m.inst.node.IPFSCoreAPI()
+	if err != nil {
+		return err
+	}
+
+	dr := reporef.ConvertToDsref(ref)
+	dr.Path = ""
+	items, err := m.inst.repo.Logbook().Items(ctx, dr, 0, -1)
+	if err != nil {
+		return err
+	}
+	items = pullVersions(items, ref.Path, p.Versions)
+	if len(items) == 0 {
+		return repo.ErrNoHistory
+	}
+	versions := make([]string, len(items))
+	for i, item := range items {
+		versions[i] = item.Path
+	}
+
+	f, err := os.Create(p.Output)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	keys := (&LogMethods{inst: m.inst}).peerstoreKeys
+	mf, err := bundle.Write(ctx, f, m.inst.repo.Logbook(), capi, dr, versions, keys)
+	if err != nil {
+		f.Close()
+		os.Remove(p.Output)
+		return err
+	}
+	*res = *mf
+	return f.Close()
+}
+
+// ImportBundleParams encapsulates parameters for importing a dataset bundle
+type ImportBundleParams struct {
+	Path string
+}
+
+// ImportBundle reads a bundle file, adding the bundled history and versions
+// to the repo the same way pulling them from a remote would
+func (m *DatasetMethods) ImportBundle(p *ImportBundleParams, res *bundle.Manifest) error {
+	if m.inst.rpc != nil {
+		return checkRPCError(m.inst.rpc.Call("DatasetMethods.ImportBundle", p, res))
+	}
+	ctx := m.inst.Context()
+
+	if p.Path == "" {
+		return fmt.Errorf("bundle path is required")
+	}
+	if m.inst.node == nil {
+		return fmt.Errorf("bundles require a repo with an IPFS store")
+	}
+	capi, err := m.inst.node.IPFSCoreAPI()
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Open(p.Path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	mf, err := bundle.Import(ctx, f, m.inst.repo.Logbook(), capi)
+	if err != nil {
+		return err
+	}
+
+	if err = m.addImportedRef(ctx, reporef.RefFromDsref(mf.Ref)); err != nil {
+		return err
+	}
+
+	*res = *mf
+	return nil
+}
+
+// addImportedRef records the latest imported version in the repo, keeping the
+// existing reference if it points to a more recent version
+func (m *DatasetMethods) addImportedRef(ctx context.Context, ref reporef.DatasetRef) error {
+	r := m.inst.repo
+	prev, err := r.GetRef(reporef.DatasetRef{Peername: ref.Peername, Name: ref.Name})
+	if err == repo.ErrNotFound {
+		return r.PutRef(ref)
+	} else if err != nil {
+		return err
+	}
+
+	if prev.Dataset, err = dsfs.LoadDataset(ctx, r.Store(), prev.Path); err != nil {
+		return fmt.Errorf("loading repo dataset: %w", err)
+	}
+	if ref.Dataset, err = dsfs.LoadDataset(ctx, r.Store(), ref.Path); err != nil {
+		return fmt.Errorf("loading imported dataset: %w", err)
+	}
+	return base.ReplaceRefIfMoreRecent(r, &prev, &ref)
+}
@@ -24,12 +24,15 @@ import (
 	"github.com/qri-io/qri/dscache/build"
 	"github.com/qri-io/qri/dsref"
 	"github.com/qri-io/qri/errors"
+	"github.com/qri-io/qri/event"
 	"github.com/qri-io/qri/fsi"
 	"github.com/qri-io/qri/p2p"
 	"github.com/qri-io/qri/repo"
 	"github.com/qri-io/qri/repo/profile"
 	reporef "github.com/qri-io/qri/repo/ref"
 	"github.com/qri-io/qri/resolver/loader"
+	"github.com/qri-io/qri/sql"
+	"github.com/qri-io/qri/startf"
 )
 
 // DatasetMethods encapsulates business logic for working with Datasets on Qri
@@ -276,6 +279,9 @@ func (m *DatasetMethods) Get(p *GetParams, res *GetResult) error {
 		_ = initID
 	}
 
+	if err = m.inst.partialBody(ctx, *res.Ref, ds, p.Selector == "body"); err != nil {
+		return err
+	}
 	if err = base.OpenDataset(ctx, m.inst.repo.Filesystem(), ds); err != nil {
 		log.Debugf("Get dataset, base.OpenDataset failed, error: %s", err)
 		return err
@@ -397,6 +403,14 @@ type SaveParams struct {
 	FilePaths []string
 	// secrets for transform execution
 	Secrets map[string]string
+	// resource limits for transform execution, zero values mean no limit
+	TransformLimits startf.Limits
+	// record http requests the transform makes into a fixture stored in the
+	// transform component
+	RecordHTTP bool
+	// serve transform http requests from the recorded fixture instead of the
+	// network
+	ReplayHTTP bool
 	// optional writer to have transform script record standard output to
 	// note: this won't work over RPC, only on local calls
 	ScriptOutput io.Writer
@@ -485,6 +499,9 @@ func (m *DatasetMethods) Save(p *SaveParams, res *reporef.DatasetRef) error {
 	if ref.Username != "" && ref.Username != "me" && ref.Username != pro.Peername {
 		return fmt.Errorf("cannot save using a different username than \"%s\"", pro.Peername)
 	}
+	if ref.Branch != "" && p.Publish {
+		return fmt.Errorf("can't publish while saving to a branch")
+	}
 
 	// Parsed human-friendly dsref can only have username and name.
 	datasetRef := reporef.DatasetRef{
@@ -601,6 +618,9 @@ func (m *DatasetMethods) Save(p *SaveParams, res *reporef.DatasetRef) error {
 	if fsiPath != "" && p.Drop != "" {
 		return errors.New(fmt.Errorf("cannot drop while FSI-linked"), "can't drop component from a working-directory, delete files instead.")
 	}
+	if fsiPath != "" && ref.Branch != "" {
+		return fmt.Errorf("cannot save to a branch while FSI-linked")
+	}
 
 	fileHint := p.BodyPath
 	if len(p.FilePaths) > 0 {
@@ -617,8 +637,14 @@ func (m *DatasetMethods) Save(p *SaveParams, res *reporef.DatasetRef) error {
 		ShouldRender:        p.ShouldRender,
 		NewName:             p.NewName,
 		Drop:                p.Drop,
-	}
-	datasetRef, err = base.SaveDataset(ctx, m.inst.repo, m.inst.node.LocalStreams, ds, p.Secrets, p.ScriptOutput, switches)
+		Branch:              ref.Branch,
+	}
+	datasetRef, err = base.SaveDataset(ctx, m.inst.repo, m.inst.node.LocalStreams, ds, p.Secrets, p.ScriptOutput, switches,
+		startf.SetLimits(p.TransformLimits),
+		startf.SetRecordHTTP(p.RecordHTTP),
+		startf.SetReplayHTTP(p.ReplayHTTP),
+		startf.SetSQLService(sql.New(m.inst.repo)),
+	)
 	if err != nil {
 		log.Debugf("create ds error: %s\n", err.Error())
 		return err
@@ -657,6 +683,14 @@ func (m *DatasetMethods) Save(p *SaveParams, res *reporef.DatasetRef) error {
 
 	*res = datasetRef
 
+	if !p.DryRun {
+		savedRef := reporef.ConvertToDsref(datasetRef)
+		savedRef.Branch = ref.Branch
+		m.inst.bus.Publish(event.ETDatasetSaveEvent, event.DatasetSaveEvent{
+			Ref: savedRef,
+		})
+	}
+
 	if fsiPath != "" && !p.DryRun {
 		// Need to pass filesystem here so that we can read the README component and write it
 		// properly back to disk.
@@ -764,6 +798,11 @@ func (m *DatasetMethods) Rename(p *RenameParams, res *dsref.VersionInfo) error {
 		return err
 	}
 	*res = *info
+
+	m.inst.bus.Publish(event.ETDatasetRenameEvent, event.DatasetRenameEvent{
+		Prev: p.Current,
+		Next: info.SimpleRef(),
+	})
 	return nil
 }
 
@@ -940,6 +979,13 @@ func (m *DatasetMethods) Remove(p *RemoveParams, res *RemoveResponse) error {
 			fsi.WriteComponents(ds, info.FSIPath, m.inst.repo.Filesystem())
 		}
 	}
+
+	if res.NumDeleted != 0 {
+		m.inst.bus.Publish(event.ETDatasetRemoveEvent, event.DatasetRemoveEvent{
+			Ref:        reporef.ConvertToDsref(ref),
+			NumDeleted: res.NumDeleted,
+		})
+	}
 	log.Debugf("Remove finished")
 	return nil
 }
@@ -973,6 +1019,12 @@ func (m *DatasetMethods) Add(p *AddParams, res *reporef.DatasetRef) error {
 	}
 
 	mergeLogsError := m.inst.remoteClient.CloneLogs(ctx, reporef.ConvertToDsref(ref), p.RemoteAddr)
+	if mergeLogsError == nil {
+		m.inst.bus.Publish(event.ETLogsyncPullEvent, event.RemoteEvent{
+			Ref:        reporef.ConvertToDsref(ref),
+			RemoteAddr: p.RemoteAddr,
+		})
+	}
 	if p.LogsOnly {
 		return mergeLogsError
 	}
@@ -981,6 +1033,11 @@ func (m *DatasetMethods) Add(p *AddParams, res *reporef.DatasetRef) error {
 		return err
 	}
 
+	m.inst.bus.Publish(event.ETRemotePullEvent, event.RemoteEvent{
+		Ref:        reporef.ConvertToDsref(ref),
+		RemoteAddr: p.RemoteAddr,
+	})
+
 	*res = ref
 
 	if p.LinkDir != "" {
@@ -26,6 +26,7 @@ import (
 	"github.com/qri-io/qri/base/dsfs"
 	"github.com/qri-io/qri/config"
 	"github.com/qri-io/qri/dsref"
+	"github.com/qri-io/qri/event"
 	"github.com/qri-io/qri/p2p"
 	p2ptest "github.com/qri-io/qri/p2p/test"
 	"github.com/qri-io/qri/repo"
@@ -686,6 +687,8 @@ func TestDatasetRequestsRename(t *testing.T) {
 		Next:    dsref.Ref{Username: "peer", Name: "new_movies"},
 	}
 
+	renamed := inst.Bus().SubscribeOnce(event.ETDatasetRenameEvent)
+
 	res := &dsref.VersionInfo{}
 	if err := m.Rename(p, res); err != nil {
 		t.Errorf("unexpected error renaming: %s", err)
@@ -696,6 +699,19 @@ func TestDatasetRequestsRename(t *testing.T) {
 		t.Errorf("response mismatch. expected: %s, got: %s", expect.Alias(), res.Alias())
 	}
 
+	select {
+	case e := <-renamed:
+		re, ok := e.Payload.(event.DatasetRenameEvent)
+		if !ok {
+			t.Fatalf("expected rename event payload, got: %T", e.Payload)
+		}
+		if re.Prev.Alias() != "peer/movies" || re.Next.Alias() != expect.Alias() {
+			t.Errorf("rename event mismatch. got: %s -> %s", re.Prev.Alias(), re.Next.Alias())
+		}
+	case <-time.After(time.Second):
+		t.Error("expected a rename event to be published")
+	}
+
 	// get log by id this time
 	after, err := mr.Logbook().Log(ctx, log.ID())
 	if err != nil {
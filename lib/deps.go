@@ -0,0 +1,202 @@
+package lib
+
+import (
+	"errors"
+	"io"
+
+	"github.com/qri-io/qri/base"
+	"github.com/qri-io/qri/base/dsfs"
+	"github.com/qri-io/qri/repo"
+	reporef "github.com/qri-io/qri/repo/ref"
+)
+
+// DepInfo describes a dataset linked to another by a transform dependency
+type DepInfo struct {
+	// Ref is the "peername/name" alias of the linked dataset
+	Ref string `json:"ref"`
+	// Path is the version the dependency was recorded at. For upstream
+	// datasets this is the version that was loaded, for downstream datasets
+	// it's the head version that did the loading
+	Path string `json:"path"`
+	// Head is the current head version of the linked dataset, if it's in the
+	// local repo
+	Head string `json:"head,omitempty"`
+	// Stale is true when the dependency was recorded against a version that
+	// is no longer the head of the upstream dataset
+	Stale bool `json:"stale"`
+}
+
+// DepsResult lists the datasets a dataset depends on & is depended on by
+type DepsResult struct {
+	Ref        string    `json:"ref"`
+	Path       string    `json:"path"`
+	Upstream   []DepInfo `json:"upstream"`
+	Downstream []DepInfo `json:"downstream"`
+}
+
+// Deps lists the datasets the head transform of a dataset loaded, and the
+// local datasets whose head transforms loaded it
+func (m *DatasetMethods) Deps(refstr *string, res *DepsResult) error {
+	if m.inst.rpc != nil {
+		return checkRPCError(m.inst.rpc.Call("DatasetMethods.Deps", refstr, res))
+	}
+	ctx := m.inst.Context()
+
+	ref, err := m.canonicalDepsRef(*refstr)
+	if err != nil {
+		return err
+	}
+	g, err := base.LoadDependencyGraph(ctx, m.inst.repo)
+	if err != nil {
+		return err
+	}
+
+	alias := ref.AliasString()
+	res.Ref = alias
+	res.Path = ref.Path
+	res.Upstream = []DepInfo{}
+	for _, d := range g.Upstream[alias] {
+		head := g.Heads[d.Ref]
+		res.Upstream = append(res.Upstream, DepInfo{
+			Ref:   d.Ref,
+			Path:  d.Path,
+			Head:  head,
+			Stale: head != "" && head != d.Path,
+		})
+	}
+	res.Downstream = []DepInfo{}
+	for _, d := range g.Downstream(alias) {
+		info := DepInfo{Ref: d.Ref, Path: d.Path, Head: d.Path}
+		for _, up := range g.Upstream[d.Ref] {
+			if up.Ref == alias && up.Path != ref.Path {
+				info.Stale = true
+			}
+		}
+		res.Downstream = append(res.Downstream, info)
+	}
+	return nil
+}
+
+func (m *DatasetMethods) canonicalDepsRef(refstr string) (reporef.DatasetRef, error) {
+	if refstr == "" {
+		return reporef.DatasetRef{}, repo.ErrEmptyRef
+	}
+	ref, err := repo.ParseDatasetRef(refstr)
+	if err != nil {
+		return ref, err
+	}
+	err = repo.CanonicalizeDatasetRef(m.inst.repo, &ref)
+	return ref, err
+}
+
+// CascadeParams encapsulates parameters for re-running downstream transforms
+type CascadeParams struct {
+	// Ref is the upstream dataset that changed
+	Ref string
+	// secrets for transform execution
+	Secrets map[string]string
+	// optional writer to have transform scripts record standard output to
+	// note: this won't work over RPC, only on local calls
+	ScriptOutput io.Writer
+}
+
+// CascadeStatus enumerates outcomes of re-running a downstream transform
+type CascadeStatus string
+
+const (
+	// CascadeSaved indicates the transform saved a new version
+	CascadeSaved = CascadeStatus("saved")
+	// CascadeUnchanged indicates the transform produced no changes, or that
+	// none of the dataset's upstream dependencies changed
+	CascadeUnchanged = CascadeStatus("unchanged")
+	// CascadeFailed indicates the transform errored
+	CascadeFailed = CascadeStatus("failed")
+	// CascadeSkipped indicates the transform wasn't run because an upstream
+	// transform failed
+	CascadeSkipped = CascadeStatus("skipped")
+)
+
+// CascadeResult is the outcome of re-running a single downstream transform
+type CascadeResult struct {
+	Ref    string        `json:"ref"`
+	Status CascadeStatus `json:"status"`
+	// Path of the saved version, if any
+	Path  string `json:"path,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+// Cascade re-runs the transforms of all datasets downstream of a dataset in
+// dependency order, so each transform runs after every transform it loads
+// from. Datasets are only saved if their transform produces changes.
+// Datasets downstream of a failed transform are skipped
+func (m *DatasetMethods) Cascade(p *CascadeParams, res *[]CascadeResult) error {
+	if m.inst.rpc != nil {
+		p.ScriptOutput = nil
+		return checkRPCError(m.inst.rpc.Call("DatasetMethods.Cascade", p, res))
+	}
+	ctx := m.inst.Context()
+
+	ref, err := m.canonicalDepsRef(p.Ref)
+	if err != nil {
+		return err
+	}
+	g, err := base.LoadDependencyGraph(ctx, m.inst.repo)
+	if err != nil {
+		return err
+	}
+	alias := ref.AliasString()
+	order, err := g.CascadeOrder(alias)
+	if err != nil {
+		return err
+	}
+
+	statuses := map[string]CascadeStatus{alias: CascadeSaved}
+	results := make([]CascadeResult, 0, len(order))
+	for _, ds := range order {
+		r := CascadeResult{Ref: ds, Status: CascadeUnchanged}
+		for _, up := range g.Upstream[ds] {
+			switch statuses[up.Ref] {
+			case CascadeFailed, CascadeSkipped:
+				r.Status = CascadeSkipped
+			case CascadeSaved:
+				if r.Status != CascadeSkipped {
+					r.Status = CascadeSaved
+				}
+			}
+		}
+
+		// only re-run transforms with an upstream dataset that has a new version
+		if r.Status == CascadeSaved {
+			r.Path, err = m.cascadeSave(ds, p)
+			switch {
+			case errors.Is(err, dsfs.ErrNoChanges):
+				r.Status = CascadeUnchanged
+			case err != nil:
+				log.Debugf("cascading to %s: %s", ds, err)
+				r.Status = CascadeFailed
+				r.Error = err.Error()
+			}
+		}
+		statuses[ds] = r.Status
+		results = append(results, r)
+	}
+
+	*res = results
+	return nil
+}
+
+// cascadeSave re-runs the transform of a dataset, returning the saved path
+func (m *DatasetMethods) cascadeSave(ref string, p *CascadeParams) (string, error) {
+	sp := &SaveParams{
+		Ref:          ref,
+		Recall:       "tf",
+		Secrets:      p.Secrets,
+		ScriptOutput: p.ScriptOutput,
+		ShouldRender: true,
+	}
+	res := &reporef.DatasetRef{}
+	if err := m.Save(sp, res); err != nil {
+		return "", err
+	}
+	return res.Path, nil
+}
@@ -13,6 +13,7 @@ import (
 	"github.com/qri-io/qfs"
 	"github.com/qri-io/qri/base/archive"
 	"github.com/qri-io/qri/base/fill"
+	"github.com/qri-io/qri/startf"
 	"gopkg.in/yaml.v2"
 )
 
@@ -120,6 +121,13 @@ func readSingleFile(path string) (*dataset.Dataset, string, error) {
 			ds.Transform.SetScriptFile(qfs.NewMemfileReader("transform.star", f))
 			return &ds, "tf", nil
 
+		case ".sql":
+			// sql files are assumed to be a transform written as a single SQL
+			// SELECT statement
+			ds.Transform = &dataset.Transform{ScriptPath: path, Syntax: startf.SQLSyntax}
+			ds.Transform.SetScriptFile(qfs.NewMemfileReader("transform.sql", f))
+			return &ds, "tf", nil
+
 		case ".html":
 			// html files are assumped to be a viz script with no additional viz
 			// component details
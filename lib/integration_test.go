@@ -85,6 +85,49 @@ func TestTwoActorRegistryIntegration(t *testing.T) {
 	// TODO (b5) - assert hinshun has world bank dataset blocks
 }
 
+func TestPullDepthIntegration(t *testing.T) {
+	tr := NewNetworkIntegrationTestRunner(t, "integration_pull_depth")
+	defer tr.Cleanup()
+
+	nasim := tr.InitNasim(t)
+	ref := InitWorldBankDataset(t, nasim)
+	PublishToRegistry(t, nasim, ref.AliasString())
+	first := ref.Path
+	ref = Commit2WorldBank(t, nasim)
+	PublishToRegistry(t, nasim, ref.AliasString())
+
+	hinshun := tr.InitHinshun(t)
+	rm := NewRemoteMethods(hinshun)
+
+	items := []DatasetLogItem{}
+	if err := rm.PullDataset(&PullParams{Ref: ref.AliasString()}, &items); err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 1 || items[0].Path != ref.Path {
+		t.Errorf("expected default pull to fetch the latest version. got: %v", items)
+	}
+
+	p := &PullParams{Ref: ref.AliasString(), Components: []string{"bdy"}}
+	if err := rm.PullDataset(p, &items); err == nil {
+		t.Error("expected unknown component to error")
+	}
+
+	p = &PullParams{
+		Ref:        ref.AliasString(),
+		Depth:      -1,
+		Components: []string{"meta", "structure"},
+	}
+	if err := rm.PullDataset(p, &items); err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 2 || items[1].Path != first {
+		t.Fatalf("expected pull to fetch both versions. got: %v", items)
+	}
+	if has, err := hinshun.Repo().Store().Has(tr.Ctx, first); err != nil || !has {
+		t.Errorf("expected first version to be pulled. has: %t, err: %v", has, err)
+	}
+}
+
 type NetworkIntegrationTestRunner struct {
 	Ctx                                  context.Context
 	prefix                               string
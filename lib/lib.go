@@ -14,6 +14,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 
 	golog "github.com/ipfs/go-log"
 	homedir "github.com/mitchellh/go-homedir"
@@ -23,6 +24,7 @@ import (
 	"github.com/qri-io/qri/base"
 	"github.com/qri-io/qri/config"
 	"github.com/qri-io/qri/config/migrate"
+	"github.com/qri-io/qri/cron"
 	"github.com/qri-io/qri/dscache"
 	qrierr "github.com/qri-io/qri/errors"
 	"github.com/qri-io/qri/event"
@@ -37,6 +39,7 @@ import (
 	"github.com/qri-io/qri/repo/profile"
 	"github.com/qri-io/qri/stats"
 	"github.com/qri-io/qri/watchfs"
+	"github.com/qri-io/qri/webhook"
 )
 
 var (
@@ -79,6 +82,9 @@ func Receivers(inst *Instance) []Methods {
 		NewSQLMethods(inst),
 		NewRenderRequests(r, nil),
 		NewFSIMethods(inst),
+		NewWebhookMethods(inst),
+		NewBranchMethods(inst),
+		NewScheduleMethods(inst),
 	}
 }
 
@@ -406,6 +412,14 @@ func NewInstance(ctx context.Context, repoPath string, opts ...Option) (qri *Ins
 			return
 		}
 	}
+	if inst.node != nil {
+		inst.node.Publisher = inst.bus
+	}
+	if inst.repo != nil {
+		// verify logs forwarded on behalf of other authors with the keys of
+		// peers we've connected to
+		inst.repo.Logbook().SetKeyResolver((&LogMethods{inst: inst}).peerstoreKeys)
+	}
 
 	// Check if this is coming from a test, which is requesting a MockRemoteClient.
 	key := InstanceContextKey("RemoteClient")
@@ -418,7 +432,7 @@ func NewInstance(ctx context.Context, repoPath string, opts ...Option) (qri *Ins
 		inst.node.LocalStreams = o.Streams
 
 		if _, e := inst.node.IPFSCoreAPI(); e == nil {
-			if inst.remoteClient, err = remote.NewClient(inst.node); err != nil {
+			if inst.remoteClient, err = remote.NewClient(inst.node, inst.remoteClientOpts); err != nil {
 				log.Error("initializing remote client:", err.Error())
 				return
 			}
@@ -429,13 +443,20 @@ func NewInstance(ctx context.Context, repoPath string, opts ...Option) (qri *Ins
 				o.remoteOptsFunc = func(*remote.Options) {}
 			}
 
-			if inst.remote, err = remote.NewRemote(inst.node, cfg.Remote, o.remoteOptsFunc); err != nil {
+			if inst.remote, err = remote.NewRemote(inst.node, cfg.Remote, inst.remoteUsageOpts, inst.remoteSubscribersOpts, o.remoteOptsFunc, remoteEventHooks(inst.bus)); err != nil {
 				log.Error("intializing remote:", err.Error())
 				return
 			}
 		}
 	}
 
+	if cfg.Webhooks != nil && cfg.Webhooks.Enabled {
+		if inst.webhooks, err = webhook.NewDispatcher(cfg.Webhooks, webhooksPath(inst.repoPath)); err != nil {
+			log.Error("initializing webhooks:", err.Error())
+			return
+		}
+	}
+
 	return
 }
 
@@ -543,10 +564,11 @@ func NewInstanceFromConfigAndNode(cfg *config.Config, node *p2p.QriNode) *Instan
 		cfg:      cfg,
 		node:     node,
 		stats:    stats.New(nil),
+		bus:      event.NewBus(ctx),
 	}
 
 	var err error
-	inst.remoteClient, err = remote.NewClient(node)
+	inst.remoteClient, err = remote.NewClient(node, inst.remoteClientOpts)
 	if err != nil {
 		panic(err)
 	}
@@ -555,7 +577,6 @@ func NewInstanceFromConfigAndNode(cfg *config.Config, node *p2p.QriNode) *Instan
 		inst.repo = node.Repo
 		inst.store = node.Repo.Store()
 		inst.qfs = node.Repo.Filesystem()
-		inst.bus = event.NewBus(ctx)
 		inst.fsi = fsi.NewFSI(inst.repo, inst.bus)
 	}
 
@@ -588,6 +609,17 @@ type Instance struct {
 	logbook      *logbook.Book
 	dscache      *dscache.Dscache
 	bus          event.Bus
+	webhooks     *webhook.Dispatcher
+	transfers    *remote.Checkpoints
+	partialPulls *remote.PartialPulls
+	mirror       *remote.Mirror
+
+	subsLk        sync.Mutex
+	subscriptions *remote.Subscriptions
+	subsWatcher   *subscriptionWatcher
+
+	cronLk sync.Mutex
+	cron   *cron.Cron
 
 	Watcher *watchfs.FilesysWatcher
 
@@ -606,16 +638,43 @@ func (inst *Instance) Connect(ctx context.Context) (err error) {
 	// old instance, we run into issues where the online instance can't "see"
 	// the additions. We fix that by re-initializing the client with the new
 	// instance
-	if inst.remoteClient, err = remote.NewClient(inst.node); err != nil {
+	if inst.remoteClient, err = remote.NewClient(inst.node, inst.remoteClientOpts); err != nil {
 		log.Debugf("initializing remote client: %s", err.Error())
 		return
 	}
 
+	if inst.webhooks != nil {
+		inst.webhooks.Start(inst.ctx, inst.bus)
+	}
+
+	if inst.remote != nil {
+		inst.remote.StartSweeper(inst.ctx)
+	}
+
+	if err = inst.startMirror(); err != nil {
+		log.Errorf("starting mirror: %s", err.Error())
+		return
+	}
+
+	if err = inst.startSubscriptions(); err != nil {
+		log.Errorf("watching subscriptions: %s", err.Error())
+		return
+	}
+
+	if err = inst.startScheduler(); err != nil {
+		log.Errorf("starting scheduler: %s", err.Error())
+		return
+	}
+
 	return nil
 }
 
-// Context returns the base context for this instance
+// Context returns the base context for this instance. Methods run with this
+// context, which is cancelled when the instance shuts down
 func (inst *Instance) Context() context.Context {
+	if inst.ctx == nil {
+		return context.Background()
+	}
 	return inst.ctx
 }
 
@@ -4,7 +4,10 @@ import (
 	"context"
 	"fmt"
 
+	crypto "github.com/libp2p/go-libp2p-core/crypto"
+	peer "github.com/libp2p/go-libp2p-core/peer"
 	"github.com/qri-io/qri/base"
+	"github.com/qri-io/qri/dsref"
 	"github.com/qri-io/qri/logbook"
 	"github.com/qri-io/qri/repo"
 	reporef "github.com/qri-io/qri/repo/ref"
@@ -30,7 +33,8 @@ func NewLogMethods(inst *Instance) *LogMethods {
 // LogParams defines parameters for the Log method
 type LogParams struct {
 	ListParams
-	// Reference to data to fetch history for
+	// Reference to data to fetch history for, a reference that names a branch
+	// (eg: me/dataset#branch) fetches the history of that branch
 	Ref string
 }
 
@@ -47,7 +51,8 @@ func (m *LogMethods) Log(params *LogParams, res *[]DatasetLogItem) error {
 	if params.Ref == "" {
 		return repo.ErrEmptyRef
 	}
-	ref, err := repo.ParseDatasetRef(params.Ref)
+	refstr, branch := splitBranch(params.Ref)
+	ref, err := repo.ParseDatasetRef(refstr)
 	if err != nil {
 		return fmt.Errorf("'%s' is not a valid dataset reference", params.Ref)
 	}
@@ -67,6 +72,10 @@ func (m *LogMethods) Log(params *LogParams, res *[]DatasetLogItem) error {
 		params.Offset = 0
 	}
 
+	if branch != "" {
+		*res, err = base.DatasetBranchLog(ctx, m.inst.node.Repo, ref, branch, params.Limit, params.Offset)
+		return err
+	}
 	*res, err = base.DatasetLog(ctx, m.inst.node.Repo, ref, params.Limit, params.Offset, true)
 	return err
 }
@@ -121,3 +130,82 @@ func (m *LogMethods) PlainLogs(p *PlainLogsParams, res *PlainLogs) error {
 	*res, err = m.inst.repo.Logbook().PlainLogs(ctx)
 	return err
 }
+
+// VerifyLogsParams encapsulates parameters for the VerifyLogs method
+type VerifyLogsParams struct {
+	// Reference to the dataset to verify logs for, empty verifies all logs
+	Ref string
+}
+
+// VerifyReport describes the outcome of verifying logs
+type VerifyReport = logbook.VerifyReport
+
+// VerifyLogs checks logbook signatures, authorship, and version links against
+// author public keys. Keys for authors other than this peer are looked up in
+// the p2p peerstore, logs from authors without a known key are reported as
+// problems
+func (m *LogMethods) VerifyLogs(p *VerifyLogsParams, res *VerifyReport) error {
+	if m.inst.rpc != nil {
+		return checkRPCError(m.inst.rpc.Call("LogMethods.VerifyLogs", p, res))
+	}
+	ctx := m.inst.Context()
+
+	ref := dsref.Ref{}
+	if p.Ref != "" {
+		r, err := repo.ParseDatasetRef(p.Ref)
+		if err != nil {
+			return err
+		}
+		if err = repo.CanonicalizeDatasetRef(m.inst.repo, &r); err != nil && err != repo.ErrNoHistory {
+			return err
+		}
+		ref = reporef.ConvertToDsref(r)
+	}
+
+	report, err := m.inst.repo.Logbook().Verify(ctx, ref, m.peerstoreKeys)
+	if err != nil {
+		return err
+	}
+	*res = *report
+	return nil
+}
+
+// peerstoreKeys resolves author key IDs with the public keys of connected
+// peers. key IDs are the base58 encoding of a peer ID
+func (m *LogMethods) peerstoreKeys(keyID string) (crypto.PubKey, error) {
+	if m.inst.node == nil || m.inst.node.Host() == nil {
+		return nil, fmt.Errorf("no p2p host to look up public keys")
+	}
+	id, err := peer.IDB58Decode(keyID)
+	if err != nil {
+		return nil, err
+	}
+	if pub := m.inst.node.Host().Peerstore().PubKey(id); pub != nil {
+		return pub, nil
+	}
+	return nil, fmt.Errorf("public key not found in peerstore")
+}
+
+// CompactLogsParams encapsulates parameters for the CompactLogs method
+type CompactLogsParams struct {
+	// no options yet
+}
+
+// CompactResult summarizes the outcome of compacting the logbook
+type CompactResult = logbook.CompactResult
+
+// CompactLogs squashes removed logs and amend chains in logs written by this
+// peer, then rewrites the logbook to a single file
+func (m *LogMethods) CompactLogs(p *CompactLogsParams, res *CompactResult) error {
+	if m.inst.rpc != nil {
+		return checkRPCError(m.inst.rpc.Call("LogMethods.CompactLogs", p, res))
+	}
+	ctx := m.inst.Context()
+
+	got, err := m.inst.repo.Logbook().Compact(ctx)
+	if err != nil {
+		return err
+	}
+	*res = *got
+	return nil
+}
@@ -0,0 +1,151 @@
+package lib
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/qri-io/qri/config"
+	"github.com/qri-io/qri/remote"
+)
+
+// mirrorsPath is the directory replication status for mirrored datasets is
+// kept in
+func mirrorsPath(repoPath string) string {
+	return filepath.Join(repoPath, "mirrors")
+}
+
+// startMirror begins scheduled replication from any upstreams configured for
+// this node's remote. Mirrors only run while the remote is enabled
+func (inst *Instance) startMirror() error {
+	cfg := inst.cfg
+	if cfg.Remote == nil || !cfg.Remote.Enabled || len(cfg.Remote.Mirrors) == 0 || inst.remoteClient == nil {
+		return nil
+	}
+
+	m, err := remote.NewMirror(inst.node, inst.remoteClient, cfg, func(o *remote.MirrorOptions) {
+		o.Publisher = inst.bus
+		if inst.remote != nil {
+			o.Search = inst.remote.Search
+		}
+		if inst.repoPath != "" {
+			o.StatusDir = mirrorsPath(inst.repoPath)
+		}
+	})
+	if err != nil {
+		return err
+	}
+	inst.mirror = m
+	inst.mirror.Start(inst.ctx)
+	return nil
+}
+
+// MirrorParams configures replication of datasets from an upstream remote
+type MirrorParams struct {
+	// Upstream is a remote name or address to replicate from
+	Upstream string
+	// Patterns select datasets to replicate, eg: "nasa/*"
+	Patterns []string
+	// Interval between replication checks as a duration string, eg: "1h"
+	Interval string
+}
+
+// AddMirror adds dataset patterns to replicate from an upstream remote to this
+// node's remote config. Patterns for an upstream that's already mirrored are
+// merged with existing patterns. Mirrors take effect the next time this node
+// connects
+func (r *RemoteMethods) AddMirror(p *MirrorParams, res *config.Mirror) error {
+	if r.inst.rpc != nil {
+		return checkRPCError(r.inst.rpc.Call("RemoteMethods.AddMirror", p, res))
+	}
+
+	if p.Upstream == "" {
+		return fmt.Errorf("upstream is required")
+	}
+	if len(p.Patterns) == 0 {
+		return fmt.Errorf("at least one dataset pattern is required")
+	}
+
+	cfg := r.inst.cfg.Copy()
+	if _, err := remote.MirrorAddress(cfg, p.Upstream); err != nil {
+		return err
+	}
+	if cfg.Remote == nil {
+		cfg.Remote = &config.Remote{}
+	}
+
+	var m *config.Mirror
+	for _, mc := range cfg.Remote.Mirrors {
+		if mc.Upstream == p.Upstream {
+			m = mc
+			break
+		}
+	}
+	if m == nil {
+		m = &config.Mirror{Upstream: p.Upstream}
+		cfg.Remote.Mirrors = append(cfg.Remote.Mirrors, m)
+	}
+	for _, pattern := range p.Patterns {
+		if !containsString(m.Patterns, pattern) {
+			m.Patterns = append(m.Patterns, pattern)
+		}
+	}
+	if p.Interval != "" {
+		m.Interval = p.Interval
+	}
+	if _, err := m.IntervalDuration(); err != nil {
+		return err
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return fmt.Errorf("validating config: %s", err)
+	}
+	if err := r.inst.ChangeConfig(cfg); err != nil {
+		return err
+	}
+
+	*res = *m.Copy()
+	return nil
+}
+
+// ListMirrors lists the upstream remotes this node replicates datasets from
+func (r *RemoteMethods) ListMirrors(in *bool, res *[]*config.Mirror) error {
+	if r.inst.rpc != nil {
+		return checkRPCError(r.inst.rpc.Call("RemoteMethods.ListMirrors", in, res))
+	}
+
+	mirrors := []*config.Mirror{}
+	if r.inst.cfg.Remote != nil {
+		for _, m := range r.inst.cfg.Remote.Mirrors {
+			mirrors = append(mirrors, m.Copy())
+		}
+	}
+	*res = mirrors
+	return nil
+}
+
+// MirrorStatus lists the replication status of each mirrored dataset
+func (r *RemoteMethods) MirrorStatus(in *bool, res *[]*remote.MirrorStatus) (err error) {
+	if r.inst.rpc != nil {
+		return checkRPCError(r.inst.rpc.Call("RemoteMethods.MirrorStatus", in, res))
+	}
+
+	if r.inst.mirror != nil {
+		*res = r.inst.mirror.Status()
+		return nil
+	}
+	if r.inst.repoPath == "" {
+		*res = []*remote.MirrorStatus{}
+		return nil
+	}
+	*res, err = remote.ReadMirrorStatus(mirrorsPath(r.inst.repoPath))
+	return err
+}
+
+func containsString(strs []string, str string) bool {
+	for _, s := range strs {
+		if s == str {
+			return true
+		}
+	}
+	return false
+}
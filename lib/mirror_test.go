@@ -0,0 +1,70 @@
+package lib
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/qri-io/qri/config"
+	"github.com/qri-io/qri/p2p"
+	"github.com/qri-io/qri/remote"
+	testrepo "github.com/qri-io/qri/repo/test"
+)
+
+func TestAddListMirrors(t *testing.T) {
+	cfg := config.DefaultConfigForTesting()
+	cfg.Remotes = &config.Remotes{"backup": "http://localhost:2503"}
+	mr, err := testrepo.NewTestRepo()
+	if err != nil {
+		t.Fatalf("error allocating test repo: %s", err)
+	}
+	node, err := p2p.NewQriNode(mr, cfg.P2P)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	inst := NewInstanceFromConfigAndNode(cfg, node)
+	m := NewRemoteMethods(inst)
+
+	bad := []*MirrorParams{
+		{Patterns: []string{"nasa/*"}},
+		{Upstream: "backup"},
+		{Upstream: "unknown", Patterns: []string{"nasa/*"}},
+		{Upstream: "backup", Patterns: []string{"nasa/*"}, Interval: "often"},
+	}
+	for i, p := range bad {
+		if err := m.AddMirror(p, &config.Mirror{}); err == nil {
+			t.Errorf("case %d: expected error, got nil", i)
+		}
+	}
+
+	res := &config.Mirror{}
+	if err := m.AddMirror(&MirrorParams{Upstream: "backup", Patterns: []string{"nasa/*"}}, res); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.AddMirror(&MirrorParams{Upstream: "backup", Patterns: []string{"nasa/*", "b5/world_bank_population"}, Interval: "1h"}, res); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.AddMirror(&MirrorParams{Upstream: "http://localhost:2504", Patterns: []string{"*/*"}}, res); err != nil {
+		t.Fatal(err)
+	}
+
+	got := []*config.Mirror{}
+	if err := m.ListMirrors(nil, &got); err != nil {
+		t.Fatal(err)
+	}
+	expect := []*config.Mirror{
+		{Upstream: "backup", Patterns: []string{"nasa/*", "b5/world_bank_population"}, Interval: "1h"},
+		{Upstream: "http://localhost:2504", Patterns: []string{"*/*"}},
+	}
+	if diff := cmp.Diff(expect, got); diff != "" {
+		t.Errorf("result mismatch (-want +got):\n%s", diff)
+	}
+
+	status := []*remote.MirrorStatus{}
+	if err := m.MirrorStatus(nil, &status); err != nil {
+		t.Fatal(err)
+	}
+	if len(status) != 0 {
+		t.Errorf("expected no status for a node that isn't mirroring, got: %v", status)
+	}
+}
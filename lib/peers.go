@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"github.com/qri-io/qri/config"
+	"github.com/qri-io/qri/event"
 	"github.com/qri-io/qri/p2p"
 	"github.com/qri-io/qri/repo"
 	"github.com/qri-io/qri/repo/profile"
@@ -161,6 +162,15 @@ func (m *PeerMethods) ConnectToPeer(p *PeerConnectionParamsPod, res *config.Prof
 		return err
 	}
 
+	pe := event.PeerEvent{
+		ProfileID: prof.ID.String(),
+		Peername:  prof.Peername,
+	}
+	if len(prof.PeerIDs) > 0 {
+		pe.PeerID = prof.PeerIDs[0].Pretty()
+	}
+	m.inst.bus.Publish(event.ETPeerConnectEvent, pe)
+
 	pro, err := prof.Encode()
 	if err != nil {
 		return err
@@ -186,6 +196,15 @@ func (m *PeerMethods) DisconnectFromPeer(p *PeerConnectionParamsPod, res *bool)
 		return err
 	}
 
+	pe := event.PeerEvent{
+		Peername: pcp.Peername,
+		PeerID:   pcp.PeerID.Pretty(),
+	}
+	if pcp.ProfileID != "" {
+		pe.ProfileID = pcp.ProfileID.String()
+	}
+	m.inst.bus.Publish(event.ETPeerDisconnectEvent, pe)
+
 	*res = true
 	return nil
 }
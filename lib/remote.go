@@ -2,12 +2,19 @@ package lib
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/qri-io/dataset"
+	"github.com/qri-io/qfs"
 	"github.com/qri-io/qri/base"
 	"github.com/qri-io/qri/base/dsfs"
 	"github.com/qri-io/qri/dsref"
+	"github.com/qri-io/qri/event"
 	"github.com/qri-io/qri/logbook"
 	"github.com/qri-io/qri/remote"
 	"github.com/qri-io/qri/repo"
@@ -32,6 +39,65 @@ func NewRemoteMethods(inst *Instance) *RemoteMethods {
 // CoreRequestsName implements the Requests interface
 func (*RemoteMethods) CoreRequestsName() string { return "remote" }
 
+// transfersPath is the directory checkpoints for unfinished dataset transfers
+// are kept in
+func transfersPath(repoPath string) string {
+	return filepath.Join(repoPath, "transfers")
+}
+
+// remoteUsagePath is the directory usage of this node's remote is kept in
+func remoteUsagePath(repoPath string) string {
+	return filepath.Join(repoPath, "remote")
+}
+
+// remoteUsageOpts configures the remote to persist per-profile storage usage
+// to the repo, so quotas & retention survive restarts
+func (inst *Instance) remoteUsageOpts(o *remote.Options) {
+	if inst.repoPath == "" {
+		return
+	}
+	usage, err := remote.NewUsage(remoteUsagePath(inst.repoPath))
+	if err != nil {
+		log.Errorf("loading remote usage: %s", err)
+		return
+	}
+	o.Usage = usage
+}
+
+// remoteClientOpts configures remote clients to publish transfer progress on
+// the instance event bus, checkpoint transfers to the repo so interrupted
+// pushes & pulls can be resumed, and record versions pulled without all of
+// their components
+func (inst *Instance) remoteClientOpts(o *remote.ClientOptions) {
+	o.Publisher = inst.bus
+	if inst.transfers == nil {
+		dir := ""
+		if inst.repoPath != "" {
+			dir = transfersPath(inst.repoPath)
+		}
+		cps, err := remote.NewCheckpoints(dir)
+		if err != nil {
+			log.Errorf("loading transfer checkpoints: %s", err)
+			return
+		}
+		inst.transfers = cps
+	}
+	o.Checkpoints = inst.transfers
+	if inst.partialPulls == nil {
+		dir := ""
+		if inst.repoPath != "" {
+			dir = transfersPath(inst.repoPath)
+		}
+		pp, err := remote.NewPartialPulls(dir)
+		if err != nil {
+			log.Errorf("loading partial pulls: %s", err)
+			return
+		}
+		inst.partialPulls = pp
+	}
+	o.PartialPulls = inst.partialPulls
+}
+
 // FetchParams encapsulates parameters for a fetch request
 type FetchParams struct {
 	Ref        string
@@ -67,6 +133,10 @@ func (r *RemoteMethods) Fetch(p *FetchParams, res *[]DatasetLogItem) error {
 	if err != nil {
 		return err
 	}
+	r.inst.bus.Publish(event.ETLogsyncPullEvent, event.RemoteEvent{
+		Ref:        reporef.ConvertToDsref(ref),
+		RemoteAddr: addr,
+	})
 
 	// TODO (b5) - FetchLogs currently returns oplogs arranged in user > dataset > branch
 	// hierarchy, and we need to descend to the branch oplog to get commit history
@@ -105,6 +175,51 @@ func (r *RemoteMethods) Fetch(p *FetchParams, res *[]DatasetLogItem) error {
 	return nil
 }
 
+// FetchAllParams encapsulates parameters for fetching logs for all of a
+// user's datasets
+type FetchAllParams struct {
+	Username   string
+	RemoteName string
+}
+
+// FetchAll syncs logbook data for every dataset a user has on a remote into
+// the local logbook in a single batch. Only logs that differ from the local
+// logbook are transferred. The result lists datasets with updated logs
+func (r *RemoteMethods) FetchAll(p *FetchAllParams, res *[]dsref.Ref) error {
+	if r.inst.rpc != nil {
+		return checkRPCError(r.inst.rpc.Call("RemoteMethods.FetchAll", p, res))
+	}
+
+	username := p.Username
+	if username == "" || username == "me" {
+		pro, err := r.inst.Repo().Profile()
+		if err != nil {
+			return err
+		}
+		username = pro.Peername
+	}
+
+	addr, err := remote.Address(r.inst.Config(), p.RemoteName)
+	if err != nil {
+		return err
+	}
+
+	ctx := r.inst.Context()
+	refs, err := r.inst.RemoteClient().CloneUserLogs(ctx, username, addr)
+	if err != nil {
+		return err
+	}
+	for _, ref := range refs {
+		r.inst.bus.Publish(event.ETLogsyncPullEvent, event.RemoteEvent{
+			Ref:        ref,
+			RemoteAddr: addr,
+		})
+	}
+
+	*res = refs
+	return nil
+}
+
 // PublicationParams encapsulates parmeters for dataset publication
 type PublicationParams struct {
 	Ref        string
@@ -120,46 +235,183 @@ func (r *RemoteMethods) Publish(p *PublicationParams, res *dsref.Ref) error {
 		return checkRPCError(r.inst.rpc.Call("RemoteMethods.Publish", p, res))
 	}
 
-	ref, err := repo.ParseDatasetRef(p.Ref)
-	if err != nil {
-		return err
-	}
-	if ref.Path != "" {
-		return fmt.Errorf("can only publish entire dataset, cannot use version %s", ref.Path)
-	}
-	if err = repo.CanonicalizeDatasetRef(r.inst.Repo(), &ref); err != nil {
-		return err
-	}
+	// TODO (b5) - need contexts yo
+	ctx := context.TODO()
 
-	addr, err := remote.Address(r.inst.Config(), p.RemoteName)
+	ref, branch, addr, err := r.publicationRef(ctx, p)
 	if err != nil {
 		return err
 	}
 
-	// TODO (b5) - need contexts yo
-	ctx := context.TODO()
-
 	// TODO (b5) - we're early in log syncronization days. This is going to fail a bunch
 	// while we work to upgrade the stack. Long term we may want to consider a mechanism
 	// for allowing partial completion where only one of logs or dataset pushing works
 	// by doing both in parallel and reporting issues on both
 	if pushLogsErr := r.inst.RemoteClient().PushLogs(ctx, reporef.ConvertToDsref(ref), addr); pushLogsErr != nil {
 		log.Errorf("pushing logs: %s", pushLogsErr)
+	} else {
+		r.inst.bus.Publish(event.ETLogsyncPushEvent, event.RemoteEvent{
+			Ref:        reporef.ConvertToDsref(ref),
+			RemoteAddr: addr,
+		})
 	}
 
 	if err = r.inst.RemoteClient().PushDataset(ctx, ref, addr); err != nil {
 		return err
 	}
+	r.inst.bus.Publish(event.ETRemotePublishEvent, event.RemoteEvent{
+		Ref:        reporef.ConvertToDsref(ref),
+		RemoteAddr: addr,
+	})
+
+	*res = reporef.ConvertToDsref(ref)
+	if branch != "" {
+		// publish status describes the dataset head, which hasn't changed
+		res.Branch = branch
+		return nil
+	}
 
 	ref.Published = true
 	if err = base.SetPublishStatus(r.inst.node.Repo, &ref, ref.Published); err != nil {
 		return err
 	}
+	r.inst.updateSearch(ctx, ref.AliasString())
 
 	*res = reporef.ConvertToDsref(ref)
 	return nil
 }
 
+// updateSearch keeps the search index of this node's remote current with the
+// publish status of local datasets
+func (inst *Instance) updateSearch(ctx context.Context, alias string) {
+	if inst.remote != nil {
+		remote.UpdateSearch(ctx, inst.remote.Search, alias)
+	}
+}
+
+// PublishPreview describes what publishing a dataset would do, without
+// sending anything to the remote
+type PublishPreview struct {
+	Ref        dsref.Ref
+	RemoteAddr string
+	// blocks & bytes that would be transferred, versions the remote already
+	// has & any pre-check rejection
+	remote.PushPreview
+	// Versions is the number of versions in local history
+	Versions int
+	// Diverged is true when the remote's log for this dataset has a head this
+	// node's history doesn't contain
+	Diverged bool
+	// RemoteHead is the path of the newest version in the remote's log
+	RemoteHead string
+}
+
+// PreviewPublish reports the blocks & bytes a publish would transfer, which
+// versions the remote already has, whether the remote's log has diverged from
+// local history & whether the remote would reject the push
+func (r *RemoteMethods) PreviewPublish(p *PublicationParams, res *PublishPreview) error {
+	if r.inst.rpc != nil {
+		return checkRPCError(r.inst.rpc.Call("RemoteMethods.PreviewPublish", p, res))
+	}
+
+	ctx := r.inst.Context()
+
+	ref, branch, addr, err := r.publicationRef(ctx, p)
+	if err != nil {
+		return err
+	}
+	dr := reporef.ConvertToDsref(ref)
+	dr.Branch = branch
+
+	items, err := r.inst.Repo().Logbook().Items(ctx, dr, 0, -1)
+	if err != nil {
+		return err
+	}
+	local := map[string]bool{}
+	versions := make([]string, len(items))
+	for i, item := range items {
+		local[item.Path] = true
+		versions[i] = item.Path
+	}
+
+	preview, err := r.inst.RemoteClient().PreviewPush(ctx, ref, versions, addr)
+	if err != nil {
+		return err
+	}
+
+	*res = PublishPreview{
+		Ref:         dr,
+		RemoteAddr:  addr,
+		PushPreview: *preview,
+		Versions:    len(items),
+	}
+
+	// a remote without a log for this dataset can't have diverged
+	logs, err := r.inst.RemoteClient().FetchLogs(ctx, dr, addr)
+	if errors.Is(err, logbook.ErrNotFound) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("fetching remote logs: %w", err)
+	}
+	if len(logs.Logs) == 0 {
+		return nil
+	}
+	branchLog, err := logs.Logs[0].HeadRef(branchOrDefault(branch))
+	if err != nil {
+		return nil
+	}
+	if remoteItems := logbook.ConvertLogsToItems(branchLog, dr); len(remoteItems) > 0 {
+		res.RemoteHead = remoteItems[0].Path
+		res.Diverged = !local[res.RemoteHead]
+	}
+	return nil
+}
+
+// branchOrDefault returns the default branch name for an empty string
+func branchOrDefault(branch string) string {
+	if branch == "" {
+		return logbook.DefaultBranchName
+	}
+	return branch
+}
+
+// publicationRef resolves the dataset version & remote address a publication
+// targets. Publishing a branch targets the branch head
+func (r *RemoteMethods) publicationRef(ctx context.Context, p *PublicationParams) (ref reporef.DatasetRef, branch, addr string, err error) {
+	refstr, branch := splitBranch(p.Ref)
+	if ref, err = repo.ParseDatasetRef(refstr); err != nil {
+		return
+	}
+	if ref.Path != "" {
+		err = fmt.Errorf("can only publish entire dataset, cannot use version %s", ref.Path)
+		return
+	}
+	if err = repo.CanonicalizeDatasetRef(r.inst.Repo(), &ref); err != nil {
+		return
+	}
+
+	if addr, err = remote.Address(r.inst.Config(), p.RemoteName); err != nil {
+		return
+	}
+
+	if branch != "" {
+		// publishing a branch pushes the branch head. pushed logs carry all
+		// branches, so the remote can tell branch heads from the dataset head
+		dr := reporef.ConvertToDsref(ref)
+		dr.Branch = branch
+		var items []logbook.DatasetLogItem
+		if items, err = r.inst.Repo().Logbook().Items(ctx, dr, 0, 1); err != nil {
+			return
+		}
+		if len(items) == 0 {
+			err = repo.ErrNoHistory
+			return
+		}
+		ref.Path = items[0].Path
+	}
+	return ref, branch, addr, nil
+}
+
 // Unpublish asks a remote to remove a dataset
 func (r *RemoteMethods) Unpublish(p *PublicationParams, res *dsref.Ref) error {
 	if r.inst.rpc != nil {
@@ -198,32 +450,234 @@ func (r *RemoteMethods) Unpublish(p *PublicationParams, res *dsref.Ref) error {
 	if err := r.inst.RemoteClient().RemoveDataset(ctx, ref, addr); err != nil {
 		return err
 	}
+	r.inst.bus.Publish(event.ETRemoteUnpublishEvent, event.RemoteEvent{
+		Ref:        reporef.ConvertToDsref(ref),
+		RemoteAddr: addr,
+	})
 
 	ref.Published = false
 	if err = base.SetPublishStatus(r.inst.node.Repo, &ref, ref.Published); err != nil {
 		return err
 	}
+	r.inst.updateSearch(ctx, ref.AliasString())
 
 	*res = reporef.ConvertToDsref(ref)
 	return nil
 }
 
-// PullDataset fetches a dataset ref from a remote
-func (r *RemoteMethods) PullDataset(p *PublicationParams, res *bool) error {
+// DefaultPullConcurrency is the number of dataset versions pulled at once when
+// PullParams doesn't specify a concurrency
+const DefaultPullConcurrency = 3
+
+// PullParams encapsulates parameters for pulling dataset versions from a
+// remote
+type PullParams struct {
+	Ref        string
+	RemoteName string
+	// Depth is the number of versions to pull, starting with the latest. Zero
+	// pulls only the latest version, -1 pulls all versions
+	Depth int
+	// Components limits the components pulled for versions older than the
+	// latest. The dataset & commit are always pulled. Leave empty to pull
+	// complete versions
+	Components []string
+	// Concurrency is the number of versions to pull at once, defaults to
+	// DefaultPullConcurrency
+	Concurrency int
+}
+
+// PullDataset fetches the logbook for a dataset from a remote, then walks the
+// fetched history pulling the data for each version up to the requested depth
+func (r *RemoteMethods) PullDataset(p *PullParams, res *[]DatasetLogItem) error {
 	if r.inst.rpc != nil {
 		return checkRPCError(r.inst.rpc.Call("RemoteMethods.PullDataset", p, res))
 	}
 
+	for _, comp := range p.Components {
+		if !remote.IsDatasetComponent(comp) {
+			return fmt.Errorf("unknown component %q. valid components are: %s", comp, strings.Join(remote.DatasetComponents, ", "))
+		}
+	}
+
 	ref, err := repo.ParseDatasetRef(p.Ref)
 	if err != nil {
 		return err
 	}
+	if err = repo.CanonicalizeDatasetRef(r.inst.Repo(), &ref); err != nil && err != repo.ErrNotFound {
+		return err
+	}
+
+	addr, err := remote.Address(r.inst.Config(), p.RemoteName)
+	if err != nil {
+		return err
+	}
 
 	// TODO (b5) - need contexts yo
 	ctx := context.TODO()
 
-	err = r.inst.RemoteClient().PullDataset(ctx, &ref, p.RemoteName)
-	return err
+	dr := reporef.ConvertToDsref(ref)
+	dr.Path = ""
+	if err = r.inst.RemoteClient().CloneLogs(ctx, dr, addr); err != nil {
+		return err
+	}
+	r.inst.bus.Publish(event.ETLogsyncPullEvent, event.RemoteEvent{
+		Ref:        dr,
+		RemoteAddr: addr,
+	})
+
+	items, err := r.inst.Repo().Logbook().Items(ctx, dr, 0, -1)
+	if err != nil {
+		return err
+	}
+	items = pullVersions(items, ref.Path, p.Depth)
+	if len(items) == 0 {
+		return repo.ErrNoHistory
+	}
+
+	concurrency := p.Concurrency
+	if concurrency <= 0 {
+		concurrency = DefaultPullConcurrency
+	}
+
+	errs := make([]error, len(items))
+	sem := make(chan struct{}, concurrency)
+	wg := sync.WaitGroup{}
+	for i := range items {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			vref := reporef.DatasetRef{
+				Peername:  ref.Peername,
+				ProfileID: ref.ProfileID,
+				Name:      ref.Name,
+				Path:      items[i].Path,
+			}
+			// the first version is always pulled in full
+			var comps []string
+			if i > 0 {
+				comps = p.Components
+			}
+			if errs[i] = r.inst.RemoteClient().PullDatasetComponents(ctx, &vref, addr, comps); errs[i] != nil {
+				return
+			}
+			r.inst.bus.Publish(event.ETRemotePullEvent, event.RemoteEvent{
+				Ref:        reporef.ConvertToDsref(vref),
+				RemoteAddr: addr,
+			})
+		}(i)
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			return fmt.Errorf("pulling version %s: %w", items[i].Path, err)
+		}
+	}
+
+	*res = items
+	return nil
+}
+
+// pullVersions selects the versions to pull from a list of items ordered
+// newest-first, starting at path if one is given
+func pullVersions(items []DatasetLogItem, path string, depth int) []DatasetLogItem {
+	if path != "" {
+		start := -1
+		for i, item := range items {
+			if item.Path == path {
+				start = i
+				break
+			}
+		}
+		if start == -1 {
+			return []DatasetLogItem{{VersionInfo: dsref.VersionInfo{Path: path}}}
+		}
+		items = items[start:]
+	}
+
+	if depth == 0 {
+		depth = 1
+	}
+	if depth > 0 && depth < len(items) {
+		items = items[:depth]
+	}
+	return items
+}
+
+// partialBody handles dataset versions that were pulled without their body.
+// Getting the body fetches it from the remote the version was pulled from,
+// otherwise the body is left absent & reading it reports the missing body
+func (inst *Instance) partialBody(ctx context.Context, ref dsref.Ref, ds *dataset.Dataset, fetch bool) error {
+	pp := inst.partialPulls.Get(ds.Path)
+	if pp == nil || pp.Has("body") {
+		return nil
+	}
+	if !fetch {
+		ds.SetBodyFile(qfs.NewMemfileReader(filepath.Base(ds.BodyPath), absentBody(ds.Path)))
+		return nil
+	}
+
+	client := inst.RemoteClient()
+	if client == nil {
+		return fmt.Errorf("the body of %s wasn't pulled, and there is no remote client to fetch it", ds.Path)
+	}
+	vref := reporef.RefFromDsref(ref)
+	vref.Path = ds.Path
+	if err := client.PullDatasetComponents(ctx, &vref, pp.RemoteAddr, []string{"body"}); err != nil {
+		return fmt.Errorf("the body of %s wasn't pulled, fetching it from %s: %w", ds.Path, pp.RemoteAddr, err)
+	}
+	return nil
+}
+
+// absentBody is the body of a version pulled without its body
+type absentBody string
+
+func (path absentBody) Read(p []byte) (int, error) {
+	return 0, fmt.Errorf("the body of %s wasn't pulled, use `qri get body` to fetch it", string(path))
+}
+
+// RemoteSearchParams encapsulates parameters for searching a remote
+type RemoteSearchParams struct {
+	RemoteName string
+	Query      string
+	// Username, Format & UpdatedSince filter results when set
+	Username     string
+	Format       string
+	UpdatedSince time.Time
+	Offset       int
+	Limit        int
+}
+
+// Search queries the datasets a remote holds
+func (r *RemoteMethods) Search(p *RemoteSearchParams, res *[]dsref.VersionInfo) error {
+	if r.inst.rpc != nil {
+		return checkRPCError(r.inst.rpc.Call("RemoteMethods.Search", p, res))
+	}
+	ctx := r.inst.Context()
+
+	addr, err := remote.Address(r.inst.Config(), p.RemoteName)
+	if err != nil {
+		return err
+	}
+
+	results, err := r.inst.RemoteClient().Search(ctx, remote.SearchParams{
+		Query:        p.Query,
+		Username:     p.Username,
+		Format:       p.Format,
+		UpdatedSince: p.UpdatedSince,
+		Offset:       p.Offset,
+		Limit:        p.Limit,
+	}, addr)
+	if err != nil {
+		return err
+	}
+
+	*res = results
+	return nil
 }
 
 // Feeds returns a listing of datasets from a number of feeds like featured and
@@ -248,6 +702,29 @@ func (r *RemoteMethods) Feeds(remoteName *string, res *map[string][]dsref.Versio
 	return nil
 }
 
+// Usage reports storage used on this node's remote by each profile that has
+// pushed to it
+func (r *RemoteMethods) Usage(in *bool, res *[]remote.ProfileUsage) error {
+	if r.inst.rpc != nil {
+		return checkRPCError(r.inst.rpc.Call("RemoteMethods.Usage", in, res))
+	}
+
+	if r.inst.remote != nil {
+		*res = r.inst.remote.Usage()
+		return nil
+	}
+
+	versions := []*remote.StoredVersion{}
+	if r.inst.repoPath != "" {
+		var err error
+		if versions, err = remote.ReadStoredVersions(remoteUsagePath(r.inst.repoPath)); err != nil {
+			return err
+		}
+	}
+	*res = remote.UsageReport(versions, r.inst.cfg.Remote)
+	return nil
+}
+
 // PreviewParams provides arguments to the preview method
 type PreviewParams struct {
 	RemoteName string
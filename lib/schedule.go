@@ -0,0 +1,234 @@
+package lib
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+
+	"github.com/qri-io/qri/base/dsfs"
+	"github.com/qri-io/qri/cron"
+	"github.com/qri-io/qri/repo"
+	reporef "github.com/qri-io/qri/repo/ref"
+)
+
+// schedulesPath is the directory scheduled transform jobs & their run
+// history are kept in
+func schedulesPath(repoPath string) string {
+	return filepath.Join(repoPath, "schedules")
+}
+
+// scheduler returns the scheduler of transform runs, loading it on first use
+func (inst *Instance) scheduler() (*cron.Cron, error) {
+	inst.cronLk.Lock()
+	defer inst.cronLk.Unlock()
+	if inst.cron == nil {
+		if inst.repoPath == "" {
+			return nil, fmt.Errorf("scheduling transforms requires a repo directory")
+		}
+		c, err := cron.NewCron(schedulesPath(inst.repoPath), inst.runScheduledTransform)
+		if err != nil {
+			return nil, err
+		}
+		inst.cron = c
+	}
+	return inst.cron, nil
+}
+
+// startScheduler runs scheduled transforms while the instance is connected
+func (inst *Instance) startScheduler() error {
+	if inst.repoPath == "" {
+		return nil
+	}
+	c, err := inst.scheduler()
+	if err != nil {
+		return err
+	}
+	c.Start(inst.ctx)
+	return nil
+}
+
+// runScheduledTransform re-runs the transform of a dataset, saving a new
+// version only if the body or meta changed
+func (inst *Instance) runScheduledTransform(ctx context.Context, ref string, w io.Writer) (string, error) {
+	p := &SaveParams{
+		Ref:          ref,
+		Recall:       "tf",
+		ScriptOutput: w,
+		ShouldRender: true,
+		// ForceIfNoChanges is false, unchanged results aren't saved
+		Force: false,
+	}
+	res := &reporef.DatasetRef{}
+	if err := NewDatasetMethods(inst).Save(p, res); err != nil {
+		if errors.Is(err, dsfs.ErrNoChanges) {
+			return "", cron.ErrNoChanges
+		}
+		return "", err
+	}
+	return res.Path, nil
+}
+
+// ScheduleMethods encapsulates business logic for running dataset
+// transforms on a schedule
+type ScheduleMethods struct {
+	inst *Instance
+}
+
+// CoreRequestsName implements the Requets interface
+func (m ScheduleMethods) CoreRequestsName() string { return "schedule" }
+
+// NewScheduleMethods creates a ScheduleMethods pointer from a qri instance
+func NewScheduleMethods(inst *Instance) *ScheduleMethods {
+	return &ScheduleMethods{
+		inst: inst,
+	}
+}
+
+// ScheduleParams encapsulates parameters for scheduling a dataset transform
+type ScheduleParams struct {
+	Ref string
+	// Schedule is a crontab expression, descriptor like "@daily", or interval
+	// like "@every 6h"
+	Schedule string
+}
+
+// Add schedules the transform of a dataset to run, replacing any existing
+// schedule for the dataset. Scheduled transforms run while the qri daemon is
+// connected
+func (m *ScheduleMethods) Add(p *ScheduleParams, res *cron.Job) error {
+	if m.inst.rpc != nil {
+		return checkRPCError(m.inst.rpc.Call("ScheduleMethods.Add", p, res))
+	}
+	ctx := m.inst.Context()
+
+	if p.Schedule == "" {
+		return fmt.Errorf("schedule is required")
+	}
+	ref, err := m.transformRef(ctx, p.Ref)
+	if err != nil {
+		return err
+	}
+	c, err := m.inst.scheduler()
+	if err != nil {
+		return err
+	}
+	job, err := c.Schedule(ref, p.Schedule)
+	if err != nil {
+		return err
+	}
+	*res = *job
+	return nil
+}
+
+// transformRef resolves a reference to a dataset with a transform component,
+// returning the dataset alias
+func (m *ScheduleMethods) transformRef(ctx context.Context, refstr string) (string, error) {
+	if refstr == "" {
+		return "", repo.ErrEmptyRef
+	}
+	ref, err := repo.ParseDatasetRef(refstr)
+	if err != nil {
+		return "", err
+	}
+	if err = repo.CanonicalizeDatasetRef(m.inst.repo, &ref); err != nil {
+		return "", err
+	}
+	ds, err := dsfs.LoadDataset(ctx, m.inst.repo.Store(), ref.Path)
+	if err != nil {
+		return "", err
+	}
+	if ds.Transform == nil {
+		return "", fmt.Errorf("%s has no transform to schedule", ref.AliasString())
+	}
+	return ref.AliasString(), nil
+}
+
+// Remove unschedules the transform of a dataset
+func (m *ScheduleMethods) Remove(refstr *string, res *bool) error {
+	if m.inst.rpc != nil {
+		return checkRPCError(m.inst.rpc.Call("ScheduleMethods.Remove", refstr, res))
+	}
+
+	ref, err := repo.ParseDatasetRef(*refstr)
+	if err != nil {
+		return err
+	}
+	if err = repo.CanonicalizeDatasetRef(m.inst.repo, &ref); err != nil && err != repo.ErrNotFound {
+		return err
+	}
+	c, err := m.inst.scheduler()
+	if err != nil {
+		return err
+	}
+	if err := c.Unschedule(ref.AliasString()); err != nil {
+		return err
+	}
+	*res = true
+	return nil
+}
+
+// List lists scheduled transforms, ordered by next run
+func (m *ScheduleMethods) List(p *ListParams, res *[]*cron.Job) error {
+	if m.inst.rpc != nil {
+		return checkRPCError(m.inst.rpc.Call("ScheduleMethods.List", p, res))
+	}
+
+	c, err := m.inst.scheduler()
+	if err != nil {
+		return err
+	}
+	jobs, err := c.Jobs()
+	if err != nil {
+		return err
+	}
+	*res = jobs
+	return nil
+}
+
+// ScheduleLogsParams encapsulates parameters for listing scheduled runs
+type ScheduleLogsParams struct {
+	// Ref limits runs to a single dataset, empty lists runs of all datasets
+	Ref           string
+	Offset, Limit int
+}
+
+// Logs lists the history of scheduled transform runs, most recent first
+func (m *ScheduleMethods) Logs(p *ScheduleLogsParams, res *[]*cron.Run) error {
+	if m.inst.rpc != nil {
+		return checkRPCError(m.inst.rpc.Call("ScheduleMethods.Logs", p, res))
+	}
+
+	// ensure valid limit value
+	if p.Limit <= 0 {
+		p.Limit = DefaultPageSize
+	}
+	// ensure valid offset value
+	if p.Offset < 0 {
+		p.Offset = 0
+	}
+
+	alias := ""
+	if p.Ref != "" {
+		ref, err := repo.ParseDatasetRef(p.Ref)
+		if err != nil {
+			return err
+		}
+		if err = repo.CanonicalizeDatasetRef(m.inst.repo, &ref); err != nil && err != repo.ErrNotFound {
+			return err
+		}
+		alias = ref.AliasString()
+	}
+
+	c, err := m.inst.scheduler()
+	if err != nil {
+		return err
+	}
+	runs, err := c.Runs(alias, p.Offset, p.Limit)
+	if err != nil {
+		return err
+	}
+	*res = runs
+	return nil
+}
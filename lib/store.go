@@ -0,0 +1,65 @@
+package lib
+
+import (
+	"fmt"
+
+	"github.com/qri-io/qri/config"
+	"github.com/qri-io/qri/repo/buildrepo"
+	"github.com/qri-io/qri/repo/storage"
+)
+
+// MigrateStoreParams encapsulates parameters for copying the configured store
+// into a new store
+type MigrateStoreParams struct {
+	// Type of the destination store, eg: "fs" or "s3"
+	Type string
+	// Path of the destination store, required for "fs" stores
+	Path string
+	// Options for the destination store, eg: the bucket of an "s3" store
+	Options map[string]interface{}
+}
+
+// MigrateStore copies all data in the configured store into a new store &
+// updates the configuration to use it. The new store is used the next time
+// qri starts. Data is not removed from the original store
+func (m *ConfigMethods) MigrateStore(p *MigrateStoreParams, res *storage.MigrateResult) error {
+	if m.inst.rpc != nil {
+		return checkRPCError(m.inst.rpc.Call("ConfigMethods.MigrateStore", p, res))
+	}
+
+	cfg := m.inst.cfg.Copy()
+	if p.Type == cfg.Store.Type && p.Path == cfg.Store.Path {
+		return fmt.Errorf("destination is the configured store")
+	}
+	cfg.Store = &config.Store{
+		Type:    p.Type,
+		Path:    p.Path,
+		Options: p.Options,
+	}
+	if err := cfg.Validate(); err != nil {
+		return fmt.Errorf("validating config: %s", err)
+	}
+
+	ctx := m.inst.Context()
+
+	// the destination store only receives blocks, build it offline without the
+	// p2p identity of the running node
+	dstCfg := cfg.Copy()
+	dstCfg.P2P = nil
+	dst, err := buildrepo.NewCAFSStore(ctx, dstCfg)
+	if err != nil {
+		return fmt.Errorf("creating %s store: %s", p.Type, err)
+	}
+	defer func() {
+		if err := storage.Close(dst); err != nil {
+			log.Debugf("closing %s store: %s", p.Type, err)
+		}
+	}()
+
+	r, err := storage.Migrate(ctx, m.inst.store, dst)
+	if err != nil {
+		return err
+	}
+	*res = *r
+	return m.inst.ChangeConfig(cfg)
+}
@@ -0,0 +1,291 @@
+package lib
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/qri-io/qri/event"
+	"github.com/qri-io/qri/remote"
+	"github.com/qri-io/qri/repo"
+	reporef "github.com/qri-io/qri/repo/ref"
+)
+
+// notificationRetryInterval is the time to wait before reconnecting to a
+// remote after a notification stream drops
+var notificationRetryInterval = time.Second * 30
+
+// subscriptionsPath is the directory datasets this node is subscribed to are
+// kept in
+func subscriptionsPath(repoPath string) string {
+	return filepath.Join(repoPath, "subscriptions")
+}
+
+// remoteSubscribersOpts configures the remote to persist subscribers to the
+// repo, so subscriptions survive restarts
+func (inst *Instance) remoteSubscribersOpts(o *remote.Options) {
+	if inst.repoPath == "" {
+		return
+	}
+	subs, err := remote.NewSubscribers(remoteUsagePath(inst.repoPath))
+	if err != nil {
+		log.Errorf("loading remote subscribers: %s", err)
+		return
+	}
+	o.Subscribers = subs
+}
+
+// subscriptionStore returns the store of datasets this node is subscribed to,
+// loading it on first use
+func (inst *Instance) subscriptionStore() (*remote.Subscriptions, error) {
+	inst.subsLk.Lock()
+	defer inst.subsLk.Unlock()
+	if inst.subscriptions == nil {
+		dir := ""
+		if inst.repoPath != "" {
+			dir = subscriptionsPath(inst.repoPath)
+		}
+		subs, err := remote.NewSubscriptions(dir)
+		if err != nil {
+			return nil, err
+		}
+		inst.subscriptions = subs
+	}
+	return inst.subscriptions, nil
+}
+
+// SubscribeParams encapsulates parameters for subscribing to a dataset
+type SubscribeParams struct {
+	Ref        string
+	RemoteName string
+	// AutoPull pulls new versions as the remote announces them
+	AutoPull bool
+}
+
+// Subscribe asks a remote to notify this node when new versions of a dataset
+// are pushed
+func (r *RemoteMethods) Subscribe(p *SubscribeParams, res *remote.Subscription) error {
+	if r.inst.rpc != nil {
+		return checkRPCError(r.inst.rpc.Call("RemoteMethods.Subscribe", p, res))
+	}
+
+	ref, err := repo.ParseDatasetRef(p.Ref)
+	if err != nil {
+		return err
+	}
+	if err = repo.CanonicalizeDatasetRef(r.inst.Repo(), &ref); err != nil && err != repo.ErrNotFound {
+		return err
+	}
+
+	addr, err := remote.Address(r.inst.Config(), p.RemoteName)
+	if err != nil {
+		return err
+	}
+	store, err := r.inst.subscriptionStore()
+	if err != nil {
+		return err
+	}
+
+	ctx := r.inst.Context()
+	if err := r.inst.RemoteClient().Subscribe(ctx, reporef.ConvertToDsref(ref), addr); err != nil {
+		return err
+	}
+
+	sub := &remote.Subscription{
+		Ref:        ref.AliasString(),
+		RemoteName: p.RemoteName,
+		RemoteAddr: addr,
+		AutoPull:   p.AutoPull,
+		Subscribed: time.Now(),
+	}
+	if err := store.Put(sub); err != nil {
+		return err
+	}
+	if r.inst.subsWatcher != nil {
+		r.inst.subsWatcher.watch(addr)
+	}
+
+	*res = *sub
+	return nil
+}
+
+// Unsubscribe stops notifications for a dataset
+func (r *RemoteMethods) Unsubscribe(p *SubscribeParams, res *bool) error {
+	if r.inst.rpc != nil {
+		return checkRPCError(r.inst.rpc.Call("RemoteMethods.Unsubscribe", p, res))
+	}
+
+	ref, err := repo.ParseDatasetRef(p.Ref)
+	if err != nil {
+		return err
+	}
+	if err = repo.CanonicalizeDatasetRef(r.inst.Repo(), &ref); err != nil && err != repo.ErrNotFound {
+		return err
+	}
+	store, err := r.inst.subscriptionStore()
+	if err != nil {
+		return err
+	}
+
+	sub := store.Get(ref.AliasString())
+	if sub == nil {
+		return fmt.Errorf("not subscribed to %s", ref.AliasString())
+	}
+	addr := sub.RemoteAddr
+	if p.RemoteName != "" {
+		if addr, err = remote.Address(r.inst.Config(), p.RemoteName); err != nil {
+			return err
+		}
+	}
+
+	ctx := r.inst.Context()
+	if err := r.inst.RemoteClient().Unsubscribe(ctx, reporef.ConvertToDsref(ref), addr); err != nil {
+		return err
+	}
+	if err := store.Delete(sub.Ref); err != nil {
+		return err
+	}
+
+	*res = true
+	return nil
+}
+
+// Subscriptions lists the datasets this node is subscribed to
+func (r *RemoteMethods) Subscriptions(in *bool, res *[]*remote.Subscription) error {
+	if r.inst.rpc != nil {
+		return checkRPCError(r.inst.rpc.Call("RemoteMethods.Subscriptions", in, res))
+	}
+
+	store, err := r.inst.subscriptionStore()
+	if err != nil {
+		return err
+	}
+	*res = store.List()
+	return nil
+}
+
+// subscriptionWatcher listens for notifications on the remotes this node has
+// subscriptions on, publishing them to the instance event bus & pulling new
+// versions of datasets subscribed to with AutoPull
+type subscriptionWatcher struct {
+	inst *Instance
+	ctx  context.Context
+
+	lk       sync.Mutex
+	watching map[string]bool
+	// most recent path pulled for each dataset, notifications arrive over both
+	// HTTP & p2p
+	pulled map[string]string
+}
+
+// startSubscriptions begins watching for notifications on all remotes this
+// node is subscribed to datasets on
+func (inst *Instance) startSubscriptions() error {
+	if inst.remoteClient == nil {
+		return nil
+	}
+	store, err := inst.subscriptionStore()
+	if err != nil {
+		return err
+	}
+
+	w := &subscriptionWatcher{
+		inst:     inst,
+		ctx:      inst.ctx,
+		watching: map[string]bool{},
+		pulled:   map[string]string{},
+	}
+	inst.subsWatcher = w
+
+	events := inst.bus.Subscribe(event.ETRemoteDatasetNotificationEvent)
+	go func() {
+		for {
+			select {
+			case <-w.ctx.Done():
+				inst.bus.Unsubscribe(events)
+				return
+			case e := <-events:
+				if re, ok := e.Payload.(event.RemoteEvent); ok {
+					w.handle(re)
+				}
+			}
+		}
+	}()
+
+	for _, sub := range store.List() {
+		w.watch(sub.RemoteAddr)
+	}
+	return nil
+}
+
+// watch reads the notification stream of a remote until the instance context
+// is cancelled, reconnecting if the stream drops
+func (w *subscriptionWatcher) watch(addr string) {
+	w.lk.Lock()
+	defer w.lk.Unlock()
+	if w.watching[addr] {
+		return
+	}
+	w.watching[addr] = true
+
+	go func() {
+		for {
+			refs, err := w.inst.RemoteClient().Notifications(w.ctx, addr)
+			if err != nil {
+				log.Debugf("opening notification stream for %s: %s", addr, err)
+			} else {
+				for ref := range refs {
+					w.inst.bus.Publish(event.ETRemoteDatasetNotificationEvent, event.RemoteEvent{
+						Ref:        ref,
+						RemoteAddr: addr,
+					})
+				}
+			}
+
+			select {
+			case <-w.ctx.Done():
+				return
+			case <-time.After(notificationRetryInterval):
+			}
+		}
+	}()
+}
+
+// handle pulls the version a notification announces if the dataset is
+// subscribed to with AutoPull & the version hasn't been pulled already
+func (w *subscriptionWatcher) handle(e event.RemoteEvent) {
+	store, err := w.inst.subscriptionStore()
+	if err != nil {
+		log.Errorf("loading subscriptions: %s", err)
+		return
+	}
+	alias := e.Ref.Alias()
+	sub := store.Get(alias)
+	if sub == nil || !sub.AutoPull {
+		return
+	}
+
+	w.lk.Lock()
+	if e.Ref.Path != "" && w.pulled[alias] == e.Ref.Path {
+		w.lk.Unlock()
+		return
+	}
+	w.pulled[alias] = e.Ref.Path
+	w.lk.Unlock()
+
+	if local, err := w.inst.Repo().GetRef(reporef.DatasetRef{Peername: e.Ref.Username, Name: e.Ref.Name}); err == nil && e.Ref.Path != "" && local.Path == e.Ref.Path {
+		return
+	}
+
+	p := &PullParams{Ref: alias, RemoteName: sub.RemoteName}
+	res := []DatasetLogItem{}
+	if err := NewRemoteMethods(w.inst).PullDataset(p, &res); err != nil {
+		log.Errorf("pulling %s: %s", alias, err)
+		// allow a later notification to retry
+		w.lk.Lock()
+		delete(w.pulled, alias)
+		w.lk.Unlock()
+	}
+}
@@ -0,0 +1,72 @@
+package lib
+
+import (
+	"testing"
+	"time"
+
+	"github.com/qri-io/qri/remote"
+)
+
+func TestSubscribeAutoPullIntegration(t *testing.T) {
+	tr := NewNetworkIntegrationTestRunner(t, "integration_subscribe_auto_pull")
+	defer tr.Cleanup()
+
+	nasim := tr.InitNasim(t)
+	ref := InitWorldBankDataset(t, nasim)
+	PublishToRegistry(t, nasim, ref.AliasString())
+
+	hinshun := tr.InitHinshun(t)
+	// close hinshun's notification stream before the registry server shuts down
+	defer hinshun.Teardown()
+	rm := NewRemoteMethods(hinshun)
+
+	sub := &remote.Subscription{}
+	if err := rm.Subscribe(&SubscribeParams{Ref: ref.AliasString(), AutoPull: true}, sub); err != nil {
+		t.Fatal(err)
+	}
+	if sub.Ref != ref.AliasString() || !sub.AutoPull {
+		t.Errorf("subscription mismatch. got: %v", sub)
+	}
+
+	subs := []*remote.Subscription{}
+	if err := rm.Subscriptions(nil, &subs); err != nil {
+		t.Fatal(err)
+	}
+	if len(subs) != 1 {
+		t.Fatalf("expected one subscription, got: %v", subs)
+	}
+
+	if err := hinshun.startSubscriptions(); err != nil {
+		t.Fatal(err)
+	}
+	// give the watcher a moment to open its notification stream
+	time.Sleep(time.Millisecond * 100)
+
+	ref = Commit2WorldBank(t, nasim)
+	PublishToRegistry(t, nasim, ref.AliasString())
+
+	deadline := time.Now().Add(time.Second * 10)
+	for {
+		if has, err := hinshun.Repo().Store().Has(tr.Ctx, ref.Path); err == nil && has {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s to be pulled", ref.Path)
+		}
+		time.Sleep(time.Millisecond * 50)
+	}
+
+	unsubscribed := false
+	if err := rm.Unsubscribe(&SubscribeParams{Ref: ref.AliasString()}, &unsubscribed); err != nil {
+		t.Fatal(err)
+	}
+	if err := rm.Subscriptions(nil, &subs); err != nil {
+		t.Fatal(err)
+	}
+	if len(subs) != 0 {
+		t.Errorf("expected no subscriptions after unsubscribing, got: %v", subs)
+	}
+	if err := rm.Unsubscribe(&SubscribeParams{Ref: ref.AliasString()}, &unsubscribed); err == nil {
+		t.Error("expected unsubscribing twice to error")
+	}
+}
@@ -0,0 +1,56 @@
+package lib
+
+import (
+
+	"github.com/qri-io/qfs"
+	"github.com/qri-io/qri/errors"
+	"github.com/qri-io/qri/sql"
+	"github.com/qri-io/qri/startf"
+)
+
+// TestTransformParams are parameters for running the tests of a transform
+// script
+type TestTransformParams struct {
+	// ScriptPath is the path to the transform script under test
+	ScriptPath string
+	// TestPath is the path to the test script. Defaults to the companion
+	// test script of ScriptPath, eg: transform.star -> transform_test.star
+	TestPath string
+	// resource limits for test execution, zero values mean no limit
+	TransformLimits startf.Limits
+}
+
+// TestTransform runs the test_ functions of a transform test script against
+// a transform script. Tests run with network access disabled. A report is
+// returned even when tests fail, errors are only returned when tests can't
+// be run
+func (m *DatasetMethods) TestTransform(p *TestTransformParams, res *startf.TestReport) error {
+	if p.ScriptPath == "" {
+		return errors.New(ErrBadArgs, "a transform script path is required")
+	}
+	if p.TestPath == "" {
+		p.TestPath = startf.TestScriptPath(p.ScriptPath)
+	}
+	// absolutize local paths before a possible trip over RPC to another
+	// local process
+	if err := qfs.AbsPath(&p.ScriptPath); err != nil {
+		return err
+	}
+	if err := qfs.AbsPath(&p.TestPath); err != nil {
+		return err
+	}
+	if m.inst.rpc != nil {
+		return checkRPCError(m.inst.rpc.Call("DatasetMethods.TestTransform", p, res))
+	}
+	ctx := m.inst.Context()
+
+	report, err := startf.RunTests(ctx, p.ScriptPath, p.TestPath,
+		startf.AddQriRepo(m.inst.repo),
+		startf.SetLimits(p.TransformLimits),
+		startf.SetSQLService(sql.New(m.inst.repo)),
+	)
+	if report != nil {
+		*res = *report
+	}
+	return err
+}
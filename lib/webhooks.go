@@ -0,0 +1,80 @@
+package lib
+
+import (
+	"context"
+	"path/filepath"
+
+	"github.com/qri-io/qri/event"
+	"github.com/qri-io/qri/remote"
+	"github.com/qri-io/qri/repo/profile"
+	reporef "github.com/qri-io/qri/repo/ref"
+	"github.com/qri-io/qri/webhook"
+)
+
+// WebhookMethods extends a lib.Instance with business logic for inspecting
+// outbound webhook deliveries
+type WebhookMethods struct {
+	inst *Instance
+}
+
+// CoreRequestsName implements the Requets interface
+func (m WebhookMethods) CoreRequestsName() string { return "webhooks" }
+
+// NewWebhookMethods creates a WebhookMethods pointer from a qri instance
+func NewWebhookMethods(inst *Instance) *WebhookMethods {
+	return &WebhookMethods{
+		inst: inst,
+	}
+}
+
+// Log lists webhook delivery attempts, most recent first
+func (m *WebhookMethods) Log(p *ListParams, res *[]webhook.LogEntry) error {
+	if m.inst.rpc != nil {
+		return checkRPCError(m.inst.rpc.Call("WebhookMethods.Log", p, res))
+	}
+
+	// ensure valid limit value
+	if p.Limit <= 0 {
+		p.Limit = DefaultPageSize
+	}
+	// ensure valid offset value
+	if p.Offset < 0 {
+		p.Offset = 0
+	}
+
+	entries, err := webhook.ReadLog(webhooksPath(m.inst.repoPath), p.Offset, p.Limit)
+	if err != nil {
+		return err
+	}
+	*res = entries
+	return nil
+}
+
+func webhooksPath(repoPath string) string {
+	return filepath.Join(repoPath, "webhooks")
+}
+
+// remoteEventHooks publishes remote hook calls as events on bus, calling any
+// hooks already set on the remote options first
+func remoteEventHooks(bus event.Bus) func(*remote.Options) {
+	publishHook := func(t event.Topic, h remote.Hook) remote.Hook {
+		return func(ctx context.Context, pid profile.ID, ref reporef.DatasetRef) error {
+			if h != nil {
+				if err := h(ctx, pid, ref); err != nil {
+					return err
+				}
+			}
+			bus.Publish(t, event.RemoteHookEvent{
+				ProfileID: pid.String(),
+				Ref:       reporef.ConvertToDsref(ref),
+			})
+			return nil
+		}
+	}
+
+	return func(o *remote.Options) {
+		o.DatasetPushed = publishHook(event.ETRemoteDatasetPushedEvent, o.DatasetPushed)
+		o.DatasetRemoved = publishHook(event.ETRemoteDatasetRemovedEvent, o.DatasetRemoved)
+		o.LogPushed = publishHook(event.ETRemoteLogPushedEvent, o.LogPushed)
+	}
+}
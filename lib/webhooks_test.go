@@ -0,0 +1,81 @@
+package lib
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/qri-io/qri/event"
+	"github.com/qri-io/qri/remote"
+	"github.com/qri-io/qri/repo/profile"
+	reporef "github.com/qri-io/qri/repo/ref"
+	"github.com/qri-io/qri/webhook"
+)
+
+func TestRemoteEventHooks(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	bus := event.NewBus(ctx)
+
+	called := false
+	o := &remote.Options{
+		DatasetPushed: func(ctx context.Context, pid profile.ID, ref reporef.DatasetRef) error {
+			called = true
+			return nil
+		},
+		DatasetRemoved: func(ctx context.Context, pid profile.ID, ref reporef.DatasetRef) error {
+			return fmt.Errorf("rejected")
+		},
+	}
+	remoteEventHooks(bus)(o)
+
+	events := bus.Subscribe(event.ETRemoteDatasetPushedEvent, event.ETRemoteDatasetRemovedEvent)
+	ref := reporef.DatasetRef{Peername: "peer", Name: "movies", Path: "/map/QmFoo"}
+
+	errs := make(chan error)
+	go func() {
+		errs <- o.DatasetPushed(ctx, profile.ID(""), ref)
+	}()
+	e := <-events
+	if err := <-errs; err != nil {
+		t.Fatal(err)
+	}
+	if !called {
+		t.Error("expected existing DatasetPushed hook to be called")
+	}
+	if e.Topic != event.ETRemoteDatasetPushedEvent {
+		t.Errorf("topic mismatch. expected: %q, got: %q", event.ETRemoteDatasetPushedEvent, e.Topic)
+	}
+	if p, ok := e.Payload.(event.RemoteHookEvent); !ok || p.Ref.Name != "movies" {
+		t.Errorf("unexpected payload: %#v", e.Payload)
+	}
+
+	// errors from existing hooks are returned without publishing
+	if err := o.DatasetRemoved(ctx, profile.ID(""), ref); err == nil {
+		t.Error("expected error from existing DatasetRemoved hook")
+	}
+
+	// hooks that weren't set are still wrapped to publish events
+	if o.LogPushed == nil {
+		t.Error("expected LogPushed hook to be set")
+	}
+}
+
+func TestWebhookMethodsLog(t *testing.T) {
+	dir, err := ioutil.TempDir("", "TestWebhookMethodsLog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	m := NewWebhookMethods(&Instance{repoPath: dir})
+	res := []webhook.LogEntry{}
+	if err := m.Log(&ListParams{}, &res); err != nil {
+		t.Fatal(err)
+	}
+	if len(res) != 0 {
+		t.Errorf("expected empty log, got %d entries", len(res))
+	}
+}
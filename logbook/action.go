@@ -16,12 +16,17 @@ const (
 	ActionDatasetDeleteAll
 	// ActionDatasetRename is when a dataset is renamed
 	ActionDatasetRename
+	// ActionBranchInit is when a new branch of dataset history is created
+	ActionBranchInit
+	// ActionBranchDelete is when a branch of dataset history is removed
+	ActionBranchDelete
 )
 
 // Action represents the result of an action that logbook just completed
 type Action struct {
 	Type       ActionType
 	InitID     string
+	Branch     string
 	TopIndex   int
 	ProfileID  string
 	Username   string
@@ -0,0 +1,77 @@
+package logbook
+
+import (
+	"context"
+
+	crypto "github.com/libp2p/go-libp2p-core/crypto"
+	"github.com/qri-io/qri/identity"
+	"github.com/qri-io/qri/logbook/oplog"
+)
+
+// CompactResult summarizes the outcome of compacting a logbook
+type CompactResult struct {
+	OpsBefore int `json:"opsBefore"`
+	OpsAfter  int `json:"opsAfter"`
+}
+
+// Compact squashes logs authored by this book: removed logs are reduced to
+// their initialization and removal operations, and chains of amend operations
+// are reduced to the latest amend. Logs from other authors are left as-is so
+// their signatures remain verifiable, compacted logs authored by this book are
+// re-signed. Compact always saves the book in full, folding any segments into
+// a single file.
+//
+// A remote holding an uncompacted copy of a log keeps the longer copy when the
+// compacted log is pushed, and pulling that copy replaces the compacted log
+func (book *Book) Compact(ctx context.Context) (*CompactResult, error) {
+	if book == nil {
+		return nil, ErrNoLogbook
+	}
+
+	keyID, err := identity.KeyIDFromPriv(book.pk)
+	if err != nil {
+		return nil, err
+	}
+	logs, err := book.store.Logs(ctx, 0, -1)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &CompactResult{}
+	for _, l := range logs {
+		res.OpsBefore += countOps(l)
+		if len(l.Ops) > 0 && l.Ops[0].AuthorID == keyID {
+			l.Compact()
+			if err := signTree(l, book.pk); err != nil {
+				return nil, err
+			}
+		}
+		res.OpsAfter += countOps(l)
+	}
+
+	if err := book.saveFull(ctx); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// signTree signs a log & all descendants in place
+func signTree(l *oplog.Log, pk crypto.PrivKey) error {
+	if err := l.Sign(pk); err != nil {
+		return err
+	}
+	for _, child := range l.Logs {
+		if err := signTree(child, pk); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func countOps(l *oplog.Log) int {
+	n := len(l.Ops)
+	for _, child := range l.Logs {
+		n += countOps(child)
+	}
+	return n
+}
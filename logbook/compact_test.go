@@ -0,0 +1,137 @@
+package logbook
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	crypto "github.com/libp2p/go-libp2p-core/crypto"
+	"github.com/qri-io/qfs"
+	"github.com/qri-io/qfs/localfs"
+)
+
+func TestSegmentedSave(t *testing.T) {
+	tr, cleanup := newTestRunner(t)
+	defer cleanup()
+
+	dir, err := ioutil.TempDir("", "logbook_segments")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	location := filepath.Join(dir, "logbook.qfb")
+	fs := localfs.NewFS()
+	book, err := NewJournal(testPrivKey(t), tr.Username, fs, location)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr.Book = book
+
+	initID := tr.WriteWorldBankExample(t)
+	tr.WriteRenameExample(t)
+
+	if book.segments == 0 {
+		t.Fatal("expected saves to write segments")
+	}
+	expect, err := book.Items(tr.Ctx, tr.WorldBankRef(), 0, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reload := func() *Book {
+		b, err := NewJournal(testPrivKey(t), tr.Username, fs, location)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return b
+	}
+
+	got, err := reload().Items(tr.Ctx, tr.WorldBankRef(), 0, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(expect, got); diff != "" {
+		t.Errorf("items mismatch after reloading segments (-want +got):\n%s", diff)
+	}
+
+	// rename twice to build an amend chain, then delete a dataset
+	if err := book.WriteDatasetRename(tr.Ctx, initID, "wb_pop"); err != nil {
+		t.Fatal(err)
+	}
+	if err := book.WriteDatasetRename(tr.Ctx, initID, "world_bank_population"); err != nil {
+		t.Fatal(err)
+	}
+	renameInitID, err := book.RefToInitID(tr.RenameRef())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := book.WriteDatasetDelete(tr.Ctx, renameInitID); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := book.Compact(tr.Ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.OpsAfter >= res.OpsBefore {
+		t.Errorf("expected compaction to drop operations. before: %d after: %d", res.OpsBefore, res.OpsAfter)
+	}
+	if book.segments != 0 {
+		t.Errorf("expected compaction to fold segments into the full file. got %d segments", book.segments)
+	}
+	if data, err := ioutil.ReadFile(segmentPath(location, 1)); err != nil || len(data) != 0 {
+		t.Errorf("expected folded segments to be emptied. got %d bytes, error: %v", len(data), err)
+	}
+
+	reloaded := reload()
+	got, err = reloaded.Items(tr.Ctx, tr.WorldBankRef(), 0, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(expect, got); diff != "" {
+		t.Errorf("items mismatch after compaction (-want +got):\n%s", diff)
+	}
+	if _, err := reloaded.BranchRef(tr.Ctx, tr.RenameRef()); err == nil {
+		t.Errorf("expected deleted dataset to stay deleted after compaction")
+	}
+
+	report, err := reloaded.Verify(tr.Ctx, tr.WorldBankRef(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !report.OK() {
+		t.Errorf("expected compacted logs to verify. got: %s", report.Err())
+	}
+
+	// compacted logs carry signatures peers can check
+	lg, err := reloaded.UserDatasetRef(tr.Ctx, tr.WorldBankRef())
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := func(string) (crypto.PubKey, error) { return book.AuthorPubKey(), nil }
+	if report := VerifyLog(lg, keys); !report.OK() {
+		t.Errorf("expected compacted logs to be signed. got: %s", report.Err())
+	}
+}
+
+func TestLoadMissingSegments(t *testing.T) {
+	tr, cleanup := newTestRunner(t)
+	defer cleanup()
+
+	fs := qfs.NewMemFS()
+	book, err := NewJournal(testPrivKey(t), tr.Username, fs, "/mem/logbook.qfb")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr.Book = book
+	tr.WriteWorldBankExample(t)
+
+	// filesystems that don't store files at requested paths have no segments
+	// to read, loading must stop on the filesystem's not found error
+	if _, err := NewJournal(testPrivKey(t), tr.Username, fs, book.fsLocation); err != nil {
+		t.Errorf("expected reloading a book to succeed. got: %s", err)
+	}
+}
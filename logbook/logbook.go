@@ -9,9 +9,9 @@ package logbook
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io/ioutil"
-	"strings"
 	"time"
 
 	logger "github.com/ipfs/go-log"
@@ -32,6 +32,9 @@ var (
 	// append-only, passing a shorter log than the one on file is grounds
 	// for rejection
 	ErrLogTooShort = fmt.Errorf("logbook: log is too short")
+	// ErrUnverifiedAuthor indicates a log claims an author whose public key
+	// isn't known, so the log's signature can't be checked
+	ErrUnverifiedAuthor = fmt.Errorf("logbook: unverified author")
 
 	// NewTimestamp generates the current unix nanosecond time.
 	// This is mainly here for tests to override
@@ -56,9 +59,9 @@ const (
 	ACLModel
 )
 
-// DefaultBranchName is the default name all branch-level logbook data is read
-// from and written to. we currently don't present branches as a user-facing
-// feature in qri, but logbook supports them
+// DefaultBranchName is the name of the branch every dataset is initialized
+// with. branch-level logbook data is read from and written to the default
+// branch unless a reference specifies another branch
 const DefaultBranchName = "main"
 
 // ModelString gets a unique string descriptor for an integral model identifier
@@ -91,10 +94,23 @@ type Book struct {
 
 	fsLocation string
 	fs         qfs.Filesystem
+	// number of segment files written on top of fsLocation
+	segments int
+	// segmented is true when the filesystem stores files at the paths they're
+	// given, which is required to find segments when loading
+	segmented bool
 
 	listener func(*Action)
+	// resolves public keys of authors other than this book's author
+	keys KeyResolver
+	// strict books reject merged logs from authors they can't resolve a key for
+	strict bool
 }
 
+// MaxSegments is the number of segments a book will append before folding all
+// segments into a single file on the next save
+var MaxSegments = 100
+
 // NewBook creates a book with a user-provided logstore
 func NewBook(pk crypto.PrivKey, store oplog.Logstore) *Book {
 	return &Book{pk: pk, store: store}
@@ -209,25 +225,78 @@ func (book *Book) DeleteAuthor() error {
 
 // save writes the book to book.fsLocation
 func (book *Book) save(ctx context.Context) (err error) {
+	if sl, ok := book.store.(oplog.SegmentedLogstore); ok && book.segmented && book.segments < MaxSegments {
+		ciphertext, err := sl.SegmentCipher(book.pk)
+		if err == nil {
+			if ciphertext == nil {
+				// nothing to save
+				return nil
+			}
+			path := segmentPath(book.fsLocation, book.segments+1)
+			got, err := book.fs.Put(ctx, qfs.NewMemfileBytes(path, ciphertext))
+			if err != nil {
+				return err
+			}
+			if got == path {
+				book.segments++
+				sl.Persisted()
+				return nil
+			}
+			// the filesystem doesn't honor paths, segments can't be found on load
+			book.segmented = false
+		} else if err != oplog.ErrRewriteRequired {
+			return err
+		}
+	}
+
+	return book.saveFull(ctx)
+}
+
+// saveFull writes the entire book to book.fsLocation, dropping any segments
+func (book *Book) saveFull(ctx context.Context) (err error) {
 	if al, ok := book.store.(oplog.AuthorLogstore); ok {
 		ciphertext, err := al.FlatbufferCipher(book.pk)
 		if err != nil {
 			return err
 		}
 
-		file := qfs.NewMemfileBytes(book.fsLocation, ciphertext)
-		book.fsLocation, err = book.fs.Put(ctx, file)
+		requested := book.fsLocation
+		file := qfs.NewMemfileBytes(requested, ciphertext)
+		if book.fsLocation, err = book.fs.Put(ctx, file); err != nil {
+			return err
+		}
+		book.segmented = book.fsLocation == requested
+
+		// segments are read until the first empty segment, blank out segments
+		// that are now part of the full file. the filesystem interface doesn't
+		// support deletion everywhere
+		for i := 1; i <= book.segments; i++ {
+			if _, err = book.fs.Put(ctx, qfs.NewMemfileBytes(segmentPath(requested, i), []byte{})); err != nil {
+				return err
+			}
+		}
+		book.segments = 0
+
+		if sl, ok := al.(oplog.SegmentedLogstore); ok {
+			sl.Persisted()
+		}
 	}
 	return err
 }
 
-// load reads the book dataset from book.fsLocation
+// segmentPath gives the location of the ith segment for a book stored at
+// location. segments are numbered from 1
+func segmentPath(location string, i int) string {
+	return fmt.Sprintf("%s.%d", location, i)
+}
+
+// load reads the book dataset from book.fsLocation, followed by any segments
 func (book *Book) load(ctx context.Context) error {
 	if al, ok := book.store.(oplog.AuthorLogstore); ok {
 
 		f, err := book.fs.Get(ctx, book.fsLocation)
 		if err != nil {
-			if strings.Contains(err.Error(), "not found") {
+			if errors.Is(err, qfs.ErrNotFound) {
 				return ErrNotFound
 			}
 			return err
@@ -242,11 +311,43 @@ func (book *Book) load(ctx context.Context) error {
 			return err
 		}
 
+		if sl, ok := al.(oplog.SegmentedLogstore); ok {
+			if err = book.loadSegments(ctx, sl); err != nil {
+				return err
+			}
+		}
+
 		book.authorID = al.ID()
 	}
 	return nil
 }
 
+// loadSegments applies segments in order, stopping at the first segment that
+// is missing or empty
+func (book *Book) loadSegments(ctx context.Context, sl oplog.SegmentedLogstore) error {
+	book.segmented = true
+	for i := 1; ; i++ {
+		f, err := book.fs.Get(ctx, segmentPath(book.fsLocation, i))
+		if err != nil {
+			if errors.Is(err, qfs.ErrNotFound) {
+				return nil
+			}
+			return err
+		}
+		ciphertext, err := ioutil.ReadAll(f)
+		if err != nil {
+			return err
+		}
+		if len(ciphertext) == 0 {
+			return nil
+		}
+		if err = sl.UnmarshalSegmentCipher(ctx, book.pk, ciphertext); err != nil {
+			return fmt.Errorf("logbook: reading segment %d: %w", i, err)
+		}
+		book.segments = i
+	}
+}
+
 // WriteAuthorRename adds an operation updating the author's username
 func (book *Book) WriteAuthorRename(ctx context.Context, newName string) error {
 	if book == nil {
@@ -416,16 +517,29 @@ func (book *Book) datasetLog(ctx context.Context, initID string) (*DatasetLog, e
 	return &DatasetLog{l: lg}, nil
 }
 
-// Return a strongly typed BranchLog
-func (book *Book) branchLog(ctx context.Context, initID string) (*BranchLog, error) {
+// Return a strongly typed BranchLog for a named branch. An empty branch name
+// returns the default branch
+func (book *Book) branchLog(ctx context.Context, initID, branch string) (*BranchLog, error) {
 	lg, err := book.store.Get(ctx, initID)
 	if err != nil {
 		return nil, err
 	}
-	if len(lg.Logs) != 1 {
-		return nil, fmt.Errorf("expected dataset to have 1 branch, has %d", len(lg.Logs))
+	br, err := lg.HeadRef(branchName(branch))
+	if err != nil {
+		if err == oplog.ErrNotFound {
+			return nil, fmt.Errorf("%w: branch %q", ErrNotFound, branchName(branch))
+		}
+		return nil, err
+	}
+	return &BranchLog{l: br}, nil
+}
+
+// branchName returns the default branch name for an empty string
+func branchName(name string) string {
+	if name == "" {
+		return DefaultBranchName
 	}
-	return &BranchLog{l: lg.Logs[0]}, nil
+	return name
 }
 
 // WriteDatasetDelete closes a dataset, marking it as deleted
@@ -455,15 +569,150 @@ func (book *Book) WriteDatasetDelete(ctx context.Context, initID string) error {
 	return book.save(ctx)
 }
 
+// WriteBranchInit creates a new named branch of dataset history. The new
+// branch starts with a copy of the commit history of the from branch, an empty
+// from value branches from the default branch
+func (book *Book) WriteBranchInit(ctx context.Context, initID, name, from string) error {
+	if book == nil {
+		return ErrNoLogbook
+	}
+	if err := dsref.EnsureValidBranch(name); err != nil {
+		return err
+	}
+	log.Debugf("WriteBranchInit: %s, branch: %s, from: %s", initID, name, branchName(from))
+
+	dsLog, err := book.store.Get(ctx, initID)
+	if err != nil {
+		return err
+	}
+	if _, err := dsLog.HeadRef(name); err == nil {
+		return fmt.Errorf("logbook: branch %q already exists", name)
+	}
+	src, err := book.branchLog(ctx, initID, from)
+	if err != nil {
+		return err
+	}
+
+	branch := oplog.InitLog(oplog.Op{
+		Type:      oplog.OpTypeInit,
+		Model:     BranchModel,
+		AuthorID:  book.AuthorID(),
+		Name:      name,
+		Relations: []string{branchName(from)},
+		Timestamp: NewTimestamp(),
+	})
+	blog := &BranchLog{l: branch}
+	for _, op := range src.Ops() {
+		if op.Model == CommitModel {
+			blog.Append(op)
+		}
+	}
+	dsLog.AddChild(branch)
+
+	if book.listener != nil {
+		act := &Action{
+			Type:   ActionBranchInit,
+			InitID: initID,
+			Branch: name,
+		}
+		if items := branchToLogItems(blog, dsref.Ref{}, 0, -1, true); len(items) > 0 {
+			act.TopIndex = len(items)
+			act.HeadRef = items[0].Path
+			act.Info = &items[0].VersionInfo
+		}
+		book.listener(act)
+	}
+
+	return book.save(ctx)
+}
+
+// WriteBranchDelete marks a named branch as removed. The default branch
+// cannot be deleted
+func (book *Book) WriteBranchDelete(ctx context.Context, initID, name string) error {
+	if book == nil {
+		return ErrNoLogbook
+	}
+	if branchName(name) == DefaultBranchName {
+		return fmt.Errorf("logbook: cannot delete the default branch")
+	}
+	log.Debugf("WriteBranchDelete: %s, branch: %s", initID, name)
+
+	blog, err := book.branchLog(ctx, initID, name)
+	if err != nil {
+		return err
+	}
+	blog.Append(oplog.Op{
+		Type:      oplog.OpTypeRemove,
+		Model:     BranchModel,
+		AuthorID:  book.AuthorID(),
+		Timestamp: NewTimestamp(),
+	})
+
+	if book.listener != nil {
+		book.listener(&Action{
+			Type:   ActionBranchDelete,
+			InitID: initID,
+			Branch: name,
+		})
+	}
+
+	return book.save(ctx)
+}
+
+// Branches lists the branches of a dataset that haven't been deleted, starting
+// with the default branch
+func (book Book) Branches(ctx context.Context, ref dsref.Ref) ([]BranchInfo, error) {
+	dsLog, err := book.DatasetRef(ctx, ref)
+	if err != nil {
+		if err == oplog.ErrNotFound {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	res := []BranchInfo{}
+	for _, l := range dsLog.Logs {
+		if l.Model() != BranchModel || l.Removed() {
+			continue
+		}
+		info := BranchInfo{
+			Name:    l.Name(),
+			Created: time.Unix(0, l.Ops[0].Timestamp),
+		}
+		if len(l.Ops[0].Relations) > 0 {
+			info.From = l.Ops[0].Relations[0]
+		}
+		items := branchToLogItems(branchLogFromRawLog(l), dsref.Ref{}, 0, -1, true)
+		info.NumVersions = len(items)
+		if len(items) > 0 {
+			info.Path = items[0].Path
+			info.CommitTime = items[0].CommitTime
+		}
+
+		if info.Name == DefaultBranchName {
+			res = append([]BranchInfo{info}, res...)
+		} else {
+			res = append(res, info)
+		}
+	}
+	return res, nil
+}
+
 // WriteVersionSave adds an operation to a log marking the creation of a
 // dataset version. Book will copy details from the provided dataset pointer
 func (book *Book) WriteVersionSave(ctx context.Context, initID string, ds *dataset.Dataset) error {
+	return book.WriteBranchVersionSave(ctx, initID, DefaultBranchName, ds)
+}
+
+// WriteBranchVersionSave adds an operation to the log of a named branch
+// marking the creation of a dataset version. Only the named branch advances
+func (book *Book) WriteBranchVersionSave(ctx context.Context, initID, branch string, ds *dataset.Dataset) error {
 	if book == nil {
 		return ErrNoLogbook
 	}
 
-	log.Debugf("WriteVersionSave: %s", initID)
-	branchLog, err := book.branchLog(ctx, initID)
+	log.Debugf("WriteBranchVersionSave: %s, branch: %s", initID, branchName(branch))
+	branchLog, err := book.branchLog(ctx, initID, branch)
 	if err != nil {
 		return err
 	}
@@ -481,6 +730,7 @@ func (book *Book) WriteVersionSave(ctx context.Context, initID string, ds *datas
 		book.listener(&Action{
 			Type:     ActionDatasetCommitChange,
 			InitID:   initID,
+			Branch:   branchName(branch),
 			TopIndex: topIndex,
 			HeadRef:  info.Path,
 			Info:     &info,
@@ -517,7 +767,7 @@ func (book *Book) WriteVersionAmend(ctx context.Context, initID string, ds *data
 	}
 	log.Debugf("WriteVersionAmend: '%s'", initID)
 
-	branchLog, err := book.branchLog(ctx, initID)
+	branchLog, err := book.branchLog(ctx, initID, DefaultBranchName)
 	if err != nil {
 		return err
 	}
@@ -539,12 +789,18 @@ func (book *Book) WriteVersionAmend(ctx context.Context, initID string, ds *data
 // versions from HEAD as deleted. Because logs are append-only, deletes are
 // recorded as "tombstone" operations that mark removal.
 func (book *Book) WriteVersionDelete(ctx context.Context, initID string, revisions int) error {
+	return book.WriteBranchVersionDelete(ctx, initID, DefaultBranchName, revisions)
+}
+
+// WriteBranchVersionDelete adds an operation to the log of a named branch
+// marking a number of sequential versions from the branch HEAD as deleted
+func (book *Book) WriteBranchVersionDelete(ctx context.Context, initID, branch string, revisions int) error {
 	if book == nil {
 		return ErrNoLogbook
 	}
-	log.Debugf("WriteVersionDelete: %s, revisions: %d", initID, revisions)
+	log.Debugf("WriteBranchVersionDelete: %s, branch: %s, revisions: %d", initID, branchName(branch), revisions)
 
-	branchLog, err := book.branchLog(ctx, initID)
+	branchLog, err := book.branchLog(ctx, initID, branch)
 	if err != nil {
 		return err
 	}
@@ -565,6 +821,7 @@ func (book *Book) WriteVersionDelete(ctx context.Context, initID string, revisio
 			book.listener(&Action{
 				Type:     ActionDatasetCommitChange,
 				InitID:   initID,
+				Branch:   branchName(branch),
 				TopIndex: len(items),
 				HeadRef:  lastItem.Path,
 				Info:     &lastItem.VersionInfo,
@@ -583,7 +840,7 @@ func (book *Book) WritePublish(ctx context.Context, initID string, revisions int
 	}
 	log.Debugf("WritePublish: %s, revisions: %d, destinations: %v", initID, revisions, destinations)
 
-	branchLog, err := book.branchLog(ctx, initID)
+	branchLog, err := book.branchLog(ctx, initID, DefaultBranchName)
 	if err != nil {
 		return err
 	}
@@ -608,7 +865,7 @@ func (book *Book) WriteUnpublish(ctx context.Context, initID string, revisions i
 	}
 	log.Debugf("WriteUnpublish: %s, revisions: %d, destinations: %v", initID, revisions, destinations)
 
-	branchLog, err := book.branchLog(ctx, initID)
+	branchLog, err := book.branchLog(ctx, initID, DefaultBranchName)
 	if err != nil {
 		return err
 	}
@@ -674,7 +931,7 @@ func (book Book) UserDatasetRef(ctx context.Context, ref dsref.Ref) (*oplog.Log,
 	ds.AddChild(br)
 
 	// construct a sparse oplog of just user, dataset, and branches
-	sparseLog := &oplog.Log{Ops: author.Ops}
+	sparseLog := &oplog.Log{Ops: author.Ops, Signature: author.Signature}
 	sparseLog.AddChild(ds)
 	return sparseLog, nil
 }
@@ -683,9 +940,6 @@ func (book Book) UserDatasetRef(ctx context.Context, ref dsref.Ref) (*oplog.Log,
 // activity affecting an entire dataset. Things like dataset name changes and
 // access control changes are kept in the dataset log
 //
-// currently all logs are hardcoded to only accept one branch name. This
-// function will always return a single branch
-//
 // TODO(dustmop): Do not add new callers to this, transition away (preferring datasetLog instead),
 // and delete it.
 func (book Book) DatasetRef(ctx context.Context, ref dsref.Ref) (*oplog.Log, error) {
@@ -700,10 +954,8 @@ func (book Book) DatasetRef(ctx context.Context, ref dsref.Ref) (*oplog.Log, err
 }
 
 // BranchRef gets a branch log for a dataset reference. Branch logs describe
-// a line of commits
-//
-// currently all logs are hardcoded to only accept one branch name. This
-// function always returns
+// a line of commits. References without a branch name return the default
+// branch
 //
 // TODO(dustmop): Do not add new callers to this, transition away (preferring branchLog instead),
 // and delete it.
@@ -715,15 +967,43 @@ func (book Book) BranchRef(ctx context.Context, ref dsref.Ref) (*oplog.Log, erro
 		return nil, fmt.Errorf("logbook: ref.Name is required")
 	}
 
-	return book.store.HeadRef(ctx, ref.Username, ref.Name, DefaultBranchName)
+	return book.store.HeadRef(ctx, ref.Username, ref.Name, branchName(ref.Branch))
 }
 
-// LogBytes signs a log with this book's private key and writes to a flatbuffer
+// LogBytes writes a log to a flatbuffer. Logs authored by this book are signed
+// with this book's private key, logs from other authors keep the signatures
+// they arrived with so receivers can verify them against the original author
 func (book Book) LogBytes(log *oplog.Log) ([]byte, error) {
-	if err := log.Sign(book.pk); err != nil {
+	keyID, err := identity.KeyIDFromPriv(book.pk)
+	if err != nil {
 		return nil, err
 	}
-	return log.FlatbufferBytes(), nil
+	if len(log.Ops) > 0 && log.Ops[0].AuthorID != keyID {
+		return log.FlatbufferBytes(), nil
+	}
+
+	signed, err := signedLog(log, book.pk)
+	if err != nil {
+		return nil, err
+	}
+	return signed.FlatbufferBytes(), nil
+}
+
+// signedLog copies a log tree, signing every log in the copy with a private
+// key. The passed-in log is left untouched
+func signedLog(lg *oplog.Log, pk crypto.PrivKey) (*oplog.Log, error) {
+	cp := &oplog.Log{Ops: lg.Ops}
+	if err := cp.Sign(pk); err != nil {
+		return nil, err
+	}
+	for _, l := range lg.Logs {
+		child, err := signedLog(l, pk)
+		if err != nil {
+			return nil, err
+		}
+		cp.AddChild(child)
+	}
+	return cp, nil
 }
 
 // DsrefAliasForLog parses log data into a dataset alias reference, populating
@@ -750,16 +1030,43 @@ func DsrefAliasForLog(log *oplog.Log) (dsref.Ref, error) {
 	return ref, nil
 }
 
-// MergeLog adds a log to the logbook, merging with any existing log data
+// SetKeyResolver sets the resolver book uses to find the public keys of
+// authors other than the sender & the book's own author when merging logs
+func (book *Book) SetKeyResolver(keys KeyResolver) {
+	if book == nil {
+		return
+	}
+	book.keys = keys
+}
+
+// SetStrict configures whether book rejects merged logs from authors it
+// can't resolve a public key for. Books aren't strict by default, which lets
+// remotes forward logs of authors book hasn't connected to
+func (book *Book) SetStrict(strict bool) {
+	if book == nil {
+		return
+	}
+	book.strict = strict
+}
+
+// MergeLog adds a log to the logbook, merging with any existing log data.
+// Logs must be signed by the author they claim if book can resolve the
+// author's public key. Logs forwarded on behalf of other authors keep their
+// author's signatures, strict books reject them if the author's key can't be
+// resolved
 func (book *Book) MergeLog(ctx context.Context, sender identity.Author, lg *oplog.Log) error {
 	if book == nil {
 		return ErrNoLogbook
 	}
 	// eventually access control will dictate which logs can be written by whom.
-	// For now we only allow users to merge logs they've written
-	// book will need access to a store of public keys before we can verify
-	// signatures non-same-senders
-	if err := lg.Verify(sender.AuthorPubKey()); err != nil {
+	// For now logs must carry a valid signature from any author book knows
+	pub, err := book.authorKey(sender, lg)
+	if err != nil {
+		if !errors.Is(err, ErrUnverifiedAuthor) || book.strict {
+			return err
+		}
+		log.Debugf("merging log of unverified author %q", lg.Ops[0].AuthorID)
+	} else if err := lg.Verify(pub); err != nil {
 		return err
 	}
 
@@ -774,6 +1081,40 @@ func (book *Book) MergeLog(ctx context.Context, sender identity.Author, lg *oplo
 	return book.save(ctx)
 }
 
+// authorKey resolves the public key of the author a log claims, checking the
+// sender, this book's author, and the book's key resolver in that order.
+// Resolved keys must hash to the claimed author key ID
+func (book *Book) authorKey(sender identity.Author, lg *oplog.Log) (crypto.PubKey, error) {
+	if len(lg.Ops) == 0 || lg.Ops[0].AuthorID == "" {
+		return sender.AuthorPubKey(), nil
+	}
+	keyID := lg.Ops[0].AuthorID
+
+	if id, err := identity.KeyIDFromPub(sender.AuthorPubKey()); err == nil && id == keyID {
+		return sender.AuthorPubKey(), nil
+	}
+	return book.AuthorKey(keyID)
+}
+
+// AuthorKey returns a trusted public key for an author key ID, either the
+// book's own key or a key from the book's key resolver that hashes to keyID
+func (book *Book) AuthorKey(keyID string) (crypto.PubKey, error) {
+	if book == nil {
+		return nil, ErrNoLogbook
+	}
+	if id, err := identity.KeyIDFromPub(book.AuthorPubKey()); err == nil && id == keyID {
+		return book.AuthorPubKey(), nil
+	}
+	if book.keys != nil {
+		if pub, err := book.keys(keyID); err == nil {
+			if id, err := identity.KeyIDFromPub(pub); err == nil && id == keyID {
+				return pub, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("%w: no public key to verify log authored by %q", ErrUnverifiedAuthor, keyID)
+}
+
 // RemoveLog removes an entire log from a logbook
 func (book *Book) RemoveLog(ctx context.Context, sender identity.Author, ref dsref.Ref) error {
 	if book == nil {
@@ -822,7 +1163,8 @@ func dsRefToLogPath(ref dsref.Ref) (path []string) {
 
 // ConstructDatasetLog creates a sparse log from a connected dataset history
 // where no prior log exists
-// the given history MUST be ordered from oldest to newest commits
+// the given history MUST be ordered from oldest to newest commits. History is
+// written to the branch ref names, creating the branch if it isn't the default
 // TODO (b5) - this presently only works for datasets in an author's user
 // namespace
 func (book *Book) ConstructDatasetLog(ctx context.Context, ref dsref.Ref, history []*dataset.Dataset) error {
@@ -840,7 +1182,12 @@ func (book *Book) ConstructDatasetLog(ctx context.Context, ref dsref.Ref, histor
 	if err != nil {
 		return err
 	}
-	branchLog, err := book.branchLog(ctx, initID)
+	if branchName(ref.Branch) != DefaultBranchName {
+		if err := book.WriteBranchInit(ctx, initID, ref.Branch, DefaultBranchName); err != nil {
+			return err
+		}
+	}
+	branchLog, err := book.branchLog(ctx, initID, ref.Branch)
 	if err != nil {
 		return err
 	}
@@ -870,7 +1217,7 @@ func (book Book) Items(ctx context.Context, ref dsref.Ref, offset, limit int) ([
 	if err != nil {
 		return nil, err
 	}
-	branchLog, err := book.branchLog(ctx, initID)
+	branchLog, err := book.branchLog(ctx, initID, ref.Branch)
 	if err != nil {
 		return nil, err
 	}
@@ -1049,6 +1396,22 @@ func NewPlainLog(lg *oplog.Log) PlainLog {
 	}
 }
 
+// BranchInfo describes a branch of dataset history
+type BranchInfo struct {
+	// Name of the branch
+	Name string `json:"name"`
+	// Branch this branch was created from. Empty for the default branch
+	From string `json:"from,omitempty"`
+	// Path of the most recent version on this branch
+	Path string `json:"path,omitempty"`
+	// Number of versions in the branch history
+	NumVersions int `json:"numVersions"`
+	// Timestamp of the most recent version on this branch
+	CommitTime time.Time `json:"commitTime,omitempty"`
+	// When the branch was created
+	Created time.Time `json:"created"`
+}
+
 // DatasetLogItem is a line item in a dataset response
 type DatasetLogItem struct {
 	// Decription of a dataset reference
@@ -2,12 +2,15 @@ package logbook
 
 import (
 	"context"
+	"crypto/rand"
 	"encoding/base64"
+	"errors"
 	"fmt"
 	"testing"
 	"time"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
 	crypto "github.com/libp2p/go-libp2p-core/crypto"
 	"github.com/qri-io/dataset"
 	"github.com/qri-io/qfs"
@@ -596,6 +599,84 @@ func TestLogTransfer(t *testing.T) {
 	}
 }
 
+func TestMergeForeignAuthorLog(t *testing.T) {
+	tr, cleanup := newTestRunner(t)
+	defer cleanup()
+
+	tr.WriteWorldBankExample(t)
+	authored := func() *oplog.Log {
+		lg, err := tr.Book.UserDatasetRef(tr.Ctx, tr.WorldBankRef())
+		if err != nil {
+			t.Fatal(err)
+		}
+		return lg
+	}
+
+	// sender forwards logs it didn't author
+	sender, err := NewJournal(testPrivKey2(t), "user2", qfs.NewMemFS(), "/mem/sender")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pk3, _, err := crypto.GenerateEd25519Key(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	receiver, err := NewJournal(pk3, "user3", qfs.NewMemFS(), "/mem/receiver")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// books merge forwarded logs of authors they can't resolve a key for
+	// unless they're strict
+	lenient, err := NewJournal(pk3, "user3", qfs.NewMemFS(), "/mem/lenient")
+	if err != nil {
+		t.Fatal(err)
+	}
+	forwarded := authored()
+	if err := forwarded.Sign(tr.Book.pk); err != nil {
+		t.Fatal(err)
+	}
+	if err := lenient.MergeLog(tr.Ctx, sender.Author(), forwarded); err != nil {
+		t.Errorf("expected a book that isn't strict to merge a forwarded log, got: %s", err)
+	}
+
+	receiver.SetStrict(true)
+
+	// a log claiming tr.Book as author, signed by the sender
+	forged := authored()
+	if err := forged.Sign(testPrivKey2(t)); err != nil {
+		t.Fatal(err)
+	}
+	if err := receiver.MergeLog(tr.Ctx, sender.Author(), forged); !errors.Is(err, ErrUnverifiedAuthor) {
+		t.Errorf("expected merging a log from an unknown author to fail with ErrUnverifiedAuthor, got: %v", err)
+	}
+
+	authorKeys := func(keyID string) (crypto.PubKey, error) {
+		return tr.Book.AuthorPubKey(), nil
+	}
+	receiver.SetKeyResolver(authorKeys)
+	if err := receiver.MergeLog(tr.Ctx, sender.Author(), forged); err == nil {
+		t.Error("expected merging a log with a forged author to fail")
+	}
+
+	// resolved keys must match the claimed author
+	receiver.SetKeyResolver(func(keyID string) (crypto.PubKey, error) {
+		return testPrivKey2(t).GetPublic(), nil
+	})
+	if err := receiver.MergeLog(tr.Ctx, sender.Author(), forged); !errors.Is(err, ErrUnverifiedAuthor) {
+		t.Errorf("expected a resolved key that doesn't match the author to fail with ErrUnverifiedAuthor, got: %v", err)
+	}
+
+	signed := authored()
+	if err := signed.Sign(tr.Book.pk); err != nil {
+		t.Fatal(err)
+	}
+	receiver.SetKeyResolver(authorKeys)
+	if err := receiver.MergeLog(tr.Ctx, sender.Author(), signed); err != nil {
+		t.Errorf("expected forwarded log signed by its author to merge, got: %s", err)
+	}
+}
+
 func TestRenameAuthor(t *testing.T) {
 	tr, cleanup := newTestRunner(t)
 	defer cleanup()
@@ -731,6 +812,137 @@ func TestItems(t *testing.T) {
 	}
 }
 
+func TestBranches(t *testing.T) {
+	tr, cleanup := newTestRunner(t)
+	defer cleanup()
+
+	initID := tr.WriteWorldBankExample(t)
+	book := tr.Book
+
+	if err := book.WriteBranchInit(tr.Ctx, initID, "experimental", ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := book.WriteBranchInit(tr.Ctx, initID, "experimental", ""); err == nil {
+		t.Error("expected creating a duplicate branch to error")
+	}
+	if err := book.WriteBranchInit(tr.Ctx, initID, "_invalid", ""); err == nil {
+		t.Error("expected creating a branch with an invalid name to error")
+	}
+
+	// saves to the experimental branch shouldn't advance main
+	ds := &dataset.Dataset{
+		Peername: tr.Username,
+		Name:     "world_bank_population",
+		Commit: &dataset.Commit{
+			Timestamp: time.Date(2000, time.January, 4, 0, 0, 0, 0, time.UTC),
+			Title:     "experiment",
+		},
+		Path:         "QmHashOfExperiment",
+		PreviousPath: "QmHashOfVersion3",
+	}
+	if err := book.WriteBranchVersionSave(tr.Ctx, initID, "experimental", ds); err != nil {
+		t.Fatal(err)
+	}
+	tr.WriteMoreWorldBankCommits(t, initID)
+
+	ref := tr.WorldBankRef()
+	ref.Branch = "experimental"
+	items, err := book.Items(tr.Ctx, ref, 0, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gotPaths := []string{}
+	for _, item := range items {
+		gotPaths = append(gotPaths, item.Path)
+	}
+	expectPaths := []string{"QmHashOfExperiment", "QmHashOfVersion3"}
+	if diff := cmp.Diff(expectPaths, gotPaths); diff != "" {
+		t.Errorf("branch items mismatch (-want +got):\n%s", diff)
+	}
+
+	branches, err := book.Branches(tr.Ctx, tr.WorldBankRef())
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := []BranchInfo{
+		{Name: DefaultBranchName, Path: "QmHashOfVersion5", NumVersions: 3},
+		{Name: "experimental", From: DefaultBranchName, Path: "QmHashOfExperiment", NumVersions: 2},
+	}
+	if diff := cmp.Diff(expect, branches, cmpopts.IgnoreFields(BranchInfo{}, "CommitTime", "Created")); diff != "" {
+		t.Errorf("branches mismatch (-want +got):\n%s", diff)
+	}
+
+	if err := book.WriteBranchDelete(tr.Ctx, initID, DefaultBranchName); err == nil {
+		t.Error("expected deleting the default branch to error")
+	}
+	if err := book.WriteBranchDelete(tr.Ctx, initID, "experimental"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := book.Items(tr.Ctx, ref, 0, -1); err == nil {
+		t.Error("expected reading a deleted branch to error")
+	}
+	if branches, err = book.Branches(tr.Ctx, tr.WorldBankRef()); err != nil {
+		t.Fatal(err)
+	}
+	if len(branches) != 1 {
+		t.Errorf("expected 1 branch after delete, got %d", len(branches))
+	}
+
+	// branch names can be reused after deletion
+	if err := book.WriteBranchInit(tr.Ctx, initID, "experimental", ""); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestBranchVersionOps(t *testing.T) {
+	tr, cleanup := newTestRunner(t)
+	defer cleanup()
+
+	initID := tr.WriteWorldBankExample(t)
+	book := tr.Book
+
+	if err := book.WriteBranchInit(tr.Ctx, initID, "experimental", ""); err != nil {
+		t.Fatal(err)
+	}
+	ds := &dataset.Dataset{
+		Peername: tr.Username,
+		Name:     "world_bank_population",
+		Commit: &dataset.Commit{
+			Timestamp: time.Date(2000, time.January, 4, 0, 0, 0, 0, time.UTC),
+			Title:     "experiment",
+		},
+		Path:         "QmHashOfExperiment",
+		PreviousPath: "QmHashOfVersion3",
+	}
+	if err := book.WriteBranchVersionSave(tr.Ctx, initID, "experimental", ds); err != nil {
+		t.Fatal(err)
+	}
+	if err := book.WriteBranchVersionDelete(tr.Ctx, initID, "experimental", 1); err != nil {
+		t.Fatal(err)
+	}
+
+	paths := func(branch string) []string {
+		ref := tr.WorldBankRef()
+		ref.Branch = branch
+		items, err := book.Items(tr.Ctx, ref, 0, -1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res := []string{}
+		for _, item := range items {
+			res = append(res, item.Path)
+		}
+		return res
+	}
+
+	if diff := cmp.Diff([]string{"QmHashOfVersion3"}, paths(DefaultBranchName)); diff != "" {
+		t.Errorf("deleting a branch version changed the default branch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{"QmHashOfVersion3"}, paths("experimental")); diff != "" {
+		t.Errorf("branch items mismatch (-want +got):\n%s", diff)
+	}
+}
+
 func TestConstructDatasetLog(t *testing.T) {
 	tr, cleanup := newTestRunner(t)
 	defer cleanup()
@@ -789,6 +1001,25 @@ func TestConstructDatasetLog(t *testing.T) {
 	if len(items) != 3 {
 		t.Errorf("expected 3 dslog items to return from history. got: %d", len(items))
 	}
+
+	// constructing a log for a branch writes history to that branch
+	branchRef := dsref.Ref{Username: tr.Username, Name: "to_reconstruct_branch", Branch: "imported"}
+	if err := book.ConstructDatasetLog(tr.Ctx, branchRef, history); err != nil {
+		t.Fatal(err)
+	}
+	if items, err = book.Items(tr.Ctx, branchRef, 0, 100); err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 3 {
+		t.Errorf("expected 3 branch items, got: %d", len(items))
+	}
+	branchRef.Branch = ""
+	if items, err = book.Items(tr.Ctx, branchRef, 0, 100); err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 0 {
+		t.Errorf("expected default branch to be empty, got %d items", len(items))
+	}
 }
 
 func mustTime(str string) time.Time {
@@ -0,0 +1,380 @@
+package logsync
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/qri-io/qri/dsref"
+	"github.com/qri-io/qri/identity"
+	"github.com/qri-io/qri/logbook"
+	"github.com/qri-io/qri/logbook/oplog"
+)
+
+// maxBatchLogSize caps the size of a single log read from a batch stream
+const maxBatchLogSize = 1 << 26
+
+// Summary describes the dataset logs a logbook holds for a user, mapping the
+// ID of each dataset log to the hashes of the head operations of the dataset
+// log and all of its branch logs. Comparing summaries shows which logs differ
+// between two logbooks without sending the logs themselves
+type Summary map[string][]string
+
+// Differs returns true if the heads of a dataset log don't match the summary
+func (s Summary) Differs(id string, heads []string) bool {
+	have, ok := s[id]
+	if !ok || len(have) != len(heads) {
+		return true
+	}
+	for i, h := range heads {
+		if have[i] != h {
+			return true
+		}
+	}
+	return false
+}
+
+// Summarize creates a summary of all dataset logs in a logbook for a username.
+// Summaries of users the logbook has no record of are empty
+func Summarize(ctx context.Context, book *logbook.Book, username string) (Summary, error) {
+	user, err := userLog(ctx, book, username)
+	if err == logbook.ErrNotFound {
+		return Summary{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	s := Summary{}
+	for _, ds := range user.Logs {
+		s[ds.ID()] = headHashes(ds)
+	}
+	return s, nil
+}
+
+// headHashes lists the hashes of the head operations of a log and all of its
+// descendants, sorted so results don't depend on the order logs were merged
+func headHashes(lg *oplog.Log) []string {
+	hashes := []string{lg.Head().Hash()}
+	for _, l := range lg.Logs {
+		hashes = append(hashes, headHashes(l)...)
+	}
+	sort.Strings(hashes)
+	return hashes
+}
+
+// userLog finds the author log for a username. Like HeadRef lookups, logs
+// that have been removed are skipped
+func userLog(ctx context.Context, book *logbook.Book, username string) (*oplog.Log, error) {
+	if username == "" {
+		return nil, fmt.Errorf("logsync: username is required")
+	}
+	logs, err := book.ListAllLogs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, l := range logs {
+		if len(l.Ops) > 0 && l.Model() == logbook.AuthorModel && l.Name() == username && !l.Removed() {
+			return l, nil
+		}
+	}
+	return nil, logbook.ErrNotFound
+}
+
+// sparseDatasetLog constructs a user log with a single dataset log containing
+// all branches, the same shape Book.UserDatasetRef returns. The dataset log is
+// copied so the logbook's log tree isn't modified
+func sparseDatasetLog(user, ds *oplog.Log) *oplog.Log {
+	sparse := &oplog.Log{Ops: user.Ops, Signature: user.Signature}
+	sparse.AddChild(ds.DeepCopy())
+	return sparse
+}
+
+// writeLogBatch writes a sequence of length-prefixed log flatbuffers
+func writeLogBatch(w io.Writer, logs [][]byte) error {
+	prefix := make([]byte, binary.MaxVarintLen64)
+	for _, data := range logs {
+		n := binary.PutUvarint(prefix, uint64(len(data)))
+		if _, err := w.Write(prefix[:n]); err != nil {
+			return err
+		}
+		if _, err := w.Write(data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readLogBatch reads a stream written by writeLogBatch
+func readLogBatch(r io.Reader) ([]*oplog.Log, error) {
+	br := bufio.NewReader(r)
+	logs := []*oplog.Log{}
+	for {
+		size, err := binary.ReadUvarint(br)
+		if err == io.EOF {
+			return logs, nil
+		} else if err != nil {
+			return nil, err
+		}
+		if size > maxBatchLogSize {
+			return nil, fmt.Errorf("logsync: batch log of %d bytes exceeds maximum size", size)
+		}
+
+		data := make([]byte, size)
+		if _, err := io.ReadFull(br, data); err != nil {
+			return nil, err
+		}
+		lg := &oplog.Log{}
+		if err := lg.UnmarshalFlatbufferBytes(data); err != nil {
+			return nil, err
+		}
+		logs = append(logs, lg)
+	}
+}
+
+// NewBatchPush prepares a BatchPush from the local logsync to a remote
+// destination, placing all of a user's dataset logs that differ from the
+// remote on the remote
+func (lsync *Logsync) NewBatchPush(username, remoteAddr string) (*BatchPush, error) {
+	if lsync == nil {
+		return nil, ErrNoLogsync
+	}
+
+	rem, err := lsync.remoteClient(context.TODO(), remoteAddr)
+	if err != nil {
+		return nil, err
+	}
+
+	return &BatchPush{
+		book:     lsync.book,
+		remote:   rem,
+		username: username,
+	}, nil
+}
+
+// NewBatchPull prepares a BatchPull from the local logsync to a remote
+// destination, fetching all of a user's dataset logs that differ from the
+// local logbook
+func (lsync *Logsync) NewBatchPull(username, remoteAddr string) (*BatchPull, error) {
+	if lsync == nil {
+		return nil, ErrNoLogsync
+	}
+
+	rem, err := lsync.remoteClient(context.TODO(), remoteAddr)
+	if err != nil {
+		return nil, err
+	}
+
+	return &BatchPull{
+		book:         lsync.book,
+		remote:       rem,
+		username:     username,
+		strictVerify: lsync.strictVerify,
+	}, nil
+}
+
+// summary is the remote-side summary of a user's logs, leaving out datasets
+// the pull pre-check rejects for author
+func (lsync *Logsync) summary(ctx context.Context, author identity.Author, username string) (Summary, error) {
+	if lsync == nil {
+		return nil, ErrNoLogsync
+	}
+
+	user, dsLogs, err := lsync.readableDatasetLogs(ctx, author, username)
+	if err == logbook.ErrNotFound {
+		return Summary{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	s := Summary{}
+	for _, ds := range dsLogs {
+		s[ds.ID()] = headHashes(ds)
+	}
+	logger.Debugf("summarized %d of %d logs for %s", len(s), len(user.Logs), username)
+	return s, nil
+}
+
+// getBatch writes all logs for a user that differ from a summary to a single
+// stream
+func (lsync *Logsync) getBatch(ctx context.Context, author identity.Author, username string, have Summary) (identity.Author, io.Reader, error) {
+	if lsync == nil {
+		return nil, nil, ErrNoLogsync
+	}
+
+	user, dsLogs, err := lsync.readableDatasetLogs(ctx, author, username)
+	if err != nil {
+		return lsync.Author(), nil, err
+	}
+
+	batch := [][]byte{}
+	for _, ds := range dsLogs {
+		if !have.Differs(ds.ID(), headHashes(ds)) {
+			continue
+		}
+
+		sparse := sparseDatasetLog(user, ds)
+		data, err := lsync.book.LogBytes(sparse)
+		if err != nil {
+			return nil, nil, err
+		}
+		batch = append(batch, data)
+
+		if lsync.pulled != nil {
+			ref := dsref.Ref{Username: username, Name: ds.Name()}
+			if err := lsync.pulled(ctx, author, ref, sparse); err != nil {
+				logger.Errorf("pulled hook: %s", err)
+			}
+		}
+	}
+
+	buf := &bytes.Buffer{}
+	if err := writeLogBatch(buf, batch); err != nil {
+		return nil, nil, err
+	}
+	return lsync.Author(), buf, nil
+}
+
+// readableDatasetLogs fetches the user log for a username along with the
+// dataset logs the pull pre-check accepts for author
+func (lsync *Logsync) readableDatasetLogs(ctx context.Context, author identity.Author, username string) (user *oplog.Log, dsLogs []*oplog.Log, err error) {
+	if user, err = userLog(ctx, lsync.book, username); err != nil {
+		return nil, nil, err
+	}
+
+	for _, ds := range user.Logs {
+		if lsync.pullPreCheck != nil {
+			ref := dsref.Ref{Username: username, Name: ds.Name()}
+			if err := lsync.pullPreCheck(ctx, author, ref, nil); err != nil {
+				logger.Debugf("skipping %s: %s", ref, err)
+				continue
+			}
+		}
+		dsLogs = append(dsLogs, ds)
+	}
+	return user, dsLogs, nil
+}
+
+// putBatch merges a stream of logs into the logbook
+func (lsync *Logsync) putBatch(ctx context.Context, author identity.Author, r io.Reader) error {
+	if lsync == nil {
+		return ErrNoLogsync
+	}
+
+	if lsync.pushPreCheck != nil {
+		if err := lsync.pushPreCheck(ctx, author, dsref.Ref{}, nil); err != nil {
+			return err
+		}
+	}
+
+	logs, err := readLogBatch(r)
+	if err != nil {
+		return err
+	}
+	for _, lg := range logs {
+		if err := lsync.merge(ctx, author, lg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// BatchPush is a request to place all of a user's logs on a remote
+type BatchPush struct {
+	book     *logbook.Book
+	remote   remote
+	username string
+}
+
+// Do executes the push, returning references to the datasets that were sent.
+// Only datasets with logs that differ from the remote's are sent
+func (p *BatchPush) Do(ctx context.Context) ([]dsref.Ref, error) {
+	theirs, err := p.remote.summary(ctx, p.book.Author(), p.username)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := userLog(ctx, p.book, p.username)
+	if err != nil {
+		return nil, err
+	}
+
+	refs := []dsref.Ref{}
+	batch := [][]byte{}
+	for _, ds := range user.Logs {
+		if !theirs.Differs(ds.ID(), headHashes(ds)) {
+			continue
+		}
+		data, err := p.book.LogBytes(sparseDatasetLog(user, ds))
+		if err != nil {
+			return nil, err
+		}
+		batch = append(batch, data)
+		refs = append(refs, dsref.Ref{Username: p.username, Name: ds.Name()})
+	}
+
+	if len(batch) == 0 {
+		return refs, nil
+	}
+
+	buf := &bytes.Buffer{}
+	if err := writeLogBatch(buf, batch); err != nil {
+		return nil, err
+	}
+	if err := p.remote.putBatch(ctx, p.book.Author(), buf); err != nil {
+		return nil, err
+	}
+	return refs, nil
+}
+
+// BatchPull is a request to fetch all of a user's logs from a remote
+type BatchPull struct {
+	book         *logbook.Book
+	remote       remote
+	username     string
+	strictVerify bool
+
+	// set to true to merge these logs into the local store on successful pull
+	Merge bool
+}
+
+// Do executes the pull, returning the logs that differ from the local
+// logbook. Each returned log is a user log with a single dataset log
+func (p *BatchPull) Do(ctx context.Context) ([]*oplog.Log, error) {
+	have, err := Summarize(ctx, p.book, p.username)
+	if err != nil {
+		return nil, err
+	}
+
+	sender, r, err := p.remote.getBatch(ctx, p.book.Author(), p.username, have)
+	if err != nil {
+		return nil, err
+	}
+	logs, err := readLogBatch(r)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, lg := range logs {
+		if p.strictVerify {
+			if err := verifyReceived(p.book, sender, lg); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if p.Merge {
+		for _, lg := range logs {
+			// merging can adopt a log into the logbook's tree, merge copies so
+			// logs from the same user don't accumulate each other's datasets
+			if err := p.book.MergeLog(ctx, sender, lg.DeepCopy()); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return logs, nil
+}
@@ -0,0 +1,268 @@
+package logsync
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/qri/dsref"
+	"github.com/qri-io/qri/identity"
+	"github.com/qri-io/qri/logbook"
+	"github.com/qri-io/qri/logbook/oplog"
+)
+
+func TestBatchSyncHTTP(t *testing.T) {
+	tr, cleanup := newTestRunner(t)
+	defer cleanup()
+
+	pulled := 0
+	a := New(tr.A, func(o *Options) {
+		o.Pulled = func(ctx context.Context, author identity.Author, ref dsref.Ref, l *oplog.Log) error {
+			pulled++
+			return nil
+		}
+	})
+	b := New(tr.B)
+
+	server := httptest.NewServer(HTTPHandler(a))
+	defer server.Close()
+
+	nasdaqRef, err := writeNasdaqLogs(tr.Ctx, tr.A)
+	if err != nil {
+		t.Fatal(err)
+	}
+	worldBankRef, err := writeWorldBankLogs(tr.Ctx, tr.A)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pull, err := b.NewBatchPull("a", server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pull.Merge = true
+
+	logs, err := pull.Do(tr.Ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(logs) != 2 || pulled != 2 {
+		t.Errorf("expected first pull to send 2 logs. got: %d, pulled hook calls: %d", len(logs), pulled)
+	}
+
+	for _, ref := range []dsref.Ref{nasdaqRef, worldBankRef} {
+		expect, err := tr.A.Items(tr.Ctx, ref, 0, 100)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := tr.B.Items(tr.Ctx, ref, 0, 100)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(expect, got); diff != "" {
+			t.Errorf("%s result mismatch. (-want +got):\n%s", ref, diff)
+		}
+	}
+
+	// pulling again moves nothing
+	pulled = 0
+	if logs, err = pull.Do(tr.Ctx); err != nil {
+		t.Fatal(err)
+	}
+	if len(logs) != 0 || pulled != 0 {
+		t.Errorf("expected pull of synced logs to send nothing. got: %d logs", len(logs))
+	}
+
+	// a new version on A only sends the changed log
+	if err := writeVersion(tr.Ctx, tr.A, nasdaqRef, "v2", "v1"); err != nil {
+		t.Fatal(err)
+	}
+	if logs, err = pull.Do(tr.Ctx); err != nil {
+		t.Fatal(err)
+	}
+	if len(logs) != 1 {
+		t.Fatalf("expected pull to send 1 changed log. got: %d", len(logs))
+	}
+	if name := logs[0].Logs[0].Name(); name != nasdaqRef.Name {
+		t.Errorf("expected changed log to be %q. got: %q", nasdaqRef.Name, name)
+	}
+	items, err := tr.B.Items(tr.Ctx, nasdaqRef, 0, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 3 {
+		t.Errorf("expected B to have 3 versions of %s. got: %d", nasdaqRef, len(items))
+	}
+
+	if _, err := b.NewBatchPull("a", ""); err == nil {
+		t.Errorf("expected invalid remote address to error")
+	}
+	missing, err := b.NewBatchPull("missing_user", server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := missing.Do(tr.Ctx); err == nil {
+		t.Errorf("expected pulling an unknown user to error")
+	}
+}
+
+func TestBatchPushHTTP(t *testing.T) {
+	tr, cleanup := newTestRunner(t)
+	defer cleanup()
+
+	a, b := tr.DefaultLogsyncs()
+	server := httptest.NewServer(HTTPHandler(a))
+	defer server.Close()
+
+	worldBankRef, err := writeWorldBankLogs(tr.Ctx, tr.B)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := writeNasdaqLogs(tr.Ctx, tr.B); err != nil {
+		t.Fatal(err)
+	}
+
+	push, err := b.NewBatchPush("b", server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	refs, err := push.Do(tr.Ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(refs) != 2 {
+		t.Errorf("expected first push to send 2 logs. got: %d", len(refs))
+	}
+
+	expect, err := tr.B.Items(tr.Ctx, worldBankRef, 0, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := tr.A.Items(tr.Ctx, worldBankRef, 0, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(expect, got); diff != "" {
+		t.Errorf("result mismatch. (-want +got):\n%s", diff)
+	}
+
+	if refs, err = push.Do(tr.Ctx); err != nil {
+		t.Fatal(err)
+	}
+	if len(refs) != 0 {
+		t.Errorf("expected push of synced logs to send nothing. got: %v", refs)
+	}
+}
+
+func TestBatchHookErrors(t *testing.T) {
+	tr, cleanup := newTestRunner(t)
+	defer cleanup()
+
+	nasdaqRef, err := writeNasdaqLogs(tr.Ctx, tr.A)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := writeWorldBankLogs(tr.Ctx, tr.A); err != nil {
+		t.Fatal(err)
+	}
+
+	// A only allows pulling nasdaq
+	a := New(tr.A, func(o *Options) {
+		o.PullPreCheck = func(ctx context.Context, author identity.Author, ref dsref.Ref, l *oplog.Log) error {
+			if ref.Name != nasdaqRef.Name {
+				return fmt.Errorf("hook failed")
+			}
+			return nil
+		}
+	})
+	b := New(tr.B)
+
+	server := httptest.NewServer(HTTPHandler(a))
+	defer server.Close()
+
+	pull, err := b.NewBatchPull("a", server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	logs, err := pull.Do(tr.Ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(logs) != 1 {
+		t.Errorf("expected pre-check to limit pull to 1 log. got: %d", len(logs))
+	}
+
+	// A rejects all pushes
+	a.pushPreCheck = func(ctx context.Context, author identity.Author, ref dsref.Ref, l *oplog.Log) error {
+		return fmt.Errorf("hook failed")
+	}
+	if _, err := writeWorldBankLogs(tr.Ctx, tr.B); err != nil {
+		t.Fatal(err)
+	}
+	push, err := b.NewBatchPush("b", server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := push.Do(tr.Ctx); err == nil {
+		t.Errorf("expected push pre-check error")
+	}
+}
+
+func TestLogBatchEncoding(t *testing.T) {
+	tr, cleanup := newTestRunner(t)
+	defer cleanup()
+
+	ref, err := writeNasdaqLogs(tr.Ctx, tr.A)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lg, err := tr.A.UserDatasetRef(tr.Ctx, ref)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := tr.A.LogBytes(lg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	buf := &bytes.Buffer{}
+	if err := writeLogBatch(buf, [][]byte{data, data, data}); err != nil {
+		t.Fatal(err)
+	}
+	logs, err := readLogBatch(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(logs) != 3 {
+		t.Fatalf("expected 3 logs. got: %d", len(logs))
+	}
+	if logs[2].ID() != lg.ID() {
+		t.Errorf("decoded log ID mismatch. want: %s got: %s", lg.ID(), logs[2].ID())
+	}
+
+	if _, err := readLogBatch(bytes.NewReader([]byte{0x10, 0x01})); err == nil {
+		t.Errorf("expected truncated batch to error")
+	}
+}
+
+func writeVersion(ctx context.Context, book *logbook.Book, ref dsref.Ref, path, prev string) error {
+	initID, err := book.RefToInitID(ref)
+	if err != nil {
+		return err
+	}
+	return book.WriteVersionSave(ctx, initID, &dataset.Dataset{
+		Peername: ref.Username,
+		Name:     ref.Name,
+		Commit: &dataset.Commit{
+			Timestamp: time.Date(2000, time.January, 4, 0, 0, 0, 0, time.UTC),
+			Title:     "added a version",
+		},
+		Path:         path,
+		PreviousPath: prev,
+	})
+}
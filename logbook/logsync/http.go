@@ -1,13 +1,16 @@
 package logsync
 
 import (
+	"bytes"
 	"context"
 	"encoding/base64"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"net/http"
+	"net/url"
 
 	crypto "github.com/libp2p/go-libp2p-core/crypto"
 	"github.com/qri-io/qri/dsref"
@@ -66,6 +69,10 @@ func (c *httpClient) get(ctx context.Context, author identity.Author, ref dsref.
 	if err != nil {
 		return nil, nil, err
 	}
+	if res.StatusCode == http.StatusNotFound {
+		res.Body.Close()
+		return nil, nil, fmt.Errorf("%w: %s", logbook.ErrNotFound, ref)
+	}
 	if res.StatusCode != http.StatusOK {
 		if errmsg, err := ioutil.ReadAll(res.Body); err == nil {
 			return nil, nil, fmt.Errorf(string(errmsg))
@@ -104,6 +111,83 @@ func (c *httpClient) del(ctx context.Context, author identity.Author, ref dsref.
 	return err
 }
 
+// batchURL builds a URL for a batch request
+func (c *httpClient) batchURL(username string) string {
+	q := url.Values{}
+	q.Set("batch", "true")
+	if username != "" {
+		q.Set("username", username)
+	}
+	return fmt.Sprintf("%s?%s", c.URL, q.Encode())
+}
+
+// doBatch sends a batch request, returning the response if it succeeds
+func (c *httpClient) doBatch(ctx context.Context, author identity.Author, method, username string, body io.Reader) (*http.Response, error) {
+	req, err := http.NewRequest(method, c.batchURL(username), body)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+
+	if err := addAuthorHTTPHeaders(req.Header, author); err != nil {
+		return nil, err
+	}
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode != http.StatusOK {
+		defer res.Body.Close()
+		if errmsg, err := ioutil.ReadAll(res.Body); err == nil {
+			return nil, fmt.Errorf(string(errmsg))
+		}
+		return nil, fmt.Errorf("logsync: unexpected status %d", res.StatusCode)
+	}
+	return res, nil
+}
+
+func (c *httpClient) summary(ctx context.Context, author identity.Author, username string) (Summary, error) {
+	res, err := c.doBatch(ctx, author, "GET", username, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	s := Summary{}
+	if err := json.NewDecoder(res.Body).Decode(&s); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (c *httpClient) getBatch(ctx context.Context, author identity.Author, username string, have Summary) (identity.Author, io.Reader, error) {
+	data, err := json.Marshal(have)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	res, err := c.doBatch(ctx, author, "POST", username, bytes.NewReader(data))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	sender, err := senderFromHTTPHeaders(res.Header)
+	if err != nil {
+		res.Body.Close()
+		return nil, nil, err
+	}
+	return sender, res.Body, nil
+}
+
+func (c *httpClient) putBatch(ctx context.Context, author identity.Author, r io.Reader) error {
+	res, err := c.doBatch(ctx, author, "PUT", "", r)
+	if err != nil {
+		return err
+	}
+	return res.Body.Close()
+}
+
 func addAuthorHTTPHeaders(h http.Header, author identity.Author) error {
 	h.Set("ID", author.AuthorID())
 
@@ -140,6 +224,11 @@ func HTTPHandler(lsync *Logsync) http.HandlerFunc {
 			return
 		}
 
+		if r.URL.Query().Get("batch") == "true" {
+			handleBatch(lsync, sender, w, r)
+			return
+		}
+
 		switch r.Method {
 		case "PUT":
 			if err := lsync.put(r.Context(), sender, r.Body); err != nil {
@@ -191,3 +280,54 @@ func HTTPHandler(lsync *Logsync) http.HandlerFunc {
 		}
 	}
 }
+
+// handleBatch answers batch requests, which work with all logs for a user
+func handleBatch(lsync *Logsync, sender identity.Author, w http.ResponseWriter, r *http.Request) {
+	username := r.URL.Query().Get("username")
+
+	switch r.Method {
+	case "GET":
+		s, err := lsync.summary(r.Context(), sender, username)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(err.Error()))
+			return
+		}
+
+		addAuthorHTTPHeaders(w.Header(), lsync.Author())
+		json.NewEncoder(w).Encode(s)
+	case "POST":
+		have := Summary{}
+		if err := json.NewDecoder(r.Body).Decode(&have); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(err.Error()))
+			return
+		}
+
+		receiver, data, err := lsync.getBatch(r.Context(), sender, username, have)
+		if err != nil {
+			if errors.Is(err, logbook.ErrNotFound) {
+				w.WriteHeader(http.StatusNotFound)
+				w.Write([]byte(err.Error()))
+				return
+			}
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(err.Error()))
+			return
+		}
+
+		addAuthorHTTPHeaders(w.Header(), receiver)
+		io.Copy(w, data)
+	case "PUT":
+		if err := lsync.putBatch(r.Context(), sender, r.Body); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(err.Error()))
+			return
+		}
+		r.Body.Close()
+
+		addAuthorHTTPHeaders(w.Header(), lsync.Author())
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
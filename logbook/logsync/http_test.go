@@ -1,12 +1,14 @@
 package logsync
 
 import (
+	"crypto/rand"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
 	cmp "github.com/google/go-cmp/cmp"
+	crypto "github.com/libp2p/go-libp2p-core/crypto"
 	"github.com/qri-io/qri/dsref"
 	"github.com/qri-io/qri/logbook"
 )
@@ -92,6 +94,71 @@ func TestSyncHTTP(t *testing.T) {
 	}
 }
 
+func TestPullThirdPartyLogHTTP(t *testing.T) {
+	tr, cleanup := newTestRunner(t)
+	defer cleanup()
+
+	// a third author pushes to A, which B pulls from
+	pk, _, err := crypto.GenerateEd25519Key(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	author, err := newTestbook("c", pk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := New(tr.A)
+	server := httptest.NewServer(HTTPHandler(a))
+	defer server.Close()
+
+	ref, err := writeNasdaqLogs(tr.Ctx, author)
+	if err != nil {
+		t.Fatal(err)
+	}
+	push, err := New(author).NewPush(ref, server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := push.Do(tr.Ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	strict := New(tr.B, func(o *Options) {
+		o.StrictVerify = true
+	})
+	pull, err := strict.NewPull(ref, server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pull.Merge = true
+	if _, err := pull.Do(tr.Ctx); err == nil {
+		t.Error("expected strict pull of a third party's log to fail verification")
+	} else if _, ok := err.(*logbook.VerifyError); !ok {
+		t.Errorf("expected strict pull of a third party's log to fail with a verify error. got: %v", err)
+	}
+
+	b := New(tr.B)
+	if pull, err = b.NewPull(ref, server.URL); err != nil {
+		t.Fatal(err)
+	}
+	pull.Merge = true
+	if _, err := pull.Do(tr.Ctx); err != nil {
+		t.Fatalf("pulling a third party's log: %s", err)
+	}
+
+	expect, err := author.Items(tr.Ctx, ref, 0, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := tr.B.Items(tr.Ctx, ref, 0, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(expect, got); diff != "" {
+		t.Errorf("result mismatch. (-want +got):\n%s", diff)
+	}
+}
+
 func TestHTTPClientErrors(t *testing.T) {
 	tr, cleanup := newTestRunner(t)
 	defer cleanup()
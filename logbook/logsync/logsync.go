@@ -10,6 +10,7 @@ import (
 	"strings"
 
 	golog "github.com/ipfs/go-log"
+	crypto "github.com/libp2p/go-libp2p-core/crypto"
 	host "github.com/libp2p/go-libp2p-core/host"
 	peer "github.com/libp2p/go-libp2p-core/peer"
 	"github.com/qri-io/qri/dsref"
@@ -28,8 +29,9 @@ var (
 // Logsync fulfills requests from clients, logsync wraps a logbook.Book, pushing
 // and pulling logs from remote sources to its logbook
 type Logsync struct {
-	book       *logbook.Book
-	p2pHandler *p2pHandler
+	book         *logbook.Book
+	p2pHandler   *p2pHandler
+	strictVerify bool
 
 	pushPreCheck   Hook
 	pushFinalCheck Hook
@@ -59,6 +61,11 @@ type Options struct {
 	RemovePreCheck Hook
 	// called after removing
 	Removed Hook
+
+	// StrictVerify rejects received logs that fail logbook.VerifyLog, both on
+	// push and pull. Logs of authors whose keys can't be resolved from the
+	// sender or the local book fail verification
+	StrictVerify bool
 }
 
 // New creates a remote from a logbook and optional configuration functions
@@ -69,7 +76,8 @@ func New(book *logbook.Book, opts ...func(*Options)) *Logsync {
 	}
 
 	logsync := &Logsync{
-		book: book,
+		book:         book,
+		strictVerify: o.StrictVerify,
 
 		pushPreCheck:   o.PushPreCheck,
 		pushFinalCheck: o.PushFinalCheck,
@@ -130,9 +138,10 @@ func (lsync *Logsync) NewPull(ref dsref.Ref, remoteAddr string) (*Pull, error) {
 	}
 
 	return &Pull{
-		book:   lsync.book,
-		remote: rem,
-		ref:    ref,
+		book:         lsync.book,
+		remote:       rem,
+		ref:          ref,
+		strictVerify: lsync.strictVerify,
 	}, nil
 }
 
@@ -179,6 +188,10 @@ type remote interface {
 	put(ctx context.Context, author identity.Author, r io.Reader) error
 	get(ctx context.Context, author identity.Author, ref dsref.Ref) (sender identity.Author, data io.Reader, err error)
 	del(ctx context.Context, author identity.Author, ref dsref.Ref) error
+
+	summary(ctx context.Context, author identity.Author, username string) (Summary, error)
+	getBatch(ctx context.Context, author identity.Author, username string, have Summary) (sender identity.Author, data io.Reader, err error)
+	putBatch(ctx context.Context, author identity.Author, r io.Reader) error
 }
 
 // assert at compile-time that Logsync is a remote
@@ -208,11 +221,22 @@ func (lsync *Logsync) put(ctx context.Context, author identity.Author, r io.Read
 		return err
 	}
 
+	return lsync.merge(ctx, author, lg)
+}
+
+// merge checks & stores a log received from author, calling push hooks
+func (lsync *Logsync) merge(ctx context.Context, author identity.Author, lg *oplog.Log) error {
 	ref, err := logbook.DsrefAliasForLog(lg)
 	if err != nil {
 		return err
 	}
 
+	if lsync.strictVerify {
+		if err := verifyReceived(lsync.book, author, lg); err != nil {
+			return err
+		}
+	}
+
 	if lsync.pushFinalCheck != nil {
 		if err := lsync.pushFinalCheck(ctx, author, ref, lg); err != nil {
 			return err
@@ -308,9 +332,10 @@ func (p *Push) Do(ctx context.Context) error {
 
 // Pull is a request to fetch a log
 type Pull struct {
-	book   *logbook.Book
-	ref    dsref.Ref
-	remote remote
+	book         *logbook.Book
+	ref          dsref.Ref
+	remote       remote
+	strictVerify bool
 
 	// set to true to merge these logs into the local store on successful pull
 	Merge bool
@@ -332,6 +357,12 @@ func (p *Pull) Do(ctx context.Context) (*oplog.Log, error) {
 		return nil, err
 	}
 
+	if p.strictVerify {
+		if err := verifyReceived(p.book, sender, l); err != nil {
+			return nil, err
+		}
+	}
+
 	if p.Merge {
 		if err := p.book.MergeLog(ctx, sender, l); err != nil {
 			return nil, err
@@ -340,3 +371,16 @@ func (p *Pull) Do(ctx context.Context) (*oplog.Log, error) {
 
 	return l, nil
 }
+
+// verifyReceived checks a log received from sender, resolving author keys
+// from the sender and the keys the local book trusts. Logs authored by anyone
+// else can't be verified and are rejected
+func verifyReceived(book *logbook.Book, sender identity.Author, lg *oplog.Log) error {
+	keys := func(keyID string) (crypto.PubKey, error) {
+		if id, err := identity.KeyIDFromPub(sender.AuthorPubKey()); err == nil && id == keyID {
+			return sender.AuthorPubKey(), nil
+		}
+		return book.AuthorKey(keyID)
+	}
+	return logbook.VerifyLog(lg, keys).Err()
+}
@@ -1,6 +1,7 @@
 package logsync
 
 import (
+	"bytes"
 	"context"
 	"encoding/base64"
 	"fmt"
@@ -296,6 +297,57 @@ func TestHookErrors(t *testing.T) {
 	}
 }
 
+func TestStrictVerify(t *testing.T) {
+	tr, cleanup := newTestRunner(t)
+	defer cleanup()
+
+	worldBankRef, err := writeWorldBankLogs(tr.Ctx, tr.A)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b := New(tr.B, func(o *Options) {
+		o.StrictVerify = true
+	})
+
+	lg, err := tr.A.UserDatasetRef(tr.Ctx, worldBankRef)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := tr.A.LogBytes(lg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// break the link between the last two versions. Prev isn't covered by the
+	// log signature, so only verification catches it
+	tampered := &oplog.Log{}
+	if err := tampered.UnmarshalFlatbufferBytes(data); err != nil {
+		t.Fatal(err)
+	}
+	branch := tampered.Logs[0].Logs[0]
+	for i, op := range branch.Ops {
+		if op.Prev == "v1" {
+			branch.Ops[i].Prev = "v0"
+		}
+	}
+
+	err = b.put(tr.Ctx, tr.A.Author(), bytes.NewReader(tampered.FlatbufferBytes()))
+	if _, ok := err.(*logbook.VerifyError); !ok {
+		t.Fatalf("expected tampered log to be rejected with a verify error. got: %v", err)
+	}
+	if _, err := tr.B.UserDatasetRef(tr.Ctx, worldBankRef); err == nil {
+		t.Errorf("expected rejected log not to be merged")
+	}
+
+	if err := b.put(tr.Ctx, tr.A.Author(), bytes.NewReader(data)); err != nil {
+		t.Fatalf("expected signed log to be accepted. got: %s", err)
+	}
+	if _, err := tr.B.UserDatasetRef(tr.Ctx, worldBankRef); err != nil {
+		t.Errorf("expected accepted log to be merged. got: %s", err)
+	}
+}
+
 func TestNilCallable(t *testing.T) {
 	var logsync *Logsync
 
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -36,6 +37,15 @@ var (
 	mtGet = p2putil.MsgType("get")
 	// mtDel identifies the "del" message type, a request to remove a log
 	mtDel = p2putil.MsgType("del")
+	// mtSummary identifies the "summary" message type, a request for the heads
+	// of all logs for a user
+	mtSummary = p2putil.MsgType("summary")
+	// mtGetBatch identifies the "get_batch" message type, a request for all
+	// logs for a user that differ from a summary
+	mtGetBatch = p2putil.MsgType("get_batch")
+	// mtPutBatch identifies the "put_batch" message type, a client pushing a
+	// stream of logs to a remote
+	mtPutBatch = p2putil.MsgType("put_batch")
 )
 
 type p2pClient struct {
@@ -98,6 +108,83 @@ func (c *p2pClient) del(ctx context.Context, author identity.Author, ref dsref.R
 	return err
 }
 
+func (c *p2pClient) summary(ctx context.Context, author identity.Author, username string) (Summary, error) {
+	headers := []string{
+		"phase", "request",
+		"username", username,
+	}
+	headers, err := addAuthorP2PHeaders(headers, author)
+	if err != nil {
+		return nil, err
+	}
+
+	msg := p2putil.NewMessage(c.host.ID(), mtSummary, nil).WithHeaders(headers...)
+	res, err := c.sendMessage(ctx, msg, c.remotePeerID)
+	if err != nil {
+		return nil, err
+	}
+	if errmsg := res.Header("error"); errmsg != "" {
+		return nil, fmt.Errorf(errmsg)
+	}
+
+	s := Summary{}
+	if err := json.Unmarshal(res.Body, &s); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (c *p2pClient) getBatch(ctx context.Context, author identity.Author, username string, have Summary) (identity.Author, io.Reader, error) {
+	data, err := json.Marshal(have)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	headers := []string{
+		"phase", "request",
+		"username", username,
+	}
+	headers, err = addAuthorP2PHeaders(headers, author)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	msg := p2putil.NewMessage(c.host.ID(), mtGetBatch, data).WithHeaders(headers...)
+	res, err := c.sendMessage(ctx, msg, c.remotePeerID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if errmsg := res.Header("error"); errmsg != "" {
+		return nil, nil, fmt.Errorf(errmsg)
+	}
+
+	sender, err := authorFromP2PHeaders(res)
+	return sender, bytes.NewReader(res.Body), err
+}
+
+func (c *p2pClient) putBatch(ctx context.Context, author identity.Author, r io.Reader) error {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	headers := []string{"phase", "request"}
+	headers, err = addAuthorP2PHeaders(headers, author)
+	if err != nil {
+		return err
+	}
+
+	msg := p2putil.NewMessage(c.host.ID(), mtPutBatch, data).WithHeaders(headers...)
+	res, err := c.sendMessage(ctx, msg, c.remotePeerID)
+	if err != nil {
+		return err
+	}
+	if errmsg := res.Header("error"); errmsg != "" {
+		return fmt.Errorf(errmsg)
+	}
+	return nil
+}
+
 func addAuthorP2PHeaders(h []string, author identity.Author) ([]string, error) {
 	pkb, err := author.AuthorPubKey().Bytes()
 	if err != nil {
@@ -136,6 +223,10 @@ func newp2pHandler(logsync *Logsync, host host.Host) *p2pHandler {
 		mtPut: c.HandlePut,
 		mtGet: c.HandleGet,
 		mtDel: c.HandleDel,
+
+		mtSummary:  c.HandleSummary,
+		mtGetBatch: c.HandleGetBatch,
+		mtPutBatch: c.HandlePutBatch,
 	}
 
 	go host.SetStreamHandler(LogsyncProtocolID, c.LibP2PStreamHandler)
@@ -245,6 +336,102 @@ func (c *p2pHandler) HandleDel(ws *p2putil.WrappedStream, msg p2putil.Message) (
 	return true
 }
 
+// HandleSummary responds with a summary of the logs this peer has for a user.
+// Batch handlers reply with an "error" header instead of hanging up when a
+// request fails, so the requester isn't left waiting on a response
+func (c *p2pHandler) HandleSummary(ws *p2putil.WrappedStream, msg p2putil.Message) (hangup bool) {
+	if msg.Header("phase") == "request" {
+		ctx := context.Background()
+		author, err := authorFromP2PHeaders(msg)
+		if err != nil {
+			return replyError(ws, msg, err)
+		}
+
+		s, err := c.logsync.summary(ctx, author, msg.Header("username"))
+		if err != nil {
+			return replyError(ws, msg, err)
+		}
+		data, err := json.Marshal(s)
+		if err != nil {
+			return replyError(ws, msg, err)
+		}
+
+		res := msg.WithHeaders("phase", "response").Update(data)
+		if err := ws.SendMessage(res); err != nil {
+			return true
+		}
+	}
+	return true
+}
+
+// HandleGetBatch responds with all logs for a user that differ from the
+// summary sent in the request body
+func (c *p2pHandler) HandleGetBatch(ws *p2putil.WrappedStream, msg p2putil.Message) (hangup bool) {
+	if msg.Header("phase") == "request" {
+		ctx := context.Background()
+		author, err := authorFromP2PHeaders(msg)
+		if err != nil {
+			return replyError(ws, msg, err)
+		}
+
+		have := Summary{}
+		if err := json.Unmarshal(msg.Body, &have); err != nil {
+			return replyError(ws, msg, err)
+		}
+
+		sender, r, err := c.logsync.getBatch(ctx, author, msg.Header("username"), have)
+		if err != nil {
+			return replyError(ws, msg, err)
+		}
+		data, err := ioutil.ReadAll(r)
+		if err != nil {
+			return replyError(ws, msg, err)
+		}
+
+		headers := []string{
+			"phase", "response",
+		}
+		headers, err = addAuthorP2PHeaders(headers, sender)
+		if err != nil {
+			return replyError(ws, msg, err)
+		}
+
+		res := msg.WithHeaders(headers...).Update(data)
+		if err := ws.SendMessage(res); err != nil {
+			return true
+		}
+	}
+	return true
+}
+
+// HandlePutBatch merges a stream of logs sent in the request body
+func (c *p2pHandler) HandlePutBatch(ws *p2putil.WrappedStream, msg p2putil.Message) (hangup bool) {
+	if msg.Header("phase") == "request" {
+		ctx := context.Background()
+		author, err := authorFromP2PHeaders(msg)
+		if err != nil {
+			return replyError(ws, msg, err)
+		}
+
+		if err = c.logsync.putBatch(ctx, author, bytes.NewReader(msg.Body)); err != nil {
+			return replyError(ws, msg, err)
+		}
+
+		res := msg.WithHeaders("phase", "response").Update(nil)
+		if err := ws.SendMessage(res); err != nil {
+			return true
+		}
+	}
+	return true
+}
+
+// replyError responds to a request with an error header & hangs up
+func replyError(ws *p2putil.WrappedStream, msg p2putil.Message, err error) (hangup bool) {
+	res := msg.WithHeaders("phase", "response", "error", err.Error()).Update(nil)
+	ws.SendMessage(res)
+	return true
+}
+
 // sendMessage opens a stream & sends a message to a peer id
 func (c *p2pHandler) sendMessage(ctx context.Context, msg p2putil.Message, pid peer.ID) (p2putil.Message, error) {
 	s, err := c.host.NewStream(ctx, pid, LogsyncProtocolID)
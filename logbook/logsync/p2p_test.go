@@ -101,6 +101,105 @@ func TestP2PLogsync(t *testing.T) {
 	}
 }
 
+func TestP2PBatchLogsync(t *testing.T) {
+	tr, cleanup := newTestRunner(t)
+	defer cleanup()
+
+	aHost := p2pHost(tr.Ctx, tr.APrivKey, t)
+	bHost := p2pHost(tr.Ctx, tr.BPrivKey, t)
+
+	lsA := New(tr.A, func(o *Options) {
+		o.Libp2pHost = aHost
+	})
+	lsB := New(tr.B, func(o *Options) {
+		o.Libp2pHost = bHost
+	})
+
+	if err := aHost.Connect(tr.Ctx, pstore.PeerInfo{ID: bHost.ID(), Addrs: bHost.Addrs()}); err != nil {
+		t.Fatal(err)
+	}
+
+	worldBankRef, err := writeWorldBankLogs(tr.Ctx, tr.A)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := writeNasdaqLogs(tr.Ctx, tr.A); err != nil {
+		t.Fatal(err)
+	}
+
+	aID, err := tr.A.ActivePeerID(tr.Ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// pull all of A's logs to B
+	pull, err := lsB.NewBatchPull("a", aID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pull.Merge = true
+
+	logs, err := pull.Do(tr.Ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(logs) != 2 {
+		t.Errorf("expected 2 logs. got: %d", len(logs))
+	}
+	if _, err := tr.B.Items(tr.Ctx, worldBankRef, 0, 10); err != nil {
+		t.Errorf("expected no error fetching dslog items after pull. got: %s", err)
+	}
+	if logs, err = pull.Do(tr.Ctx); err != nil {
+		t.Fatal(err)
+	}
+	if len(logs) != 0 {
+		t.Errorf("expected pull of synced logs to send nothing. got: %d logs", len(logs))
+	}
+
+	// errors are sent back to the requester
+	missing, err := lsB.NewBatchPull("missing_user", aID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := missing.Do(tr.Ctx); err == nil {
+		t.Errorf("expected pulling an unknown user to error")
+	}
+
+	// push all of B's logs to A
+	bNasdaqRef, err := writeNasdaqLogs(tr.Ctx, tr.B)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bID, err := tr.B.ActivePeerID(tr.Ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	push, err := lsA.NewBatchPush("a", bID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if refs, err := push.Do(tr.Ctx); err != nil {
+		t.Fatal(err)
+	} else if len(refs) != 0 {
+		t.Errorf("expected push of synced logs to send nothing. got: %v", refs)
+	}
+
+	push, err = lsB.NewBatchPush("b", aID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	refs, err := push.Do(tr.Ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(refs) != 1 {
+		t.Errorf("expected 1 pushed log. got: %d", len(refs))
+	}
+	if _, err := tr.A.Items(tr.Ctx, bNasdaqRef, 0, 10); err != nil {
+		t.Errorf("expected no error fetching dslog items after push. got: %s", err)
+	}
+}
+
 // makeBasicHost creates a LibP2P host from a NodeCfg
 func p2pHost(ctx context.Context, pk crypto.PrivKey, t *testing.T) host.Host {
 	pid, err := peer.IDFromPrivateKey(pk)
@@ -30,6 +30,9 @@ import (
 var (
 	// ErrNotFound is a sentinel error for data not found in a logbook
 	ErrNotFound = fmt.Errorf("log: not found")
+	// ErrRewriteRequired indicates changes to a store can't be expressed as
+	// appended operations, and the store must be persisted in full
+	ErrRewriteRequired = fmt.Errorf("log: changes require a full rewrite")
 )
 
 // Logstore persists a set of operations organized into hierarchical append-only
@@ -110,16 +113,37 @@ type AuthorLogstore interface {
 	UnmarshalFlatbufferCipher(ctx context.Context, pk crypto.PrivKey, ciphertext []byte) error
 }
 
+// SegmentedLogstore is an AuthorLogstore that can persist changes as a series
+// of append-only segments instead of rewriting the entire store on each save.
+// A store is read back by unmarshaling the full flatbuffer cipher, then each
+// segment written after it in order
+type SegmentedLogstore interface {
+	AuthorLogstore
+
+	// marshals operations added since the store was last persisted to a
+	// segment encrypted with the given private key. SegmentCipher returns nil
+	// bytes if nothing has changed, and ErrRewriteRequired when changes can't
+	// be expressed as appended operations
+	SegmentCipher(pk crypto.PrivKey) ([]byte, error)
+	// decrypt a segment, applying its operations to the store
+	UnmarshalSegmentCipher(ctx context.Context, pk crypto.PrivKey, ciphertext []byte) error
+	// mark all operations in the store as saved. Persisted must be called after
+	// successfully writing the output of FlatbufferCipher or SegmentCipher
+	Persisted()
+}
+
 // Journal is a store of logs known to a single author, representing their
 // view of an abstract dataset graph. journals live in memory by default, and
 // can be encrypted for storage
 type Journal struct {
 	id   string
 	logs []*Log
+	// set when changes can't be persisted as a segment
+	rewrite bool
 }
 
-// assert at compile time that a Journal pointer is an AuthorLogstore
-var _ AuthorLogstore = (*Journal)(nil)
+// assert at compile time that a Journal pointer is a SegmentedLogstore
+var _ SegmentedLogstore = (*Journal)(nil)
 
 // ID gets the journal author identifier
 func (j *Journal) ID() string {
@@ -133,6 +157,7 @@ func (j *Journal) SetID(ctx context.Context, id string) error {
 	}
 
 	j.id = id
+	j.rewrite = true
 	return nil
 }
 
@@ -169,6 +194,7 @@ func (j *Journal) RemoveLog(ctx context.Context, names ...string) error {
 		for i, l := range j.logs {
 			if l.Name() == remove {
 				j.logs = append(j.logs[:i], j.logs[i+1:]...)
+				j.rewrite = true
 				return nil
 			}
 		}
@@ -184,6 +210,7 @@ func (j *Journal) RemoveLog(ctx context.Context, names ...string) error {
 	for i, l := range parent.Logs {
 		if l.Name() == remove {
 			parent.Logs = append(parent.Logs[:i], parent.Logs[i+1:]...)
+			j.rewrite = true
 			return nil
 		}
 	}
@@ -235,7 +262,11 @@ func (j *Journal) UnmarshalFlatbufferCipher(ctx context.Context, pk crypto.PrivK
 		return err
 	}
 
-	return j.unmarshalFlatbuffer(logfb.GetRootAsBook(plaintext, 0))
+	if err := j.unmarshalFlatbuffer(logfb.GetRootAsBook(plaintext, 0)); err != nil {
+		return err
+	}
+	j.Persisted()
+	return nil
 }
 
 // Children gets all descentants of a log, because logbook stores all
@@ -263,6 +294,207 @@ func (j Journal) FlatbufferCipher(pk crypto.PrivKey) ([]byte, error) {
 	return j.encrypt(pk, j.flatbufferBytes())
 }
 
+// SegmentCipher marshals operations added since the journal was last
+// persisted to a flatbuffer segment, encrypted with the given private key.
+// Segments contain only logs with new operations and their ancestors. Each
+// segment log starts with its initialization operation, followed by the
+// operations added since the last save
+func (j *Journal) SegmentCipher(pk crypto.PrivKey) ([]byte, error) {
+	if j.rewrite {
+		return nil, ErrRewriteRequired
+	}
+
+	changed := map[*Log]bool{}
+	top := []*Log{}
+	for _, l := range j.logs {
+		c, err := segmentChanges(l, changed)
+		if err != nil {
+			return nil, err
+		}
+		if c {
+			top = append(top, l)
+		}
+	}
+	if len(top) == 0 {
+		return nil, nil
+	}
+
+	builder := flatbuffers.NewBuilder(0)
+	id := builder.CreateString(j.id)
+	offsets := make([]flatbuffers.UOffsetT, len(top))
+	for i, l := range top {
+		offsets[i] = marshalSegmentLog(builder, l, changed)
+	}
+	logfb.BookStartLogsVector(builder, len(offsets))
+	for i := len(offsets) - 1; i >= 0; i-- {
+		builder.PrependUOffsetT(offsets[i])
+	}
+	logs := builder.EndVector(len(offsets))
+	logfb.BookStart(builder)
+	logfb.BookAddIdentifier(builder, id)
+	logfb.BookAddLogs(builder, logs)
+	builder.Finish(logfb.BookEnd(builder))
+
+	return j.encrypt(pk, builder.FinishedBytes())
+}
+
+// segmentChanges records which logs in a tree have unsaved operations
+// themselves or in a descendant, returning true if l has changed
+func segmentChanges(l *Log, changed map[*Log]bool) (bool, error) {
+	if l.saved < 0 || l.saved > len(l.Ops) {
+		return false, ErrRewriteRequired
+	}
+	c := l.saved < len(l.Ops)
+	for _, child := range l.Logs {
+		childChanged, err := segmentChanges(child, changed)
+		if err != nil {
+			return false, err
+		}
+		c = c || childChanged
+	}
+	changed[l] = c
+	return c, nil
+}
+
+func marshalSegmentLog(builder *flatbuffers.Builder, l *Log, changed map[*Log]bool) flatbuffers.UOffsetT {
+	logoffsets := []flatbuffers.UOffsetT{}
+	for _, child := range l.Logs {
+		if changed[child] {
+			logoffsets = append(logoffsets, marshalSegmentLog(builder, child, changed))
+		}
+	}
+	logfb.LogStartLogsVector(builder, len(logoffsets))
+	for i := len(logoffsets) - 1; i >= 0; i-- {
+		builder.PrependUOffsetT(logoffsets[i])
+	}
+	logs := builder.EndVector(len(logoffsets))
+
+	signature := builder.CreateByteString(l.Signature)
+
+	offset := l.saved
+	if offset < 1 {
+		offset = 1
+	}
+	ops := append([]Op{l.Ops[0]}, l.Ops[offset:]...)
+	opoffsets := make([]flatbuffers.UOffsetT, len(ops))
+	for i, o := range ops {
+		opoffsets[i] = o.MarshalFlatbuffer(builder)
+	}
+	logfb.LogStartOpsetVector(builder, len(opoffsets))
+	for i := len(opoffsets) - 1; i >= 0; i-- {
+		builder.PrependUOffsetT(opoffsets[i])
+	}
+	opset := builder.EndVector(len(opoffsets))
+
+	logfb.LogStart(builder)
+	logfb.LogAddSignature(builder, signature)
+	logfb.LogAddOpset(builder, opset)
+	logfb.LogAddLogs(builder, logs)
+	logfb.LogAddOffset(builder, int64(offset))
+	return logfb.LogEnd(builder)
+}
+
+// UnmarshalSegmentCipher decrypts a segment, appending its operations to the
+// journal. Segments that have already been applied are skipped
+func (j *Journal) UnmarshalSegmentCipher(ctx context.Context, pk crypto.PrivKey, ciphertext []byte) error {
+	plaintext, err := j.decrypt(pk, ciphertext)
+	if err != nil {
+		return err
+	}
+
+	b := logfb.GetRootAsBook(plaintext, 0)
+	lfb := &logfb.Log{}
+	for i := 0; i < b.LogsLength(); i++ {
+		if b.Logs(lfb, i) {
+			if err := j.applySegmentLog(nil, lfb); err != nil {
+				return err
+			}
+		}
+	}
+	j.Persisted()
+	return nil
+}
+
+func (j *Journal) applySegmentLog(parent *Log, lfb *logfb.Log) error {
+	if lfb.OpsetLength() == 0 {
+		return fmt.Errorf("oplog: segment log is missing an initialization operation")
+	}
+	opfb := &logfb.Operation{}
+	lfb.Opset(opfb, 0)
+	init := UnmarshalOpFlatbuffer(opfb)
+
+	siblings := j.logs
+	if parent != nil {
+		siblings = parent.Logs
+	}
+	var found *Log
+	for _, l := range siblings {
+		if len(l.Ops) > 0 && l.Ops[0].Equal(init) {
+			found = l
+			break
+		}
+	}
+
+	offset := int(lfb.Offset())
+	added := lfb.OpsetLength() - 1
+	if found == nil {
+		if offset > 1 {
+			return fmt.Errorf("oplog: segment appends to log %s, which doesn't exist", init.Hash())
+		}
+		l := &Log{}
+		if err := l.UnmarshalFlatbuffer(lfb, parent); err != nil {
+			return err
+		}
+		if parent == nil {
+			j.logs = append(j.logs, l)
+		} else {
+			parent.AddChild(l)
+		}
+		return nil
+	}
+
+	switch {
+	case len(found.Ops) == offset:
+		for i := 1; i <= added; i++ {
+			lfb.Opset(opfb, i)
+			found.Append(UnmarshalOpFlatbuffer(opfb))
+		}
+		if len(lfb.Signature()) != 0 {
+			found.Signature = lfb.Signature()
+		}
+	case len(found.Ops) >= offset+added:
+		// operations are already present, the journal was saved in full after
+		// this segment was written
+	default:
+		return fmt.Errorf("oplog: segment for log %s doesn't line up with stored operations", found.ID())
+	}
+
+	childfb := &logfb.Log{}
+	for i := 0; i < lfb.LogsLength(); i++ {
+		if lfb.Logs(childfb, i) {
+			if err := j.applySegmentLog(found, childfb); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Persisted marks all operations in the journal as saved
+func (j *Journal) Persisted() {
+	var mark func(l *Log)
+	mark = func(l *Log) {
+		l.saved = len(l.Ops)
+		for _, child := range l.Logs {
+			mark(child)
+		}
+	}
+	for _, l := range j.logs {
+		mark(l)
+	}
+	j.rewrite = false
+}
+
 func (j Journal) cipher(pk crypto.PrivKey) (cipher.AEAD, error) {
 	pkBytes, err := pk.Raw()
 	if err != nil {
@@ -367,6 +599,9 @@ type Log struct {
 	name     string // name value cache. not persisted
 	authorID string // authorID value cache. not persisted
 	parent   *Log   // parent link
+	// number of operations written to storage, -1 if stored operations no
+	// longer match. not persisted
+	saved int
 
 	Signature []byte
 	Ops       []Op
@@ -398,6 +633,8 @@ func (lg *Log) Append(op Op) {
 		}
 	}
 	lg.Ops = append(lg.Ops, op)
+	// the signature no longer covers all operations
+	lg.Signature = nil
 }
 
 // ID returns the hash of the initialization operation
@@ -468,6 +705,66 @@ func (lg Log) Removed() bool {
 	return false
 }
 
+// Compact squashes operations in a log & its descendants, returning the number
+// of operations dropped. Removed logs are reduced to their initialization and
+// removal operations, dropping all descendants. Runs of consecutive amend
+// operations on the same model are reduced to the last amend in the run.
+// Compacted logs keep their ID, but changed logs lose their signature
+func (lg *Log) Compact() (dropped int) {
+	if len(lg.Ops) == 0 {
+		return 0
+	}
+
+	var ops []Op
+	if lg.Removed() {
+		m := lg.Model()
+		rm := Op{}
+		for _, op := range lg.Ops {
+			if op.Model == m && op.Type == OpTypeRemove {
+				rm = op
+			}
+		}
+		ops = []Op{lg.Ops[0], rm}
+		for _, child := range lg.Logs {
+			dropped += child.opCount()
+		}
+		if len(lg.Logs) > 0 {
+			lg.Logs = nil
+			lg.saved = -1
+		}
+	} else {
+		ops = make([]Op, 0, len(lg.Ops))
+		for i, op := range lg.Ops {
+			if op.Type == OpTypeAmend && i+1 < len(lg.Ops) && lg.Ops[i+1].Type == OpTypeAmend && lg.Ops[i+1].Model == op.Model {
+				continue
+			}
+			ops = append(ops, op)
+		}
+		for _, child := range lg.Logs {
+			dropped += child.Compact()
+		}
+	}
+
+	if len(ops) < len(lg.Ops) {
+		dropped += len(lg.Ops) - len(ops)
+		lg.Ops = ops
+		lg.Signature = nil
+		lg.name = ""
+		lg.authorID = ""
+		lg.saved = -1
+	}
+	return dropped
+}
+
+// opCount counts operations in a log & all descendants
+func (lg *Log) opCount() int {
+	n := len(lg.Ops)
+	for _, child := range lg.Logs {
+		n += child.opCount()
+	}
+	return n
+}
+
 // DeepCopy produces a fresh duplicate of this log
 func (lg *Log) DeepCopy() *Log {
 	lg.FlatbufferBytes()
@@ -517,6 +814,7 @@ func (lg *Log) AddChild(l *Log) {
 	for i, ch := range lg.Logs {
 		if ch.ID() == l.ID() {
 			if len(l.Ops) > len(ch.Ops) {
+				l.saved = -1
 				lg.Logs[i] = l
 			}
 			return
@@ -530,12 +828,14 @@ func (lg *Log) AddChild(l *Log) {
 // Merging relies on comparison of initialization operations, which
 // must be present to constitute a match
 func (lg *Log) Merge(l *Log) {
-	// if the incoming log has more operations, use it & clear the cache
+	// if the incoming log has more operations, use it & clear the cache. the
+	// incoming signature covers the adopted operations, so it comes along
 	if len(l.Ops) > len(lg.Ops) {
 		lg.Ops = l.Ops
 		lg.name = ""
 		lg.authorID = ""
-		lg.Signature = nil
+		lg.Signature = l.Signature
+		lg.saved = -1
 	}
 
 LOOP:
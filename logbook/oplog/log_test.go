@@ -181,6 +181,121 @@ func TestJournalCiphertext(t *testing.T) {
 	}
 }
 
+func TestJournalSegments(t *testing.T) {
+	tr, cleanup := newTestRunner(t)
+	defer cleanup()
+	ctx := tr.Ctx
+
+	j := tr.Journal
+	root := tr.RandomLog(Op{Type: OpTypeInit, Model: 0x1, Name: "apples"}, 3)
+	child := tr.RandomLog(Op{Type: OpTypeInit, Model: 0x2, Name: "oranges"}, 2)
+	root.AddChild(child)
+	if err := j.MergeLog(ctx, root); err != nil {
+		t.Fatal(err)
+	}
+
+	full, err := j.FlatbufferCipher(tr.PrivKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	j.Persisted()
+
+	if seg, err := j.SegmentCipher(tr.PrivKey); err != nil || seg != nil {
+		t.Fatalf("expected unchanged journal to produce no segment. got: %v, %v", seg, err)
+	}
+
+	// append to an existing log & add a new one
+	child.Append(Op{Type: OpTypeAmend, Model: 0x2, Name: "oranges", Note: "appended"})
+	root.AddChild(tr.RandomLog(Op{Type: OpTypeInit, Model: 0x2, Name: "pears"}, 1))
+
+	seg, err := j.SegmentCipher(tr.PrivKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(seg) >= len(full) {
+		t.Errorf("expected segment to be smaller than the full journal. segment: %d bytes, full: %d bytes", len(seg), len(full))
+	}
+	j.Persisted()
+
+	got := &Journal{}
+	if err := got.UnmarshalFlatbufferCipher(ctx, tr.PrivKey, full); err != nil {
+		t.Fatal(err)
+	}
+	if err := got.UnmarshalSegmentCipher(ctx, tr.PrivKey, seg); err != nil {
+		t.Fatal(err)
+	}
+	// applying a segment twice is a no-op
+	if err := got.UnmarshalSegmentCipher(ctx, tr.PrivKey, seg); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(j.logs, got.logs, allowUnexported, cmpopts.IgnoreUnexported(Log{})); diff != "" {
+		t.Errorf("result mismatch (-want +got):\n%s", diff)
+	}
+
+	if err := j.RemoveLog(ctx, "apples", "pears"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := j.SegmentCipher(tr.PrivKey); err != ErrRewriteRequired {
+		t.Errorf("expected removing a log to require a rewrite. got: %v", err)
+	}
+}
+
+func TestLogCompact(t *testing.T) {
+	lg := &Log{
+		Signature: []byte{1, 2, 3},
+		Ops: []Op{
+			{Type: OpTypeInit, Model: 0x1, Name: "root"},
+			{Type: OpTypeAmend, Model: 0x1, Name: "a"},
+			{Type: OpTypeAmend, Model: 0x1, Name: "b"},
+			{Type: OpTypeInit, Model: 0x3, Ref: "v1"},
+			{Type: OpTypeAmend, Model: 0x1, Name: "c"},
+		},
+		Logs: []*Log{
+			{
+				Ops: []Op{
+					{Type: OpTypeInit, Model: 0x2, Name: "removed"},
+					{Type: OpTypeAmend, Model: 0x2, Name: "renamed"},
+					{Type: OpTypeRemove, Model: 0x2},
+				},
+				Logs: []*Log{
+					{Ops: []Op{{Type: OpTypeInit, Model: 0x4, Name: "gone"}}},
+				},
+			},
+		},
+	}
+	id := lg.ID()
+
+	if dropped := lg.Compact(); dropped != 3 {
+		t.Errorf("dropped count mismatch. expected: 3, got: %d", dropped)
+	}
+
+	expect := &Log{
+		Ops: []Op{
+			{Type: OpTypeInit, Model: 0x1, Name: "root"},
+			{Type: OpTypeAmend, Model: 0x1, Name: "b"},
+			{Type: OpTypeInit, Model: 0x3, Ref: "v1"},
+			{Type: OpTypeAmend, Model: 0x1, Name: "c"},
+		},
+		Logs: []*Log{
+			{
+				Ops: []Op{
+					{Type: OpTypeInit, Model: 0x2, Name: "removed"},
+					{Type: OpTypeRemove, Model: 0x2},
+				},
+			},
+		},
+	}
+	if diff := cmp.Diff(expect, lg, allowUnexported, cmpopts.IgnoreUnexported(Log{})); diff != "" {
+		t.Errorf("result mismatch (-want +got):\n%s", diff)
+	}
+	if lg.ID() != id {
+		t.Errorf("expected compaction to keep the log ID")
+	}
+	if dropped := lg.Compact(); dropped != 0 {
+		t.Errorf("expected compacting twice to drop nothing. got: %d", dropped)
+	}
+}
+
 func TestJournalSignLog(t *testing.T) {
 	tr, cleanup := newTestRunner(t)
 	defer cleanup()
@@ -90,8 +90,20 @@ func (rcv *Log) LogsLength() int {
 	return 0
 }
 
+func (rcv *Log) Offset() int64 {
+	o := flatbuffers.UOffsetT(rcv._tab.Offset(14))
+	if o != 0 {
+		return rcv._tab.GetInt64(o + rcv._tab.Pos)
+	}
+	return 0
+}
+
+func (rcv *Log) MutateOffset(n int64) bool {
+	return rcv._tab.MutateInt64Slot(14, n)
+}
+
 func LogStart(builder *flatbuffers.Builder) {
-	builder.StartObject(5)
+	builder.StartObject(6)
 }
 func LogAddName(builder *flatbuffers.Builder, name flatbuffers.UOffsetT) {
 	builder.PrependUOffsetTSlot(0, flatbuffers.UOffsetT(name), 0)
@@ -114,6 +126,9 @@ func LogAddLogs(builder *flatbuffers.Builder, logs flatbuffers.UOffsetT) {
 func LogStartLogsVector(builder *flatbuffers.Builder, numElems int) flatbuffers.UOffsetT {
 	return builder.StartVector(4, numElems, 4)
 }
+func LogAddOffset(builder *flatbuffers.Builder, offset int64) {
+	builder.PrependInt64Slot(5, offset, 0)
+}
 func LogEnd(builder *flatbuffers.Builder) flatbuffers.UOffsetT {
 	return builder.EndObject()
 }
@@ -0,0 +1,241 @@
+package logbook
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	crypto "github.com/libp2p/go-libp2p-core/crypto"
+	"github.com/qri-io/qri/dsref"
+	"github.com/qri-io/qri/identity"
+	"github.com/qri-io/qri/logbook/oplog"
+)
+
+// ProblemKind enumerates the ways a log can fail verification
+type ProblemKind string
+
+const (
+	// ProblemUnsigned indicates a log that doesn't carry a signature
+	ProblemUnsigned = ProblemKind("unsigned")
+	// ProblemSignature indicates a log signature that doesn't match the
+	// author's public key
+	ProblemSignature = ProblemKind("signature")
+	// ProblemKey indicates an author public key that is unknown, or doesn't
+	// match the key ID recorded in the author's log
+	ProblemKey = ProblemKind("key")
+	// ProblemAuthor indicates an operation attributed to someone other than
+	// the author of the log it's written in
+	ProblemAuthor = ProblemKind("author")
+	// ProblemPrev indicates a commit operation whose Prev field doesn't link
+	// to the preceding version
+	ProblemPrev = ProblemKind("prev")
+)
+
+// VerifyProblem describes a single verification failure
+type VerifyProblem struct {
+	Kind  ProblemKind `json:"kind"`
+	LogID string      `json:"logID"`
+	Model string      `json:"model"`
+	Name  string      `json:"name,omitempty"`
+	// index of the failing operation within the log, -1 for problems that
+	// affect the log as a whole
+	Op      int    `json:"op"`
+	Message string `json:"message"`
+}
+
+// String formats a problem as a single line
+func (p VerifyProblem) String() string {
+	loc := fmt.Sprintf("%s log %s", p.Model, p.LogID)
+	if p.Name != "" {
+		loc = fmt.Sprintf("%s log %q (%s)", p.Model, p.Name, p.LogID)
+	}
+	if p.Op >= 0 {
+		loc = fmt.Sprintf("%s op %d", loc, p.Op)
+	}
+	return fmt.Sprintf("%s: %s: %s", p.Kind, loc, p.Message)
+}
+
+// VerifyReport is the result of verifying one or more logs
+type VerifyReport struct {
+	LogsChecked int             `json:"logsChecked"`
+	OpsChecked  int             `json:"opsChecked"`
+	Problems    []VerifyProblem `json:"problems"`
+}
+
+// OK returns true if verification found no problems
+func (r *VerifyReport) OK() bool {
+	return len(r.Problems) == 0
+}
+
+// Err returns a *VerifyError if the report has problems, nil otherwise
+func (r *VerifyReport) Err() error {
+	if r.OK() {
+		return nil
+	}
+	return &VerifyError{Report: r}
+}
+
+func (r *VerifyReport) addProblem(kind ProblemKind, lg *oplog.Log, op int, format string, args ...interface{}) {
+	r.Problems = append(r.Problems, VerifyProblem{
+		Kind:    kind,
+		LogID:   lg.ID(),
+		Model:   ModelString(lg.Model()),
+		Name:    lg.Name(),
+		Op:      op,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// VerifyError is returned when logs fail verification, carrying the full
+// report of which operations failed and why
+type VerifyError struct {
+	Report *VerifyReport
+}
+
+// Error implements the error interface
+func (e *VerifyError) Error() string {
+	lines := make([]string, len(e.Report.Problems))
+	for i, p := range e.Report.Problems {
+		lines[i] = "  " + p.String()
+	}
+	return fmt.Sprintf("logbook: verification failed with %d problem(s):\n%s", len(lines), strings.Join(lines, "\n"))
+}
+
+// KeyResolver gets the public key for an author key ID
+type KeyResolver func(keyID string) (crypto.PubKey, error)
+
+// VerifyLog checks a log tree rooted at an author log. Every log in the tree
+// must be signed by the author, operations must be attributed to the author,
+// and commit operations must link to the version before them
+func VerifyLog(lg *oplog.Log, keys KeyResolver) *VerifyReport {
+	report := &VerifyReport{}
+	verifyLog(report, lg, keys, true)
+	return report
+}
+
+// Verify checks logs in the book against their author's public keys. If ref
+// is empty, all logs in the book are checked. Logs this book authored don't
+// need signatures, they are signed when sent. keys may be nil, in which case
+// only this book's author key is known
+func (book *Book) Verify(ctx context.Context, ref dsref.Ref, keys KeyResolver) (*VerifyReport, error) {
+	if book == nil {
+		return nil, ErrNoLogbook
+	}
+
+	ownKeyID, err := identity.KeyIDFromPub(book.AuthorPubKey())
+	if err != nil {
+		return nil, err
+	}
+	resolve := func(keyID string) (crypto.PubKey, error) {
+		if keyID == ownKeyID {
+			return book.AuthorPubKey(), nil
+		}
+		if keys == nil {
+			return nil, ErrNotFound
+		}
+		return keys(keyID)
+	}
+
+	var logs []*oplog.Log
+	if ref.Username == "" && ref.Name == "" {
+		if logs, err = book.store.Logs(ctx, 0, -1); err != nil {
+			return nil, err
+		}
+	} else {
+		l, err := book.UserDatasetRef(ctx, ref)
+		if err != nil {
+			return nil, err
+		}
+		logs = []*oplog.Log{l}
+	}
+
+	report := &VerifyReport{}
+	for _, l := range logs {
+		own := len(l.Ops) > 0 && l.Ops[0].AuthorID == ownKeyID
+		verifyLog(report, l, resolve, !own)
+	}
+	return report, nil
+}
+
+func verifyLog(report *VerifyReport, root *oplog.Log, keys KeyResolver, requireSig bool) {
+	if len(root.Ops) == 0 || root.Model() != AuthorModel {
+		report.addProblem(ProblemAuthor, root, -1, "log isn't rooted as an author")
+		return
+	}
+
+	keyID := root.Ops[0].AuthorID
+	pub, err := keys(keyID)
+	if err != nil {
+		report.addProblem(ProblemKey, root, 0, "no public key for author key ID %q: %s", keyID, err)
+		pub = nil
+	} else if id, err := identity.KeyIDFromPub(pub); err != nil || id != keyID {
+		report.addProblem(ProblemKey, root, 0, "public key doesn't match author key ID %q", keyID)
+		pub = nil
+	}
+
+	authorID := root.ID()
+	var walk func(l *oplog.Log)
+	walk = func(l *oplog.Log) {
+		report.LogsChecked++
+		report.OpsChecked += len(l.Ops)
+
+		if len(l.Signature) == 0 {
+			if requireSig {
+				report.addProblem(ProblemUnsigned, l, -1, "log is not signed")
+			}
+		} else if pub != nil {
+			if err := l.Verify(pub); err != nil {
+				report.addProblem(ProblemSignature, l, -1, "signature doesn't match author key: %s", err)
+			}
+		}
+
+		for i, op := range l.Ops {
+			if l == root && i == 0 {
+				continue
+			}
+			if op.AuthorID != "" && op.AuthorID != authorID {
+				report.addProblem(ProblemAuthor, l, i, "operation author %q doesn't match log author %q", op.AuthorID, authorID)
+			}
+		}
+
+		if l.Model() == BranchModel {
+			verifyPrev(report, l)
+		}
+		for _, child := range l.Logs {
+			walk(child)
+		}
+	}
+	walk(root)
+}
+
+// verifyPrev checks each commit in a branch log links to the version before
+// it, following the same amend & remove semantics used to list versions
+func verifyPrev(report *VerifyReport, l *oplog.Log) {
+	heads := []string{}
+	for i, op := range l.Ops {
+		if op.Model != CommitModel {
+			continue
+		}
+		switch op.Type {
+		case oplog.OpTypeInit:
+			head := ""
+			if len(heads) > 0 {
+				head = heads[len(heads)-1]
+			}
+			if op.Prev != "" && op.Prev != head {
+				report.addProblem(ProblemPrev, l, i, "prev %q doesn't match previous version %q", op.Prev, head)
+			}
+			heads = append(heads, op.Ref)
+		case oplog.OpTypeAmend:
+			if len(heads) > 0 {
+				heads[len(heads)-1] = op.Ref
+			}
+		case oplog.OpTypeRemove:
+			n := int(op.Size)
+			if n > len(heads) {
+				n = len(heads)
+			}
+			heads = heads[:len(heads)-n]
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package logbook
+
+import (
+	"testing"
+
+	crypto "github.com/libp2p/go-libp2p-core/crypto"
+	"github.com/qri-io/qri/dsref"
+	"github.com/qri-io/qri/logbook/oplog"
+)
+
+func TestBookVerify(t *testing.T) {
+	tr, cleanup := newTestRunner(t)
+	defer cleanup()
+
+	initID := tr.WriteWorldBankExample(t)
+	tr.WriteMoreWorldBankCommits(t, initID)
+	tr.WriteRenameExample(t)
+
+	report, err := tr.Book.Verify(tr.Ctx, dsref.Ref{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !report.OK() {
+		t.Errorf("expected unsigned logs authored by the book to verify. got: %s", report.Err())
+	}
+	if report.LogsChecked == 0 || report.OpsChecked == 0 {
+		t.Errorf("expected logs & ops to be checked. got %d logs, %d ops", report.LogsChecked, report.OpsChecked)
+	}
+
+	report, err = tr.Book.Verify(tr.Ctx, tr.WorldBankRef(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !report.OK() {
+		t.Errorf("expected world bank ref to verify. got: %s", report.Err())
+	}
+}
+
+func TestVerifyLog(t *testing.T) {
+	tr, cleanup := newTestRunner(t)
+	defer cleanup()
+
+	initID := tr.WriteWorldBankExample(t)
+	tr.WriteMoreWorldBankCommits(t, initID)
+
+	keys := func(keyID string) (crypto.PubKey, error) {
+		return tr.Book.AuthorPubKey(), nil
+	}
+	wrongKeys := func(keyID string) (crypto.PubKey, error) {
+		return testPrivKey2(t).GetPublic(), nil
+	}
+
+	received := func() *oplog.Log {
+		lg, err := tr.Book.UserDatasetRef(tr.Ctx, tr.WorldBankRef())
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := tr.Book.LogBytes(lg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := &oplog.Log{}
+		if err := got.UnmarshalFlatbufferBytes(data); err != nil {
+			t.Fatal(err)
+		}
+		return got
+	}
+	branchLog := func(lg *oplog.Log) *oplog.Log {
+		return lg.Logs[0].Logs[0]
+	}
+
+	if report := VerifyLog(received(), keys); !report.OK() {
+		t.Errorf("expected signed log to verify. got: %s", report.Err())
+	}
+
+	unsigned, err := tr.Book.UserDatasetRef(tr.Ctx, tr.WorldBankRef())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bad := received()
+	branchLog(bad).Ops[len(branchLog(bad).Ops)-1].Ref = "QmNotTheHashYouSigned"
+	badPrev := received()
+	for i, op := range branchLog(badPrev).Ops {
+		if op.Model == CommitModel && op.Prev == "QmHashOfVersion4" {
+			branchLog(badPrev).Ops[i].Prev = "QmHashOfVersion2"
+		}
+	}
+	badAuthor := received()
+	badAuthor.Logs[0].Ops[0].AuthorID = "QmSomebodyElse"
+
+	cases := []struct {
+		description string
+		lg          *oplog.Log
+		keys        KeyResolver
+		kind        ProblemKind
+	}{
+		{"unsigned log", unsigned, keys, ProblemUnsigned},
+		{"modified commit ref", bad, keys, ProblemSignature},
+		{"broken prev link", badPrev, keys, ProblemPrev},
+		{"mismatched author", badAuthor, keys, ProblemAuthor},
+		{"wrong author key", received(), wrongKeys, ProblemKey},
+	}
+
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			report := VerifyLog(c.lg, c.keys)
+			if report.OK() {
+				t.Fatal("expected verification to fail")
+			}
+			found := false
+			for _, p := range report.Problems {
+				if p.Kind == c.kind {
+					found = true
+				}
+			}
+			if !found {
+				t.Errorf("expected a %q problem. got: %s", c.kind, report.Err())
+			}
+			if _, ok := report.Err().(*VerifyError); !ok {
+				t.Errorf("expected report error to be a *VerifyError")
+			}
+		})
+	}
+}
@@ -23,6 +23,7 @@ import (
 	"github.com/qri-io/ioes"
 	ipfs_filestore "github.com/qri-io/qfs/cafs/ipfs"
 	"github.com/qri-io/qri/config"
+	"github.com/qri-io/qri/event"
 	p2ptest "github.com/qri-io/qri/p2p/test"
 	"github.com/qri-io/qri/repo"
 )
@@ -77,6 +78,10 @@ type QriNode struct {
 	// local feedback as opposed to p2p connections
 	LocalStreams ioes.IOStreams
 
+	// Publisher receives events for messages peers send to this node, like
+	// dataset notifications. Defaults to a NilPublisher
+	Publisher event.Publisher
+
 	// TODO - waiting on next IPFS release
 	// autoNAT service
 	// autonat *autonat.AutoNATService
@@ -111,6 +116,7 @@ func NewQriNode(r repo.Repo, p2pconf *config.P2P) (node *QriNode, err error) {
 		// Make sure we always have proper IOStreams, this can be set
 		// later
 		LocalStreams: ioes.NewDiscardIOStreams(),
+		Publisher:    &event.NilPublisher{},
 	}
 	node.handlers = MakeHandlers(node)
 
@@ -447,12 +453,13 @@ func (n *QriNode) SimplePeerInfo() pstore.PeerInfo {
 // MakeHandlers generates a map of MsgTypes to their corresponding handler functions
 func MakeHandlers(n *QriNode) map[MsgType]HandlerFunc {
 	return map[MsgType]HandlerFunc{
-		MtPing:              n.handlePing,
-		MtProfile:           n.handleProfile,
-		MtDatasetInfo:       n.handleDataset,
-		MtDatasets:          n.handleDatasetsList,
-		MtConnected:         n.handleConnected,
-		MtResolveDatasetRef: n.handleResolveDatasetRef,
-		MtQriPeers:          n.handleQriPeers,
+		MtPing:                n.handlePing,
+		MtProfile:             n.handleProfile,
+		MtDatasetInfo:         n.handleDataset,
+		MtDatasets:            n.handleDatasetsList,
+		MtConnected:           n.handleConnected,
+		MtResolveDatasetRef:   n.handleResolveDatasetRef,
+		MtQriPeers:            n.handleQriPeers,
+		MtDatasetNotification: n.handleDatasetNotification,
 	}
 }
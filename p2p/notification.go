@@ -0,0 +1,44 @@
+package p2p
+
+import (
+	"context"
+	"encoding/json"
+
+	peer "github.com/libp2p/go-libp2p-core/peer"
+	"github.com/qri-io/qri/dsref"
+	"github.com/qri-io/qri/event"
+)
+
+// MtDatasetNotification announces a new version of a dataset to subscribed
+// peers
+const MtDatasetNotification = MsgType("dataset_notification")
+
+// SendDatasetNotification tells peers a new version of a dataset is available.
+// Notifications are one-way, peers don't reply
+func (n *QriNode) SendDatasetNotification(ctx context.Context, ref dsref.Ref, pids ...peer.ID) error {
+	msg, err := NewJSONBodyMessage(n.ID, MtDatasetNotification, ref)
+	if err != nil {
+		return err
+	}
+	return n.SendMessage(ctx, msg, nil, pids...)
+}
+
+// handleDatasetNotification publishes dataset notifications from peers as
+// ETRemoteDatasetNotificationEvent events
+func (n *QriNode) handleDatasetNotification(ws *WrappedStream, msg Message) (hangup bool) {
+	hangup = true
+
+	ref := dsref.Ref{}
+	if err := json.Unmarshal(msg.Body, &ref); err != nil {
+		log.Debugf("invalid dataset notification: %s", err.Error())
+		return
+	}
+
+	if n.Publisher != nil {
+		n.Publisher.Publish(event.ETRemoteDatasetNotificationEvent, event.RemoteEvent{
+			Ref:        ref,
+			RemoteAddr: msg.Initiator.Pretty(),
+		})
+	}
+	return
+}
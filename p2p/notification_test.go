@@ -0,0 +1,50 @@
+package p2p
+
+import (
+	"testing"
+
+	"github.com/qri-io/qri/dsref"
+	"github.com/qri-io/qri/event"
+)
+
+type recordingPublisher struct {
+	topics   []event.Topic
+	payloads []interface{}
+}
+
+func (p *recordingPublisher) Publish(t event.Topic, data interface{}) {
+	p.topics = append(p.topics, t)
+	p.payloads = append(p.payloads, data)
+}
+
+func TestHandleDatasetNotification(t *testing.T) {
+	tr, cleanup := newTestRunner(t)
+	defer cleanup()
+
+	node := tr.IPFSBackedQriNode(t, "notification_peer")
+	pub := &recordingPublisher{}
+	node.Publisher = pub
+
+	ref := dsref.Ref{Username: "peer", Name: "ds", Path: "/ipfs/QmNewVersion"}
+	msg, err := NewJSONBodyMessage(node.ID, MtDatasetNotification, ref)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if hangup := node.handleDatasetNotification(nil, msg); !hangup {
+		t.Error("expected notification handler to hang up")
+	}
+	if len(pub.topics) != 1 || pub.topics[0] != event.ETRemoteDatasetNotificationEvent {
+		t.Fatalf("expected one notification event, got: %v", pub.topics)
+	}
+	got := pub.payloads[0].(event.RemoteEvent)
+	if !got.Ref.Equals(ref) || got.RemoteAddr != node.ID.Pretty() {
+		t.Errorf("notification event mismatch. got: %v", got)
+	}
+
+	// malformed notifications are dropped
+	node.handleDatasetNotification(nil, NewMessage(node.ID, MtDatasetNotification, []byte("not json")))
+	if len(pub.topics) != 1 {
+		t.Errorf("expected malformed notification not to publish")
+	}
+}
@@ -0,0 +1,113 @@
+package remote
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/qri-io/dag"
+)
+
+const checkpointsFilename = "checkpoints.json"
+
+// Checkpoint records an unfinished dataset transfer. Blocks moved before an
+// interruption are kept by the receiver, so a transfer resumed from a
+// checkpoint reuses the recorded manifest & only moves the blocks that are
+// still missing
+type Checkpoint struct {
+	Direction   string    `json:"direction"`
+	RemoteAddr  string    `json:"remoteAddr"`
+	Path        string    `json:"path"`
+	Info        *dag.Info `json:"info"`
+	BlocksDone  int       `json:"blocksDone"`
+	BlocksTotal int       `json:"blocksTotal"`
+	Updated     time.Time `json:"updated"`
+}
+
+func (cp *Checkpoint) key() string {
+	return checkpointKey(cp.Direction, cp.RemoteAddr, cp.Path)
+}
+
+func checkpointKey(direction, remoteAddr, path string) string {
+	return fmt.Sprintf("%s %s %s", direction, remoteAddr, path)
+}
+
+// Checkpoints stores transfer checkpoints, persisting them to a directory
+type Checkpoints struct {
+	dir string
+	lk  sync.Mutex
+	cps map[string]*Checkpoint
+}
+
+// NewCheckpoints creates a checkpoint store backed by a directory. An empty
+// dir keeps checkpoints in memory only
+func NewCheckpoints(dir string) (*Checkpoints, error) {
+	cps := &Checkpoints{dir: dir, cps: map[string]*Checkpoint{}}
+	if dir == "" {
+		return cps, nil
+	}
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return nil, err
+	}
+
+	list := []*Checkpoint{}
+	if err := readJSONFile(dir, checkpointsFilename, &list); err != nil {
+		return nil, fmt.Errorf("reading transfer checkpoints: %w", err)
+	}
+	for _, cp := range list {
+		cps.cps[cp.key()] = cp
+	}
+	return cps, nil
+}
+
+// Get returns the checkpoint for a transfer, nil if none exists
+func (c *Checkpoints) Get(direction, remoteAddr, path string) *Checkpoint {
+	if c == nil {
+		return nil
+	}
+	c.lk.Lock()
+	defer c.lk.Unlock()
+	return c.cps[checkpointKey(direction, remoteAddr, path)]
+}
+
+// Put adds or replaces a checkpoint
+func (c *Checkpoints) Put(cp *Checkpoint) error {
+	if c == nil {
+		return nil
+	}
+	c.lk.Lock()
+	defer c.lk.Unlock()
+	c.cps[cp.key()] = cp
+	return c.write()
+}
+
+// Delete removes the checkpoint for a transfer
+func (c *Checkpoints) Delete(direction, remoteAddr, path string) error {
+	if c == nil {
+		return nil
+	}
+	c.lk.Lock()
+	defer c.lk.Unlock()
+	key := checkpointKey(direction, remoteAddr, path)
+	if _, ok := c.cps[key]; !ok {
+		return nil
+	}
+	delete(c.cps, key)
+	return c.write()
+}
+
+func (c *Checkpoints) list() []*Checkpoint {
+	list := make([]*Checkpoint, 0, len(c.cps))
+	for _, cp := range c.cps {
+		list = append(list, cp)
+	}
+	return list
+}
+
+func (c *Checkpoints) write() error {
+	if c.dir == "" {
+		return nil
+	}
+	return writeJSONFile(c.dir, checkpointsFilename, c.list())
+}
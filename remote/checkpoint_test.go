@@ -0,0 +1,73 @@
+package remote
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/qri-io/dag"
+)
+
+func TestCheckpoints(t *testing.T) {
+	dir, err := ioutil.TempDir("", "remote_checkpoints")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	cps, err := NewCheckpoints(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cp := cps.Get(DirectionPush, "http://remote", "/ipfs/QmFoo"); cp != nil {
+		t.Errorf("expected empty store to have no checkpoints")
+	}
+
+	info := &dag.Info{
+		Manifest: &dag.Manifest{Nodes: []string{"a", "b"}, Links: [][2]int{{0, 1}}},
+		Sizes:    []uint64{10, 20},
+	}
+	if err := cps.Put(&Checkpoint{Direction: DirectionPush, RemoteAddr: "http://remote", Path: "/ipfs/QmFoo", Info: info, BlocksDone: 1, BlocksTotal: 2}); err != nil {
+		t.Fatal(err)
+	}
+	if err := cps.Put(&Checkpoint{Direction: DirectionPull, RemoteAddr: "http://remote", Path: "/ipfs/QmFoo", Info: info}); err != nil {
+		t.Fatal(err)
+	}
+
+	reloaded, err := NewCheckpoints(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cp := reloaded.Get(DirectionPush, "http://remote", "/ipfs/QmFoo")
+	if cp == nil {
+		t.Fatal("expected checkpoint to persist")
+	}
+	if cp.BlocksDone != 1 || cp.BlocksTotal != 2 || len(cp.Info.Manifest.Nodes) != 2 || cp.Info.Sizes[1] != 20 {
+		t.Errorf("reloaded checkpoint mismatch. got: %#v", cp)
+	}
+
+	if err := reloaded.Delete(DirectionPush, "http://remote", "/ipfs/QmFoo"); err != nil {
+		t.Fatal(err)
+	}
+	reloaded, err = NewCheckpoints(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cp := reloaded.Get(DirectionPush, "http://remote", "/ipfs/QmFoo"); cp != nil {
+		t.Error("expected deleted checkpoint to be removed")
+	}
+	if cp := reloaded.Get(DirectionPull, "http://remote", "/ipfs/QmFoo"); cp == nil {
+		t.Error("expected delete to leave other checkpoints in place")
+	}
+
+	mem, err := NewCheckpoints("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := mem.Put(&Checkpoint{Direction: DirectionPull, RemoteAddr: "http://remote", Path: "/ipfs/QmBar", Info: info}); err != nil {
+		t.Fatal(err)
+	}
+	if cp := mem.Get(DirectionPull, "http://remote", "/ipfs/QmBar"); cp == nil {
+		t.Error("expected in-memory store to keep checkpoints")
+	}
+}
@@ -15,15 +15,24 @@ type Client interface {
 	ResolveHeadRef(ctx context.Context, ref *reporef.DatasetRef, remoteAddr string) error
 
 	PushDataset(ctx context.Context, ref reporef.DatasetRef, remoteAddr string) error
+	PreviewPush(ctx context.Context, ref reporef.DatasetRef, versions []string, remoteAddr string) (*PushPreview, error)
 	PullDataset(ctx context.Context, ref *reporef.DatasetRef, remoteAddr string) error
+	PullDatasetComponents(ctx context.Context, ref *reporef.DatasetRef, remoteAddr string, components []string) error
 	RemoveDataset(ctx context.Context, ref reporef.DatasetRef, remoteAddr string) error
 	AddDataset(ctx context.Context, ref *reporef.DatasetRef, remoteAddr string) error
 
 	PushLogs(ctx context.Context, ref dsref.Ref, remoteAddr string) error
 	FetchLogs(ctx context.Context, ref dsref.Ref, remoteAddr string) (*oplog.Log, error)
 	CloneLogs(ctx context.Context, ref dsref.Ref, remoteAddr string) error
+	CloneUserLogs(ctx context.Context, username, remoteAddr string) ([]dsref.Ref, error)
 	RemoveLogs(ctx context.Context, ref dsref.Ref, remoteAddr string) error
 
 	Feeds(ctx context.Context, remoteAddr string) (map[string][]dsref.VersionInfo, error)
+	Feed(ctx context.Context, remoteAddr, name string, offset, limit int) ([]dsref.VersionInfo, error)
 	Preview(ctx context.Context, ref dsref.Ref, remoteAddr string) (*dataset.Dataset, error)
+	Search(ctx context.Context, p SearchParams, remoteAddr string) ([]dsref.VersionInfo, error)
+
+	Subscribe(ctx context.Context, ref dsref.Ref, remoteAddr string) error
+	Unsubscribe(ctx context.Context, ref dsref.Ref, remoteAddr string) error
+	Notifications(ctx context.Context, remoteAddr string) (<-chan dsref.Ref, error)
 }
@@ -0,0 +1,81 @@
+package remote
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/qri-io/dag"
+	"github.com/qri-io/qri/base/dsfs"
+)
+
+// DatasetComponents lists the names of components a dataset version can be
+// partially pulled by
+var DatasetComponents = []string{"meta", "structure", "commit", "transform", "viz", "readme", "body"}
+
+// IsDatasetComponent returns true if name is one of DatasetComponents
+func IsDatasetComponent(name string) bool {
+	for _, comp := range DatasetComponents {
+		if name == comp {
+			return true
+		}
+	}
+	return false
+}
+
+// packageFileComponent gives the component a file in a dataset package belongs
+// to. Files every version needs to load return the empty string
+func packageFileComponent(name string) string {
+	switch name {
+	case dsfs.PackageFileDataset.String(), dsfs.PackageFileCommit.String():
+		return ""
+	case dsfs.PackageFileRenderedViz.String():
+		return "viz"
+	}
+
+	base := strings.TrimSuffix(name, filepath.Ext(name))
+	for _, comp := range DatasetComponents {
+		if strings.HasPrefix(base, comp) {
+			return comp
+		}
+	}
+	return ""
+}
+
+// subDAGsInfo narrows info to the root node & the sub-DAGs rooted at ids,
+// keeping the order of nodes in the original manifest
+func subDAGsInfo(info *dag.Info, ids []string) (*dag.Info, error) {
+	if info.Manifest == nil || len(info.Manifest.Nodes) == 0 {
+		return info, nil
+	}
+	keep := map[string]bool{info.Manifest.Nodes[0]: true}
+	for _, id := range ids {
+		sub, err := info.InfoAtID(id)
+		if err != nil {
+			return nil, err
+		}
+		for _, n := range sub.Manifest.Nodes {
+			keep[n] = true
+		}
+	}
+
+	res := &dag.Info{Manifest: &dag.Manifest{}}
+	idx := map[int]int{}
+	for i, n := range info.Manifest.Nodes {
+		if !keep[n] {
+			continue
+		}
+		idx[i] = len(res.Manifest.Nodes)
+		res.Manifest.Nodes = append(res.Manifest.Nodes, n)
+		if i < len(info.Sizes) {
+			res.Sizes = append(res.Sizes, info.Sizes[i])
+		}
+	}
+	for _, l := range info.Manifest.Links {
+		from, fromOk := idx[l[0]]
+		to, toOk := idx[l[1]]
+		if fromOk && toOk {
+			res.Manifest.Links = append(res.Manifest.Links, [2]int{from, to})
+		}
+	}
+	return res, nil
+}
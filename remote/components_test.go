@@ -0,0 +1,136 @@
+package remote
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	cid "github.com/ipfs/go-cid"
+	"github.com/qri-io/dag"
+	"github.com/qri-io/dag/dsync"
+)
+
+func TestPackageFileComponent(t *testing.T) {
+	cases := []struct {
+		name, expect string
+	}{
+		{"dataset.json", ""},
+		{"commit.json", ""},
+		{"meta.json", "meta"},
+		{"structure.json", "structure"},
+		{"body.csv", "body"},
+		{"body.json", "body"},
+		{"index.html", "viz"},
+		{"viz.json", "viz"},
+		{"readme.md", "readme"},
+		{"transform.json", "transform"},
+		{"unknown.txt", ""},
+	}
+	for _, c := range cases {
+		if got := packageFileComponent(c.name); got != c.expect {
+			t.Errorf("%s: expected %q, got %q", c.name, c.expect, got)
+		}
+	}
+}
+
+func TestSubDAGsInfo(t *testing.T) {
+	//   a
+	//  / \
+	// b   c
+	//    / \
+	//   d   e
+	info := &dag.Info{
+		Manifest: &dag.Manifest{
+			Nodes: []string{"a", "c", "b", "d", "e"},
+			Links: [][2]int{{0, 1}, {0, 2}, {1, 3}, {1, 4}},
+		},
+		Sizes: []uint64{1, 2, 3, 4, 5},
+	}
+
+	got, err := subDAGsInfo(info, []string{"c"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := &dag.Info{
+		Manifest: &dag.Manifest{
+			Nodes: []string{"a", "c", "d", "e"},
+			Links: [][2]int{{0, 1}, {1, 2}, {1, 3}},
+		},
+		Sizes: []uint64{1, 2, 4, 5},
+	}
+	if diff := cmp.Diff(expect, got); diff != "" {
+		t.Errorf("result mismatch (-want +got):\n%s", diff)
+	}
+
+	got, err = subDAGsInfo(info, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Manifest.Nodes) != 1 || got.Manifest.Nodes[0] != "a" {
+		t.Errorf("expected only the root node. got: %v", got.Manifest.Nodes)
+	}
+
+	if _, err := subDAGsInfo(info, []string{"z"}); err == nil {
+		t.Error("expected unknown id to error")
+	}
+}
+
+func TestPullDatasetComponents(t *testing.T) {
+	tr, cleanup := newTestRunner(t)
+	defer cleanup()
+
+	rem := tr.NodeARemote(t)
+	server := tr.RemoteTestServer(rem)
+	defer server.Close()
+
+	worldBankRef := writeWorldBankPopulation(tr.Ctx, t, tr.NodeA.Repo)
+	cli := tr.NodeBClient(t)
+
+	if err := cli.PullDatasetComponents(tr.Ctx, &worldBankRef, server.URL, []string{"nope"}); err == nil {
+		t.Error("expected unknown component to error")
+	}
+
+	if err := cli.PullDatasetComponents(tr.Ctx, &worldBankRef, server.URL, []string{"meta", "structure"}); err != nil {
+		t.Fatal(err)
+	}
+
+	capi, err := tr.NodeA.IPFSCoreAPI()
+	if err != nil {
+		t.Fatal(err)
+	}
+	lng, err := dsync.NewLocalNodeGetter(capi)
+	if err != nil {
+		t.Fatal(err)
+	}
+	root, err := cid.Parse(worldBankRef.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	node, err := lng.Get(tr.Ctx, root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	checked := 0
+	for _, l := range node.Links() {
+		has, err := tr.NodeB.Repo.Store().Has(tr.Ctx, l.Cid.String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		switch {
+		case strings.HasPrefix(l.Name, "body"):
+			checked++
+			if has {
+				t.Errorf("expected body file %s not to be pulled", l.Name)
+			}
+		case l.Name == "dataset.json", l.Name == "commit.json", l.Name == "meta.json", l.Name == "structure.json":
+			checked++
+			if !has {
+				t.Errorf("expected %s to be pulled", l.Name)
+			}
+		}
+	}
+	if checked != 5 {
+		t.Errorf("expected to check 5 package files. checked %d", checked)
+	}
+}
@@ -0,0 +1,35 @@
+package remote
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// writeJSONFile replaces a JSON file in dir, writing to a temp file first so a
+// crash mid-write can't corrupt it
+func writeJSONFile(dir, filename string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(dir, filename)
+	tmp := path + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// readJSONFile decodes a JSON file in dir into v, leaving v unchanged if the
+// file doesn't exist
+func readJSONFile(dir, filename string, v interface{}) error {
+	data, err := ioutil.ReadFile(filepath.Join(dir, filename))
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
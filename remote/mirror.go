@@ -0,0 +1,416 @@
+package remote
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/qri-io/qfs/cafs"
+	"github.com/qri-io/qri/config"
+	"github.com/qri-io/qri/dsref"
+	"github.com/qri-io/qri/event"
+	"github.com/qri-io/qri/logbook"
+	"github.com/qri-io/qri/p2p"
+	"github.com/qri-io/qri/repo"
+	reporef "github.com/qri-io/qri/repo/ref"
+)
+
+const mirrorStatusFilename = "status.json"
+
+const (
+	// number of feed entries to request per page when listing upstream datasets
+	mirrorFeedPageSize = 100
+	// maximum number of feed pages read when matching wildcard patterns
+	mirrorFeedMaxPages = 100
+)
+
+// MirrorStatus describes the replication state of a single dataset mirrored
+// from an upstream remote
+type MirrorStatus struct {
+	// Upstream is the mirror upstream as written in config
+	Upstream string `json:"upstream"`
+	// Ref is the "peername/name" alias of the dataset
+	Ref string `json:"ref"`
+	// Path of the most recently replicated version
+	Path string `json:"path,omitempty"`
+	// LastCheck is the last time the upstream was asked for the dataset head
+	LastCheck time.Time `json:"lastCheck"`
+	// LastSync is the last time a new version was replicated
+	LastSync time.Time `json:"lastSync,omitempty"`
+	// Error from the most recent check, if any
+	Error string `json:"error,omitempty"`
+}
+
+func (s *MirrorStatus) key() string {
+	return mirrorStatusKey(s.Upstream, s.Ref)
+}
+
+func mirrorStatusKey(upstream, alias string) string {
+	return fmt.Sprintf("%s %s", upstream, alias)
+}
+
+// MirrorOptions configures a mirror
+type MirrorOptions struct {
+	// Publisher receives an ETRemoteDatasetMirroredEvent for each replicated
+	// version
+	Publisher event.Publisher
+	// StatusDir is the directory replication status is persisted to. empty
+	// keeps status in memory only
+	StatusDir string
+	// Search is updated as datasets are replicated, if it's a SearchUpdater
+	Search Search
+}
+
+// Mirror replicates datasets from upstream remotes into the local repo on a
+// schedule. Replicated datasets are pinned & marked as published, so a remote
+// running a mirror serves them to its own clients
+type Mirror struct {
+	node      *p2p.QriNode
+	cli       Client
+	pub       event.Publisher
+	search    Search
+	upstreams []*config.Mirror
+	addrs     map[string]string
+	dir       string
+	now       func() time.Time
+
+	lk     sync.Mutex
+	status map[string]*MirrorStatus
+}
+
+// NewMirror creates a mirror for the upstreams listed in cfg.Remote.Mirrors.
+// Upstreams are resolved to addresses using cfg.Remotes
+func NewMirror(node *p2p.QriNode, cli Client, cfg *config.Config, opts ...func(o *MirrorOptions)) (*Mirror, error) {
+	o := &MirrorOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	if node == nil {
+		return nil, fmt.Errorf("mirror requires a non-nil node")
+	}
+	if cli == nil {
+		return nil, ErrNoRemoteClient
+	}
+	if o.Publisher == nil {
+		o.Publisher = &event.NilPublisher{}
+	}
+
+	m := &Mirror{
+		node:   node,
+		cli:    cli,
+		pub:    o.Publisher,
+		search: o.Search,
+		addrs:  map[string]string{},
+		dir:    o.StatusDir,
+		now:    time.Now,
+		status: map[string]*MirrorStatus{},
+	}
+
+	if cfg.Remote != nil {
+		for _, mc := range cfg.Remote.Mirrors {
+			if _, err := mc.IntervalDuration(); err != nil {
+				return nil, err
+			}
+			addr, err := MirrorAddress(cfg, mc.Upstream)
+			if err != nil {
+				return nil, err
+			}
+			m.addrs[mc.Upstream] = addr
+			m.upstreams = append(m.upstreams, mc.Copy())
+		}
+	}
+
+	if m.dir != "" {
+		if err := os.MkdirAll(m.dir, os.ModePerm); err != nil {
+			return nil, err
+		}
+		list, err := ReadMirrorStatus(m.dir)
+		if err != nil {
+			return nil, err
+		}
+		for _, s := range list {
+			m.status[s.key()] = s
+		}
+	}
+
+	return m, nil
+}
+
+// MirrorAddress resolves a mirror upstream to a remote address. upstreams that
+// are already addresses are returned as-is
+func MirrorAddress(cfg *config.Config, upstream string) (string, error) {
+	if addressType(upstream) != "" {
+		return upstream, nil
+	}
+	return Address(cfg, upstream)
+}
+
+// Start runs replication for each upstream at its configured interval until
+// ctx is cancelled. Each upstream is synced once immediately
+func (m *Mirror) Start(ctx context.Context) {
+	for _, mc := range m.upstreams {
+		go func(mc *config.Mirror) {
+			interval, _ := mc.IntervalDuration()
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+			for {
+				if err := m.SyncUpstream(ctx, mc); err != nil {
+					log.Errorf("mirroring from %s: %s", mc.Upstream, err)
+				}
+				select {
+				case <-ticker.C:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}(mc)
+	}
+}
+
+// Sync performs a single replication pass over all upstreams, returning the
+// first upstream-level error encountered. Errors replicating individual
+// datasets are recorded in dataset status
+func (m *Mirror) Sync(ctx context.Context) error {
+	var firstErr error
+	for _, mc := range m.upstreams {
+		if err := m.SyncUpstream(ctx, mc); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// SyncUpstream replicates new versions of all datasets matching the patterns
+// of a single mirror
+func (m *Mirror) SyncUpstream(ctx context.Context, mc *config.Mirror) error {
+	addr, ok := m.addrs[mc.Upstream]
+	if !ok {
+		return fmt.Errorf("unknown mirror upstream %q", mc.Upstream)
+	}
+
+	refs, err := m.matches(ctx, addr, mc.Patterns)
+	if err != nil {
+		return err
+	}
+
+	for _, ref := range refs {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		m.syncDataset(ctx, mc.Upstream, addr, ref)
+	}
+	return nil
+}
+
+// matches lists datasets on the upstream that match any of patterns.
+// patterns without wildcards name a dataset directly, wildcard patterns are
+// matched against the upstream "recent" feed
+func (m *Mirror) matches(ctx context.Context, addr string, patterns []string) ([]reporef.DatasetRef, error) {
+	seen := map[string]bool{}
+	res := []reporef.DatasetRef{}
+	add := func(peername, name string) {
+		alias := peername + "/" + name
+		if seen[alias] {
+			return
+		}
+		seen[alias] = true
+		res = append(res, reporef.DatasetRef{Peername: peername, Name: name})
+	}
+
+	var wildcards []string
+	for _, p := range patterns {
+		if strings.ContainsAny(p, "*?[") {
+			if _, err := path.Match(p, ""); err != nil {
+				return nil, fmt.Errorf("invalid mirror pattern %q: %w", p, err)
+			}
+			wildcards = append(wildcards, p)
+			continue
+		}
+		ref, err := dsref.ParseHumanFriendly(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid mirror pattern %q: %w", p, err)
+		}
+		add(ref.Username, ref.Name)
+	}
+	if len(wildcards) == 0 {
+		return res, nil
+	}
+
+	for i := 0; i < mirrorFeedMaxPages; i++ {
+		page, err := m.cli.Feed(ctx, addr, "recent", i*mirrorFeedPageSize, mirrorFeedPageSize)
+		if err != nil {
+			return nil, fmt.Errorf("listing upstream datasets: %w", err)
+		}
+		for _, vi := range page {
+			for _, p := range wildcards {
+				if ok, _ := path.Match(p, vi.Username+"/"+vi.Name); ok {
+					add(vi.Username, vi.Name)
+					break
+				}
+			}
+		}
+		if len(page) < mirrorFeedPageSize {
+			return res, nil
+		}
+	}
+	log.Errorf("mirror upstream %s feed exceeds %d entries, matching stopped early", addr, mirrorFeedMaxPages*mirrorFeedPageSize)
+	return res, nil
+}
+
+// syncDataset replicates versions of a dataset from an upstream that are newer
+// than the last mirrored version, recording the outcome in dataset status
+func (m *Mirror) syncDataset(ctx context.Context, upstream, addr string, ref reporef.DatasetRef) {
+	st := &MirrorStatus{Upstream: upstream, Ref: ref.AliasString()}
+	if prev := m.getStatus(st.key()); prev != nil {
+		*st = *prev
+	}
+	st.LastCheck = m.now()
+
+	synced, err := m.replicate(ctx, addr, &ref, st.Path)
+	if err != nil {
+		log.Debugf("mirroring %s from %s: %s", st.Ref, upstream, err)
+		st.Error = err.Error()
+	} else {
+		st.Error = ""
+		st.Path = ref.Path
+		if len(synced) > 0 {
+			st.LastSync = st.LastCheck
+		}
+		for _, v := range synced {
+			m.pub.Publish(event.ETRemoteDatasetMirroredEvent, event.RemoteEvent{
+				Ref:        reporef.ConvertToDsref(v),
+				RemoteAddr: addr,
+			})
+		}
+	}
+
+	if err := m.putStatus(st); err != nil {
+		log.Errorf("writing mirror status: %s", err)
+	}
+}
+
+// replicate fetches the logs of a dataset from addr & pulls every version
+// newer than lastPath the local repo doesn't have, oldest first. ref is set to
+// the upstream head. replicate returns the versions it pulled
+func (m *Mirror) replicate(ctx context.Context, addr string, ref *reporef.DatasetRef, lastPath string) ([]reporef.DatasetRef, error) {
+	dr := reporef.ConvertToDsref(*ref)
+	lg, err := m.cli.FetchLogs(ctx, dr, addr)
+	if err != nil {
+		return nil, fmt.Errorf("fetching logs: %w", err)
+	}
+	// fetched logs are arranged user > dataset > branch
+	if len(lg.Logs) == 0 || len(lg.Logs[0].Logs) == 0 {
+		return nil, repo.ErrNoHistory
+	}
+	items := logbook.ConvertLogsToItems(lg.Logs[0].Logs[0], dr)
+	if len(items) == 0 {
+		return nil, repo.ErrNoHistory
+	}
+	ref.Path = items[0].Path
+
+	pulled := []reporef.DatasetRef{}
+	for _, item := range mirrorVersions(items, lastPath) {
+		if local, err := m.node.Repo.Store().Has(ctx, item.Path); err == nil && local {
+			continue
+		}
+		vref := *ref
+		vref.Path = item.Path
+		if err := m.cli.PullDataset(ctx, &vref, addr); err != nil {
+			return pulled, fmt.Errorf("pulling version %s: %w", item.Path, err)
+		}
+		if pinner, ok := m.node.Repo.Store().(cafs.Pinner); ok {
+			if err := pinner.Pin(ctx, item.Path, true); err != nil {
+				return pulled, fmt.Errorf("pinning version %s: %w", item.Path, err)
+			}
+		}
+		pulled = append(pulled, vref)
+	}
+
+	local, err := m.node.Repo.GetRef(reporef.DatasetRef{Peername: ref.Peername, Name: ref.Name})
+	if err != nil && err != repo.ErrNotFound {
+		return pulled, err
+	}
+	if err == nil && local.Path == ref.Path && len(pulled) == 0 {
+		return pulled, nil
+	}
+
+	// merge logs once versions are stored, so history never references
+	// missing data
+	if err := m.cli.CloneLogs(ctx, dr, addr); err != nil {
+		return pulled, fmt.Errorf("fetching logs: %w", err)
+	}
+	// mirrored datasets are served to this remote's clients
+	ref.Published = true
+	if err := m.node.Repo.PutRef(*ref); err != nil {
+		return pulled, err
+	}
+	UpdateSearch(ctx, m.search, ref.AliasString())
+	return pulled, nil
+}
+
+// mirrorVersions selects versions newer than lastPath from items ordered
+// newest-first, returning them oldest-first. All versions are returned if
+// lastPath isn't in items
+func mirrorVersions(items []logbook.DatasetLogItem, lastPath string) []logbook.DatasetLogItem {
+	res := []logbook.DatasetLogItem{}
+	for _, item := range items {
+		if item.Path == lastPath {
+			break
+		}
+		res = append([]logbook.DatasetLogItem{item}, res...)
+	}
+	return res
+}
+
+// Status lists the replication status of all mirrored datasets, sorted by
+// upstream & dataset
+func (m *Mirror) Status() []*MirrorStatus {
+	m.lk.Lock()
+	defer m.lk.Unlock()
+	return sortedMirrorStatus(m.status)
+}
+
+func (m *Mirror) getStatus(key string) *MirrorStatus {
+	m.lk.Lock()
+	defer m.lk.Unlock()
+	return m.status[key]
+}
+
+func (m *Mirror) putStatus(s *MirrorStatus) error {
+	m.lk.Lock()
+	defer m.lk.Unlock()
+	m.status[s.key()] = s
+	if m.dir == "" {
+		return nil
+	}
+
+	return writeJSONFile(m.dir, mirrorStatusFilename, sortedMirrorStatus(m.status))
+}
+
+func sortedMirrorStatus(status map[string]*MirrorStatus) []*MirrorStatus {
+	list := make([]*MirrorStatus, 0, len(status))
+	for _, s := range status {
+		cpy := *s
+		list = append(list, &cpy)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].key() < list[j].key()
+	})
+	return list
+}
+
+// ReadMirrorStatus loads replication status persisted to dir by a mirror,
+// allowing status to be inspected without running the mirror
+func ReadMirrorStatus(dir string) ([]*MirrorStatus, error) {
+	list := []*MirrorStatus{}
+	if err := readJSONFile(dir, mirrorStatusFilename, &list); err != nil {
+		return nil, fmt.Errorf("reading mirror status: %w", err)
+	}
+	return list, nil
+}
@@ -0,0 +1,161 @@
+package remote
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/qri-io/qri/config"
+	"github.com/qri-io/qri/dsref"
+	"github.com/qri-io/qri/event"
+	"github.com/qri-io/qri/logbook"
+	reporef "github.com/qri-io/qri/repo/ref"
+)
+
+func TestMirrorSync(t *testing.T) {
+	tr, cleanup := newTestRunner(t)
+	defer cleanup()
+
+	wbp := writeWorldBankPopulation(tr.Ctx, t, tr.NodeA.Repo)
+	publishRef(t, tr.NodeA.Repo, &wbp)
+	vvs := writeVideoViewStats(tr.Ctx, t, tr.NodeA.Repo)
+	publishRef(t, tr.NodeA.Repo, &vvs)
+
+	server := tr.RemoteTestServer(tr.NodeARemote(t))
+	defer server.Close()
+
+	cfg := &config.Config{
+		Remotes: &config.Remotes{"primary": server.URL},
+		Remote: &config.Remote{
+			Enabled: true,
+			Mirrors: []*config.Mirror{
+				{Upstream: "primary", Patterns: []string{"A/world_*"}},
+				{Upstream: server.URL, Patterns: []string{"A/video_view_stats", "A/missing"}},
+			},
+		},
+	}
+
+	tmp, err := ioutil.TempDir("", "mirror_sync")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+
+	mirrored := 0
+	pub := publisherFunc(func(topic event.Topic, data interface{}) {
+		if topic == event.ETRemoteDatasetMirroredEvent {
+			mirrored++
+		}
+	})
+	dir := filepath.Join(tmp, "mirrors")
+	m, err := NewMirror(tr.NodeB, tr.NodeBClient(t), cfg, func(o *MirrorOptions) {
+		o.Publisher = pub
+		o.StatusDir = dir
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := m.Sync(tr.Ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, expect := range []reporef.DatasetRef{wbp, vvs} {
+		got, err := tr.NodeB.Repo.GetRef(reporef.DatasetRef{Peername: expect.Peername, Name: expect.Name})
+		if err != nil {
+			t.Fatalf("expected %s to be mirrored: %s", expect.AliasString(), err)
+		}
+		if got.Path != expect.Path {
+			t.Errorf("%s path mismatch. expected: %s, got: %s", expect.AliasString(), expect.Path, got.Path)
+		}
+		if !got.Published {
+			t.Errorf("expected mirrored %s to be published", expect.AliasString())
+		}
+	}
+
+	status := m.Status()
+	if len(status) != 3 {
+		t.Fatalf("expected 3 status entries, got %d: %v", len(status), status)
+	}
+	byRef := map[string]*MirrorStatus{}
+	for _, s := range status {
+		byRef[s.Ref] = s
+	}
+	if s := byRef["A/missing"]; s == nil || s.Error == "" || s.Path != "" {
+		t.Errorf("expected missing dataset to record an error. got: %v", s)
+	}
+	wbpStatus := byRef[wbp.AliasString()]
+	if wbpStatus == nil || wbpStatus.Upstream != "primary" || wbpStatus.Path != wbp.Path || wbpStatus.LastSync.IsZero() || wbpStatus.Error != "" {
+		t.Errorf("unexpected world bank status: %v", wbpStatus)
+	}
+
+	if mirrored != 2 {
+		t.Errorf("expected 2 mirrored events, got %d", mirrored)
+	}
+
+	// a second pass finds nothing new
+	if err := m.Sync(tr.Ctx); err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range m.Status() {
+		if s.Ref == wbp.AliasString() && !s.LastSync.Equal(wbpStatus.LastSync) {
+			t.Errorf("expected up-to-date dataset not to sync again")
+		}
+	}
+
+	persisted, err := ReadMirrorStatus(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(persisted) != 3 {
+		t.Errorf("expected 3 persisted status entries, got %d", len(persisted))
+	}
+}
+
+func TestNewMirrorBadUpstream(t *testing.T) {
+	tr, cleanup := newTestRunner(t)
+	defer cleanup()
+
+	cfg := &config.Config{
+		Remote: &config.Remote{
+			Mirrors: []*config.Mirror{{Upstream: "unknown", Patterns: []string{"nasa/*"}}},
+		},
+	}
+	if _, err := NewMirror(tr.NodeB, tr.NodeBClient(t), cfg); err == nil {
+		t.Error("expected unknown upstream to error")
+	}
+}
+
+type publisherFunc func(topic event.Topic, data interface{})
+
+func (f publisherFunc) Publish(topic event.Topic, data interface{}) { f(topic, data) }
+
+func TestMirrorVersions(t *testing.T) {
+	items := []logbook.DatasetLogItem{
+		{VersionInfo: dsref.VersionInfo{Path: "/ipfs/QmC"}},
+		{VersionInfo: dsref.VersionInfo{Path: "/ipfs/QmB"}},
+		{VersionInfo: dsref.VersionInfo{Path: "/ipfs/QmA"}},
+	}
+
+	cases := []struct {
+		last   string
+		expect []string
+	}{
+		{"", []string{"/ipfs/QmA", "/ipfs/QmB", "/ipfs/QmC"}},
+		{"/ipfs/QmUnknown", []string{"/ipfs/QmA", "/ipfs/QmB", "/ipfs/QmC"}},
+		{"/ipfs/QmA", []string{"/ipfs/QmB", "/ipfs/QmC"}},
+		{"/ipfs/QmC", []string{}},
+	}
+
+	for _, c := range cases {
+		got := []string{}
+		for _, item := range mirrorVersions(items, c.last) {
+			got = append(got, item.Path)
+		}
+		if diff := cmp.Diff(c.expect, got); diff != "" {
+			t.Errorf("last %q result mismatch (-want +got):\n%s", c.last, diff)
+		}
+	}
+}
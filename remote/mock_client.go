@@ -37,6 +37,11 @@ func (c *MockClient) PushDataset(ctx context.Context, ref reporef.DatasetRef, re
 	return ErrNotImplemented
 }
 
+// PreviewPush is not implemented
+func (c *MockClient) PreviewPush(ctx context.Context, ref reporef.DatasetRef, versions []string, remoteAddr string) (*PushPreview, error) {
+	return nil, ErrNotImplemented
+}
+
 // FetchLogs is not implemented
 func (c *MockClient) FetchLogs(ctx context.Context, ref dsref.Ref, remoteAddr string) (*oplog.Log, error) {
 	return nil, ErrNotImplemented
@@ -47,6 +52,11 @@ func (c *MockClient) CloneLogs(ctx context.Context, ref dsref.Ref, remoteAddr st
 	return ErrNotImplemented
 }
 
+// CloneUserLogs is not implemented
+func (c *MockClient) CloneUserLogs(ctx context.Context, username, remoteAddr string) ([]dsref.Ref, error) {
+	return nil, ErrNotImplemented
+}
+
 // RemoveDataset is not implemented
 func (c *MockClient) RemoveDataset(ctx context.Context, ref reporef.DatasetRef, remoteAddr string) error {
 	return ErrNotImplemented
@@ -96,6 +106,11 @@ func (c *MockClient) PullDataset(ctx context.Context, ref *reporef.DatasetRef, r
 	return ErrNotImplemented
 }
 
+// PullDatasetComponents is not implemented
+func (c *MockClient) PullDatasetComponents(ctx context.Context, ref *reporef.DatasetRef, remoteAddr string, components []string) error {
+	return ErrNotImplemented
+}
+
 // RemoveLogs is not implemented
 func (c *MockClient) RemoveLogs(ctx context.Context, ref dsref.Ref, remoteAddr string) error {
 	return ErrNotImplemented
@@ -111,7 +126,32 @@ func (c *MockClient) Feeds(ctx context.Context, remoteAddr string) (map[string][
 	return nil, ErrNotImplemented
 }
 
+// Feed is not implemented
+func (c *MockClient) Feed(ctx context.Context, remoteAddr, name string, offset, limit int) ([]dsref.VersionInfo, error) {
+	return nil, ErrNotImplemented
+}
+
 // Preview is not implemented
 func (c *MockClient) Preview(ctx context.Context, ref dsref.Ref, remoteAddr string) (*dataset.Dataset, error) {
 	return nil, ErrNotImplemented
 }
+
+// Subscribe is not implemented
+func (c *MockClient) Subscribe(ctx context.Context, ref dsref.Ref, remoteAddr string) error {
+	return ErrNotImplemented
+}
+
+// Unsubscribe is not implemented
+func (c *MockClient) Unsubscribe(ctx context.Context, ref dsref.Ref, remoteAddr string) error {
+	return ErrNotImplemented
+}
+
+// Search is not implemented
+func (c *MockClient) Search(ctx context.Context, p SearchParams, remoteAddr string) ([]dsref.VersionInfo, error) {
+	return nil, ErrNotImplemented
+}
+
+// Notifications is not implemented
+func (c *MockClient) Notifications(ctx context.Context, remoteAddr string) (<-chan dsref.Ref, error) {
+	return nil, ErrNotImplemented
+}
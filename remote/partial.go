@@ -0,0 +1,112 @@
+package remote
+
+import (
+	"fmt"
+	"os"
+	"sync"
+)
+
+const partialPullsFilename = "partial_pulls.json"
+
+// PartialPull records a dataset version that was pulled without all of its
+// components. Components that weren't pulled can be fetched from RemoteAddr
+type PartialPull struct {
+	Path       string   `json:"path"`
+	RemoteAddr string   `json:"remoteAddr"`
+	Components []string `json:"components"`
+}
+
+// Has returns true if a component was pulled
+func (p *PartialPull) Has(component string) bool {
+	for _, comp := range p.Components {
+		if comp == component {
+			return true
+		}
+	}
+	return false
+}
+
+// PartialPulls stores records of partially pulled versions, persisting them to
+// a directory
+type PartialPulls struct {
+	dir   string
+	lk    sync.Mutex
+	pulls map[string]*PartialPull
+}
+
+// NewPartialPulls creates a partial pull store backed by a directory. An empty
+// dir keeps records in memory only
+func NewPartialPulls(dir string) (*PartialPulls, error) {
+	pp := &PartialPulls{dir: dir, pulls: map[string]*PartialPull{}}
+	if dir == "" {
+		return pp, nil
+	}
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return nil, err
+	}
+
+	list := []*PartialPull{}
+	if err := readJSONFile(dir, partialPullsFilename, &list); err != nil {
+		return nil, fmt.Errorf("reading partial pulls: %w", err)
+	}
+	for _, p := range list {
+		pp.pulls[p.Path] = p
+	}
+	return pp, nil
+}
+
+// Get returns the partial pull record for a version path, nil if the version
+// wasn't partially pulled
+func (s *PartialPulls) Get(path string) *PartialPull {
+	if s == nil {
+		return nil
+	}
+	s.lk.Lock()
+	defer s.lk.Unlock()
+	return s.pulls[path]
+}
+
+// Record notes components of a version pulled from a remote. Pulling no
+// components pulls the complete version, removing any partial record
+func (s *PartialPulls) Record(path, remoteAddr string, components []string) error {
+	if s == nil {
+		return nil
+	}
+	s.lk.Lock()
+	defer s.lk.Unlock()
+
+	if len(components) == 0 {
+		if _, ok := s.pulls[path]; !ok {
+			return nil
+		}
+		delete(s.pulls, path)
+		return s.write()
+	}
+
+	p := s.pulls[path]
+	if p == nil {
+		p = &PartialPull{Path: path}
+		s.pulls[path] = p
+	}
+	p.RemoteAddr = remoteAddr
+	for _, comp := range components {
+		if !p.Has(comp) {
+			p.Components = append(p.Components, comp)
+		}
+	}
+	if len(p.Components) == len(DatasetComponents) {
+		delete(s.pulls, path)
+	}
+	return s.write()
+}
+
+func (s *PartialPulls) write() error {
+	if s.dir == "" {
+		return nil
+	}
+	list := make([]*PartialPull, 0, len(s.pulls))
+	for _, p := range s.pulls {
+		list = append(list, p)
+	}
+	return writeJSONFile(s.dir, partialPullsFilename, list)
+}
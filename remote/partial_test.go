@@ -0,0 +1,64 @@
+package remote
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestPartialPulls(t *testing.T) {
+	dir, err := ioutil.TempDir("", "remote_partial_pulls")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	pp, err := NewPartialPulls(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p := pp.Get("/ipfs/QmFoo"); p != nil {
+		t.Errorf("expected empty store to have no partial pulls")
+	}
+
+	if err := pp.Record("/ipfs/QmFoo", "http://remote", []string{"meta"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := pp.Record("/ipfs/QmFoo", "http://remote", []string{"readme", "meta"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := pp.Record("/ipfs/QmBar", "http://remote", []string{"meta"}); err != nil {
+		t.Fatal(err)
+	}
+
+	reloaded, err := NewPartialPulls(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := reloaded.Get("/ipfs/QmFoo")
+	if p == nil {
+		t.Fatal("expected partial pull to persist")
+	}
+	if p.RemoteAddr != "http://remote" || len(p.Components) != 2 || !p.Has("meta") || !p.Has("readme") || p.Has("body") {
+		t.Errorf("reloaded partial pull mismatch. got: %#v", p)
+	}
+
+	// pulling a complete version clears the record
+	if err := reloaded.Record("/ipfs/QmFoo", "http://remote", nil); err != nil {
+		t.Fatal(err)
+	}
+	// pulling every component clears the record
+	if err := reloaded.Record("/ipfs/QmBar", "http://remote", DatasetComponents); err != nil {
+		t.Fatal(err)
+	}
+	reloaded, err = NewPartialPulls(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p := reloaded.Get("/ipfs/QmFoo"); p != nil {
+		t.Error("expected a complete pull to remove the partial record")
+	}
+	if p := reloaded.Get("/ipfs/QmBar"); p != nil {
+		t.Error("expected pulling all components to remove the partial record")
+	}
+}
@@ -1,6 +1,7 @@
 package remote
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
@@ -10,15 +11,21 @@ import (
 	"strings"
 	"time"
 
+	cid "github.com/ipfs/go-cid"
+	ipld "github.com/ipfs/go-ipld-format"
 	coreiface "github.com/ipfs/interface-go-ipfs-core"
 	crypto "github.com/libp2p/go-libp2p-core/crypto"
 	peer "github.com/libp2p/go-libp2p-core/peer"
+	"github.com/qri-io/apiutil"
+	"github.com/qri-io/dag"
 	"github.com/qri-io/dag/dsync"
 	"github.com/qri-io/dataset"
 	"github.com/qri-io/qfs/cafs"
 	"github.com/qri-io/qri/base"
 	"github.com/qri-io/qri/base/dsfs"
 	"github.com/qri-io/qri/dsref"
+	"github.com/qri-io/qri/event"
+	"github.com/qri-io/qri/logbook"
 	"github.com/qri-io/qri/logbook/logsync"
 	"github.com/qri-io/qri/logbook/oplog"
 	"github.com/qri-io/qri/p2p"
@@ -37,19 +44,57 @@ var (
 
 // PeerSyncClient talks to a remote in order to sync peer data
 type PeerSyncClient struct {
-	pk      crypto.PrivKey
-	ds      *dsync.Dsync
-	logsync *logsync.Logsync
-	capi    coreiface.CoreAPI
-	node    *p2p.QriNode
+	pk          crypto.PrivKey
+	ds          *dsync.Dsync
+	lng         ipld.NodeGetter
+	logsync     *logsync.Logsync
+	capi        coreiface.CoreAPI
+	node        *p2p.QriNode
+	pub         event.Publisher
+	checkpoints *Checkpoints
+	partials    *PartialPulls
+}
+
+// ClientOptions configures a remote client
+type ClientOptions struct {
+	// Publisher receives progress events while datasets are transferred.
+	// Default discards events
+	Publisher event.Publisher
+	// Checkpoints records unfinished transfers so they can be resumed. Default
+	// keeps checkpoints in memory
+	Checkpoints *Checkpoints
+	// PartialPulls records versions pulled without all of their components.
+	// Default keeps records in memory
+	PartialPulls *PartialPulls
 }
 
 // NewClient creates a remote client suitable for syncing peers
-func NewClient(node *p2p.QriNode) (c Client, err error) {
-	var ds *dsync.Dsync
+func NewClient(node *p2p.QriNode, opts ...func(o *ClientOptions)) (c Client, err error) {
+	o := &ClientOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.Publisher == nil {
+		o.Publisher = &event.NilPublisher{}
+	}
+	if o.Checkpoints == nil {
+		if o.Checkpoints, err = NewCheckpoints(""); err != nil {
+			return nil, err
+		}
+	}
+	if o.PartialPulls == nil {
+		if o.PartialPulls, err = NewPartialPulls(""); err != nil {
+			return nil, err
+		}
+	}
+
+	var (
+		ds  *dsync.Dsync
+		lng ipld.NodeGetter
+	)
 	capi, capiErr := node.IPFSCoreAPI()
 	if capiErr == nil {
-		lng, err := dsync.NewLocalNodeGetter(capi)
+		lng, err = dsync.NewLocalNodeGetter(capi)
 		if err != nil {
 			return nil, err
 		}
@@ -78,11 +123,15 @@ func NewClient(node *p2p.QriNode) (c Client, err error) {
 	}
 
 	return &PeerSyncClient{
-		pk:      node.Repo.PrivateKey(),
-		ds:      ds,
-		logsync: ls,
-		capi:    capi,
-		node:    node,
+		pk:          node.Repo.PrivateKey(),
+		ds:          ds,
+		lng:         lng,
+		logsync:     ls,
+		capi:        capi,
+		node:        node,
+		pub:         o.Publisher,
+		checkpoints: o.Checkpoints,
+		partials:    o.PartialPulls,
 	}, nil
 }
 
@@ -124,6 +173,41 @@ func (c *PeerSyncClient) CloneLogs(ctx context.Context, ref dsref.Ref, remoteAdd
 	return err
 }
 
+// CloneUserLogs pulls logbook data for all of a user's datasets from a remote
+// & stores it locally. Logs are synced in a single batch, only logs that
+// differ from the local logbook are transferred. CloneUserLogs returns
+// references to the datasets that changed
+func (c *PeerSyncClient) CloneUserLogs(ctx context.Context, username, remoteAddr string) ([]dsref.Ref, error) {
+	if c == nil {
+		return nil, ErrNoRemoteClient
+	}
+
+	if t := addressType(remoteAddr); t == "http" {
+		remoteAddr = remoteAddr + "/remote/logsync"
+	}
+	log.Debugf("cloning logs for %s from %s", username, remoteAddr)
+	pull, err := c.logsync.NewBatchPull(username, remoteAddr)
+	if err != nil {
+		return nil, err
+	}
+
+	pull.Merge = true
+	logs, err := pull.Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	refs := make([]dsref.Ref, 0, len(logs))
+	for _, l := range logs {
+		ref, err := logbook.DsrefAliasForLog(l)
+		if err != nil {
+			return nil, err
+		}
+		refs = append(refs, ref)
+	}
+	return refs, nil
+}
+
 // PushLogs pushes logbook data to a remote address
 func (c *PeerSyncClient) PushLogs(ctx context.Context, ref dsref.Ref, remoteAddr string) error {
 	if c == nil {
@@ -156,7 +240,9 @@ func (c *PeerSyncClient) RemoveLogs(ctx context.Context, ref dsref.Ref, remoteAd
 	return c.logsync.DoRemove(ctx, ref, remoteAddr)
 }
 
-// PushDataset pushes the contents of a dataset to a remote
+// PushDataset pushes the contents of a dataset to a remote. An interrupted
+// push is resumed from its checkpoint, the remote only asks for blocks it
+// doesn't already have
 func (c *PeerSyncClient) PushDataset(ctx context.Context, ref reporef.DatasetRef, remoteAddr string) error {
 	if c == nil {
 		return ErrNoRemoteClient
@@ -165,7 +251,19 @@ func (c *PeerSyncClient) PushDataset(ctx context.Context, ref reporef.DatasetRef
 		remoteAddr = remoteAddr + "/remote/dsync"
 	}
 	log.Debugf("pushing dataset %s to %s", ref.Path, remoteAddr)
-	push, err := c.ds.NewPush(ref.Path, remoteAddr, true)
+
+	cp, err := c.checkpoint(DirectionPush, remoteAddr, ref.Path, true, func() (*dag.Info, error) {
+		id, err := cid.Parse(ref.Path)
+		if err != nil {
+			return nil, err
+		}
+		return dag.NewInfo(ctx, c.lng, id)
+	})
+	if err != nil {
+		return err
+	}
+
+	push, err := c.ds.NewPushInfo(cp.Info, remoteAddr, true)
 	if err != nil {
 		return err
 	}
@@ -176,27 +274,92 @@ func (c *PeerSyncClient) PushDataset(ctx context.Context, ref reporef.DatasetRef
 	}
 	push.SetMeta(params)
 
-	go func() {
-		updates := push.Updates()
-		for {
-			select {
-			case update := <-updates:
-				fmt.Printf("%d/%d blocks transferred\n", update.CompletedBlocks(), len(update))
-				if update.Complete() {
-					fmt.Println("done!")
-				}
-			case <-ctx.Done():
-				// don't leak goroutines
-				return
-			}
+	return c.transfer(ctx, reporef.ConvertToDsref(ref), cp, push.Updates(), push.Do)
+}
+
+// PreviewPush asks a remote how it would respond to pushing a dataset version,
+// without transferring any blocks. versions lists paths in the dataset history
+// to check the remote for
+func (c *PeerSyncClient) PreviewPush(ctx context.Context, ref reporef.DatasetRef, versions []string, remoteAddr string) (*PushPreview, error) {
+	if c == nil {
+		return nil, ErrNoRemoteClient
+	}
+	if at := addressType(remoteAddr); at != "http" {
+		return nil, fmt.Errorf("push previews are only supported over HTTP")
+	}
+
+	id, err := cid.Parse(ref.Path)
+	if err != nil {
+		return nil, err
+	}
+	info, err := dag.NewInfo(ctx, c.lng, id)
+	if err != nil {
+		return nil, err
+	}
+	meta, err := sigParams(c.pk, ref)
+	if err != nil {
+		return nil, err
+	}
+	body, err := json.Marshal(&PushPreviewRequest{
+		Info:     info,
+		Meta:     meta,
+		Versions: versions,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("%s/remote/push/preview", remoteAddr), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+	if err := c.signHTTPRequest(req); err != nil {
+		return nil, err
+	}
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		if strings.Contains(err.Error(), "no such host") {
+			return nil, ErrRemoteNotFound
 		}
-	}()
+		return nil, err
+	}
+	defer res.Body.Close()
+	// add response to an envelope
+	env := struct {
+		Data *PushPreview
+		Meta struct {
+			Error  string
+			Status string
+			Code   int
+		}
+	}{}
 
-	return push.Do(ctx)
+	if err := json.NewDecoder(res.Body).Decode(&env); err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("error %d: %s", res.StatusCode, env.Meta.Error)
+	}
+
+	return env.Data, nil
 }
 
-// PullDataset fetches a dataset from a remote source
+// PullDataset fetches a dataset from a remote source. Blocks already in the
+// local store aren't fetched again, so rerunning an interrupted pull only
+// transfers what's missing
 func (c *PeerSyncClient) PullDataset(ctx context.Context, ref *reporef.DatasetRef, remoteAddr string) error {
+	return c.PullDatasetComponents(ctx, ref, remoteAddr, nil)
+}
+
+// PullDatasetComponents fetches the blocks of a dataset version that make up
+// the given components. The dataset & commit files are always fetched. An
+// empty list of components fetches the entire version. Versions pulled
+// without all components are recorded as partial pulls
+func (c *PeerSyncClient) PullDatasetComponents(ctx context.Context, ref *reporef.DatasetRef, remoteAddr string, components []string) error {
 	if c == nil {
 		return ErrNoRemoteClient
 	}
@@ -215,13 +378,147 @@ func (c *PeerSyncClient) PullDataset(ctx context.Context, ref *reporef.DatasetRe
 		return err
 	}
 
-	pull, err := c.ds.NewPull(ref.Path, remoteAddr+"/remote/dsync", params)
+	// always ask the remote for dag info, even when resuming. Remotes check
+	// permissions & record pulls when info is requested
+	rem := &dsync.HTTPClient{URL: remoteAddr + "/remote/dsync"}
+	cp, err := c.checkpoint(DirectionPull, rem.URL, ref.Path, false, func() (*dag.Info, error) {
+		info, err := rem.GetDagInfo(ctx, ref.Path, params)
+		if err != nil || len(components) == 0 {
+			return info, err
+		}
+		return c.componentsInfo(ctx, rem, info, components)
+	})
+	if err != nil {
+		log.Error("fetching dag info: ", err)
+		return err
+	}
+
+	pull, err := dsync.NewPullWithInfo(cp.Info, c.lng, c.capi.Block(), rem, params)
 	if err != nil {
 		log.Error("creating pull: ", err)
 		return err
 	}
 
-	return pull.Do(ctx)
+	if err := c.transfer(ctx, reporef.ConvertToDsref(*ref), cp, pull.Updates(), pull.Do); err != nil {
+		return err
+	}
+	return c.partials.Record(ref.Path, remoteAddr, components)
+}
+
+// componentsInfo narrows the dag info of a dataset version to the blocks that
+// make up a set of components
+func (c *PeerSyncClient) componentsInfo(ctx context.Context, rem dsync.DagSyncable, info *dag.Info, components []string) (*dag.Info, error) {
+	include := map[string]bool{}
+	for _, comp := range components {
+		if !IsDatasetComponent(comp) {
+			return nil, fmt.Errorf("unknown dataset component %q", comp)
+		}
+		include[comp] = true
+	}
+
+	// the root block lists the files in the dataset package. fetch it to learn
+	// which blocks belong to each component
+	root := info.RootCID()
+	data, err := rem.GetBlock(ctx, root.String())
+	if err != nil {
+		return nil, err
+	}
+	if _, err := c.capi.Block().Put(ctx, bytes.NewReader(data)); err != nil {
+		return nil, err
+	}
+	node, err := c.lng.Get(ctx, root)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := []string{}
+	for _, l := range node.Links() {
+		if comp := packageFileComponent(l.Name); comp == "" || include[comp] {
+			ids = append(ids, l.Cid.String())
+		}
+	}
+	return subDAGsInfo(info, ids)
+}
+
+// checkpoint gets the checkpoint for a transfer, creating one if none exists.
+// reuse controls whether the dag info of an existing checkpoint is used as-is
+func (c *PeerSyncClient) checkpoint(direction, remoteAddr, path string, reuse bool, getInfo func() (*dag.Info, error)) (*Checkpoint, error) {
+	prev := c.checkpoints.Get(direction, remoteAddr, path)
+	if prev != nil && prev.Info != nil && reuse {
+		log.Debugf("resuming %s of %s at %d/%d blocks", direction, path, prev.BlocksDone, prev.BlocksTotal)
+		return prev, nil
+	}
+
+	info, err := getInfo()
+	if err != nil {
+		return nil, err
+	}
+	cp := &Checkpoint{
+		Direction:   direction,
+		RemoteAddr:  remoteAddr,
+		Path:        path,
+		Info:        info,
+		BlocksTotal: len(info.Manifest.Nodes),
+		Updated:     nowFunc(),
+	}
+	if prev != nil {
+		log.Debugf("resuming %s of %s at %d/%d blocks", direction, path, prev.BlocksDone, prev.BlocksTotal)
+		cp.BlocksDone = prev.BlocksDone
+	}
+	if err := c.checkpoints.Put(cp); err != nil {
+		return nil, err
+	}
+	return cp, nil
+}
+
+// transfer runs a push or pull, publishing progress events & updating the
+// transfer checkpoint as blocks move. The checkpoint is removed once the
+// transfer completes
+func (c *PeerSyncClient) transfer(ctx context.Context, ref dsref.Ref, cp *Checkpoint, updates <-chan dag.Completion, do func(context.Context) error) error {
+	tracker := newProgressTracker(c.pub, ref, cp.RemoteAddr, cp.Direction, cp.Info)
+
+	stop := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		written := nowFunc()
+		for {
+			select {
+			case update := <-updates:
+				e, published := tracker.update(update)
+				if published && nowFunc().Sub(written) >= checkpointInterval {
+					written = nowFunc()
+					next := *cp
+					next.BlocksDone = e.BlocksDone
+					next.Updated = written
+					if err := c.checkpoints.Put(&next); err != nil {
+						log.Debugf("writing transfer checkpoint: %s", err)
+					}
+				}
+			case <-stop:
+				return
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	err := do(ctx)
+	close(stop)
+	<-stopped
+	if err == nil {
+		// dsync returns without error when a transfer is cancelled
+		err = ctx.Err()
+	}
+	if err != nil {
+		return err
+	}
+
+	tracker.finish(len(cp.Info.Manifest.Nodes))
+	if err := c.checkpoints.Delete(cp.Direction, cp.RemoteAddr, cp.Path); err != nil {
+		log.Debugf("removing transfer checkpoint: %s", err)
+	}
+	return nil
 }
 
 // RemoveDataset asks a remote to remove a dataset
@@ -503,23 +800,9 @@ func (c *PeerSyncClient) AddDataset(ctx context.Context, ref *reporef.DatasetRef
 }
 
 func (c *PeerSyncClient) signHTTPRequest(req *http.Request) error {
-	pk := c.node.Repo.PrivateKey()
-	now := fmt.Sprintf("%d", nowFunc().In(time.UTC).Unix())
-
-	// TODO (b5) - we shouldn't be calculating profile IDs here
-	peerID, err := calcProfileID(pk)
-	if err != nil {
-		return err
-	}
-
-	b64Sig, err := signString(pk, requestSigningString(now, peerID, req.URL.Path))
-	if err != nil {
+	if err := signHTTPRequest(c.node.Repo.PrivateKey(), req); err != nil {
 		return err
 	}
-
-	req.Header.Add("timestamp", now)
-	req.Header.Add("pid", peerID)
-	req.Header.Add("signature", b64Sig)
 	req.Header.Add("qri-version", version.String)
 	return nil
 }
@@ -568,6 +851,53 @@ func (c *PeerSyncClient) Feeds(ctx context.Context, remoteAddr string) (map[stri
 	return env.Data, nil
 }
 
+// Feed fetches a page of a single named feed
+func (c *PeerSyncClient) Feed(ctx context.Context, remoteAddr, name string, offset, limit int) ([]dsref.VersionInfo, error) {
+	if at := addressType(remoteAddr); at != "http" {
+		return nil, fmt.Errorf("feeds are only supported over HTTP")
+	}
+
+	page := apiutil.NewPageFromOffsetAndLimit(offset, limit)
+	u := fmt.Sprintf("%s/remote/feeds/%s?page=%d&pageSize=%d", remoteAddr, name, page.Number, page.Size)
+	req, err := http.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+
+	if err := c.signHTTPRequest(req); err != nil {
+		return nil, err
+	}
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		if strings.Contains(err.Error(), "no such host") {
+			return nil, ErrNoRemoteClient
+		}
+		return nil, err
+	}
+	defer res.Body.Close()
+	// add response to an envelope
+	env := struct {
+		Data []dsref.VersionInfo
+		Meta struct {
+			Error  string
+			Status string
+			Code   int
+		}
+	}{}
+
+	if err := json.NewDecoder(res.Body).Decode(&env); err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("error %d: %s", res.StatusCode, env.Meta.Error)
+	}
+
+	return env.Data, nil
+}
+
 // Preview fetches a dataset preview from the registry
 func (c *PeerSyncClient) Preview(ctx context.Context, ref dsref.Ref, remoteAddr string) (*dataset.Dataset, error) {
 	if at := addressType(remoteAddr); at != "http" {
@@ -610,3 +940,165 @@ func (c *PeerSyncClient) Preview(ctx context.Context, ref dsref.Ref, remoteAddr
 
 	return env.Data, nil
 }
+
+// Search queries the datasets a remote holds
+func (c *PeerSyncClient) Search(ctx context.Context, p SearchParams, remoteAddr string) ([]dsref.VersionInfo, error) {
+	if at := addressType(remoteAddr); at != "http" {
+		return nil, fmt.Errorf("search is only supported over HTTP")
+	}
+
+	params := url.Values{}
+	params.Set("q", p.Query)
+	if p.Username != "" {
+		params.Set("username", p.Username)
+	}
+	if p.Format != "" {
+		params.Set("format", p.Format)
+	}
+	if !p.UpdatedSince.IsZero() {
+		params.Set("updatedSince", p.UpdatedSince.Format(time.RFC3339))
+	}
+	page := apiutil.NewPageFromOffsetAndLimit(p.Offset, p.Limit)
+	params.Set("page", fmt.Sprintf("%d", page.Number))
+	params.Set("pageSize", fmt.Sprintf("%d", page.Size))
+
+	req, err := http.NewRequest("GET", fmt.Sprintf("%s/remote/search?%s", remoteAddr, params.Encode()), nil)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+
+	if err := c.signHTTPRequest(req); err != nil {
+		return nil, err
+	}
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		if strings.Contains(err.Error(), "no such host") {
+			return nil, ErrRemoteNotFound
+		}
+		return nil, err
+	}
+	defer res.Body.Close()
+	// add response to an envelope
+	env := struct {
+		Data []dsref.VersionInfo
+		Meta struct {
+			Error  string
+			Status string
+			Code   int
+		}
+	}{}
+
+	if err := json.NewDecoder(res.Body).Decode(&env); err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("error %d: %s", res.StatusCode, env.Meta.Error)
+	}
+
+	return env.Data, nil
+}
+
+// Subscribe asks a remote to notify this node when new versions of a dataset
+// are pushed. Notifications are sent over p2p when this node is online & its
+// peer ID matches the profile key, and are always available from
+// Notifications
+func (c *PeerSyncClient) Subscribe(ctx context.Context, ref dsref.Ref, remoteAddr string) error {
+	params := url.Values{}
+	params.Set("ref", ref.Alias())
+	// remotes only accept the peer ID of the key requests are signed with
+	if pid, err := calcProfileID(c.node.Repo.PrivateKey()); err == nil && c.node.ID.Pretty() == pid {
+		params.Set("peerID", pid)
+	}
+	return c.subscriptionRequest(ctx, http.MethodPost, params, remoteAddr)
+}
+
+// Unsubscribe stops notifications for a dataset from a remote
+func (c *PeerSyncClient) Unsubscribe(ctx context.Context, ref dsref.Ref, remoteAddr string) error {
+	params := url.Values{}
+	params.Set("ref", ref.Alias())
+	return c.subscriptionRequest(ctx, http.MethodDelete, params, remoteAddr)
+}
+
+func (c *PeerSyncClient) subscriptionRequest(ctx context.Context, method string, params url.Values, remoteAddr string) error {
+	if at := addressType(remoteAddr); at != "http" {
+		return fmt.Errorf("subscriptions are only supported over HTTP")
+	}
+
+	u := fmt.Sprintf("%s/remote/subscriptions?%s", remoteAddr, params.Encode())
+	req, err := http.NewRequest(method, u, nil)
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+
+	if err := c.signHTTPRequest(req); err != nil {
+		return err
+	}
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		if strings.Contains(err.Error(), "no such host") {
+			return ErrRemoteNotFound
+		}
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		env := struct {
+			Meta struct {
+				Error string
+			}
+		}{}
+		if err := json.NewDecoder(res.Body).Decode(&env); err != nil {
+			return fmt.Errorf("error %d", res.StatusCode)
+		}
+		return fmt.Errorf("error %d: %s", res.StatusCode, env.Meta.Error)
+	}
+	return nil
+}
+
+// Notifications opens a stream of notifications for datasets this node is
+// subscribed to on a remote. The returned channel is closed when ctx is
+// cancelled or the connection drops
+func (c *PeerSyncClient) Notifications(ctx context.Context, remoteAddr string) (<-chan dsref.Ref, error) {
+	if at := addressType(remoteAddr); at != "http" {
+		return nil, fmt.Errorf("notifications are only supported over HTTP")
+	}
+
+	req, err := http.NewRequest("GET", fmt.Sprintf("%s/remote/notifications", remoteAddr), nil)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Accept", "text/event-stream")
+
+	if err := c.signHTTPRequest(req); err != nil {
+		return nil, err
+	}
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		if strings.Contains(err.Error(), "no such host") {
+			return nil, ErrRemoteNotFound
+		}
+		return nil, err
+	}
+	if res.StatusCode != http.StatusOK {
+		res.Body.Close()
+		return nil, fmt.Errorf("error %d opening notification stream", res.StatusCode)
+	}
+
+	refs := make(chan dsref.Ref)
+	go func() {
+		defer res.Body.Close()
+		defer close(refs)
+		if err := readNotifications(ctx, res.Body, refs); err != nil && ctx.Err() == nil {
+			log.Debugf("reading notifications from %s: %s", remoteAddr, err)
+		}
+	}()
+	return refs, nil
+}
@@ -1,6 +1,7 @@
 package remote
 
 import (
+	"context"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -168,3 +169,72 @@ func connectMapStores(peers []*p2p.QriNode) {
 		}
 	}
 }
+
+func TestTransferProgressAndCheckpoints(t *testing.T) {
+	tr, cleanup := newTestRunner(t)
+	defer cleanup()
+
+	rem := tr.NodeARemote(t)
+	server := tr.RemoteTestServer(rem)
+	defer server.Close()
+
+	pub := &recordingPublisher{}
+	cps, err := NewCheckpoints("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cli, err := NewClient(tr.NodeB, func(o *ClientOptions) {
+		o.Publisher = pub
+		o.Checkpoints = cps
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lastEvent := func(t *testing.T, direction string) {
+		if len(pub.events) == 0 {
+			t.Fatal("expected transfer to publish progress events")
+		}
+		e := pub.events[len(pub.events)-1]
+		if !e.Complete || e.Direction != direction || e.BlocksDone != e.BlocksTotal {
+			t.Errorf("expected final event to be a complete %s. got: %#v", direction, e)
+		}
+		pub.events = nil
+	}
+
+	worldBankRef := writeWorldBankPopulation(tr.Ctx, t, tr.NodeA.Repo)
+	// simulate an interrupted pull
+	dsyncAddr := server.URL + "/remote/dsync"
+	if err := cps.Put(&Checkpoint{Direction: DirectionPull, RemoteAddr: dsyncAddr, Path: worldBankRef.Path, BlocksDone: 1}); err != nil {
+		t.Fatal(err)
+	}
+	if err := cli.PullDataset(tr.Ctx, &worldBankRef, server.URL); err != nil {
+		t.Fatal(err)
+	}
+	lastEvent(t, DirectionPull)
+	if cp := cps.Get(DirectionPull, dsyncAddr, worldBankRef.Path); cp != nil {
+		t.Errorf("expected completed pull to remove its checkpoint")
+	}
+
+	videoViewRef := writeVideoViewStats(tr.Ctx, t, tr.NodeB.Repo)
+	if err := cli.PushLogs(tr.Ctx, reporef.ConvertToDsref(videoViewRef), server.URL); err != nil {
+		t.Fatal(err)
+	}
+	if err := cli.PushDataset(tr.Ctx, videoViewRef, server.URL); err != nil {
+		t.Fatal(err)
+	}
+	lastEvent(t, DirectionPush)
+	if cp := cps.Get(DirectionPush, dsyncAddr, videoViewRef.Path); cp != nil {
+		t.Errorf("expected completed push to remove its checkpoint")
+	}
+
+	// cancelled transfers keep their checkpoint
+	ctx, cancel := context.WithCancel(tr.Ctx)
+	cancel()
+	if err := cli.PushDataset(ctx, videoViewRef, server.URL); err == nil {
+		t.Error("expected cancelled push to error")
+	}
+	if cp := cps.Get(DirectionPush, dsyncAddr, videoViewRef.Path); cp == nil {
+		t.Error("expected cancelled push to keep its checkpoint")
+	}
+}
@@ -0,0 +1,136 @@
+package remote
+
+import (
+	"sync"
+	"time"
+
+	"github.com/qri-io/dag"
+	"github.com/qri-io/qri/dsref"
+	"github.com/qri-io/qri/event"
+)
+
+const (
+	// DirectionPush marks a transfer from this node to a remote
+	DirectionPush = "push"
+	// DirectionPull marks a transfer from a remote to this node
+	DirectionPull = "pull"
+)
+
+// checkpointInterval is the minimum time between checkpoint writes during a
+// transfer
+var checkpointInterval = time.Second
+
+// progressTracker turns dsync completion updates into transfer progress events
+type progressTracker struct {
+	pub        event.Publisher
+	ref        dsref.Ref
+	remoteAddr string
+	direction  string
+	sizes      []uint64
+	now        func() time.Time
+
+	lk           sync.Mutex
+	started      bool
+	finished     bool
+	start        time.Time
+	resumed      int
+	resumedBytes uint64
+	last         int
+}
+
+func newProgressTracker(pub event.Publisher, ref dsref.Ref, remoteAddr, direction string, info *dag.Info) *progressTracker {
+	if pub == nil {
+		pub = &event.NilPublisher{}
+	}
+	return &progressTracker{
+		pub:        pub,
+		ref:        ref,
+		remoteAddr: remoteAddr,
+		direction:  direction,
+		sizes:      info.Sizes,
+		now:        time.Now,
+		last:       -1,
+	}
+}
+
+// update records a completion, publishing an event if the number of completed
+// blocks has changed. The first update establishes how many blocks were
+// already present. update returns the event & whether it was published
+func (t *progressTracker) update(c dag.Completion) (event.TransferProgressEvent, bool) {
+	t.lk.Lock()
+	defer t.lk.Unlock()
+
+	done, bytesDone := t.count(c)
+	if !t.started {
+		t.started = true
+		t.start = t.now()
+		t.resumed = done
+		t.resumedBytes = bytesDone
+	}
+
+	e := t.event(len(c), done, bytesDone)
+	if t.finished || done == t.last {
+		return e, false
+	}
+	t.last = done
+	t.pub.Publish(event.ETRemoteTransferProgressEvent, e)
+	return e, true
+}
+
+// finish publishes a final, complete event. Updates received after finish are
+// ignored
+func (t *progressTracker) finish(blocks int) event.TransferProgressEvent {
+	t.lk.Lock()
+	defer t.lk.Unlock()
+
+	if !t.started {
+		t.started = true
+		t.start = t.now()
+	}
+	var total uint64
+	for _, s := range t.sizes {
+		total += s
+	}
+	e := t.event(blocks, blocks, total)
+	e.Complete = true
+	t.finished = true
+	t.pub.Publish(event.ETRemoteTransferProgressEvent, e)
+	return e
+}
+
+func (t *progressTracker) count(c dag.Completion) (done int, bytesDone uint64) {
+	for i, pct := range c {
+		if pct == 100 {
+			done++
+			if i < len(t.sizes) {
+				bytesDone += t.sizes[i]
+			}
+		}
+	}
+	return done, bytesDone
+}
+
+func (t *progressTracker) event(total, done int, bytesDone uint64) event.TransferProgressEvent {
+	e := event.TransferProgressEvent{
+		Ref:         t.ref,
+		RemoteAddr:  t.remoteAddr,
+		Direction:   t.direction,
+		BlocksDone:  done,
+		BlocksTotal: total,
+		BytesDone:   bytesDone,
+		Resumed:     t.resumed,
+		Complete:    total > 0 && done == total,
+	}
+	for _, s := range t.sizes {
+		e.BytesTotal += s
+	}
+
+	elapsed := t.now().Sub(t.start).Seconds()
+	if elapsed > 0 && bytesDone > t.resumedBytes {
+		e.Rate = float64(bytesDone-t.resumedBytes) / elapsed
+		if remaining := e.BytesTotal - bytesDone; remaining > 0 {
+			e.ETA = time.Duration(float64(remaining) / e.Rate * float64(time.Second))
+		}
+	}
+	return e
+}
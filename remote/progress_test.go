@@ -0,0 +1,78 @@
+package remote
+
+import (
+	"testing"
+	"time"
+
+	"github.com/qri-io/dag"
+	"github.com/qri-io/qri/dsref"
+	"github.com/qri-io/qri/event"
+)
+
+type recordingPublisher struct {
+	events []event.TransferProgressEvent
+}
+
+func (p *recordingPublisher) Publish(t event.Topic, data interface{}) {
+	if e, ok := data.(event.TransferProgressEvent); ok {
+		p.events = append(p.events, e)
+	}
+}
+
+func TestProgressTracker(t *testing.T) {
+	pub := &recordingPublisher{}
+	info := &dag.Info{
+		Manifest: &dag.Manifest{Nodes: []string{"a", "b", "c", "d"}},
+		Sizes:    []uint64{100, 200, 300, 400},
+	}
+	ref := dsref.Ref{Username: "peer", Name: "ds", Path: "/ipfs/QmFoo"}
+	tracker := newProgressTracker(pub, ref, "http://remote", DirectionPull, info)
+
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	tracker.now = func() time.Time { return now }
+
+	// one block was already present when the transfer started
+	e, published := tracker.update(dag.Completion{100, 0, 0, 0})
+	if !published {
+		t.Fatal("expected first update to publish")
+	}
+	if e.Resumed != 1 || e.BlocksDone != 1 || e.BlocksTotal != 4 {
+		t.Errorf("first update mismatch. got resumed: %d, blocks: %d/%d", e.Resumed, e.BlocksDone, e.BlocksTotal)
+	}
+	if e.Rate != 0 || e.ETA != 0 {
+		t.Errorf("expected no rate or eta before any blocks move. got rate: %f, eta: %s", e.Rate, e.ETA)
+	}
+
+	if _, published := tracker.update(dag.Completion{100, 50, 0, 0}); published {
+		t.Error("expected update without new completed blocks not to publish")
+	}
+
+	now = now.Add(2 * time.Second)
+	e, _ = tracker.update(dag.Completion{100, 100, 100, 0})
+	if e.BytesDone != 600 || e.BytesTotal != 1000 {
+		t.Errorf("bytes mismatch. got %d/%d", e.BytesDone, e.BytesTotal)
+	}
+	if e.Rate != 250 {
+		t.Errorf("expected rate to exclude resumed bytes. expected 250, got %f", e.Rate)
+	}
+	if expect := 1600 * time.Millisecond; e.ETA != expect {
+		t.Errorf("eta mismatch. expected %s, got %s", expect, e.ETA)
+	}
+
+	e = tracker.finish(4)
+	if !e.Complete || e.BlocksDone != 4 || e.BytesDone != 1000 || e.ETA != 0 {
+		t.Errorf("finish mismatch. got: %#v", e)
+	}
+	if _, published := tracker.update(dag.Completion{100, 100, 100, 100}); published {
+		t.Error("expected updates after finish to be ignored")
+	}
+
+	if len(pub.events) != 3 {
+		t.Fatalf("expected 3 published events. got: %d", len(pub.events))
+	}
+	for _, e := range pub.events {
+		if e.Ref != ref || e.Direction != DirectionPull || e.RemoteAddr != "http://remote" {
+			t.Errorf("event details mismatch. got: %#v", e)
+		}
+	}
+}
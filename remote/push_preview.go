@@ -0,0 +1,164 @@
+package remote
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	cid "github.com/ipfs/go-cid"
+	"github.com/qri-io/apiutil"
+	"github.com/qri-io/dag"
+	"github.com/qri-io/dag/dsync"
+)
+
+// PushPreviewRequest asks a remote how it would respond to a dataset push
+type PushPreviewRequest struct {
+	// Info describes the DAG of the version that would be pushed
+	Info *dag.Info `json:"info"`
+	// Meta carries signed push parameters, as sent with a dsync push
+	Meta map[string]string `json:"meta"`
+	// Versions lists paths of all versions in the pushed history
+	Versions []string `json:"versions,omitempty"`
+}
+
+// PushPreview describes the outcome of a push without transferring any data
+type PushPreview struct {
+	// Blocks & Bytes that would be sent to the remote
+	Blocks int    `json:"blocks"`
+	Bytes  uint64 `json:"bytes"`
+	// TotalBlocks & TotalBytes in the pushed version
+	TotalBlocks int    `json:"totalBlocks"`
+	TotalBytes  uint64 `json:"totalBytes"`
+	// HasVersions lists requested versions the remote already stores
+	HasVersions []string `json:"hasVersions,omitempty"`
+	// Rejection is the error a pre-check hook would return for the push, empty
+	// if the push would be accepted
+	Rejection string `json:"rejection,omitempty"`
+}
+
+// ManifestMissing returns a manifest of the nodes in mfst this remote doesn't
+// have
+func (r *Remote) ManifestMissing(ctx context.Context, mfst *dag.Manifest) (*dag.Manifest, error) {
+	capi, err := r.node.IPFSCoreAPI()
+	if err != nil {
+		return nil, err
+	}
+	lng, err := dsync.NewLocalNodeGetter(capi)
+	if err != nil {
+		return nil, err
+	}
+	return dag.Missing(ctx, lng, mfst)
+}
+
+// hasVersion returns true if the remote holds every block of a version. Blocks
+// are only read from local storage
+func (r *Remote) hasVersion(ctx context.Context, path string) bool {
+	id, err := cid.Parse(strings.TrimPrefix(path, "/ipfs/"))
+	if err != nil {
+		return false
+	}
+	capi, err := r.node.IPFSCoreAPI()
+	if err != nil {
+		return false
+	}
+	lng, err := dsync.NewLocalNodeGetter(capi)
+	if err != nil {
+		return false
+	}
+	// building a manifest visits every block, failing on the first one that
+	// isn't stored
+	_, err = dag.NewManifest(ctx, lng, id)
+	return err == nil
+}
+
+// PreviewPush runs the pre-checks a push would run & reports the data that
+// would be transferred, without creating a push session
+func (r *Remote) PreviewPush(ctx context.Context, p *PushPreviewRequest) (*PushPreview, error) {
+	if p.Info == nil || p.Info.Manifest == nil {
+		return nil, fmt.Errorf("dag info is required")
+	}
+
+	res := &PushPreview{
+		TotalBlocks: len(p.Info.Manifest.Nodes),
+		TotalBytes:  infoSize(*p.Info),
+	}
+
+	missing, err := r.ManifestMissing(ctx, p.Info.Manifest)
+	if err != nil {
+		return nil, err
+	}
+	res.Blocks = len(missing.Nodes)
+	for _, id := range missing.Nodes {
+		if i := p.Info.Manifest.IDIndex(id); i >= 0 && i < len(p.Info.Sizes) {
+			res.Bytes += p.Info.Sizes[i]
+		}
+	}
+
+	for _, path := range p.Versions {
+		if r.hasVersion(ctx, path) {
+			res.HasVersions = append(res.HasVersions, path)
+		}
+	}
+
+	if err := r.dsPushPreCheck(ctx, *p.Info, p.Meta); err != nil {
+		res.Rejection = err.Error()
+	} else if r.logPushPreCheck != nil {
+		pid, ref, err := r.pidAndRefFromMeta(p.Meta)
+		if err != nil {
+			return nil, err
+		}
+		if err := r.logPushPreCheck(ctx, pid, ref); err != nil {
+			res.Rejection = err.Error()
+		}
+	}
+
+	return res, nil
+}
+
+// PushPreviewHTTPHandler answers push preview requests. Requests must be
+// signed by the profile named in the push parameters
+func (r *Remote) PushPreviewHTTPHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodPost {
+			apiutil.NotFoundHandler(w, req)
+			return
+		}
+
+		pid, err := verifyHTTPRequest(req)
+		if err != nil {
+			apiutil.WriteErrResponse(w, http.StatusUnauthorized, err)
+			return
+		}
+
+		p := &PushPreviewRequest{}
+		if err := json.NewDecoder(req.Body).Decode(p); err != nil {
+			apiutil.WriteErrResponse(w, http.StatusBadRequest, err)
+			return
+		}
+
+		metaPID, ref, err := r.pidAndRefFromMeta(p.Meta)
+		if err != nil {
+			apiutil.WriteErrResponse(w, http.StatusBadRequest, err)
+			return
+		}
+		if metaPID != pid {
+			apiutil.WriteErrResponse(w, http.StatusForbidden, fmt.Errorf("push parameters aren't signed by the requesting profile"))
+			return
+		}
+		if r.pushPreviewPreCheck != nil {
+			if err := r.pushPreviewPreCheck(req.Context(), pid, ref); err != nil {
+				apiutil.WriteErrResponse(w, http.StatusForbidden, err)
+				return
+			}
+		}
+
+		res, err := r.PreviewPush(req.Context(), p)
+		if err != nil {
+			apiutil.WriteErrResponse(w, http.StatusBadRequest, err)
+			return
+		}
+		apiutil.WriteResponse(w, res)
+	}
+}
@@ -0,0 +1,84 @@
+package remote
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/qri-io/qri/repo/profile"
+	reporef "github.com/qri-io/qri/repo/ref"
+)
+
+func TestPreviewPush(t *testing.T) {
+	tr, cleanup := newTestRunner(t)
+	defer cleanup()
+
+	rem := tr.NodeARemote(t)
+	server := tr.RemoteTestServer(rem)
+	defer server.Close()
+
+	cli := tr.NodeBClient(t)
+	wbp := writeWorldBankPopulation(tr.Ctx, t, tr.NodeB.Repo)
+
+	preview, err := cli.PreviewPush(tr.Ctx, wbp, []string{wbp.Path}, server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if preview.Blocks == 0 || preview.Blocks != preview.TotalBlocks || preview.Bytes != preview.TotalBytes {
+		t.Errorf("expected all blocks to be missing before pushing, got: %#v", preview)
+	}
+	if len(preview.HasVersions) != 0 {
+		t.Errorf("expected remote to have no versions, got: %v", preview.HasVersions)
+	}
+	if preview.Rejection != "" {
+		t.Errorf("expected push to be accepted, got rejection: %s", preview.Rejection)
+	}
+
+	if err := cli.PushLogs(tr.Ctx, reporef.ConvertToDsref(wbp), server.URL); err != nil {
+		t.Fatal(err)
+	}
+	if err := cli.PushDataset(tr.Ctx, wbp, server.URL); err != nil {
+		t.Fatal(err)
+	}
+
+	preview, err = cli.PreviewPush(tr.Ctx, wbp, []string{wbp.Path}, server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if preview.Blocks != 0 || preview.Bytes != 0 {
+		t.Errorf("expected no blocks to transfer after pushing, got: %#v", preview)
+	}
+	if len(preview.HasVersions) != 1 || preview.HasVersions[0] != wbp.Path {
+		t.Errorf("expected remote to have pushed version, got: %v", preview.HasVersions)
+	}
+
+	rem.datasetPushPreCheck = func(context.Context, profile.ID, reporef.DatasetRef) error {
+		return fmt.Errorf("no pushes on tuesdays")
+	}
+	preview, err = cli.PreviewPush(tr.Ctx, wbp, nil, server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if preview.Rejection != "no pushes on tuesdays" {
+		t.Errorf("expected pre-check rejection, got: %q", preview.Rejection)
+	}
+
+	rem.pushPreviewPreCheck = func(context.Context, profile.ID, reporef.DatasetRef) error {
+		return fmt.Errorf("no previews for you")
+	}
+	if _, err := cli.PreviewPush(tr.Ctx, wbp, nil, server.URL); err == nil || !strings.Contains(err.Error(), "no previews for you") {
+		t.Errorf("expected preview pre-check error, got: %v", err)
+	}
+
+	// unsigned previews are refused
+	res, err := http.Post(server.URL+"/remote/push/preview", "application/json", strings.NewReader(`{}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusUnauthorized {
+		t.Errorf("expected unsigned preview to be unauthorized, got status %d", res.StatusCode)
+	}
+}
@@ -17,6 +17,7 @@ import (
 	"github.com/qri-io/qri/config"
 	"github.com/qri-io/qri/dsref"
 	"github.com/qri-io/qri/identity"
+	"github.com/qri-io/qri/logbook"
 	"github.com/qri-io/qri/logbook/logsync"
 	"github.com/qri-io/qri/logbook/oplog"
 	"github.com/qri-io/qri/p2p"
@@ -68,6 +69,12 @@ type Options struct {
 	FeedPreCheck Hook
 	// called before a preview request is processed
 	PreviewPreCheck Hook
+	// called before a search query is processed
+	SearchPreCheck Hook
+	// called before a push preview request is processed
+	PushPreviewPreCheck Hook
+	// called before a profile subscribes to notifications for a dataset
+	SubscribePreCheck Hook
 
 	// Use a custom feeds interface implementation. Default creates a Feeds
 	// instance from node.Repo
@@ -75,6 +82,15 @@ type Options struct {
 	// Use a custom previews interface implementation. Default creates a
 	// Previews instance from node.Repo
 	Previews
+	// Use a custom search implementation. Default indexes published datasets
+	// in node.Repo
+	Search
+	// Usage records versions pushed to the remote for quotas & retention.
+	// Default keeps usage in memory
+	Usage *Usage
+	// Subscribers tracks profiles to notify of new dataset versions. Default
+	// keeps subscribers in memory
+	Subscribers *Subscribers
 }
 
 // Remote receives requests from other qri nodes to perform actions on their
@@ -86,6 +102,13 @@ type Remote struct {
 
 	Feeds    Feeds
 	Previews Previews
+	Search   Search
+
+	// quotas & retention policy
+	cfg   *config.Remote
+	usage *Usage
+
+	subscribers *Subscribers
 
 	acceptSizeMax int64
 	// TODO (b5) - dsync needs to use timeouts
@@ -98,8 +121,12 @@ type Remote struct {
 	datasetRemoved        Hook
 	datasetPullPreCheck   Hook
 	datasetPulled         Hook
+	logPushPreCheck       Hook
 	FeedPreCheck          Hook
 	PreviewPreCheck       Hook
+	SearchPreCheck        Hook
+	subscribePreCheck     Hook
+	pushPreviewPreCheck   Hook
 }
 
 // NewRemote creates a remote
@@ -115,6 +142,7 @@ func NewRemote(node *p2p.QriNode, cfg *config.Remote, opts ...func(o *Options))
 
 	r := &Remote{
 		node: node,
+		cfg:  cfg.Copy(),
 
 		acceptSizeMax:   cfg.AcceptSizeMax,
 		acceptTimeoutMs: cfg.AcceptTimeoutMs,
@@ -126,9 +154,13 @@ func NewRemote(node *p2p.QriNode, cfg *config.Remote, opts ...func(o *Options))
 		datasetRemoved:        o.DatasetRemoved,
 		datasetPullPreCheck:   o.DatasetPullPreCheck,
 		datasetPulled:         o.DatasetPulled,
+		logPushPreCheck:       o.LogPushPreCheck,
 
-		FeedPreCheck:    o.FeedPreCheck,
-		PreviewPreCheck: o.PreviewPreCheck,
+		FeedPreCheck:        o.FeedPreCheck,
+		PreviewPreCheck:     o.PreviewPreCheck,
+		SearchPreCheck:      o.SearchPreCheck,
+		subscribePreCheck:   o.SubscribePreCheck,
+		pushPreviewPreCheck: o.PushPreviewPreCheck,
 	}
 
 	if o.Feeds != nil {
@@ -143,6 +175,25 @@ func NewRemote(node *p2p.QriNode, cfg *config.Remote, opts ...func(o *Options))
 		r.Previews = RepoPreviews{node.Repo}
 	}
 
+	if o.Search != nil {
+		r.Search = o.Search
+	} else {
+		r.Search = NewSearchIndex(node.Repo)
+	}
+
+	if o.Usage != nil {
+		r.usage = o.Usage
+	} else {
+		r.usage = &Usage{versions: map[string]*StoredVersion{}}
+	}
+
+	if o.Subscribers != nil {
+		r.subscribers = o.Subscribers
+	} else {
+		// in-memory stores can't fail to initialize
+		r.subscribers, _ = NewSubscribers("")
+	}
+
 	capi, err := node.IPFSCoreAPI()
 	if err != nil {
 		return nil, err
@@ -181,6 +232,7 @@ func NewRemote(node *p2p.QriNode, cfg *config.Remote, opts ...func(o *Options))
 			lso.Pulled = r.logHook(o.LogPulled)
 			lso.RemovePreCheck = r.logHook(o.LogRemovePreCheck)
 			lso.Removed = r.logHook(o.LogRemoved)
+			lso.StrictVerify = cfg.StrictLogs
 		})
 	}
 
@@ -261,6 +313,11 @@ func (r *Remote) RemoveDataset(ctx context.Context, params map[string]string) er
 		return err
 	}
 
+	if err := r.usage.RemoveRef(ref.AliasString()); err != nil {
+		log.Errorf("recording remote usage: %s", err)
+	}
+	UpdateSearch(ctx, r.Search, ref.AliasString())
+
 	// run completed hook
 	if r.datasetRemoved != nil {
 		if err := r.datasetRemoved(ctx, pid, ref); err != nil {
@@ -289,6 +346,10 @@ func (r *Remote) dsPushPreCheck(ctx context.Context, info dag.Info, meta map[str
 		}
 	}
 
+	if err := r.checkQuota(info, meta); err != nil {
+		return err
+	}
+
 	if r.datasetPushPreCheck != nil {
 		pid, ref, err := r.pidAndRefFromMeta(meta)
 		if err != nil {
@@ -336,13 +397,57 @@ func (r *Remote) dsPushComplete(ctx context.Context, info dag.Info, meta map[str
 		}
 	}
 
+	if err := r.usage.Add(&StoredVersion{
+		ProfileID: pid.String(),
+		Ref:       ref.AliasString(),
+		Path:      ref.Path,
+		Size:      infoSize(info),
+		Blocks:    infoBlocks(info),
+		Pushed:    time.Now(),
+	}); err != nil {
+		log.Errorf("recording remote usage: %s", err)
+	}
+
+	// branch heads are tracked by the logbook pushed alongside the dataset,
+	// keep the refstore pointed at the head of the default branch
+	if r.isBranchHead(ctx, ref) {
+		return nil
+	}
+
 	// mark ref as published b/c someone just published to us
 	ref.Published = true
 
 	// add completed pushed dataset to our refs
 	// TODO (b5) - this could overwrite any FSI links & other ref details,
 	// need to investigate
-	return r.node.Repo.PutRef(ref)
+	if err := r.node.Repo.PutRef(ref); err != nil {
+		return err
+	}
+
+	UpdateSearch(ctx, r.Search, ref.AliasString())
+	r.notifySubscribers(ref)
+	return nil
+}
+
+// isBranchHead returns true if ref's path is the head of a branch other than
+// the default branch
+func (r *Remote) isBranchHead(ctx context.Context, ref reporef.DatasetRef) bool {
+	book := r.node.Repo.Logbook()
+	if book == nil || ref.Path == "" {
+		return false
+	}
+	branches, err := book.Branches(ctx, dsref.Ref{Username: ref.Peername, Name: ref.Name})
+	if err != nil {
+		return false
+	}
+	// default branch is listed first, so a path that heads both the default
+	// branch and another branch counts as the default head
+	for _, b := range branches {
+		if b.Path == ref.Path {
+			return b.Name != logbook.DefaultBranchName
+		}
+	}
+	return false
 }
 
 func (r *Remote) dsRemovePreCheck(ctx context.Context, info dag.Info, meta map[string]string) error {
@@ -428,11 +533,17 @@ func (r *Remote) AddDefaultRoutes(mux *http.ServeMux) {
 	mux.Handle("/remote/dsync", r.DsyncHTTPHandler())
 	mux.Handle("/remote/logsync", r.LogsyncHTTPHandler())
 	mux.Handle("/remote/refs", r.RefsHTTPHandler())
+	mux.Handle("/remote/subscriptions", r.SubscriptionsHTTPHandler())
+	mux.Handle("/remote/notifications", r.NotificationsHTTPHandler())
+	mux.Handle("/remote/push/preview", r.PushPreviewHTTPHandler())
 
 	if fs := r.Feeds; fs != nil {
 		mux.Handle("/remote/feeds", r.FeedsHTTPHandler())
 		mux.Handle("/remote/feeds/", r.FeedHTTPHandler("/remote/feeds/"))
 	}
+	if s := r.Search; s != nil {
+		mux.Handle("/remote/search", r.SearchHTTPHandler())
+	}
 	if ps := r.Previews; ps != nil {
 		mux.Handle("/remote/dataset/preview/", r.PreviewHTTPHandler("/remote/dataset/preview/"))
 		mux.Handle("/remote/dataset/component/", r.ComponentHTTPHandler("/remote/dataset/component/"))
@@ -236,6 +236,41 @@ type testRunner struct {
 	NodeA, NodeB *p2p.QriNode
 }
 
+func TestIsBranchHead(t *testing.T) {
+	tr, cleanup := newTestRunner(t)
+	defer cleanup()
+
+	rem := tr.NodeARemote(t)
+	r := tr.NodeA.Repo
+	mainRef := writeWorldBankPopulation(tr.Ctx, t, r)
+
+	book := r.Logbook()
+	initID, err := book.RefToInitID(reporef.ConvertToDsref(mainRef))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := book.WriteBranchInit(tr.Ctx, initID, "experimental", ""); err != nil {
+		t.Fatal(err)
+	}
+
+	// a new branch shares its head with the default branch
+	if rem.isBranchHead(tr.Ctx, mainRef) {
+		t.Error("expected default branch head to not be a branch head")
+	}
+
+	ds := &dataset.Dataset{
+		Name: mainRef.Name,
+		Meta: &dataset.Meta{Title: "experimental"},
+	}
+	branchRef, err := base.SaveDataset(tr.Ctx, r, ioes.NewDiscardIOStreams(), ds, nil, nil, base.SaveSwitches{Branch: "experimental"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !rem.isBranchHead(tr.Ctx, branchRef) {
+		t.Error("expected experimental branch head to be a branch head")
+	}
+}
+
 func newTestRunner(t *testing.T) (tr *testRunner, cleanup func()) {
 	var err error
 	tr = &testRunner{
@@ -0,0 +1,324 @@
+package remote
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/qri-io/apiutil"
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/dataset/tabular"
+	"github.com/qri-io/qri/base/dsfs"
+	"github.com/qri-io/qri/dsref"
+	"github.com/qri-io/qri/repo"
+	"github.com/qri-io/qri/repo/profile"
+	reporef "github.com/qri-io/qri/repo/ref"
+)
+
+// Search queries the datasets a remote holds. Like Feeds, the userID argument
+// is planned for future use in access control
+type Search interface {
+	Search(ctx context.Context, userID string, p SearchParams) ([]dsref.VersionInfo, error)
+}
+
+// SearchParams configures a search query. All set filters must match
+type SearchParams struct {
+	// Query is a space-separated list of terms. Each term must appear in the
+	// name, username, meta or structure fields of a result
+	Query string `json:"q,omitempty"`
+	// Username limits results to datasets owned by a user
+	Username string `json:"username,omitempty"`
+	// Format limits results to datasets with a body format, eg: "csv"
+	Format string `json:"format,omitempty"`
+	// UpdatedSince limits results to datasets committed after a time
+	UpdatedSince time.Time `json:"updatedSince,omitempty"`
+	Offset       int       `json:"offset,omitempty"`
+	Limit        int       `json:"limit,omitempty"`
+}
+
+// searchEntry is an indexed dataset
+type searchEntry struct {
+	info dsref.VersionInfo
+	// lowercased text of fields, weighted from most to least relevant
+	fields [3]string
+}
+
+// SearchUpdater is implemented by searches that index datasets as they're
+// published & unpublished
+type SearchUpdater interface {
+	// Update re-indexes a dataset by "peername/name" alias, dropping it from
+	// the index if it's no longer published
+	Update(ctx context.Context, alias string) error
+}
+
+// SearchIndex is an in-memory index of the published datasets in a repo.
+// The index is built from the repo's references on the first query, and kept
+// current by calls to Update as datasets are published & unpublished
+type SearchIndex struct {
+	repo repo.Repo
+
+	lk      sync.Mutex
+	built   bool
+	entries map[string]*searchEntry
+}
+
+// assert at compile time that SearchIndex implements the Search &
+// SearchUpdater interfaces
+var (
+	_ Search        = (*SearchIndex)(nil)
+	_ SearchUpdater = (*SearchIndex)(nil)
+)
+
+// NewSearchIndex creates a search index for a repo
+func NewSearchIndex(r repo.Repo) *SearchIndex {
+	return &SearchIndex{
+		repo:    r,
+		entries: map[string]*searchEntry{},
+	}
+}
+
+// build indexes all published references. callers must hold the lock
+func (idx *SearchIndex) build(ctx context.Context) error {
+	num, err := idx.repo.RefCount()
+	if err != nil {
+		return err
+	}
+	refs, err := idx.repo.References(0, num)
+	if err != nil {
+		return err
+	}
+
+	current := map[string]bool{}
+	for _, ref := range refs {
+		if !ref.Published || ref.Path == "" {
+			continue
+		}
+		alias := ref.AliasString()
+		current[alias] = true
+		if e, ok := idx.entries[alias]; ok && e.info.Path == ref.Path {
+			continue
+		}
+		e, err := idx.entry(ctx, ref)
+		if err != nil {
+			log.Debugf("indexing %s: %s", alias, err)
+			delete(idx.entries, alias)
+			continue
+		}
+		idx.entries[alias] = e
+	}
+
+	for alias := range idx.entries {
+		if !current[alias] {
+			delete(idx.entries, alias)
+		}
+	}
+	idx.built = true
+	return nil
+}
+
+// Update re-indexes a single dataset from the repo's references
+func (idx *SearchIndex) Update(ctx context.Context, alias string) error {
+	idx.lk.Lock()
+	defer idx.lk.Unlock()
+	if !idx.built {
+		// the first query indexes everything
+		return nil
+	}
+
+	ref, err := dsref.ParseHumanFriendly(alias)
+	if err != nil {
+		return err
+	}
+	rr, err := idx.repo.GetRef(reporef.DatasetRef{Peername: ref.Username, Name: ref.Name})
+	if err == repo.ErrNotFound || (err == nil && (!rr.Published || rr.Path == "")) {
+		delete(idx.entries, alias)
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if e, ok := idx.entries[alias]; ok && e.info.Path == rr.Path {
+		return nil
+	}
+
+	e, err := idx.entry(ctx, rr)
+	if err != nil {
+		delete(idx.entries, alias)
+		return err
+	}
+	idx.entries[alias] = e
+	return nil
+}
+
+func (idx *SearchIndex) entry(ctx context.Context, ref reporef.DatasetRef) (*searchEntry, error) {
+	ds, err := dsfs.LoadDataset(ctx, idx.repo.Store(), ref.Path)
+	if err != nil {
+		return nil, err
+	}
+	ds.Peername = ref.Peername
+	ds.Name = ref.Name
+	ds.ProfileID = ref.ProfileID.String()
+
+	e := &searchEntry{info: dsref.ConvertDatasetToVersionInfo(ds)}
+	e.info.Published = true
+	e.fields[0] = strings.ToLower(ref.Peername + " " + ref.Name)
+
+	var strong, weak []string
+	if ds.Meta != nil {
+		strong = append(strong, ds.Meta.Title)
+		strong = append(strong, ds.Meta.Keywords...)
+		weak = append(weak, ds.Meta.Description)
+		weak = append(weak, ds.Meta.Theme...)
+	}
+	weak = append(weak, structureFields(ds.Structure)...)
+	e.fields[1] = strings.ToLower(strings.Join(strong, " "))
+	e.fields[2] = strings.ToLower(strings.Join(weak, " "))
+	return e, nil
+}
+
+// structureFields returns the column titles of a tabular structure
+func structureFields(st *dataset.Structure) []string {
+	if st == nil || st.Schema == nil {
+		return nil
+	}
+	cols, _, err := tabular.ColumnsFromJSONSchema(st.Schema)
+	if err != nil {
+		return nil
+	}
+	return cols.Titles()
+}
+
+// Search queries the index
+func (idx *SearchIndex) Search(ctx context.Context, _ string, p SearchParams) ([]dsref.VersionInfo, error) {
+	idx.lk.Lock()
+	defer idx.lk.Unlock()
+	if !idx.built {
+		if err := idx.build(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	terms := strings.Fields(strings.ToLower(p.Query))
+	type result struct {
+		info  dsref.VersionInfo
+		score int
+	}
+	results := []result{}
+
+	for _, e := range idx.entries {
+		if p.Username != "" && e.info.Username != p.Username {
+			continue
+		}
+		if p.Format != "" && e.info.BodyFormat != p.Format {
+			continue
+		}
+		if !p.UpdatedSince.IsZero() && e.info.CommitTime.Before(p.UpdatedSince) {
+			continue
+		}
+		if score, ok := e.score(terms); ok {
+			results = append(results, result{info: e.info, score: score})
+		}
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].score != results[j].score {
+			return results[i].score > results[j].score
+		}
+		if !results[i].info.CommitTime.Equal(results[j].info.CommitTime) {
+			return results[i].info.CommitTime.After(results[j].info.CommitTime)
+		}
+		return results[i].info.Alias() < results[j].info.Alias()
+	})
+
+	if p.Offset >= len(results) {
+		return []dsref.VersionInfo{}, nil
+	}
+	results = results[p.Offset:]
+	if p.Limit > 0 && p.Limit < len(results) {
+		results = results[:p.Limit]
+	}
+
+	res := make([]dsref.VersionInfo, len(results))
+	for i, r := range results {
+		res[i] = r.info
+	}
+	return res, nil
+}
+
+// score ranks an entry against query terms, returning false if any term
+// doesn't match. Matches in names outweigh meta matches, which outweigh
+// description & structure matches
+func (e *searchEntry) score(terms []string) (int, bool) {
+	score := 0
+	for _, term := range terms {
+		matched := false
+		for i, field := range e.fields {
+			if strings.Contains(field, term) {
+				score += len(e.fields) - i
+				matched = true
+			}
+		}
+		if !matched {
+			return 0, false
+		}
+	}
+	return score, true
+}
+
+// UpdateSearch re-indexes a dataset in s if s is a SearchUpdater. Errors are
+// logged, a stale search index shouldn't fail the change that triggered it
+func UpdateSearch(ctx context.Context, s Search, alias string) {
+	if u, ok := s.(SearchUpdater); ok {
+		if err := u.Update(ctx, alias); err != nil {
+			log.Debugf("updating search index for %s: %s", alias, err)
+		}
+	}
+}
+
+// SearchHTTPHandler answers search queries
+func (r *Remote) SearchHTTPHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodGet {
+			apiutil.NotFoundHandler(w, req)
+			return
+		}
+		if r.SearchPreCheck != nil {
+			id, err := profile.IDB58Decode(req.Header.Get("pid"))
+			if err != nil {
+				apiutil.WriteErrResponse(w, http.StatusBadRequest, fmt.Errorf("missing signature details"))
+				return
+			}
+			if err := r.SearchPreCheck(req.Context(), id, reporef.DatasetRef{}); err != nil {
+				apiutil.WriteErrResponse(w, http.StatusForbidden, err)
+				return
+			}
+		}
+
+		page := apiutil.PageFromRequest(req)
+		p := SearchParams{
+			Query:    req.FormValue("q"),
+			Username: req.FormValue("username"),
+			Format:   req.FormValue("format"),
+			Offset:   page.Offset(),
+			Limit:    page.Limit(),
+		}
+		if since := req.FormValue("updatedSince"); since != "" {
+			t, err := time.Parse(time.RFC3339, since)
+			if err != nil {
+				apiutil.WriteErrResponse(w, http.StatusBadRequest, fmt.Errorf("invalid updatedSince: %s", err))
+				return
+			}
+			p.UpdatedSince = t
+		}
+
+		res, err := r.Search.Search(req.Context(), req.Header.Get("pid"), p)
+		if err != nil {
+			apiutil.WriteErrResponse(w, http.StatusInternalServerError, err)
+			return
+		}
+		apiutil.WritePageResponse(w, res, req, page)
+	}
+}
@@ -0,0 +1,183 @@
+package remote
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/ioes"
+	"github.com/qri-io/qfs"
+	"github.com/qri-io/qri/base"
+	"github.com/qri-io/qri/dsref"
+	"github.com/qri-io/qri/repo"
+	reporef "github.com/qri-io/qri/repo/ref"
+)
+
+func TestSearchHTTP(t *testing.T) {
+	tr, cleanup := newTestRunner(t)
+	defer cleanup()
+
+	rem := tr.NodeARemote(t)
+	server := tr.RemoteTestServer(rem)
+	defer server.Close()
+	cli := tr.NodeBClient(t)
+
+	wbp := writeWorldBankPopulation(tr.Ctx, t, tr.NodeA.Repo)
+	publishRef(t, tr.NodeA.Repo, &wbp)
+	vvs := writeVideoViewStats(tr.Ctx, t, tr.NodeA.Repo)
+	publishRef(t, tr.NodeA.Repo, &vvs)
+	cities := writeCities(tr.Ctx, t, tr.NodeA.Repo)
+	publishRef(t, tr.NodeA.Repo, &cities)
+	// unpublished datasets aren't searchable
+	unpublished := writeUnpublished(tr.Ctx, t, tr.NodeA.Repo)
+
+	cases := []struct {
+		description string
+		params      SearchParams
+		expect      []string
+	}{
+		{"all published", SearchParams{}, []string{"cities", "video_view_stats", "world_bank_population"}},
+		{"name", SearchParams{Query: "population"}, []string{"world_bank_population"}},
+		{"title, case insensitive", SearchParams{Query: "VIEW stats"}, []string{"video_view_stats"}},
+		{"keyword", SearchParams{Query: "urban"}, []string{"cities"}},
+		{"structure field", SearchParams{Query: "in_usa"}, []string{"cities"}},
+		{"all terms must match", SearchParams{Query: "population stats"}, []string{}},
+		{"format", SearchParams{Format: "csv"}, []string{"cities"}},
+		{"username", SearchParams{Username: "nobody"}, []string{}},
+		{"updated since", SearchParams{UpdatedSince: time.Now().Add(time.Hour)}, []string{}},
+		{"limit", SearchParams{Limit: 1, Query: "population"}, []string{"world_bank_population"}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			res, err := cli.Search(tr.Ctx, c.params, server.URL)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := searchNames(res); !sameNames(got, c.expect) {
+				t.Errorf("expected %v, got %v", c.expect, got)
+			}
+		})
+	}
+
+	page, err := cli.Search(tr.Ctx, SearchParams{Offset: 2, Limit: 2}, server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page) != 1 {
+		t.Errorf("expected second page to have 1 result, got: %v", searchNames(page))
+	}
+
+	// removing a reference drops it from results once the index is updated
+	if err := tr.NodeA.Repo.DeleteRef(cities); err != nil {
+		t.Fatal(err)
+	}
+	UpdateSearch(tr.Ctx, rem.Search, cities.AliasString())
+	res, err := cli.Search(tr.Ctx, SearchParams{Query: "urban"}, server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res) != 0 {
+		t.Errorf("expected removed dataset to be dropped from results, got: %v", searchNames(res))
+	}
+
+	// publishing adds a dataset to results once the index is updated
+	publishRef(t, tr.NodeA.Repo, &unpublished)
+	UpdateSearch(tr.Ctx, rem.Search, unpublished.AliasString())
+	if res, err = cli.Search(tr.Ctx, SearchParams{Username: unpublished.Peername, Query: unpublished.Name}, server.URL); err != nil {
+		t.Fatal(err)
+	}
+	if len(res) != 1 {
+		t.Errorf("expected published dataset to be found, got: %v", searchNames(res))
+	}
+}
+
+func TestSearchRanking(t *testing.T) {
+	e := &searchEntry{fields: [3]string{"b5 cities", "cities of the world", "population city in_usa"}}
+	nameMatch, ok := e.score([]string{"cities"})
+	if !ok {
+		t.Fatal("expected match")
+	}
+	structureMatch, ok := e.score([]string{"in_usa"})
+	if !ok {
+		t.Fatal("expected match")
+	}
+	if nameMatch <= structureMatch {
+		t.Errorf("expected name matches to outrank structure matches. name: %d, structure: %d", nameMatch, structureMatch)
+	}
+	if _, ok := e.score([]string{"cities", "missing"}); ok {
+		t.Error("expected entry missing a term not to match")
+	}
+}
+
+func searchNames(res []dsref.VersionInfo) []string {
+	names := make([]string, len(res))
+	for i, vi := range res {
+		names[i] = vi.Name
+	}
+	return names
+}
+
+func sameNames(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	set := map[string]bool{}
+	for _, n := range a {
+		set[n] = true
+	}
+	for _, n := range b {
+		if !set[n] {
+			return false
+		}
+	}
+	return true
+}
+
+func writeCities(ctx context.Context, t *testing.T, r repo.Repo) reporef.DatasetRef {
+	ds := &dataset.Dataset{
+		Name:   "cities",
+		Commit: &dataset.Commit{Title: "initial commit"},
+		Meta: &dataset.Meta{
+			Title:    "Cities",
+			Keywords: []string{"urban"},
+		},
+		Structure: &dataset.Structure{
+			Format:       "csv",
+			FormatConfig: map[string]interface{}{"headerRow": true},
+			Schema: map[string]interface{}{
+				"type": "array",
+				"items": map[string]interface{}{
+					"type": "array",
+					"items": []interface{}{
+						map[string]interface{}{"title": "city", "type": "string"},
+						map[string]interface{}{"title": "in_usa", "type": "boolean"},
+					},
+				},
+			},
+		},
+	}
+	ds.SetBodyFile(qfs.NewMemfileBytes("body.csv", []byte("city,in_usa\ntoronto,false\n")))
+
+	ref, err := base.CreateDataset(ctx, r, ioes.NewDiscardIOStreams(), ds, nil, base.SaveSwitches{Pin: true, ShouldRender: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ref
+}
+
+func writeUnpublished(ctx context.Context, t *testing.T, r repo.Repo) reporef.DatasetRef {
+	ds := &dataset.Dataset{
+		Name:      "unpublished_population",
+		Commit:    &dataset.Commit{Title: "initial commit"},
+		Structure: &dataset.Structure{Format: "json", Schema: dataset.BaseSchemaArray},
+	}
+	ds.SetBodyFile(qfs.NewMemfileBytes("body.json", []byte("[1]")))
+
+	ref, err := base.CreateDataset(ctx, r, ioes.NewDiscardIOStreams(), ds, nil, base.SaveSwitches{Pin: true, ShouldRender: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ref
+}
@@ -3,13 +3,22 @@ package remote
 import (
 	"encoding/base64"
 	"fmt"
+	"net/http"
 	"time"
 
 	crypto "github.com/libp2p/go-libp2p-core/crypto"
 	"github.com/multiformats/go-multihash"
+	"github.com/qri-io/qri/repo/profile"
 	reporef "github.com/qri-io/qri/repo/ref"
 )
 
+// signedRequestMaxAge is the oldest a signed HTTP request timestamp may be
+var signedRequestMaxAge = time.Minute * 5
+
+// ErrUnauthenticated is returned by requests that don't carry a valid
+// signature
+var ErrUnauthenticated = fmt.Errorf("request signature is missing or invalid")
+
 var (
 	// nowFunc is an ps function for getting timestamps
 	nowFunc = time.Now
@@ -75,6 +84,73 @@ func VerifySigParams(pubkey crypto.PubKey, params map[string]string) (bool, erro
 	return pubkey.Verify([]byte(rss), sigBytes)
 }
 
+// signHTTPRequest adds signature headers to a request
+func signHTTPRequest(pk crypto.PrivKey, req *http.Request) error {
+	now := fmt.Sprintf("%d", nowFunc().In(time.UTC).Unix())
+
+	// TODO (b5) - we shouldn't be calculating profile IDs here
+	peerID, err := calcProfileID(pk)
+	if err != nil {
+		return err
+	}
+
+	b64Sig, err := signString(pk, requestSigningString(now, peerID, req.URL.Path))
+	if err != nil {
+		return err
+	}
+	pubKey, err := crypto.MarshalPublicKey(pk.GetPublic())
+	if err != nil {
+		return err
+	}
+
+	req.Header.Add("timestamp", now)
+	req.Header.Add("pid", peerID)
+	req.Header.Add("signature", b64Sig)
+	req.Header.Add("pubkey", base64.StdEncoding.EncodeToString(pubKey))
+	return nil
+}
+
+// verifyHTTPRequest checks the signature headers of a request signed with
+// signHTTPRequest, returning the profile ID of the signer. The signing key is
+// carried in the request & must hash to the claimed profile ID
+func verifyHTTPRequest(req *http.Request) (profile.ID, error) {
+	timestamp := req.Header.Get("timestamp")
+	pid := req.Header.Get("pid")
+	signature := req.Header.Get("signature")
+	if timestamp == "" || pid == "" || signature == "" || req.Header.Get("pubkey") == "" {
+		return "", ErrUnauthenticated
+	}
+
+	var sec int64
+	if _, err := fmt.Sscanf(timestamp, "%d", &sec); err != nil {
+		return "", ErrUnauthenticated
+	}
+	if age := nowFunc().Sub(time.Unix(sec, 0)); age > signedRequestMaxAge || age < -signedRequestMaxAge {
+		return "", fmt.Errorf("%w: request timestamp is out of range", ErrUnauthenticated)
+	}
+
+	keyBytes, err := base64.StdEncoding.DecodeString(req.Header.Get("pubkey"))
+	if err != nil {
+		return "", ErrUnauthenticated
+	}
+	pubKey, err := crypto.UnmarshalPublicKey(keyBytes)
+	if err != nil {
+		return "", ErrUnauthenticated
+	}
+	if keyID, err := pubKeyProfileID(pubKey); err != nil || keyID != pid {
+		return "", fmt.Errorf("%w: key doesn't match profile ID", ErrUnauthenticated)
+	}
+
+	sigBytes, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return "", ErrUnauthenticated
+	}
+	if ok, err := pubKey.Verify([]byte(requestSigningString(timestamp, pid, req.URL.Path)), sigBytes); err != nil || !ok {
+		return "", ErrUnauthenticated
+	}
+	return profile.IDB58Decode(pid)
+}
+
 func requestSigningString(timestamp, peerID, cidStr string) string {
 	return fmt.Sprintf("%s.%s.%s", timestamp, peerID, cidStr)
 }
@@ -88,7 +164,11 @@ func signString(privKey crypto.PrivKey, str string) (b64Sig string, err error) {
 }
 
 func calcProfileID(privKey crypto.PrivKey) (string, error) {
-	pubkeybytes, err := privKey.GetPublic().Bytes()
+	return pubKeyProfileID(privKey.GetPublic())
+}
+
+func pubKeyProfileID(pubKey crypto.PubKey) (string, error) {
+	pubkeybytes, err := pubKey.Bytes()
 	if err != nil {
 		return "", fmt.Errorf("error getting pubkey bytes: %s", err.Error())
 	}
@@ -1,7 +1,10 @@
 package remote
 
 import (
+	"errors"
+	"net/http"
 	"testing"
+	"time"
 
 	"github.com/qri-io/qri/config/test"
 	"github.com/qri-io/qri/repo/profile"
@@ -51,3 +54,56 @@ func TestVerifySigParams(t *testing.T) {
 		t.Errorf("case 'should not verify', expected verification to be false, but was true")
 	}
 }
+
+func TestVerifyHTTPRequest(t *testing.T) {
+	peerInfo0 := test.GetTestPeerInfo(0)
+	peerInfo1 := test.GetTestPeerInfo(1)
+
+	signed := func(t *testing.T) *http.Request {
+		req, err := http.NewRequest("GET", "http://remote.qri.io/remote/notifications", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := signHTTPRequest(peerInfo0.PrivKey, req); err != nil {
+			t.Fatal(err)
+		}
+		return req
+	}
+
+	pid, err := verifyHTTPRequest(signed(t))
+	if err != nil {
+		t.Fatalf("expected signed request to verify, got: %s", err)
+	}
+	if pid.String() != peerInfo0.PeerID.Pretty() {
+		t.Errorf("profile ID mismatch. expected: %s, got: %s", peerInfo0.PeerID.Pretty(), pid)
+	}
+
+	unsigned, err := http.NewRequest("GET", "http://remote.qri.io/remote/notifications", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	unsigned.Header.Set("pid", peerInfo0.PeerID.Pretty())
+
+	claimsOther := signed(t)
+	claimsOther.Header.Set("pid", peerInfo1.PeerID.Pretty())
+
+	otherPath := signed(t)
+	otherPath.URL.Path = "/remote/subscriptions"
+
+	prevNow := nowFunc
+	nowFunc = func() time.Time { return prevNow().Add(-time.Hour) }
+	stale := signed(t)
+	nowFunc = prevNow
+
+	cases := map[string]*http.Request{
+		"unsigned":          unsigned,
+		"other profile":     claimsOther,
+		"other path":        otherPath,
+		"expired timestamp": stale,
+	}
+	for name, req := range cases {
+		if _, err := verifyHTTPRequest(req); !errors.Is(err, ErrUnauthenticated) {
+			t.Errorf("case %q: expected ErrUnauthenticated, got: %v", name, err)
+		}
+	}
+}
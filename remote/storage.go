@@ -0,0 +1,145 @@
+package remote
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/qri-io/dag"
+	"github.com/qri-io/qfs/cafs"
+	"github.com/qri-io/qri/dsref"
+	"github.com/qri-io/qri/repo"
+	reporef "github.com/qri-io/qri/repo/ref"
+)
+
+func infoSize(info dag.Info) (total uint64) {
+	for _, s := range info.Sizes {
+		total += s
+	}
+	return total
+}
+
+// infoBlocks maps the CIDs of blocks in a DAG to their sizes
+func infoBlocks(info dag.Info) map[string]uint64 {
+	if info.Manifest == nil || len(info.Manifest.Nodes) != len(info.Sizes) {
+		return nil
+	}
+	blocks := make(map[string]uint64, len(info.Sizes))
+	for i, id := range info.Manifest.Nodes {
+		blocks[id] = info.Sizes[i]
+	}
+	return blocks
+}
+
+// checkQuota rejects pushes that would take the pushing profile over its
+// storage quota. Blocks the profile already stores don't count toward the
+// push. Re-pushing a version that's already stored is always allowed
+func (r *Remote) checkQuota(info dag.Info, meta map[string]string) error {
+	pid, ref, err := r.pidAndRefFromMeta(meta)
+	if err != nil {
+		return err
+	}
+	quota := r.cfg.Quota(pid.String())
+	if quota <= 0 || (ref.Path != "" && r.usage.Has(ref.Path)) {
+		return nil
+	}
+
+	size, used := r.usage.AddedBytes(pid.String(), &StoredVersion{Size: infoSize(info), Blocks: infoBlocks(info)})
+	if used+size > uint64(quota) {
+		return fmt.Errorf("%w: pushing %d bytes with %d of %d bytes used", ErrQuotaExceeded, size, used, quota)
+	}
+	return nil
+}
+
+// Usage summarizes storage held by the remote for each profile that has pushed
+// to it
+func (r *Remote) Usage() []ProfileUsage {
+	return UsageReport(r.usage.Versions(), r.cfg)
+}
+
+// Sweep unpins stored versions that fall outside the remote's retention
+// policy, returning the versions removed. Versions that fail to unpin are kept
+func (r *Remote) Sweep(ctx context.Context) ([]*StoredVersion, error) {
+	if !r.cfg.Retention.Enabled() {
+		return nil, nil
+	}
+
+	versions := r.usage.Versions()
+	heads := map[string]string{}
+	for _, v := range versions {
+		if _, ok := heads[v.Ref]; ok {
+			continue
+		}
+		ref, err := dsref.ParseHumanFriendly(v.Ref)
+		if err != nil {
+			return nil, err
+		}
+		head, err := r.node.Repo.GetRef(reporef.DatasetRef{Peername: ref.Username, Name: ref.Name})
+		if err != nil && err != repo.ErrNotFound {
+			return nil, err
+		}
+		heads[v.Ref] = head.Path
+	}
+
+	pinner, _ := r.node.Repo.Store().(cafs.Pinner)
+	return unpinVersions(ctx, pinner, r.usage, expiredVersions(versions, r.cfg.Retention, heads, time.Now()))
+}
+
+// unpinVersions unpins versions, removing them from usage. Versions that fail
+// to unpin are still stored & stay in usage. Unpinning continues past
+// failures, returning the first unpin error along with the versions removed
+func unpinVersions(ctx context.Context, pinner cafs.Pinner, usage *Usage, versions []*StoredVersion) ([]*StoredVersion, error) {
+	var unpinErr error
+	removed := []*StoredVersion{}
+	for _, v := range versions {
+		if err := ctx.Err(); err != nil {
+			return removed, err
+		}
+		if pinner != nil {
+			if err := pinner.Unpin(ctx, v.Path, true); err != nil {
+				log.Debugf("unpinning %s: %s", v.Path, err)
+				if unpinErr == nil {
+					unpinErr = fmt.Errorf("unpinning %s: %w", v.Path, err)
+				}
+				continue
+			}
+		}
+		if err := usage.Remove(v.Path); err != nil {
+			return removed, err
+		}
+		removed = append(removed, v)
+	}
+	return removed, unpinErr
+}
+
+// StartSweeper runs retention sweeps at the policy's sweep interval until ctx
+// is cancelled. StartSweeper does nothing if the remote has no retention policy
+func (r *Remote) StartSweeper(ctx context.Context) {
+	if !r.cfg.Retention.Enabled() {
+		return
+	}
+	interval, err := r.cfg.Retention.SweepIntervalDuration()
+	if err != nil {
+		log.Errorf("starting retention sweeper: %s", err)
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				removed, err := r.Sweep(ctx)
+				if err != nil {
+					log.Errorf("retention sweep: %s", err)
+				}
+				if len(removed) > 0 {
+					log.Infof("retention sweep unpinned %d version(s)", len(removed))
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
@@ -0,0 +1,175 @@
+package remote
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/qri-io/dag"
+	"github.com/qri-io/qri/config"
+	reporef "github.com/qri-io/qri/repo/ref"
+)
+
+func TestPushQuota(t *testing.T) {
+	tr, cleanup := newTestRunner(t)
+	defer cleanup()
+
+	rem := tr.NodeARemote(t)
+	server := tr.RemoteTestServer(rem)
+	defer server.Close()
+
+	cli := tr.NodeBClient(t)
+	wbp := writeWorldBankPopulation(tr.Ctx, t, tr.NodeB.Repo)
+	if err := cli.PushLogs(tr.Ctx, reporef.ConvertToDsref(wbp), server.URL); err != nil {
+		t.Fatal(err)
+	}
+	if err := cli.PushDataset(tr.Ctx, wbp, server.URL); err != nil {
+		t.Fatal(err)
+	}
+
+	usage := rem.Usage()
+	if len(usage) != 1 || usage[0].Versions != 1 || usage[0].Bytes == 0 {
+		t.Fatalf("expected usage for one pushed version, got: %v", usage)
+	}
+
+	// allow a single byte more than what's already stored
+	rem.cfg.ProfileQuota = int64(usage[0].Bytes) + 1
+
+	// pushing a version that's already stored doesn't count against quota
+	if err := cli.PushDataset(tr.Ctx, wbp, server.URL); err != nil {
+		t.Errorf("expected re-push to succeed, got: %s", err)
+	}
+
+	vvs := writeVideoViewStats(tr.Ctx, t, tr.NodeB.Repo)
+	if err := cli.PushLogs(tr.Ctx, reporef.ConvertToDsref(vvs), server.URL); err != nil {
+		t.Fatal(err)
+	}
+	err := cli.PushDataset(tr.Ctx, vvs, server.URL)
+	if err == nil {
+		t.Fatal("expected push over quota to fail")
+	}
+	if !strings.Contains(err.Error(), ErrQuotaExceeded.Error()) {
+		t.Errorf("expected quota error, got: %s", err)
+	}
+
+	rem.cfg.Quotas = map[string]int64{usage[0].ProfileID: -1}
+	if err := cli.PushDataset(tr.Ctx, vvs, server.URL); err != nil {
+		t.Errorf("expected push for profile without a quota to succeed, got: %s", err)
+	}
+}
+
+func TestCheckQuota(t *testing.T) {
+	tr, cleanup := newTestRunner(t)
+	defer cleanup()
+
+	rem := tr.NodeARemote(t)
+	rem.cfg.ProfileQuota = 100
+	pro, err := tr.NodeB.Repo.Profile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	pid := pro.ID
+	if err := rem.usage.Add(&StoredVersion{ProfileID: pid.String(), Ref: "B/ds", Path: "/ipfs/QmStored", Size: 90}); err != nil {
+		t.Fatal(err)
+	}
+
+	meta := map[string]string{"pid": pid.String(), "peername": "B", "name": "ds", "path": "/ipfs/QmNew"}
+	err = rem.checkQuota(dag.Info{Sizes: []uint64{15, 5}}, meta)
+	if !errors.Is(err, ErrQuotaExceeded) {
+		t.Errorf("expected ErrQuotaExceeded, got: %v", err)
+	}
+	if err := rem.checkQuota(dag.Info{Sizes: []uint64{10}}, meta); err != nil {
+		t.Errorf("expected push within quota to be accepted, got: %s", err)
+	}
+	if err := rem.checkQuota(dag.Info{Sizes: []uint64{10}}, map[string]string{"peername": "B", "name": "ds"}); err == nil {
+		t.Error("expected push without a profile ID to error")
+	}
+}
+
+func TestSweep(t *testing.T) {
+	tr, cleanup := newTestRunner(t)
+	defer cleanup()
+
+	rem := tr.NodeARemote(t)
+	wbp := writeWorldBankPopulation(tr.Ctx, t, tr.NodeA.Repo)
+	// expired versions are unpinned, stand in a pinned version for one
+	expired := writeVideoViewStats(tr.Ctx, t, tr.NodeA.Repo).Path
+
+	old := time.Now().Add(-time.Hour * 24 * 90)
+	versions := []*StoredVersion{
+		// the head is the oldest version, but is always kept
+		{Ref: wbp.AliasString(), Path: wbp.Path, Pushed: old},
+		{Ref: wbp.AliasString(), Path: expired, Pushed: old.Add(time.Hour)},
+		{Ref: wbp.AliasString(), Path: "/ipfs/QmRecent", Pushed: time.Now()},
+	}
+	for _, v := range versions {
+		if err := rem.usage.Add(v); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	removed, err := rem.Sweep(tr.Ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(removed) != 0 {
+		t.Errorf("expected sweep without a retention policy to remove nothing, got: %v", removed)
+	}
+
+	rem.cfg.Retention = &config.Retention{KeepDays: 30}
+	if removed, err = rem.Sweep(tr.Ctx); err != nil {
+		t.Fatal(err)
+	}
+	if len(removed) != 1 || removed[0].Path != expired {
+		t.Errorf("expected only %s to be removed, got: %v", expired, removed)
+	}
+	if got := len(rem.usage.Versions()); got != 2 {
+		t.Errorf("expected 2 stored versions after sweep, got %d", got)
+	}
+}
+
+type failingPinner struct {
+	fail map[string]bool
+}
+
+func (p failingPinner) Pin(ctx context.Context, key string, recursive bool) error {
+	return nil
+}
+
+func (p failingPinner) Unpin(ctx context.Context, key string, recursive bool) error {
+	if p.fail[key] {
+		return errors.New("unpin failed")
+	}
+	return nil
+}
+
+func TestUnpinVersionsError(t *testing.T) {
+	ctx := context.Background()
+	usage := &Usage{versions: map[string]*StoredVersion{}}
+	versions := []*StoredVersion{
+		{ProfileID: "a", Ref: "a/ds", Path: "/ipfs/QmFails", Size: 10},
+		{ProfileID: "a", Ref: "a/ds", Path: "/ipfs/QmUnpins", Size: 20},
+	}
+	for _, v := range versions {
+		if err := usage.Add(v); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	pinner := failingPinner{fail: map[string]bool{"/ipfs/QmFails": true}}
+	removed, err := unpinVersions(ctx, pinner, usage, versions)
+	if err == nil || !strings.Contains(err.Error(), "/ipfs/QmFails") {
+		t.Errorf("expected an unpin error for /ipfs/QmFails, got: %v", err)
+	}
+	if len(removed) != 1 || removed[0].Path != "/ipfs/QmUnpins" {
+		t.Errorf("expected only /ipfs/QmUnpins to be removed, got: %v", removed)
+	}
+	if !usage.Has("/ipfs/QmFails") {
+		t.Error("expected a version that failed to unpin to stay in usage")
+	}
+	if got := usage.ProfileBytes("a"); got != 10 {
+		t.Errorf("expected usage to count the still-stored version, got %d bytes", got)
+	}
+}
@@ -0,0 +1,445 @@
+package remote
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	peer "github.com/libp2p/go-libp2p-core/peer"
+	"github.com/qri-io/apiutil"
+	"github.com/qri-io/qri/dsref"
+	reporef "github.com/qri-io/qri/repo/ref"
+)
+
+const (
+	subscribersFilename   = "subscribers.json"
+	subscriptionsFilename = "subscriptions.json"
+)
+
+// notificationKeepAlive is the time between keep-alive comments sent to
+// notification streams, letting both sides detect dropped connections
+var notificationKeepAlive = time.Second * 30
+
+// Subscriber is a profile a remote notifies when new versions of a dataset are
+// pushed
+type Subscriber struct {
+	ProfileID string `json:"profileID"`
+	// PeerID is sent p2p notifications if set
+	PeerID     string    `json:"peerID,omitempty"`
+	Ref        string    `json:"ref"`
+	Subscribed time.Time `json:"subscribed"`
+}
+
+func (s *Subscriber) key() string {
+	return subscriberKey(s.ProfileID, s.Ref)
+}
+
+func subscriberKey(profileID, alias string) string {
+	return fmt.Sprintf("%s %s", profileID, alias)
+}
+
+// Subscribers tracks the datasets each profile is subscribed to on a remote,
+// and delivers notifications to profiles listening for them
+type Subscribers struct {
+	dir  string
+	lk   sync.Mutex
+	subs map[string]*Subscriber
+	// notification channels for each listening profile
+	listeners map[string]map[chan dsref.Ref]struct{}
+}
+
+// NewSubscribers creates a subscriber store backed by a directory. An empty
+// dir keeps subscribers in memory only
+func NewSubscribers(dir string) (*Subscribers, error) {
+	s := &Subscribers{
+		dir:       dir,
+		subs:      map[string]*Subscriber{},
+		listeners: map[string]map[chan dsref.Ref]struct{}{},
+	}
+	if dir == "" {
+		return s, nil
+	}
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return nil, err
+	}
+
+	list := []*Subscriber{}
+	if err := readJSONFile(dir, subscribersFilename, &list); err != nil {
+		return nil, fmt.Errorf("reading subscribers: %w", err)
+	}
+	for _, sub := range list {
+		s.subs[sub.key()] = sub
+	}
+	return s, nil
+}
+
+// Add subscribes a profile to a dataset, replacing any existing subscription
+func (s *Subscribers) Add(sub *Subscriber) error {
+	s.lk.Lock()
+	defer s.lk.Unlock()
+	s.subs[sub.key()] = sub
+	return s.write()
+}
+
+// Remove unsubscribes a profile from a dataset
+func (s *Subscribers) Remove(profileID, alias string) error {
+	s.lk.Lock()
+	defer s.lk.Unlock()
+	key := subscriberKey(profileID, alias)
+	if _, ok := s.subs[key]; !ok {
+		return nil
+	}
+	delete(s.subs, key)
+	return s.write()
+}
+
+// ForProfile lists the subscriptions of a profile
+func (s *Subscribers) ForProfile(profileID string) []*Subscriber {
+	return s.filter(func(sub *Subscriber) bool { return sub.ProfileID == profileID })
+}
+
+// ForRef lists the subscribers to a dataset
+func (s *Subscribers) ForRef(alias string) []*Subscriber {
+	return s.filter(func(sub *Subscriber) bool { return sub.Ref == alias })
+}
+
+func (s *Subscribers) filter(keep func(sub *Subscriber) bool) []*Subscriber {
+	s.lk.Lock()
+	defer s.lk.Unlock()
+	res := []*Subscriber{}
+	for _, sub := range s.list() {
+		if keep(sub) {
+			res = append(res, sub)
+		}
+	}
+	return res
+}
+
+func (s *Subscribers) list() []*Subscriber {
+	list := make([]*Subscriber, 0, len(s.subs))
+	for _, sub := range s.subs {
+		cpy := *sub
+		list = append(list, &cpy)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].key() < list[j].key() })
+	return list
+}
+
+func (s *Subscribers) write() error {
+	if s.dir == "" {
+		return nil
+	}
+	return writeJSONFile(s.dir, subscribersFilename, s.list())
+}
+
+// listen registers a channel that receives notifications for a profile.
+// calling the returned function stops delivery
+func (s *Subscribers) listen(profileID string) (<-chan dsref.Ref, func()) {
+	ch := make(chan dsref.Ref, 16)
+	s.lk.Lock()
+	if s.listeners[profileID] == nil {
+		s.listeners[profileID] = map[chan dsref.Ref]struct{}{}
+	}
+	s.listeners[profileID][ch] = struct{}{}
+	s.lk.Unlock()
+
+	return ch, func() {
+		s.lk.Lock()
+		defer s.lk.Unlock()
+		delete(s.listeners[profileID], ch)
+		if len(s.listeners[profileID]) == 0 {
+			delete(s.listeners, profileID)
+		}
+	}
+}
+
+// deliver sends a notification to all listeners for a profile. Listeners that
+// aren't keeping up miss notifications rather than block the remote
+func (s *Subscribers) deliver(profileID string, ref dsref.Ref) {
+	s.lk.Lock()
+	defer s.lk.Unlock()
+	for ch := range s.listeners[profileID] {
+		select {
+		case ch <- ref:
+		default:
+			log.Debugf("dropped notification for %s: listener is full", profileID)
+		}
+	}
+}
+
+// notifySubscribers tells subscribers to a dataset a new version has been
+// pushed, both to profiles listening over HTTP & to peers over p2p
+func (r *Remote) notifySubscribers(ref reporef.DatasetRef) {
+	dr := reporef.ConvertToDsref(ref)
+	for _, sub := range r.subscribers.ForRef(ref.AliasString()) {
+		r.subscribers.deliver(sub.ProfileID, dr)
+
+		if sub.PeerID == "" || r.node.Host() == nil {
+			continue
+		}
+		pid, err := peer.IDB58Decode(sub.PeerID)
+		if err != nil {
+			log.Debugf("invalid subscriber peer ID %q: %s", sub.PeerID, err)
+			continue
+		}
+		go func(pid peer.ID) {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second*30)
+			defer cancel()
+			if err := r.node.SendDatasetNotification(ctx, dr, pid); err != nil {
+				log.Debugf("sending p2p notification to %s: %s", pid, err)
+			}
+		}(pid)
+	}
+}
+
+// SubscriptionsHTTPHandler lists, adds & removes the dataset subscriptions of
+// the requesting profile. GET lists subscriptions, POST subscribes to the
+// dataset named by the "ref" query param & DELETE unsubscribes from it. An
+// optional "peerID" param on POST requests p2p notifications, and must be the
+// peer ID of the requesting profile. Requests must be signed
+func (r *Remote) SubscriptionsHTTPHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		pid, err := verifyHTTPRequest(req)
+		if err != nil {
+			apiutil.WriteErrResponse(w, http.StatusUnauthorized, err)
+			return
+		}
+
+		if req.Method == http.MethodGet {
+			apiutil.WriteResponse(w, r.subscribers.ForProfile(pid.String()))
+			return
+		}
+
+		ref, err := dsref.ParseHumanFriendly(req.FormValue("ref"))
+		if err != nil {
+			apiutil.WriteErrResponse(w, http.StatusBadRequest, err)
+			return
+		}
+		alias := ref.Alias()
+
+		switch req.Method {
+		case http.MethodPost:
+			if r.subscribePreCheck != nil {
+				if err := r.subscribePreCheck(req.Context(), pid, reporef.DatasetRef{Peername: ref.Username, Name: ref.Name}); err != nil {
+					apiutil.WriteErrResponse(w, http.StatusForbidden, err)
+					return
+				}
+			}
+			// peers are notified over p2p, only accept the peer ID of the
+			// signing key so subscribers can't direct notifications elsewhere
+			peerID := req.FormValue("peerID")
+			if peerID != "" && peerID != pid.String() {
+				apiutil.WriteErrResponse(w, http.StatusForbidden, fmt.Errorf("peerID %q doesn't match the requesting profile", peerID))
+				return
+			}
+			sub := &Subscriber{
+				ProfileID:  pid.String(),
+				PeerID:     peerID,
+				Ref:        alias,
+				Subscribed: time.Now(),
+			}
+			if err := r.subscribers.Add(sub); err != nil {
+				apiutil.WriteErrResponse(w, http.StatusInternalServerError, err)
+				return
+			}
+			apiutil.WriteResponse(w, sub)
+		case http.MethodDelete:
+			if err := r.subscribers.Remove(pid.String(), alias); err != nil {
+				apiutil.WriteErrResponse(w, http.StatusInternalServerError, err)
+				return
+			}
+			apiutil.WriteResponse(w, map[string]string{"ref": alias})
+		default:
+			apiutil.NotFoundHandler(w, req)
+		}
+	}
+}
+
+// NotificationsHTTPHandler streams notifications for the requesting profile's
+// subscriptions as Server-Sent Events. Each event has the type "notification"
+// and a JSON dataset reference as data. Requests must be signed
+func (r *Remote) NotificationsHTTPHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		pid, err := verifyHTTPRequest(req)
+		if err != nil {
+			apiutil.WriteErrResponse(w, http.StatusUnauthorized, err)
+			return
+		}
+
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			apiutil.WriteErrResponse(w, http.StatusInternalServerError, fmt.Errorf("streaming is not supported"))
+			return
+		}
+
+		notifications, stop := r.subscribers.listen(pid.String())
+		defer stop()
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Connection", "keep-alive")
+		w.WriteHeader(http.StatusOK)
+		flusher.Flush()
+
+		keepAlive := time.NewTicker(notificationKeepAlive)
+		defer keepAlive.Stop()
+
+		ctx := req.Context()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-keepAlive.C:
+				if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+					return
+				}
+				flusher.Flush()
+			case ref := <-notifications:
+				data, err := json.Marshal(ref)
+				if err != nil {
+					log.Debugf("encoding notification: %s", err)
+					continue
+				}
+				if _, err := fmt.Fprintf(w, "event: notification\ndata: %s\n\n", data); err != nil {
+					return
+				}
+				flusher.Flush()
+			}
+		}
+	}
+}
+
+// Subscription is a dataset this node has subscribed to on a remote
+type Subscription struct {
+	Ref        string `json:"ref"`
+	RemoteName string `json:"remoteName,omitempty"`
+	RemoteAddr string `json:"remoteAddr"`
+	// AutoPull pulls new versions when the remote sends a notification
+	AutoPull   bool      `json:"autoPull"`
+	Subscribed time.Time `json:"subscribed"`
+}
+
+// Subscriptions stores the datasets this node is subscribed to, persisting
+// them to a directory
+type Subscriptions struct {
+	dir  string
+	lk   sync.Mutex
+	subs map[string]*Subscription
+}
+
+// NewSubscriptions creates a subscription store backed by a directory. An
+// empty dir keeps subscriptions in memory only
+func NewSubscriptions(dir string) (*Subscriptions, error) {
+	s := &Subscriptions{dir: dir, subs: map[string]*Subscription{}}
+	if dir == "" {
+		return s, nil
+	}
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return nil, err
+	}
+
+	list := []*Subscription{}
+	if err := readJSONFile(dir, subscriptionsFilename, &list); err != nil {
+		return nil, fmt.Errorf("reading subscriptions: %w", err)
+	}
+	for _, sub := range list {
+		s.subs[sub.Ref] = sub
+	}
+	return s, nil
+}
+
+// Put adds or replaces the subscription to a dataset
+func (s *Subscriptions) Put(sub *Subscription) error {
+	s.lk.Lock()
+	defer s.lk.Unlock()
+	s.subs[sub.Ref] = sub
+	return s.write()
+}
+
+// Get returns the subscription to a dataset, nil if none exists
+func (s *Subscriptions) Get(alias string) *Subscription {
+	s.lk.Lock()
+	defer s.lk.Unlock()
+	if sub, ok := s.subs[alias]; ok {
+		cpy := *sub
+		return &cpy
+	}
+	return nil
+}
+
+// Delete removes the subscription to a dataset
+func (s *Subscriptions) Delete(alias string) error {
+	s.lk.Lock()
+	defer s.lk.Unlock()
+	if _, ok := s.subs[alias]; !ok {
+		return nil
+	}
+	delete(s.subs, alias)
+	return s.write()
+}
+
+// List returns all subscriptions, sorted by dataset
+func (s *Subscriptions) List() []*Subscription {
+	s.lk.Lock()
+	defer s.lk.Unlock()
+	return s.list()
+}
+
+func (s *Subscriptions) list() []*Subscription {
+	list := make([]*Subscription, 0, len(s.subs))
+	for _, sub := range s.subs {
+		cpy := *sub
+		list = append(list, &cpy)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Ref < list[j].Ref })
+	return list
+}
+
+func (s *Subscriptions) write() error {
+	if s.dir == "" {
+		return nil
+	}
+	return writeJSONFile(s.dir, subscriptionsFilename, s.list())
+}
+
+// readNotifications parses a Server-Sent Event stream written by
+// NotificationsHTTPHandler, sending each notification on refs
+func readNotifications(ctx context.Context, r io.Reader, refs chan<- dsref.Ref) error {
+	var eventType, data string
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		line := sc.Text()
+		switch {
+		case line == "":
+			// a blank line dispatches the event
+			if eventType == "notification" && data != "" {
+				ref := dsref.Ref{}
+				if err := json.Unmarshal([]byte(data), &ref); err != nil {
+					log.Debugf("decoding notification: %s", err)
+				} else {
+					select {
+					case refs <- ref:
+					case <-ctx.Done():
+						return ctx.Err()
+					}
+				}
+			}
+			eventType, data = "", ""
+		case strings.HasPrefix(line, ":"):
+			// comment, used for keep-alives
+		case strings.HasPrefix(line, "event:"):
+			eventType = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case strings.HasPrefix(line, "data:"):
+			data += strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+		}
+	}
+	return sc.Err()
+}
@@ -0,0 +1,213 @@
+package remote
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/qri-io/qri/dsref"
+	"github.com/qri-io/qri/repo/profile"
+	reporef "github.com/qri-io/qri/repo/ref"
+)
+
+func TestSubscriptionNotifications(t *testing.T) {
+	tr, cleanup := newTestRunner(t)
+	defer cleanup()
+
+	rem := tr.NodeARemote(t)
+	server := tr.RemoteTestServer(rem)
+	defer server.Close()
+
+	cli := tr.NodeBClient(t)
+	wbp := writeWorldBankPopulation(tr.Ctx, t, tr.NodeB.Repo)
+	ref := reporef.ConvertToDsref(wbp)
+
+	if err := cli.Subscribe(tr.Ctx, ref, server.URL); err != nil {
+		t.Fatal(err)
+	}
+	if subs := rem.subscribers.ForRef("B/world_bank_population"); len(subs) != 1 {
+		t.Fatalf("expected one subscriber, got: %v", subs)
+	}
+
+	ctx, cancel := context.WithCancel(tr.Ctx)
+	defer cancel()
+	notifications, err := cli.Notifications(ctx, server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := cli.PushLogs(tr.Ctx, ref, server.URL); err != nil {
+		t.Fatal(err)
+	}
+	if err := cli.PushDataset(tr.Ctx, wbp, server.URL); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case got := <-notifications:
+		if got.Alias() != "B/world_bank_population" || got.Path != wbp.Path {
+			t.Errorf("notification mismatch. got: %v", got)
+		}
+	case <-time.After(time.Second * 5):
+		t.Fatal("timed out waiting for notification")
+	}
+
+	if err := cli.Unsubscribe(tr.Ctx, ref, server.URL); err != nil {
+		t.Fatal(err)
+	}
+	if subs := rem.subscribers.ForRef("B/world_bank_population"); len(subs) != 0 {
+		t.Errorf("expected no subscribers after unsubscribing, got: %v", subs)
+	}
+}
+
+func TestSubscriptionsRequireSignature(t *testing.T) {
+	tr, cleanup := newTestRunner(t)
+	defer cleanup()
+
+	rem := tr.NodeARemote(t)
+	server := tr.RemoteTestServer(rem)
+	defer server.Close()
+
+	if err := rem.subscribers.Add(&Subscriber{ProfileID: "QmVictim", Ref: "A/world_bank_population"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// an unsigned request naming another profile
+	req, err := http.NewRequest(http.MethodDelete, server.URL+"/remote/subscriptions?ref=A/world_bank_population", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("pid", "QmVictim")
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusUnauthorized {
+		t.Errorf("expected unsigned unsubscribe to be unauthorized, got status %d", res.StatusCode)
+	}
+	if subs := rem.subscribers.ForRef("A/world_bank_population"); len(subs) != 1 {
+		t.Errorf("expected subscription to be kept, got: %v", subs)
+	}
+
+	req, err = http.NewRequest(http.MethodGet, server.URL+"/remote/notifications", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("pid", "QmVictim")
+	res, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusUnauthorized {
+		t.Errorf("expected unsigned notification stream to be unauthorized, got status %d", res.StatusCode)
+	}
+
+	// signed requests can't claim another peer ID
+	cli := tr.NodeBClient(t).(*PeerSyncClient)
+	req, err = http.NewRequest(http.MethodPost, server.URL+"/remote/subscriptions?ref=A/world_bank_population&peerID=QmVictim", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cli.signHTTPRequest(req); err != nil {
+		t.Fatal(err)
+	}
+	res, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusForbidden {
+		t.Errorf("expected foreign peer ID to be forbidden, got status %d", res.StatusCode)
+	}
+}
+
+func TestSubscribePreCheck(t *testing.T) {
+	tr, cleanup := newTestRunner(t)
+	defer cleanup()
+
+	rem := tr.NodeARemote(t, func(o *Options) {
+		o.SubscribePreCheck = func(ctx context.Context, pid profile.ID, ref reporef.DatasetRef) error {
+			return fmt.Errorf("no subscriptions for you")
+		}
+	})
+	server := tr.RemoteTestServer(rem)
+	defer server.Close()
+
+	cli := tr.NodeBClient(t)
+	err := cli.Subscribe(tr.Ctx, dsref.Ref{Username: "A", Name: "world_bank_population"}, server.URL)
+	if err == nil || !strings.Contains(err.Error(), "no subscriptions for you") {
+		t.Errorf("expected pre-check error, got: %v", err)
+	}
+}
+
+func TestSubscribersStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "remote_subscribers")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	s, err := NewSubscribers(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, sub := range []*Subscriber{
+		{ProfileID: "a", Ref: "me/one"},
+		{ProfileID: "a", Ref: "me/two"},
+		{ProfileID: "b", Ref: "me/one"},
+	} {
+		if err := s.Add(sub); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.Remove("a", "me/two"); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err = NewSubscribers(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := s.ForProfile("a"); len(got) != 1 || got[0].Ref != "me/one" {
+		t.Errorf("ForProfile mismatch. got: %v", got)
+	}
+	if got := s.ForRef("me/one"); len(got) != 2 {
+		t.Errorf("expected two subscribers to me/one, got: %v", got)
+	}
+}
+
+func TestReadNotifications(t *testing.T) {
+	stream := `: keep-alive
+
+event: notification
+data: {"username":"me","name":"one","path":"/ipfs/QmA"}
+
+event: other
+data: {"username":"me","name":"ignored"}
+
+event: notification
+data: {"username":"me","name":"two"}
+
+`
+	refs := make(chan dsref.Ref, 10)
+	if err := readNotifications(context.Background(), strings.NewReader(stream), refs); err != nil {
+		t.Fatal(err)
+	}
+	close(refs)
+
+	got := []string{}
+	for ref := range refs {
+		got = append(got, ref.String())
+	}
+	expect := []string{"me/one@/ipfs/QmA", "me/two"}
+	if strings.Join(got, ",") != strings.Join(expect, ",") {
+		t.Errorf("notifications mismatch. expected: %v, got: %v", expect, got)
+	}
+}
@@ -0,0 +1,279 @@
+package remote
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/qri-io/qri/config"
+)
+
+const usageFilename = "usage.json"
+
+// ErrQuotaExceeded is returned when a push would take a profile over its
+// storage quota
+var ErrQuotaExceeded = fmt.Errorf("storage quota exceeded")
+
+// StoredVersion is a dataset version a remote holds on behalf of the profile
+// that pushed it
+type StoredVersion struct {
+	ProfileID string    `json:"profileID"`
+	Ref       string    `json:"ref"`
+	Path      string    `json:"path"`
+	Size      uint64    `json:"size"`
+	Pushed    time.Time `json:"pushed"`
+	// Blocks maps the CIDs of blocks in the version to their sizes. Blocks
+	// shared between versions are only counted once toward usage
+	Blocks map[string]uint64 `json:"blocks,omitempty"`
+}
+
+// blockSet tracks blocks already counted toward usage
+type blockSet map[string]bool
+
+// add counts a version's blocks, returning the bytes not already in the set.
+// versions recorded without blocks count their full size
+func (s blockSet) add(v *StoredVersion) (size uint64) {
+	if len(v.Blocks) == 0 {
+		return v.Size
+	}
+	for id, sz := range v.Blocks {
+		if !s[id] {
+			s[id] = true
+			size += sz
+		}
+	}
+	return size
+}
+
+// ProfileUsage summarizes the storage a remote holds for a single profile
+type ProfileUsage struct {
+	ProfileID string `json:"profileID"`
+	Datasets  int    `json:"datasets"`
+	Versions  int    `json:"versions"`
+	Bytes     uint64 `json:"bytes"`
+	// Quota is the maximum number of bytes the profile may store, zero for no
+	// limit
+	Quota int64 `json:"quota"`
+}
+
+// Usage tracks versions stored by a remote, persisting them to a directory
+type Usage struct {
+	dir      string
+	lk       sync.Mutex
+	versions map[string]*StoredVersion
+}
+
+// NewUsage creates a usage store backed by a directory. An empty dir keeps
+// usage in memory only
+func NewUsage(dir string) (*Usage, error) {
+	u := &Usage{dir: dir, versions: map[string]*StoredVersion{}}
+	if dir == "" {
+		return u, nil
+	}
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return nil, err
+	}
+
+	list, err := ReadStoredVersions(dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range list {
+		u.versions[v.Path] = v
+	}
+	return u, nil
+}
+
+// ReadStoredVersions loads versions persisted to dir by a usage store,
+// allowing usage to be inspected without running the remote
+func ReadStoredVersions(dir string) ([]*StoredVersion, error) {
+	list := []*StoredVersion{}
+	if err := readJSONFile(dir, usageFilename, &list); err != nil {
+		return nil, fmt.Errorf("reading remote usage: %w", err)
+	}
+	return list, nil
+}
+
+// Add records a stored version, replacing any version with the same path
+func (u *Usage) Add(v *StoredVersion) error {
+	if u == nil {
+		return nil
+	}
+	u.lk.Lock()
+	defer u.lk.Unlock()
+	u.versions[v.Path] = v
+	return u.write()
+}
+
+// Remove drops stored versions by path
+func (u *Usage) Remove(paths ...string) error {
+	if u == nil {
+		return nil
+	}
+	u.lk.Lock()
+	defer u.lk.Unlock()
+	for _, p := range paths {
+		delete(u.versions, p)
+	}
+	return u.write()
+}
+
+// RemoveRef drops all stored versions of a dataset
+func (u *Usage) RemoveRef(alias string) error {
+	if u == nil {
+		return nil
+	}
+	u.lk.Lock()
+	defer u.lk.Unlock()
+	for p, v := range u.versions {
+		if v.Ref == alias {
+			delete(u.versions, p)
+		}
+	}
+	return u.write()
+}
+
+// Has returns true if a version with path is stored
+func (u *Usage) Has(path string) bool {
+	if u == nil {
+		return false
+	}
+	u.lk.Lock()
+	defer u.lk.Unlock()
+	_, ok := u.versions[path]
+	return ok
+}
+
+// ProfileBytes sums the size of all blocks stored for a profile
+func (u *Usage) ProfileBytes(profileID string) uint64 {
+	total, _ := u.profileBlocks(profileID)
+	return total
+}
+
+// AddedBytes returns the bytes storing v would add to a profile's usage, and
+// the bytes the profile already uses
+func (u *Usage) AddedBytes(profileID string, v *StoredVersion) (added, used uint64) {
+	used, blocks := u.profileBlocks(profileID)
+	return blocks.add(v), used
+}
+
+func (u *Usage) profileBlocks(profileID string) (uint64, blockSet) {
+	blocks := blockSet{}
+	if u == nil {
+		return 0, blocks
+	}
+	u.lk.Lock()
+	defer u.lk.Unlock()
+	var total uint64
+	for _, v := range u.list() {
+		if v.ProfileID == profileID {
+			total += blocks.add(v)
+		}
+	}
+	return total, blocks
+}
+
+// Versions lists all stored versions, sorted by dataset & push time
+func (u *Usage) Versions() []*StoredVersion {
+	if u == nil {
+		return []*StoredVersion{}
+	}
+	u.lk.Lock()
+	defer u.lk.Unlock()
+	return u.list()
+}
+
+func (u *Usage) list() []*StoredVersion {
+	list := make([]*StoredVersion, 0, len(u.versions))
+	for _, v := range u.versions {
+		cpy := *v
+		if v.Blocks != nil {
+			cpy.Blocks = make(map[string]uint64, len(v.Blocks))
+			for id, sz := range v.Blocks {
+				cpy.Blocks[id] = sz
+			}
+		}
+		list = append(list, &cpy)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Ref != list[j].Ref {
+			return list[i].Ref < list[j].Ref
+		}
+		return list[i].Pushed.Before(list[j].Pushed)
+	})
+	return list
+}
+
+func (u *Usage) write() error {
+	if u.dir == "" {
+		return nil
+	}
+	return writeJSONFile(u.dir, usageFilename, u.list())
+}
+
+// UsageReport summarizes stored versions by profile, sorted by profile ID.
+// Quotas are read from cfg, which may be nil
+func UsageReport(versions []*StoredVersion, cfg *config.Remote) []ProfileUsage {
+	byProfile := map[string]*ProfileUsage{}
+	datasets := map[string]map[string]bool{}
+	blocks := map[string]blockSet{}
+	for _, v := range versions {
+		pu, ok := byProfile[v.ProfileID]
+		if !ok {
+			pu = &ProfileUsage{ProfileID: v.ProfileID}
+			if cfg != nil {
+				pu.Quota = cfg.Quota(v.ProfileID)
+			}
+			byProfile[v.ProfileID] = pu
+			datasets[v.ProfileID] = map[string]bool{}
+			blocks[v.ProfileID] = blockSet{}
+		}
+		pu.Versions++
+		pu.Bytes += blocks[v.ProfileID].add(v)
+		datasets[v.ProfileID][v.Ref] = true
+	}
+
+	res := make([]ProfileUsage, 0, len(byProfile))
+	for id, pu := range byProfile {
+		pu.Datasets = len(datasets[id])
+		res = append(res, *pu)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].ProfileID < res[j].ProfileID })
+	return res
+}
+
+// expiredVersions lists versions that fall outside a retention policy as of
+// now. heads maps dataset aliases to head paths, which are always kept
+func expiredVersions(versions []*StoredVersion, policy *config.Retention, heads map[string]string, now time.Time) []*StoredVersion {
+	if !policy.Enabled() {
+		return nil
+	}
+
+	byRef := map[string][]*StoredVersion{}
+	for _, v := range versions {
+		byRef[v.Ref] = append(byRef[v.Ref], v)
+	}
+
+	cutoff := now.Add(-time.Duration(policy.KeepDays) * 24 * time.Hour)
+	var expired []*StoredVersion
+	for ref, vs := range byRef {
+		// newest first
+		sort.Slice(vs, func(i, j int) bool { return vs[i].Pushed.After(vs[j].Pushed) })
+		for i, v := range vs {
+			if v.Path == heads[ref] {
+				continue
+			}
+			if policy.KeepVersions > 0 && i < policy.KeepVersions {
+				continue
+			}
+			if policy.KeepDays > 0 && v.Pushed.After(cutoff) {
+				continue
+			}
+			expired = append(expired, v)
+		}
+	}
+	sort.Slice(expired, func(i, j int) bool { return expired[i].Path < expired[j].Path })
+	return expired
+}
@@ -0,0 +1,143 @@
+package remote
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/qri-io/qri/config"
+)
+
+func TestUsage(t *testing.T) {
+	dir, err := ioutil.TempDir("", "remote_usage")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	u, err := NewUsage(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pushed := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	versions := []*StoredVersion{
+		{ProfileID: "QmA", Ref: "a/one", Path: "/ipfs/QmOne1", Size: 10, Pushed: pushed},
+		{ProfileID: "QmA", Ref: "a/one", Path: "/ipfs/QmOne2", Size: 20, Pushed: pushed.Add(time.Hour)},
+		{ProfileID: "QmA", Ref: "a/two", Path: "/ipfs/QmTwo1", Size: 5, Pushed: pushed},
+		{ProfileID: "QmB", Ref: "b/one", Path: "/ipfs/QmB1", Size: 100, Pushed: pushed},
+	}
+	for _, v := range versions {
+		if err := u.Add(v); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if got := u.ProfileBytes("QmA"); got != 35 {
+		t.Errorf("profile bytes mismatch. expected: 35, got: %d", got)
+	}
+	if !u.Has("/ipfs/QmB1") {
+		t.Error("expected stored version to be found")
+	}
+
+	// usage persists across stores
+	u, err = NewUsage(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(versions, u.Versions()); diff != "" {
+		t.Errorf("persisted versions mismatch (-want +got):\n%s", diff)
+	}
+
+	cfg := &config.Remote{ProfileQuota: 1000, Quotas: map[string]int64{"QmB": -1}}
+	expect := []ProfileUsage{
+		{ProfileID: "QmA", Datasets: 2, Versions: 3, Bytes: 35, Quota: 1000},
+		{ProfileID: "QmB", Datasets: 1, Versions: 1, Bytes: 100, Quota: 0},
+	}
+	if diff := cmp.Diff(expect, UsageReport(u.Versions(), cfg)); diff != "" {
+		t.Errorf("usage report mismatch (-want +got):\n%s", diff)
+	}
+
+	if err := u.RemoveRef("a/one"); err != nil {
+		t.Fatal(err)
+	}
+	if err := u.Remove("/ipfs/QmB1"); err != nil {
+		t.Fatal(err)
+	}
+	if got := len(u.Versions()); got != 1 {
+		t.Errorf("expected 1 version after removes, got %d", got)
+	}
+}
+
+func TestUsageSharedBlocks(t *testing.T) {
+	u, err := NewUsage("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	versions := []*StoredVersion{
+		{ProfileID: "QmA", Ref: "a/one", Path: "/ipfs/QmRoot1", Size: 30, Blocks: map[string]uint64{"QmRoot1": 10, "QmBody": 20}},
+		{ProfileID: "QmA", Ref: "a/one", Path: "/ipfs/QmRoot2", Size: 35, Blocks: map[string]uint64{"QmRoot2": 15, "QmBody": 20}},
+	}
+	for _, v := range versions {
+		if err := u.Add(v); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if got := u.ProfileBytes("QmA"); got != 45 {
+		t.Errorf("profile bytes mismatch. expected: 45, got: %d", got)
+	}
+	if got := UsageReport(u.Versions(), nil)[0].Bytes; got != 45 {
+		t.Errorf("usage report bytes mismatch. expected: 45, got: %d", got)
+	}
+
+	added, used := u.AddedBytes("QmA", &StoredVersion{Size: 25, Blocks: map[string]uint64{"QmRoot3": 5, "QmBody": 20}})
+	if added != 5 || used != 45 {
+		t.Errorf("added bytes mismatch. expected: 5 added, 45 used. got: %d added, %d used", added, used)
+	}
+	if added, _ := u.AddedBytes("QmB", versions[0]); added != 30 {
+		t.Errorf("expected blocks held for another profile to count, got: %d", added)
+	}
+}
+
+func TestExpiredVersions(t *testing.T) {
+	now := time.Date(2020, 2, 1, 0, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+	versions := []*StoredVersion{
+		{Ref: "a/ds", Path: "/ipfs/Qm1", Pushed: now.Add(-30 * day)},
+		{Ref: "a/ds", Path: "/ipfs/Qm2", Pushed: now.Add(-20 * day)},
+		{Ref: "a/ds", Path: "/ipfs/Qm3", Pushed: now.Add(-10 * day)},
+		{Ref: "a/ds", Path: "/ipfs/Qm4", Pushed: now.Add(-1 * day)},
+		{Ref: "b/ds", Path: "/ipfs/QmB1", Pushed: now.Add(-40 * day)},
+	}
+	heads := map[string]string{"a/ds": "/ipfs/Qm4", "b/ds": "/ipfs/QmB1"}
+
+	paths := func(vs []*StoredVersion) []string {
+		res := []string{}
+		for _, v := range vs {
+			res = append(res, v.Path)
+		}
+		return res
+	}
+
+	cases := []struct {
+		description string
+		policy      *config.Retention
+		expect      []string
+	}{
+		{"no policy", nil, []string{}},
+		{"keep last two", &config.Retention{KeepVersions: 2}, []string{"/ipfs/Qm1", "/ipfs/Qm2"}},
+		{"keep last 15 days", &config.Retention{KeepDays: 15}, []string{"/ipfs/Qm1", "/ipfs/Qm2"}},
+		{"keep last version or 25 days", &config.Retention{KeepVersions: 1, KeepDays: 25}, []string{"/ipfs/Qm1"}},
+	}
+
+	for _, c := range cases {
+		got := paths(expiredVersions(versions, c.policy, heads, now))
+		if diff := cmp.Diff(c.expect, got); diff != "" {
+			t.Errorf("case %q mismatch (-want +got):\n%s", c.description, diff)
+		}
+	}
+}
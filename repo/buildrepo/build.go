@@ -22,6 +22,7 @@ import (
 	"github.com/qri-io/qri/repo"
 	"github.com/qri-io/qri/repo/fs"
 	"github.com/qri-io/qri/repo/profile"
+	"github.com/qri-io/qri/repo/storage"
 )
 
 var (
@@ -142,6 +143,14 @@ func NewCAFSStore(ctx context.Context, cfg *config.Config) (store cafs.Filestore
 		return ipfs_http.New(urlStr)
 	case "map":
 		return cafs.NewMapstore(), nil
+	case "fs":
+		return storage.NewDirFilestore(ctx, cfg.Store.Path, cfg.P2P)
+	case "s3":
+		opts, err := storage.S3OptionsFromMap(cfg.Store.Options)
+		if err != nil {
+			return nil, err
+		}
+		return storage.NewS3Filestore(ctx, opts, cfg.P2P)
 	default:
 		return nil, fmt.Errorf("unknown store type: %s", cfg.Store.Type)
 	}
@@ -0,0 +1,167 @@
+package storage
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	ds "github.com/ipfs/go-datastore"
+	dsq "github.com/ipfs/go-datastore/query"
+	goprocess "github.com/jbenet/goprocess"
+)
+
+// DirDatastore stores each value as a file in a plain directory tree, where
+// the file path is the datastore key. Blocks an IPFS node keeps in a
+// DirDatastore live at DIR/blocks/KEY
+type DirDatastore struct {
+	path string
+}
+
+var _ ds.Batching = (*DirDatastore)(nil)
+
+// NewDirDatastore creates a datastore rooted at path, creating the directory
+// if it doesn't exist
+func NewDirDatastore(path string) (*DirDatastore, error) {
+	if path == "" {
+		return nil, ErrNoPath
+	}
+	if err := os.MkdirAll(path, os.ModePerm); err != nil {
+		return nil, err
+	}
+	return &DirDatastore{path: path}, nil
+}
+
+func (d *DirDatastore) filepath(key ds.Key) string {
+	return filepath.Join(d.path, filepath.FromSlash(key.String()))
+}
+
+// Put stores a value, replacing any existing value for key
+func (d *DirDatastore) Put(key ds.Key, value []byte) error {
+	path := d.filepath(key)
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return err
+	}
+
+	// write to a temp file & rename so readers never see partial values
+	tmp, err := ioutil.TempFile(filepath.Dir(path), ".tmp-")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(value); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Get reads the value for key
+func (d *DirDatastore) Get(key ds.Key) ([]byte, error) {
+	data, err := ioutil.ReadFile(d.filepath(key))
+	if os.IsNotExist(err) {
+		return nil, ds.ErrNotFound
+	}
+	return data, err
+}
+
+// Has returns true if a value exists for key
+func (d *DirDatastore) Has(key ds.Key) (bool, error) {
+	fi, err := os.Stat(d.filepath(key))
+	if os.IsNotExist(err) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	return !fi.IsDir(), nil
+}
+
+// GetSize returns the size of the value for key in bytes
+func (d *DirDatastore) GetSize(key ds.Key) (int, error) {
+	fi, err := os.Stat(d.filepath(key))
+	if os.IsNotExist(err) {
+		return -1, ds.ErrNotFound
+	} else if err != nil {
+		return -1, err
+	}
+	return int(fi.Size()), nil
+}
+
+// Delete removes the value for key. Deleting a missing key is not an error
+func (d *DirDatastore) Delete(key ds.Key) error {
+	err := os.Remove(d.filepath(key))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+// Query walks the directory tree below the query prefix, sending entries as
+// they're found. Values are read from disk as each entry is sent, so results
+// never hold more than one value in memory
+func (d *DirDatastore) Query(q dsq.Query) (dsq.Results, error) {
+	root := d.path
+	if q.Prefix != "" {
+		root = d.filepath(ds.NewKey(q.Prefix))
+	}
+
+	qr := dsq.ResultsWithProcess(q, func(p goprocess.Process, out chan<- dsq.Result) {
+		err := filepath.Walk(root, func(path string, fi os.FileInfo, err error) error {
+			if err != nil {
+				if os.IsNotExist(err) {
+					return nil
+				}
+				return err
+			}
+			if fi.IsDir() || strings.HasPrefix(fi.Name(), ".tmp-") {
+				return nil
+			}
+			rel, err := filepath.Rel(d.path, path)
+			if err != nil {
+				return err
+			}
+			e := dsq.Entry{Key: "/" + filepath.ToSlash(rel)}
+			if !q.KeysOnly {
+				if e.Value, err = ioutil.ReadFile(path); err != nil {
+					// values deleted mid-walk are skipped
+					if os.IsNotExist(err) {
+						return nil
+					}
+					return err
+				}
+			}
+			select {
+			case out <- dsq.Result{Entry: e}:
+				return nil
+			case <-p.Closing():
+				return errQueryClosed
+			}
+		})
+		if err != nil && err != errQueryClosed {
+			select {
+			case out <- dsq.Result{Error: err}:
+			case <-p.Closing():
+			}
+		}
+	})
+
+	return dsq.NaiveQueryApply(q, qr), nil
+}
+
+// errQueryClosed stops a directory walk when query results are closed early
+var errQueryClosed = errors.New("query closed")
+
+// Batch returns a batch that applies writes one at a time
+func (d *DirDatastore) Batch() (ds.Batch, error) {
+	return ds.NewBasicBatch(d), nil
+}
+
+// Close is a no-op
+func (d *DirDatastore) Close() error {
+	return nil
+}
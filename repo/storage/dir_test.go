@@ -0,0 +1,146 @@
+package storage
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"testing"
+
+	ds "github.com/ipfs/go-datastore"
+	dsq "github.com/ipfs/go-datastore/query"
+)
+
+func TestDirDatastore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "storage_dir_datastore")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	if _, err := NewDirDatastore(""); err != ErrNoPath {
+		t.Errorf("expected missing path to error with ErrNoPath, got: %v", err)
+	}
+
+	d, err := NewDirDatastore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testDatastore(t, d)
+}
+
+func TestDirDatastoreQuery(t *testing.T) {
+	dir, err := ioutil.TempDir("", "storage_dir_datastore_query")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	d, err := NewDirDatastore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 10; i++ {
+		if err := d.Put(ds.NewKey(fmt.Sprintf("/blocks/%d", i)), []byte{byte(i)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	res, err := d.Query(dsq.Query{Prefix: "/blocks", Limit: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries, err := res.Rest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 3 {
+		t.Errorf("expected 3 entries, got: %d", len(entries))
+	}
+	for _, e := range entries {
+		if want := e.Key[len("/blocks/"):]; len(e.Value) != 1 || fmt.Sprintf("%d", e.Value[0]) != want {
+			t.Errorf("value mismatch for key %s. got: %v", e.Key, e.Value)
+		}
+	}
+
+	// closing results before they're drained must stop the walk
+	res, err = d.Query(dsq.Query{Prefix: "/blocks"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r, ok := res.NextSync(); !ok || r.Error != nil {
+		t.Fatalf("expected a result. got: %v", r.Error)
+	}
+	if err := res.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err = d.Query(dsq.Query{Prefix: "/missing"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entries, err := res.Rest(); err != nil || len(entries) != 0 {
+		t.Errorf("expected missing prefix to return no entries. got: %d, err: %v", len(entries), err)
+	}
+}
+
+// testDatastore runs a datastore implementation through basic operations
+func testDatastore(t *testing.T, d ds.Batching) {
+	puts := map[string]string{
+		"/blocks/A":   "a",
+		"/blocks/B":   "bb",
+		"/local/pins": "pins",
+	}
+	for key, val := range puts {
+		if err := d.Put(ds.NewKey(key), []byte(val)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := d.Get(ds.NewKey("/blocks/B"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "bb" {
+		t.Errorf("value mismatch. expected: %q, got: %q", "bb", got)
+	}
+	if size, err := d.GetSize(ds.NewKey("/blocks/B")); err != nil || size != 2 {
+		t.Errorf("expected size 2, got: %d, err: %v", size, err)
+	}
+	if has, err := d.Has(ds.NewKey("/blocks/A")); err != nil || !has {
+		t.Errorf("expected store to have /blocks/A. has: %t, err: %v", has, err)
+	}
+	if _, err := d.Get(ds.NewKey("/blocks/missing")); err != ds.ErrNotFound {
+		t.Errorf("expected missing key to return ErrNotFound, got: %v", err)
+	}
+	if has, err := d.Has(ds.NewKey("/blocks/missing")); err != nil || has {
+		t.Errorf("expected missing key to not exist. has: %t, err: %v", has, err)
+	}
+
+	res, err := d.Query(dsq.Query{Prefix: "/blocks", KeysOnly: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries, err := res.Rest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := []string{}
+	for _, e := range entries {
+		keys = append(keys, e.Key)
+	}
+	sort.Strings(keys)
+	if len(keys) != 2 || keys[0] != "/blocks/A" || keys[1] != "/blocks/B" {
+		t.Errorf("query mismatch. got: %v", keys)
+	}
+
+	if err := d.Delete(ds.NewKey("/blocks/A")); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Delete(ds.NewKey("/blocks/A")); err != nil {
+		t.Errorf("expected deleting a missing key to succeed, got: %s", err)
+	}
+	if has, _ := d.Has(ds.NewKey("/blocks/A")); has {
+		t.Error("expected deleted key to be gone")
+	}
+}
@@ -0,0 +1,392 @@
+package storage
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	ds "github.com/ipfs/go-datastore"
+	dsq "github.com/ipfs/go-datastore/query"
+)
+
+// S3Options configures a connection to an S3-compatible object store
+type S3Options struct {
+	// Endpoint is the base URL of the object store, eg: https://s3.amazonaws.com
+	// or http://localhost:9000 for a local MinIO server
+	Endpoint string
+	// Bucket objects are stored in. The bucket must already exist
+	Bucket string
+	// Region used to sign requests. Defaults to "us-east-1"
+	Region string
+	// Prefix is prepended to all object names, allowing many stores to share
+	// a bucket
+	Prefix string
+	// AccessKeyID & SecretAccessKey are credentials for signing requests,
+	// defaulting to the AWS_ACCESS_KEY_ID & AWS_SECRET_ACCESS_KEY environment
+	// variables
+	AccessKeyID     string
+	SecretAccessKey string
+}
+
+// S3OptionsFromMap reads S3Options from a store config options map
+func S3OptionsFromMap(opts map[string]interface{}) (S3Options, error) {
+	o := S3Options{}
+	fields := map[string]*string{
+		"endpoint":        &o.Endpoint,
+		"bucket":          &o.Bucket,
+		"region":          &o.Region,
+		"prefix":          &o.Prefix,
+		"accessKeyID":     &o.AccessKeyID,
+		"secretAccessKey": &o.SecretAccessKey,
+	}
+	for key, val := range opts {
+		field, ok := fields[key]
+		if !ok {
+			continue
+		}
+		s, ok := val.(string)
+		if !ok {
+			return o, fmt.Errorf("s3 store option %q must be a string", key)
+		}
+		*field = s
+	}
+	return o, nil
+}
+
+// s3RequestTimeout bounds the time a single object store request may take
+var s3RequestTimeout = time.Minute * 2
+
+// S3Datastore stores values as objects in an S3-compatible object store
+type S3Datastore struct {
+	opts   S3Options
+	client *http.Client
+	now    func() time.Time
+}
+
+var _ ds.Batching = (*S3Datastore)(nil)
+
+// NewS3Datastore creates a datastore backed by an S3 bucket
+func NewS3Datastore(opts S3Options) (*S3Datastore, error) {
+	if opts.Endpoint == "" {
+		return nil, fmt.Errorf("s3 store requires an 'endpoint' option")
+	}
+	if opts.Bucket == "" {
+		return nil, fmt.Errorf("s3 store requires a 'bucket' option")
+	}
+	if opts.Region == "" {
+		opts.Region = "us-east-1"
+	}
+	if opts.AccessKeyID == "" {
+		opts.AccessKeyID = os.Getenv("AWS_ACCESS_KEY_ID")
+	}
+	if opts.SecretAccessKey == "" {
+		opts.SecretAccessKey = os.Getenv("AWS_SECRET_ACCESS_KEY")
+	}
+	opts.Endpoint = strings.TrimSuffix(opts.Endpoint, "/")
+	opts.Prefix = strings.Trim(opts.Prefix, "/")
+
+	return &S3Datastore{
+		opts:   opts,
+		client: &http.Client{Timeout: s3RequestTimeout},
+		now:    time.Now,
+	}, nil
+}
+
+// objectName converts a datastore key to an object name
+func (s *S3Datastore) objectName(key ds.Key) string {
+	name := strings.TrimPrefix(key.String(), "/")
+	if s.opts.Prefix != "" {
+		return s.opts.Prefix + "/" + name
+	}
+	return name
+}
+
+// key converts an object name to a datastore key
+func (s *S3Datastore) key(name string) string {
+	if s.opts.Prefix != "" {
+		name = strings.TrimPrefix(name, s.opts.Prefix+"/")
+	}
+	return "/" + name
+}
+
+// Put stores a value, replacing any existing value for key
+func (s *S3Datastore) Put(key ds.Key, value []byte) error {
+	res, err := s.do(http.MethodPut, s.objectName(key), nil, value)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	return checkS3Response(res)
+}
+
+// Get reads the value for key
+func (s *S3Datastore) Get(key ds.Key) ([]byte, error) {
+	res, err := s.do(http.MethodGet, s.objectName(key), nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode == http.StatusNotFound {
+		return nil, ds.ErrNotFound
+	}
+	if err := checkS3Response(res); err != nil {
+		return nil, err
+	}
+	return ioutil.ReadAll(res.Body)
+}
+
+// Has returns true if a value exists for key
+func (s *S3Datastore) Has(key ds.Key) (bool, error) {
+	_, err := s.GetSize(key)
+	if err == ds.ErrNotFound {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// GetSize returns the size of the value for key in bytes
+func (s *S3Datastore) GetSize(key ds.Key) (int, error) {
+	res, err := s.do(http.MethodHead, s.objectName(key), nil, nil)
+	if err != nil {
+		return -1, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode == http.StatusNotFound {
+		return -1, ds.ErrNotFound
+	}
+	if err := checkS3Response(res); err != nil {
+		return -1, err
+	}
+	return int(res.ContentLength), nil
+}
+
+// Delete removes the value for key. Deleting a missing key is not an error
+func (s *S3Datastore) Delete(key ds.Key) error {
+	res, err := s.do(http.MethodDelete, s.objectName(key), nil, nil)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode == http.StatusNotFound {
+		return nil
+	}
+	return checkS3Response(res)
+}
+
+// listBucketResult is the response body of a ListObjectsV2 request
+type listBucketResult struct {
+	Contents []struct {
+		Key string
+	}
+	IsTruncated           bool
+	NextContinuationToken string
+}
+
+// Query lists objects below the query prefix
+func (s *S3Datastore) Query(q dsq.Query) (dsq.Results, error) {
+	prefix := s.objectName(ds.NewKey(q.Prefix))
+	if q.Prefix == "" || q.Prefix == "/" {
+		prefix = s.opts.Prefix
+	}
+	if prefix != "" && !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+
+	entries := []dsq.Entry{}
+	token := ""
+	for {
+		params := url.Values{}
+		params.Set("list-type", "2")
+		if prefix != "" {
+			params.Set("prefix", prefix)
+		}
+		if token != "" {
+			params.Set("continuation-token", token)
+		}
+
+		res, err := s.do(http.MethodGet, "", params, nil)
+		if err != nil {
+			return nil, err
+		}
+		list := listBucketResult{}
+		err = checkS3Response(res)
+		if err == nil {
+			err = xml.NewDecoder(res.Body).Decode(&list)
+		}
+		res.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		for _, obj := range list.Contents {
+			e := dsq.Entry{Key: s.key(obj.Key)}
+			if !q.KeysOnly {
+				if e.Value, err = s.Get(ds.NewKey(e.Key)); err != nil {
+					return nil, err
+				}
+			}
+			entries = append(entries, e)
+		}
+
+		if !list.IsTruncated || list.NextContinuationToken == "" {
+			break
+		}
+		token = list.NextContinuationToken
+	}
+
+	return dsq.NaiveQueryApply(q, dsq.ResultsWithEntries(q, entries)), nil
+}
+
+// Batch returns a batch that applies writes one at a time
+func (s *S3Datastore) Batch() (ds.Batch, error) {
+	return ds.NewBasicBatch(s), nil
+}
+
+// Close is a no-op
+func (s *S3Datastore) Close() error {
+	return nil
+}
+
+// do performs a signed request against the bucket. object names the object
+// to operate on, an empty object addresses the bucket itself
+func (s *S3Datastore) do(method, object string, params url.Values, body []byte) (*http.Response, error) {
+	path := "/" + s.opts.Bucket
+	if object != "" {
+		path += "/" + object
+	}
+	u := s.opts.Endpoint + awsURIEncode(path, false)
+	if len(params) > 0 {
+		u += "?" + canonicalQuery(params)
+	}
+
+	req, err := http.NewRequest(method, u, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.ContentLength = int64(len(body))
+	s.sign(req, path, params, body)
+	return s.client.Do(req)
+}
+
+// sign adds an AWS Signature Version 4 authorization header to req
+func (s *S3Datastore) sign(req *http.Request, path string, params url.Values, body []byte) {
+	now := s.now().UTC()
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	payloadHash := sha256Hex(body)
+
+	req.Header.Set("x-amz-date", amzDate)
+	req.Header.Set("x-amz-content-sha256", payloadHash)
+	if s.opts.AccessKeyID == "" {
+		// anonymous requests
+		return
+	}
+
+	headers := map[string]string{
+		"host":                 req.URL.Host,
+		"x-amz-content-sha256": payloadHash,
+		"x-amz-date":           amzDate,
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	canonicalHeaders := &strings.Builder{}
+	for _, name := range names {
+		fmt.Fprintf(canonicalHeaders, "%s:%s\n", name, headers[name])
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		awsURIEncode(path, false),
+		canonicalQuery(params),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := fmt.Sprintf("%s/%s/s3/aws4_request", date, s.opts.Region)
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		sha256Hex([]byte(canonicalRequest)),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+s.opts.SecretAccessKey), date)
+	key = hmacSHA256(key, s.opts.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.opts.AccessKeyID, scope, signedHeaders, signature))
+}
+
+// checkS3Response returns an error for non-2XX responses
+func checkS3Response(res *http.Response) error {
+	if res.StatusCode >= 200 && res.StatusCode < 300 {
+		return nil
+	}
+	msg, _ := ioutil.ReadAll(io.LimitReader(res.Body, 1024))
+	return fmt.Errorf("s3 error %d: %s", res.StatusCode, strings.TrimSpace(string(msg)))
+}
+
+// canonicalQuery encodes params sorted by key, as required for signing
+func canonicalQuery(params url.Values) string {
+	keys := make([]string, 0, len(params))
+	for k := range params {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	parts := []string{}
+	for _, k := range keys {
+		for _, v := range params[k] {
+			parts = append(parts, awsURIEncode(k, true)+"="+awsURIEncode(v, true))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// awsURIEncode percent-encodes all bytes except unreserved characters.
+// slashes are only encoded when encodeSlash is true
+func awsURIEncode(s string, encodeSlash bool) string {
+	b := &strings.Builder{}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c >= 'A' && c <= 'Z', c >= 'a' && c <= 'z', c >= '0' && c <= '9',
+			c == '-', c == '_', c == '.', c == '~':
+			b.WriteByte(c)
+		case c == '/' && !encodeSlash:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
@@ -0,0 +1,155 @@
+package storage
+
+import (
+	"encoding/xml"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestS3Datastore(t *testing.T) {
+	s := newFakeS3Server(t, "qri")
+	defer s.Close()
+
+	if _, err := NewS3Datastore(S3Options{Bucket: "qri"}); err == nil {
+		t.Error("expected missing endpoint to error")
+	}
+
+	d, err := NewS3Datastore(S3Options{
+		Endpoint:        s.URL,
+		Bucket:          "qri",
+		Prefix:          "stores/test",
+		AccessKeyID:     "AKIDEXAMPLE",
+		SecretAccessKey: "secret",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	testDatastore(t, d)
+
+	if _, ok := s.objects["stores/test/blocks/B"]; !ok {
+		t.Errorf("expected objects to be named with prefix, got: %v", s.names())
+	}
+}
+
+func TestS3OptionsFromMap(t *testing.T) {
+	opts, err := S3OptionsFromMap(map[string]interface{}{
+		"endpoint": "http://localhost:9000",
+		"bucket":   "qri",
+		"api":      true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if opts.Endpoint != "http://localhost:9000" || opts.Bucket != "qri" {
+		t.Errorf("options mismatch. got: %v", opts)
+	}
+
+	if _, err := S3OptionsFromMap(map[string]interface{}{"bucket": 5}); err == nil {
+		t.Error("expected non-string option to error")
+	}
+}
+
+// fakeS3Server implements the subset of the S3 API S3Datastore uses, storing
+// objects for a single bucket in memory
+type fakeS3Server struct {
+	*httptest.Server
+	bucket  string
+	lk      sync.Mutex
+	objects map[string][]byte
+}
+
+func newFakeS3Server(t *testing.T, bucket string) *fakeS3Server {
+	s := &fakeS3Server{bucket: bucket, objects: map[string][]byte{}}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if auth := r.Header.Get("Authorization"); !strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=") {
+			t.Errorf("expected signed request, got authorization: %q", auth)
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		if r.Header.Get("x-amz-content-sha256") == "" {
+			t.Error("expected request to include a payload hash")
+		}
+
+		path := strings.TrimPrefix(r.URL.Path, "/")
+		if path == s.bucket && r.Method == http.MethodGet {
+			s.list(w, r)
+			return
+		}
+		if !strings.HasPrefix(path, s.bucket+"/") {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		name := strings.TrimPrefix(path, s.bucket+"/")
+
+		s.lk.Lock()
+		defer s.lk.Unlock()
+		switch r.Method {
+		case http.MethodPut:
+			data, _ := ioutil.ReadAll(r.Body)
+			s.objects[name] = data
+		case http.MethodGet, http.MethodHead:
+			data, ok := s.objects[name]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+			if r.Method == http.MethodGet {
+				w.Write(data)
+			}
+		case http.MethodDelete:
+			delete(s.objects, name)
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	return s
+}
+
+func (s *fakeS3Server) names() []string {
+	s.lk.Lock()
+	defer s.lk.Unlock()
+	names := []string{}
+	for name := range s.objects {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// list responds to ListObjectsV2 requests, returning a single object per page
+// to exercise pagination
+func (s *fakeS3Server) list(w http.ResponseWriter, r *http.Request) {
+	prefix := r.URL.Query().Get("prefix")
+	after := r.URL.Query().Get("continuation-token")
+
+	matches := []string{}
+	for _, name := range s.names() {
+		if strings.HasPrefix(name, prefix) && name > after {
+			matches = append(matches, name)
+		}
+	}
+
+	type content struct {
+		Key string
+	}
+	res := struct {
+		XMLName               xml.Name `xml:"ListBucketResult"`
+		Contents              []content
+		IsTruncated           bool
+		NextContinuationToken string `xml:",omitempty"`
+	}{}
+	if len(matches) > 0 {
+		res.Contents = []content{{Key: matches[0]}}
+	}
+	if len(matches) > 1 {
+		res.IsTruncated = true
+		res.NextContinuationToken = matches[0]
+	}
+	xml.NewEncoder(w).Encode(res)
+}
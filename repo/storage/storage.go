@@ -0,0 +1,187 @@
+// Package storage provides content-addressed file stores that keep blocks
+// outside of an IPFS repo, in a plain directory tree or an S3-compatible
+// object store. Stores are IPFS nodes running on a custom datastore, so
+// paths, dsync, dag manifests & dataset loading work exactly as they do with
+// an "ipfs" store
+package storage
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+
+	ds "github.com/ipfs/go-datastore"
+	syncds "github.com/ipfs/go-datastore/sync"
+	ipfscfg "github.com/ipfs/go-ipfs-config"
+	core "github.com/ipfs/go-ipfs/core"
+	"github.com/ipfs/go-ipfs/keystore"
+	ipfsrepo "github.com/ipfs/go-ipfs/repo"
+	coreiface "github.com/ipfs/interface-go-ipfs-core"
+	"github.com/ipfs/interface-go-ipfs-core/options"
+	crypto "github.com/libp2p/go-libp2p-core/crypto"
+	peer "github.com/libp2p/go-libp2p-core/peer"
+	"github.com/qri-io/qfs/cafs"
+	ipfs "github.com/qri-io/qfs/cafs/ipfs"
+	"github.com/qri-io/qri/config"
+)
+
+// ErrNoPath is returned when creating a directory store without a path
+var ErrNoPath = fmt.Errorf("fs store requires a path")
+
+// NewFilestore creates a content-addressed file store that keeps all data in
+// d. The store uses the peer identity & addresses in p2p when going online. A
+// nil p2p gives the store a throwaway identity, for stores that never go
+// online
+func NewFilestore(ctx context.Context, d ds.Batching, p2p *config.P2P) (*ipfs.Filestore, error) {
+	c := ipfscfg.Config{}
+	if p2p == nil {
+		id, err := ephemeralIdentity()
+		if err != nil {
+			return nil, err
+		}
+		c.Identity = id
+	} else {
+		c.Identity = ipfscfg.Identity{
+			PeerID:  p2p.PeerID,
+			PrivKey: p2p.PrivKey,
+		}
+		for _, addr := range p2p.Addrs {
+			c.Addresses.Swarm = append(c.Addresses.Swarm, addr.String())
+		}
+	}
+
+	r := &ipfsrepo.Mock{
+		C: c,
+		D: syncds.MutexWrap(d),
+		K: keystore.NewMemKeystore(),
+	}
+
+	return ipfs.NewFilestore(func(o *ipfs.StoreCfg) {
+		o.Ctx = ctx
+		o.FsRepoPath = ""
+		o.BuildCfg = core.BuildCfg{Repo: r}
+	})
+}
+
+// NewDirFilestore creates a file store that keeps blocks in a plain directory
+// tree at path
+func NewDirFilestore(ctx context.Context, path string, p2p *config.P2P) (*ipfs.Filestore, error) {
+	d, err := NewDirDatastore(path)
+	if err != nil {
+		return nil, err
+	}
+	return NewFilestore(ctx, d, p2p)
+}
+
+// NewS3Filestore creates a file store that keeps blocks in an S3-compatible
+// object store
+func NewS3Filestore(ctx context.Context, opts S3Options, p2p *config.P2P) (*ipfs.Filestore, error) {
+	d, err := NewS3Datastore(opts)
+	if err != nil {
+		return nil, err
+	}
+	return NewFilestore(ctx, d, p2p)
+}
+
+// ephemeralIdentity generates an IPFS identity that isn't shared with any
+// other node
+func ephemeralIdentity() (ipfscfg.Identity, error) {
+	pk, _, err := crypto.GenerateEd25519Key(rand.Reader)
+	if err != nil {
+		return ipfscfg.Identity{}, err
+	}
+	id, err := peer.IDFromPrivateKey(pk)
+	if err != nil {
+		return ipfscfg.Identity{}, err
+	}
+	data, err := crypto.MarshalPrivateKey(pk)
+	if err != nil {
+		return ipfscfg.Identity{}, err
+	}
+	return ipfscfg.Identity{
+		PeerID:  id.Pretty(),
+		PrivKey: base64.StdEncoding.EncodeToString(data),
+	}, nil
+}
+
+// Close shuts down the IPFS node backing a store. Stores that aren't backed
+// by a node are left as-is
+func Close(store cafs.Filestore) error {
+	if n, ok := store.(ipfsNoder); ok && n.Node() != nil {
+		return n.Node().Close()
+	}
+	return nil
+}
+
+// ipfsNoder is implemented by stores backed by an IPFS node
+type ipfsNoder interface {
+	Node() *core.IpfsNode
+	IPFSCoreAPI() coreiface.CoreAPI
+}
+
+// MigrateResult reports the outcome of copying one store to another
+type MigrateResult struct {
+	// Blocks copied to the destination store
+	Blocks int
+	// Skipped counts blocks the destination store already had
+	Skipped int
+	// Pins re-created in the destination store
+	Pins int
+}
+
+// Migrate copies all blocks & recursive pins from src into dst. Both stores
+// must be IPFS-backed, which includes all stores created by this package.
+// Blocks dst already has are skipped, making interrupted migrations safe to
+// re-run
+func Migrate(ctx context.Context, src, dst cafs.Filestore) (*MigrateResult, error) {
+	srcNode, ok := src.(ipfsNoder)
+	if !ok {
+		return nil, fmt.Errorf("migrating from a %q store isn't supported", src.PathPrefix())
+	}
+	dstNode, ok := dst.(ipfsNoder)
+	if !ok {
+		return nil, fmt.Errorf("migrating to a %q store isn't supported", dst.PathPrefix())
+	}
+
+	res := &MigrateResult{}
+	keys, err := srcNode.Node().Blockstore.AllKeysChan(ctx)
+	if err != nil {
+		return nil, err
+	}
+	dstBlocks := dstNode.Node().Blockstore
+	for id := range keys {
+		has, err := dstBlocks.Has(id)
+		if err != nil {
+			return nil, err
+		}
+		if has {
+			res.Skipped++
+			continue
+		}
+		blk, err := srcNode.Node().Blockstore.Get(id)
+		if err != nil {
+			return nil, fmt.Errorf("reading block %s: %w", id, err)
+		}
+		if err := dstBlocks.Put(blk); err != nil {
+			return nil, fmt.Errorf("writing block %s: %w", id, err)
+		}
+		res.Blocks++
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	pins, err := srcNode.IPFSCoreAPI().Pin().Ls(ctx, options.Pin.Type.Recursive())
+	if err != nil {
+		return nil, fmt.Errorf("listing pins: %w", err)
+	}
+	for _, p := range pins {
+		if err := dstNode.IPFSCoreAPI().Pin().Add(ctx, p.Path()); err != nil {
+			return nil, fmt.Errorf("pinning %s: %w", p.Path(), err)
+		}
+		res.Pins++
+	}
+
+	return res, nil
+}
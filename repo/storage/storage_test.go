@@ -0,0 +1,198 @@
+package storage
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	cid "github.com/ipfs/go-cid"
+	ds "github.com/ipfs/go-datastore"
+	"github.com/qri-io/dag"
+	"github.com/qri-io/dag/dsync"
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/qfs"
+	ipfs "github.com/qri-io/qfs/cafs/ipfs"
+	"github.com/qri-io/qri/base/dsfs"
+	"github.com/qri-io/qri/config"
+	cfgtest "github.com/qri-io/qri/config/test"
+)
+
+func TestDirFilestoreDataset(t *testing.T) {
+	ctx := context.Background()
+	dir, err := ioutil.TempDir("", "storage_dir_filestore")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	store, err := NewDirFilestore(ctx, dir, testP2P(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if store.PathPrefix() != "ipfs" {
+		t.Errorf("expected ipfs path prefix, got: %q", store.PathPrefix())
+	}
+
+	path := writeTestDataset(ctx, t, store)
+	ds, err := dsfs.LoadDataset(ctx, store, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ds.Meta == nil || ds.Meta.Title != "storage test" {
+		t.Errorf("loaded dataset mismatch. got meta: %v", ds.Meta)
+	}
+
+	// blocks are stored as plain files
+	fis, err := ioutil.ReadDir(dir + "/blocks")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fis) == 0 {
+		t.Error("expected blocks to be written to the store directory")
+	}
+
+	// re-opening the directory sees previously written data
+	reopened, err := NewDirFilestore(ctx, dir, testP2P(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := dsfs.LoadDataset(ctx, reopened, path); err != nil {
+		t.Errorf("loading dataset from re-opened store: %s", err)
+	}
+}
+
+func TestFilestoreDsync(t *testing.T) {
+	ctx := context.Background()
+	dir, err := ioutil.TempDir("", "storage_dsync")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	src, err := NewDirFilestore(ctx, dir, testP2P(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s3 := newFakeS3Server(t, "qri")
+	defer s3.Close()
+	dst, err := NewS3Filestore(ctx, S3Options{Endpoint: s3.URL, Bucket: "qri", AccessKeyID: "a", SecretAccessKey: "b"}, testP2P(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := writeTestDataset(ctx, t, src)
+	id, err := cid.Parse(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	srcNodes, err := dsync.NewLocalNodeGetter(src.IPFSCoreAPI())
+	if err != nil {
+		t.Fatal(err)
+	}
+	mfst, err := dag.NewManifest(ctx, srcNodes, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mfst.Nodes) == 0 {
+		t.Fatal("expected manifest to list nodes")
+	}
+	info, err := dag.NewInfo(ctx, srcNodes, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dstNodes, err := dsync.NewLocalNodeGetter(dst.IPFSCoreAPI())
+	if err != nil {
+		t.Fatal(err)
+	}
+	receiver, err := dsync.New(dstNodes, dst.IPFSCoreAPI().Block(), func(cfg *dsync.Config) {
+		cfg.PushPreCheck = func(context.Context, dag.Info, map[string]string) error { return nil }
+		cfg.PinAPI = dst.IPFSCoreAPI().Pin()
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	push, err := dsync.NewPush(srcNodes, info, receiver, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := push.Do(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := dsfs.LoadDataset(ctx, dst, path); err != nil {
+		t.Errorf("loading synced dataset from s3 store: %s", err)
+	}
+}
+
+func TestMigrate(t *testing.T) {
+	ctx := context.Background()
+	dir, err := ioutil.TempDir("", "storage_migrate")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	src, err := NewFilestore(ctx, ds.NewMapDatastore(), testP2P(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := writeTestDataset(ctx, t, src)
+
+	// destination stores don't share the source identity
+	dst, err := NewDirFilestore(ctx, dir, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer Close(dst)
+	if dst.Node().Identity == src.Node().Identity {
+		t.Error("expected destination store to have its own identity")
+	}
+	res, err := Migrate(ctx, src, dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Blocks == 0 || res.Pins == 0 {
+		t.Errorf("expected blocks & pins to be copied, got: %v", res)
+	}
+	if _, err := dsfs.LoadDataset(ctx, dst, path); err != nil {
+		t.Errorf("loading migrated dataset: %s", err)
+	}
+
+	// migrating again skips blocks that are already present
+	again, err := Migrate(ctx, src, dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.Blocks != 0 || again.Skipped < res.Blocks {
+		t.Errorf("expected re-run to skip all blocks, got: %v", again)
+	}
+}
+
+func testP2P(i int) *config.P2P {
+	pi := cfgtest.GetTestPeerInfo(i)
+	cfg := config.DefaultP2PForTesting()
+	cfg.PeerID = pi.PeerID.Pretty()
+	cfg.PrivKey = pi.EncodedPrivKey
+	return cfg
+}
+
+func writeTestDataset(ctx context.Context, t *testing.T, store *ipfs.Filestore) string {
+	ds := &dataset.Dataset{
+		Commit: &dataset.Commit{Title: "initial commit"},
+		Meta:   &dataset.Meta{Title: "storage test"},
+		Structure: &dataset.Structure{
+			Format: "json",
+			Schema: dataset.BaseSchemaArray,
+		},
+	}
+	ds.SetBodyFile(qfs.NewMemfileBytes("body.json", []byte(`[1,2,3]`)))
+
+	path, err := dsfs.CreateDataset(ctx, store, ds, nil, cfgtest.GetTestPeerInfo(0).PrivKey, dsfs.SaveSwitches{Pin: true, ShouldRender: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
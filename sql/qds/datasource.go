@@ -50,6 +50,12 @@ func NewDataSourceBuilderFactory(r repo.Repo) physical.DataSourceBuilderFactory
 				return nil, errors.Wrap(err, "couldn't get path")
 			}
 
+			// an optional path pins the version of the dataset to read
+			path, err := config.GetString(dbConfig, "path", config.WithDefault(""))
+			if err != nil {
+				return nil, errors.Wrap(err, "couldn't get path")
+			}
+
 			ref, err := base.ToDatasetRef(refstr, r, false)
 			if err != nil {
 				log.Debugf("buildSource: base.ToDatasetRef '%s': %s", refstr, err)
@@ -58,6 +64,9 @@ func NewDataSourceBuilderFactory(r repo.Repo) physical.DataSourceBuilderFactory
 				}
 				return nil, errors.Wrap(err, "preparing SQL data souce: bad dataset reference.")
 			}
+			if path != "" {
+				ref.Path = path
+			}
 
 			return &DataSource{
 				r:     r,
@@ -39,6 +39,15 @@ func New(r repo.Repo) *Service {
 
 // Exec runs an SQL query against a given dataset mapping
 func (svc *Service) Exec(ctx context.Context, w io.Writer, outFormat, query string) error {
+	return svc.ExecAt(ctx, w, outFormat, query, nil)
+}
+
+// ExecAt runs an SQL query like Exec, reading datasets at given versions.
+// paths maps dataset reference strings in the query to version paths,
+// references without a path read the latest version. Callers that record the
+// versions a query reads resolve them first, so the versions they record are
+// the versions queried
+func (svc *Service) ExecAt(ctx context.Context, w io.Writer, outFormat, query string, paths map[string]string) error {
 	processedQuery, sources, err := preprocess.Query(query)
 	if err != nil {
 		log.Errorf("mapping query: %s", err)
@@ -48,12 +57,16 @@ func (svc *Service) Exec(ctx context.Context, w io.Writer, outFormat, query stri
 	// Configuration
 	cfg := &octosqlcfg.Config{}
 	for name, refStr := range sources {
+		dsCfg := map[string]interface{}{
+			"ref": refStr,
+		}
+		if path, ok := paths[refStr]; ok {
+			dsCfg["path"] = path
+		}
 		cfg.DataSources = append(cfg.DataSources, octosqlcfg.DataSourceConfig{
-			Type: qds.CfgTypeString,
-			Name: name,
-			Config: map[string]interface{}{
-				"ref": refStr,
-			},
+			Type:   qds.CfgTypeString,
+			Name:   name,
+			Config: dsCfg,
 		})
 	}
 
@@ -0,0 +1,270 @@
+package ds
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/dataset/dsio"
+	"github.com/qri-io/qfs"
+	"github.com/qri-io/starlib/util"
+	"go.starlark.net/starlark"
+	"go.starlark.net/starlarkstruct"
+)
+
+// BodyWriter streams body entries to a temp file as they're written from
+// starlark, so large bodies don't need to be held in memory. Closing the
+// writer assigns the temp file as the dataset body
+type BodyWriter struct {
+	st     *dataset.Structure
+	tlt    string
+	file   *os.File
+	w      dsio.EntryWriter
+	count  int
+	closed bool
+}
+
+// newBodyWriter creates a body writer that writes entries of structure st.
+// check is called with the size of the written body before each write to the
+// temp file
+func newBodyWriter(st *dataset.Structure, check BodySizeCheck) (*BodyWriter, error) {
+	tlt, err := dsio.GetTopLevelType(st)
+	if err != nil {
+		return nil, err
+	}
+	f, err := ioutil.TempFile("", fmt.Sprintf("qri_body_*.%s", st.Format))
+	if err != nil {
+		return nil, err
+	}
+	var out io.Writer = f
+	if check != nil {
+		out = &sizeCheckedWriter{w: f, check: check}
+	}
+	w, err := dsio.NewEntryWriter(st, out)
+	if err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return nil, err
+	}
+	return &BodyWriter{st: st, tlt: tlt, file: f, w: w}, nil
+}
+
+// Methods exposes body writer methods as starlark values
+func (bw *BodyWriter) Methods() *starlarkstruct.Struct {
+	return starlarkstruct.FromStringDict(starlarkstruct.Default, starlark.StringDict{
+		"write":       starlark.NewBuiltin("write", bw.Write),
+		"append_rows": starlark.NewBuiltin("append_rows", bw.AppendRows),
+	})
+}
+
+// Count returns the number of entries written
+func (bw *BodyWriter) Count() int {
+	return bw.count
+}
+
+// Write writes a single entry. Array bodies take one argument: the row to
+// write. Object bodies take two, a key and a value
+func (bw *BodyWriter) Write(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var (
+		ent dsio.Entry
+		err error
+	)
+	if bw.tlt == "object" {
+		var (
+			key starlark.String
+			val starlark.Value
+		)
+		if err := starlark.UnpackPositionalArgs("write", args, kwargs, 2, &key, &val); err != nil {
+			return starlark.None, err
+		}
+		ent.Key = key.GoString()
+		if ent.Value, err = util.Unmarshal(val); err != nil {
+			return starlark.None, err
+		}
+	} else {
+		var row starlark.Value
+		if err := starlark.UnpackPositionalArgs("write", args, kwargs, 1, &row); err != nil {
+			return starlark.None, err
+		}
+		if ent.Value, err = util.Unmarshal(row); err != nil {
+			return starlark.None, err
+		}
+	}
+	return starlark.None, bw.writeEntry(ent)
+}
+
+// AppendRows writes each entry of a list of rows, or a dict for object
+// bodies
+func (bw *BodyWriter) AppendRows(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var rows starlark.Value
+	if err := starlark.UnpackPositionalArgs("append_rows", args, kwargs, 1, &rows); err != nil {
+		return starlark.None, err
+	}
+	return starlark.None, bw.appendRows(rows)
+}
+
+func (bw *BodyWriter) appendRows(rows starlark.Value) error {
+	iter, ok := rows.(starlark.Iterable)
+	if !ok {
+		return fmt.Errorf("expected rows to be iterable")
+	}
+	if _, isDict := rows.(*starlark.Dict); isDict != (bw.tlt == "object") {
+		return fmt.Errorf("expected rows for a body of type %s", bw.tlt)
+	}
+
+	r := NewEntryReader(bw.st, iter)
+	for {
+		ent, err := r.ReadEntry()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if err := bw.writeEntry(ent); err != nil {
+			return err
+		}
+	}
+}
+
+func (bw *BodyWriter) writeEntry(ent dsio.Entry) error {
+	if bw.closed {
+		return fmt.Errorf("cannot write to a closed body writer")
+	}
+	ent.Index = bw.count
+	if err := bw.w.WriteEntry(ent); err != nil {
+		return err
+	}
+	bw.count++
+	return nil
+}
+
+// Close finalizes the written body, returning the temp file as a body file.
+// The temp file is removed when the body file is read to the end or closed
+func (bw *BodyWriter) Close() (qfs.File, error) {
+	if bw.closed {
+		return nil, fmt.Errorf("body writer is already closed")
+	}
+	bw.closed = true
+	if err := bw.w.Close(); err != nil {
+		bw.Discard()
+		return nil, err
+	}
+	if _, err := bw.file.Seek(0, io.SeekStart); err != nil {
+		bw.Discard()
+		return nil, err
+	}
+	fi, err := bw.file.Stat()
+	if err != nil {
+		bw.Discard()
+		return nil, err
+	}
+	return &BodyFile{
+		name:    fmt.Sprintf("body.%s", bw.st.Format),
+		file:    bw.file,
+		size:    fi.Size(),
+		modTime: fi.ModTime(),
+	}, nil
+}
+
+// Discard drops all written entries, removing the temp file
+func (bw *BodyWriter) Discard() {
+	bw.closed = true
+	bw.file.Close()
+	os.Remove(bw.file.Name())
+}
+
+// sizeCheckedWriter checks the size a write would grow a body to before
+// writing, so an oversized body is never written in full
+type sizeCheckedWriter struct {
+	w     io.Writer
+	size  int64
+	check BodySizeCheck
+}
+
+// Write implements io.Writer
+func (w *sizeCheckedWriter) Write(p []byte) (int, error) {
+	if err := w.check(w.size + int64(len(p))); err != nil {
+		return 0, err
+	}
+	n, err := w.w.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+// BodyFile is a body written by a BodyWriter, backed by a temp file
+type BodyFile struct {
+	name    string
+	file    *os.File
+	size    int64
+	modTime time.Time
+	removed bool
+}
+
+// Confirm that BodyFile satisfies the qfs.File interface
+var _ = (qfs.File)(&BodyFile{})
+
+// Size returns the length of the body in bytes
+func (f *BodyFile) Size() int64 {
+	return f.size
+}
+
+// Read implements the io.Reader interface. Reading to the end of the file
+// removes the backing temp file
+func (f *BodyFile) Read(p []byte) (int, error) {
+	if f.removed {
+		return 0, io.EOF
+	}
+	n, err := f.file.Read(p)
+	if err == io.EOF {
+		f.Close()
+	}
+	return n, err
+}
+
+// Close closes & removes the backing temp file
+func (f *BodyFile) Close() error {
+	if f.removed {
+		return nil
+	}
+	f.removed = true
+	err := f.file.Close()
+	if rmErr := os.Remove(f.file.Name()); err == nil {
+		err = rmErr
+	}
+	return err
+}
+
+// FileName returns the body filename
+func (f *BodyFile) FileName() string {
+	return f.name
+}
+
+// FullPath returns the body filename
+func (f *BodyFile) FullPath() string {
+	return f.name
+}
+
+// IsDirectory always returns false
+func (f *BodyFile) IsDirectory() bool {
+	return false
+}
+
+// NextFile always errors, BodyFile isn't a directory
+func (f *BodyFile) NextFile() (qfs.File, error) {
+	return nil, qfs.ErrNotDirectory
+}
+
+// MediaType returns a mime type based on the file extension
+func (f *BodyFile) MediaType() string {
+	return mime.TypeByExtension(filepath.Ext(f.name))
+}
+
+// ModTime returns the time the body was finalized
+func (f *BodyFile) ModTime() time.Time {
+	return f.modTime
+}
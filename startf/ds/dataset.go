@@ -42,13 +42,21 @@ func LoadModule() (starlark.StringDict, error) {
 // a path as possible and bail if an error is returned
 type MutateFieldCheck func(path ...string) error
 
+// BodySizeCheck is a function to check the size in bytes of a body as it's
+// written. dataset calls BodySizeCheck before assigning or writing body data
+// and bails if an error is returned
+type BodySizeCheck func(size int64) error
+
 // Dataset is a qri dataset starlark type
 type Dataset struct {
 	read      *dataset.Dataset
 	write     *dataset.Dataset
 	bodyCache starlark.Iterable
 	check     MutateFieldCheck
+	sizeCheck BodySizeCheck
 	modBody   bool
+	// bodyWriter streams a body written with body_writer or append_rows
+	bodyWriter *BodyWriter
 }
 
 // NewDataset creates a dataset object, intended to be called from go-land to prepare datasets
@@ -68,6 +76,12 @@ func (d *Dataset) SetMutable(ds *dataset.Dataset) {
 	d.write = ds
 }
 
+// SetBodySizeCheck assigns a check for the size of bodies written to the
+// dataset
+func (d *Dataset) SetBodySizeCheck(check BodySizeCheck) {
+	d.sizeCheck = check
+}
+
 // IsBodyModified returns whether the body has been modified by set_body
 func (d *Dataset) IsBodyModified() bool {
 	return d.modBody
@@ -82,6 +96,8 @@ func (d *Dataset) Methods() *starlarkstruct.Struct {
 		"set_structure": starlark.NewBuiltin("set_structure", d.SetStructure),
 		"get_body":      starlark.NewBuiltin("get_body", d.GetBody),
 		"set_body":      starlark.NewBuiltin("set_body", d.SetBody),
+		"body_writer":   starlark.NewBuiltin("body_writer", d.BodyWriter),
+		"append_rows":   starlark.NewBuiltin("append_rows", d.AppendRows),
 	})
 }
 
@@ -93,6 +109,14 @@ func (d *Dataset) checkField(path ...string) error {
 	return nil
 }
 
+// checkBodySize runs the body size check if one is defined
+func (d *Dataset) checkBodySize(size int64) error {
+	if d.sizeCheck != nil {
+		return d.sizeCheck(size)
+	}
+	return nil
+}
+
 // GetMeta gets a dataset meta component
 func (d *Dataset) GetMeta(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
 	var provider *dataset.Meta
@@ -215,6 +239,9 @@ func (d *Dataset) SetStructure(thread *starlark.Thread, _ *starlark.Builtin, arg
 // GetBody returns the body of the dataset we're transforming. The read version is returned until
 // the dataset is modified by set_body, then the write version is returned instead.
 func (d *Dataset) GetBody(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	if d.bodyWriter != nil {
+		return starlark.None, fmt.Errorf("cannot call get_body on a body written with body_writer")
+	}
 	if d.bodyCache != nil {
 		return d.bodyCache, nil
 	}
@@ -299,6 +326,9 @@ func (d *Dataset) SetBody(thread *starlark.Thread, _ *starlark.Builtin, args sta
 		return starlark.None, err
 	}
 
+	// set_body replaces any streamed body
+	d.DiscardBodyWriter()
+
 	df := parseAs.GoString()
 	if df != "" {
 		if _, err := dataset.ParseDataFormatString(df); err != nil {
@@ -310,6 +340,9 @@ func (d *Dataset) SetBody(thread *starlark.Thread, _ *starlark.Builtin, args sta
 			return starlark.None, fmt.Errorf("expected data for '%s' format to be a string", df)
 		}
 
+		if err := d.checkBodySize(int64(len(str))); err != nil {
+			return starlark.None, err
+		}
 		d.write.SetBodyFile(qfs.NewMemfileBytes(fmt.Sprintf("body.%s", df), []byte(string(str))))
 		d.modBody = true
 		d.bodyCache = nil
@@ -335,6 +368,9 @@ func (d *Dataset) SetBody(thread *starlark.Thread, _ *starlark.Builtin, args sta
 	if err := w.Close(); err != nil {
 		return starlark.None, err
 	}
+	if err := d.checkBodySize(int64(len(w.Bytes()))); err != nil {
+		return starlark.None, err
+	}
 
 	d.write.SetBodyFile(qfs.NewMemfileBytes(fmt.Sprintf("body.%s", d.write.Structure.Format), w.Bytes()))
 	d.modBody = true
@@ -343,6 +379,85 @@ func (d *Dataset) SetBody(thread *starlark.Thread, _ *starlark.Builtin, args sta
 	return starlark.None, nil
 }
 
+// BodyWriter returns a writer that streams body entries to disk as they're
+// written, for bodies too large to hold in memory
+func (d *Dataset) BodyWriter(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	if err := starlark.UnpackPositionalArgs("body_writer", args, kwargs, 0); err != nil {
+		return starlark.None, err
+	}
+	bw, err := d.openBodyWriter("body_writer", starlark.NewList(nil))
+	if err != nil {
+		return starlark.None, err
+	}
+	return bw.Methods(), nil
+}
+
+// AppendRows streams rows to the end of the dataset body, starting a new body
+// on first call
+func (d *Dataset) AppendRows(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var rows starlark.Value
+	if err := starlark.UnpackPositionalArgs("append_rows", args, kwargs, 1, &rows); err != nil {
+		return starlark.None, err
+	}
+	bw, err := d.openBodyWriter("append_rows", rows)
+	if err != nil {
+		return starlark.None, err
+	}
+	return starlark.None, bw.appendRows(rows)
+}
+
+// openBodyWriter returns the open body writer, creating one if necessary.
+// data is used to pick a default structure when none is defined
+func (d *Dataset) openBodyWriter(method string, data starlark.Value) (*BodyWriter, error) {
+	if d.bodyWriter != nil {
+		return d.bodyWriter, nil
+	}
+	if d.write == nil {
+		return nil, fmt.Errorf("cannot call %s on read-only dataset", method)
+	}
+	if err := d.checkField("body"); err != nil {
+		return nil, err
+	}
+	if err := d.checkField("structure"); err != nil {
+		return nil, fmt.Errorf("cannot use a transform to set the body of a dataset and manually adjust structure at the same time")
+	}
+
+	st := d.writeStructure(data)
+	bw, err := newBodyWriter(st, d.sizeCheck)
+	if err != nil {
+		return nil, err
+	}
+	d.write.Structure = st
+	d.bodyWriter = bw
+	d.modBody = true
+	d.bodyCache = nil
+	return bw, nil
+}
+
+// CloseBodyWriter finalizes a body written with body_writer or append_rows,
+// assigning it as the body of the mutable dataset. It's a no-op if no body
+// was streamed
+func (d *Dataset) CloseBodyWriter() error {
+	if d.bodyWriter == nil {
+		return nil
+	}
+	f, err := d.bodyWriter.Close()
+	d.bodyWriter = nil
+	if err != nil {
+		return err
+	}
+	d.write.SetBodyFile(f)
+	return nil
+}
+
+// DiscardBodyWriter drops any streamed body, removing temp files
+func (d *Dataset) DiscardBodyWriter() {
+	if d.bodyWriter != nil {
+		d.bodyWriter.Discard()
+		d.bodyWriter = nil
+	}
+}
+
 // writeStructure determines the destination data structure for writing a
 // dataset body, falling back to a default json structure based on input values
 // if no prior structure exists
@@ -2,6 +2,8 @@ package ds
 
 import (
 	"fmt"
+	"io/ioutil"
+	"os"
 	"testing"
 
 	"github.com/qri-io/dataset"
@@ -228,3 +230,170 @@ bat,3,meh
 	})
 	return d
 }
+
+func TestBodyWriter(t *testing.T) {
+	resolve.AllowFloat = true
+	thread := &starlark.Thread{Load: newLoader()}
+	d := NewDataset(&dataset.Dataset{}, nil)
+	next := &dataset.Dataset{}
+	d.SetMutable(next)
+
+	script := `
+def transform(ds):
+  w = ds.body_writer()
+  w.write([1, "a"])
+  w.append_rows([[2, "b"], [3, "c"]])
+  ds.append_rows([[4, "d"]])
+`
+	globals, err := starlark.ExecFile(thread, "body_writer.star", script, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := starlark.Call(thread, globals["transform"], starlark.Tuple{d.Methods()}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := d.GetBody(thread, nil, nil, nil); err == nil {
+		t.Error("expected get_body on a streamed body to error")
+	}
+	if err := d.CloseBodyWriter(); err != nil {
+		t.Fatal(err)
+	}
+	if !d.IsBodyModified() {
+		t.Error("expected body to be modified")
+	}
+
+	bf, ok := next.BodyFile().(*BodyFile)
+	if !ok {
+		t.Fatalf("expected body file to be a *BodyFile. got: %T", next.BodyFile())
+	}
+	name := bf.file.Name()
+	data, err := ioutil.ReadAll(bf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := `[[1,"a"],[2,"b"],[3,"c"],[4,"d"]]`
+	if string(data) != expect {
+		t.Errorf("body mismatch. expected: %s, got: %s", expect, string(data))
+	}
+	if bf.Size() != int64(len(expect)) {
+		t.Errorf("size mismatch. expected: %d, got: %d", len(expect), bf.Size())
+	}
+	if _, err := os.Stat(name); !os.IsNotExist(err) {
+		t.Errorf("expected temp file to be removed after reading. got: %v", err)
+	}
+}
+
+func TestBodyWriterObject(t *testing.T) {
+	thread := &starlark.Thread{}
+	d := NewDataset(&dataset.Dataset{}, nil)
+	next := &dataset.Dataset{}
+	d.SetMutable(next)
+
+	rows := starlark.NewDict(1)
+	rows.SetKey(starlark.String("a"), starlark.MakeInt(1))
+	if _, err := d.AppendRows(thread, nil, starlark.Tuple{rows}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := d.AppendRows(thread, nil, starlark.Tuple{starlark.NewList(nil)}, nil); err == nil {
+		t.Error("expected appending a list to an object body to error")
+	}
+	bw, err := d.openBodyWriter("body_writer", starlark.None)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := bw.Write(thread, nil, starlark.Tuple{starlark.String("b"), starlark.MakeInt(2)}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.CloseBodyWriter(); err != nil {
+		t.Fatal(err)
+	}
+	data, err := ioutil.ReadAll(next.BodyFile())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{"a":1,"b":2}` {
+		t.Errorf("body mismatch. got: %s", string(data))
+	}
+}
+
+func TestSetBodyDiscardsBodyWriter(t *testing.T) {
+	thread := &starlark.Thread{}
+	d := NewDataset(&dataset.Dataset{}, nil)
+	next := &dataset.Dataset{}
+	d.SetMutable(next)
+
+	if _, err := d.BodyWriter(thread, nil, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	name := d.bodyWriter.file.Name()
+	body := starlark.NewList([]starlark.Value{starlark.MakeInt(1)})
+	if _, err := d.SetBody(thread, nil, starlark.Tuple{body}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(name); !os.IsNotExist(err) {
+		t.Errorf("expected temp file to be removed. got: %v", err)
+	}
+	if err := d.CloseBodyWriter(); err != nil {
+		t.Fatal(err)
+	}
+	data, err := ioutil.ReadAll(next.BodyFile())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `[1]` {
+		t.Errorf("body mismatch. got: %s", string(data))
+	}
+
+	ro := NewDataset(&dataset.Dataset{}, nil)
+	if _, err := ro.BodyWriter(thread, nil, nil, nil); err == nil {
+		t.Error("expected body_writer on a read-only dataset to error")
+	}
+}
+
+func TestBodySizeCheck(t *testing.T) {
+	thread := &starlark.Thread{}
+	errTooBig := fmt.Errorf("too big")
+	check := func(size int64) error {
+		if size > 20 {
+			return errTooBig
+		}
+		return nil
+	}
+	rows := func(n int) *starlark.List {
+		vals := make([]starlark.Value, n)
+		for i := range vals {
+			vals[i] = starlark.MakeInt(i)
+		}
+		return starlark.NewList(vals)
+	}
+
+	d := NewDataset(&dataset.Dataset{}, nil)
+	next := &dataset.Dataset{}
+	d.SetMutable(next)
+	d.SetBodySizeCheck(check)
+	if _, err := d.SetBody(thread, nil, starlark.Tuple{rows(100)}, nil); err != errTooBig {
+		t.Errorf("expected oversized set_body to fail the check. got: %v", err)
+	}
+	if next.BodyFile() != nil {
+		t.Error("expected oversized body not to be assigned")
+	}
+	if _, err := d.SetBody(thread, nil, starlark.Tuple{rows(3)}, nil); err != nil {
+		t.Errorf("expected small body to pass the check. got: %s", err)
+	}
+
+	streamed := NewDataset(&dataset.Dataset{}, nil)
+	streamed.SetMutable(&dataset.Dataset{})
+	streamed.SetBodySizeCheck(check)
+	var err error
+	for i := 0; i < 100 && err == nil; i++ {
+		_, err = streamed.AppendRows(thread, nil, starlark.Tuple{rows(10)}, nil)
+	}
+	if err == nil {
+		err = streamed.CloseBodyWriter()
+	} else {
+		streamed.DiscardBodyWriter()
+	}
+	if err != errTooBig {
+		t.Errorf("expected oversized streamed body to fail the check. got: %v", err)
+	}
+}
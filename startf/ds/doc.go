@@ -24,5 +24,20 @@
             structure (tuple, set, list, dict). When parse_as is set, set_body assumes the provided body value will
             be a string of serialized structured data in the given format. valid parse_as values are "json", "csv",
             "cbor", "xlsx".
+          body_writer() BodyWriter
+            stream the dataset body to disk instead of holding it in memory. Entries are written in the dataset's
+            structure format, defaulting to a json array. The streamed body becomes the dataset body when the
+            transform returns. get_body cannot be called while a body is being streamed, calling set_body drops
+            any streamed entries
+          append_rows(rows list|dict)
+            write rows to the end of the streamed body, starting a body_writer if one isn't open. rows must be a
+            dict when the body is an object
+      BodyWriter
+        streams dataset body entries to disk
+        methods:
+          write(row)
+            write a single row to the end of an array body. Object bodies take two arguments: write(key, value)
+          append_rows(rows list|dict)
+            write all rows to the end of the body
 */
 package ds
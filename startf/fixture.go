@@ -0,0 +1,235 @@
+package startf
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sync"
+)
+
+// HTTPFixtureConfigKey is the transform config key recorded http fixtures are
+// stored under. Storing fixtures in transform config keeps them alongside the
+// script that made the requests
+const HTTPFixtureConfigKey = "httpFixture"
+
+// redactedHeaders are response headers that can carry credentials. Transform
+// config is published with the dataset, recorded exchanges never include them
+var redactedHeaders = []string{
+	"Authorization",
+	"Cookie",
+	"Proxy-Authorization",
+	"Set-Cookie",
+	"Set-Cookie2",
+}
+
+var (
+	// ErrNoHTTPFixture is returned when replaying http without a recorded
+	// fixture
+	ErrNoHTTPFixture = fmt.Errorf("no recorded http fixture to replay. save with --record-http to create one")
+	// ErrNoRecordedResponse is returned when a replayed script makes a request
+	// that isn't in the fixture
+	ErrNoRecordedResponse = fmt.Errorf("no recorded response for request")
+)
+
+// HTTPFixture is a record of http requests & responses made during
+// transform execution
+type HTTPFixture struct {
+	Exchanges []*HTTPExchange `json:"exchanges"`
+}
+
+// HTTPExchange is a single recorded request & response. Requests often carry
+// credentials in query strings & bodies, exchanges store digests of query
+// values & request bodies, which is enough to match replayed requests
+type HTTPExchange struct {
+	Method string `json:"method"`
+	// URL is the request URL without user info, query values are replaced
+	// with their digests
+	URL string `json:"url"`
+	// RequestBodyDigest is the digest of the request body, if any
+	RequestBodyDigest string      `json:"requestBodyDigest,omitempty"`
+	Status            int         `json:"status"`
+	Header            http.Header `json:"header,omitempty"`
+	Body              []byte      `json:"body,omitempty"`
+}
+
+// HTTPFixtureFromConfig reads a fixture from transform config, returning nil
+// if config has no fixture
+func HTTPFixtureFromConfig(config map[string]interface{}) (*HTTPFixture, error) {
+	val, ok := config[HTTPFixtureConfigKey]
+	if !ok || val == nil {
+		return nil, nil
+	}
+
+	// config values are generic after a trip through JSON, round trip again to
+	// get back to a fixture
+	data, err := json.Marshal(val)
+	if err != nil {
+		return nil, err
+	}
+	f := &HTTPFixture{}
+	if err := json.Unmarshal(data, f); err != nil {
+		return nil, fmt.Errorf("invalid http fixture: %s", err)
+	}
+	return f, nil
+}
+
+// configValue encodes a fixture in the generic form transform config values
+// take once stored
+func (f *HTTPFixture) configValue() (interface{}, error) {
+	data, err := json.Marshal(f)
+	if err != nil {
+		return nil, err
+	}
+	var val interface{}
+	err = json.Unmarshal(data, &val)
+	return val, err
+}
+
+func exchangeKey(method, url, bodyDigest string) string {
+	return fmt.Sprintf("%s %s %s", method, url, bodyDigest)
+}
+
+// digest is a short, stable stand-in for data that shouldn't be stored
+func digest(data []byte) string {
+	if len(data) == 0 {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return "sha256-" + hex.EncodeToString(sum[:16])
+}
+
+// fixtureURL is the form of a request URL stored in fixtures, with user info
+// removed & query values replaced with their digests
+func fixtureURL(u *url.URL) string {
+	cp := *u
+	cp.User = nil
+	if cp.RawQuery != "" {
+		q := cp.Query()
+		for _, vals := range q {
+			for i, val := range vals {
+				vals[i] = digest([]byte(val))
+			}
+		}
+		cp.RawQuery = q.Encode()
+	}
+	return cp.String()
+}
+
+// fixtureHeader copies response headers without ones that carry credentials
+func fixtureHeader(h http.Header) http.Header {
+	cp := h.Clone()
+	for _, key := range redactedHeaders {
+		cp.Del(key)
+	}
+	return cp
+}
+
+// readRequestBody reads & restores the body of a request
+func readRequestBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil {
+		return nil, nil
+	}
+	data, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		return nil, err
+	}
+	req.Body.Close()
+	req.Body = ioutil.NopCloser(bytes.NewReader(data))
+	return data, nil
+}
+
+// recordTransport records exchanges made through base into a fixture
+type recordTransport struct {
+	base    http.RoundTripper
+	lk      sync.Mutex
+	fixture *HTTPFixture
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *recordTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	reqBody, err := readRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	body, err := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	res.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	t.lk.Lock()
+	defer t.lk.Unlock()
+	t.fixture.Exchanges = append(t.fixture.Exchanges, &HTTPExchange{
+		Method:            req.Method,
+		URL:               fixtureURL(req.URL),
+		RequestBodyDigest: digest(reqBody),
+		Status:            res.StatusCode,
+		Header:            fixtureHeader(res.Header),
+		Body:              body,
+	})
+	return res, nil
+}
+
+// replayTransport serves responses from a fixture without touching the
+// network. Identical requests are served in the order they were recorded,
+// repeating the last response once recorded responses run out
+type replayTransport struct {
+	lk      sync.Mutex
+	served  map[string]int
+	fixture *HTTPFixture
+}
+
+func newReplayTransport(f *HTTPFixture) *replayTransport {
+	return &replayTransport{served: map[string]int{}, fixture: f}
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *replayTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	reqBody, err := readRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+	key := exchangeKey(req.Method, fixtureURL(req.URL), digest(reqBody))
+
+	t.lk.Lock()
+	defer t.lk.Unlock()
+	var matches []*HTTPExchange
+	for _, ex := range t.fixture.Exchanges {
+		if exchangeKey(ex.Method, ex.URL, ex.RequestBodyDigest) == key {
+			matches = append(matches, ex)
+		}
+	}
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("%w: %s %s", ErrNoRecordedResponse, req.Method, req.URL)
+	}
+
+	i := t.served[key]
+	if i >= len(matches) {
+		i = len(matches) - 1
+	}
+	t.served[key]++
+	ex := matches[i]
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", ex.Status, http.StatusText(ex.Status)),
+		StatusCode:    ex.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        ex.Header,
+		Body:          ioutil.NopCloser(bytes.NewReader(ex.Body)),
+		ContentLength: int64(len(ex.Body)),
+		Request:       req,
+	}, nil
+}
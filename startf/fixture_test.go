@@ -0,0 +1,148 @@
+package startf
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/qfs"
+	"go.starlark.net/starlark"
+)
+
+func TestRecordReplayHTTP(t *testing.T) {
+	requests := 0
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Write([]byte(`[["a", 1], ["b", 2]]`))
+	}))
+	defer s.Close()
+
+	script := []byte(`
+load("http.star", "http")
+def download(ctx):
+  return http.get(test_server_url).json()
+def transform(ds, ctx):
+  if ctx.get_config("httpFixture") != None:
+    error("fixture should not be visible to scripts")
+  ds.set_body(ctx.download)`)
+	setURL := func(o *ExecOpts) {
+		o.Globals["test_server_url"] = starlark.String(s.URL)
+	}
+	exec := func(ds, prev *dataset.Dataset, opts ...func(o *ExecOpts)) ([]byte, error) {
+		if ds.Transform.Config == nil {
+			ds.Transform.Config = map[string]interface{}{"source": "test"}
+		}
+		ds.Transform.SetScriptFile(qfs.NewMemfileBytes("tf.star", script))
+		if err := ExecScript(context.Background(), ds, prev, append(opts, setURL)...); err != nil {
+			return nil, err
+		}
+		return ioutil.ReadAll(ds.BodyFile())
+	}
+
+	recorded := &dataset.Dataset{Transform: &dataset.Transform{}}
+	expect, err := exec(recorded, nil, SetRecordHTTP(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := HTTPFixtureFromConfig(recorded.Transform.Config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f == nil || len(f.Exchanges) != 1 || f.Exchanges[0].URL != s.URL {
+		t.Fatalf("expected one recorded exchange. got: %#v", f)
+	}
+
+	// config values are generic once a dataset is stored, replay should work
+	// with fixtures that have made a trip through JSON
+	data, err := json.Marshal(recorded.Transform)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tf, err := dataset.UnmarshalTransform(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	prev := &dataset.Dataset{Transform: tf}
+
+	if _, err := exec(&dataset.Dataset{Transform: &dataset.Transform{}}, nil, SetReplayHTTP(true)); !errors.Is(err, ErrNoHTTPFixture) {
+		t.Errorf("expected replay without a fixture to error. got: %v", err)
+	}
+
+	s.Close()
+	replayed := &dataset.Dataset{Transform: &dataset.Transform{}}
+	got, err := exec(replayed, prev, SetReplayHTTP(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != string(expect) {
+		t.Errorf("replayed body mismatch. expected: %s, got: %s", expect, got)
+	}
+	if requests != 1 {
+		t.Errorf("expected replay to skip the network. server got %d requests", requests)
+	}
+	if f, _ := HTTPFixtureFromConfig(replayed.Transform.Config); f == nil {
+		t.Errorf("expected replayed dataset to carry the fixture")
+	}
+	// replaying from next's own fixture keeps it hidden from the script
+	again := &dataset.Dataset{Transform: &dataset.Transform{Config: replayed.Transform.Config}}
+	if _, err := exec(again, nil, SetReplayHTTP(true)); err != nil {
+		t.Errorf("expected replay from next's config to succeed. got: %s", err)
+	}
+
+	script = []byte(`
+load("http.star", "http")
+def download(ctx):
+  return http.get(test_server_url + "/other").json()
+def transform(ds, ctx):
+  ds.set_body(ctx.download)`)
+	if _, err := exec(&dataset.Dataset{Transform: &dataset.Transform{}}, prev, SetReplayHTTP(true)); err == nil || !strings.Contains(err.Error(), ErrNoRecordedResponse.Error()) {
+		t.Errorf("expected unrecorded request to error. got: %v", err)
+	}
+}
+
+func TestRecordHTTPRedactsCredentials(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: "cookie_secret"})
+		w.Write([]byte(`[1,2,3]`))
+	}))
+	defer s.Close()
+
+	script := []byte(`
+load("http.star", "http")
+def download(ctx):
+  return http.post(test_server_url, params={"api_key": "key_secret"}, body="body_secret").json()
+def transform(ds, ctx):
+  ds.set_body(ctx.download)`)
+	exec := func(ds, prev *dataset.Dataset, opts ...func(o *ExecOpts)) error {
+		ds.Transform.SetScriptFile(qfs.NewMemfileBytes("tf.star", script))
+		return ExecScript(context.Background(), ds, prev, append(opts, func(o *ExecOpts) {
+			o.Globals["test_server_url"] = starlark.String(s.URL)
+		})...)
+	}
+
+	recorded := &dataset.Dataset{Transform: &dataset.Transform{}}
+	if err := exec(recorded, nil, SetRecordHTTP(true)); err != nil {
+		t.Fatal(err)
+	}
+	data, err := json.Marshal(recorded.Transform.Config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, secret := range []string{"key_secret", "body_secret", "cookie_secret"} {
+		if strings.Contains(string(data), secret) {
+			t.Errorf("expected recorded fixture not to contain %q. got: %s", secret, data)
+		}
+	}
+
+	s.Close()
+	prev := &dataset.Dataset{Transform: recorded.Transform}
+	if err := exec(&dataset.Dataset{Transform: &dataset.Transform{}}, prev, SetReplayHTTP(true)); err != nil {
+		t.Errorf("expected redacted fixture to replay. got: %s", err)
+	}
+}
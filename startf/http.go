@@ -0,0 +1,190 @@
+package startf
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	starhttp "github.com/qri-io/starlib/http"
+	"github.com/qri-io/starlib/util"
+	"go.starlark.net/starlark"
+	"go.starlark.net/starlarkstruct"
+)
+
+// httpModule is the http module a single transform execution loads. It
+// matches the API of starlib/http, which only supports a single, process-wide
+// client & guard. Each execution builds its own module so requests go through
+// a client bound to that execution's limits, fixtures & network guard
+type httpModule struct {
+	cli   *http.Client
+	guard *HTTPGuard
+}
+
+// newHTTPModule creates the namespace of an http module that makes requests
+// with cli when guard allows them
+func newHTTPModule(cli *http.Client, guard *HTTPGuard) starlark.StringDict {
+	m := &httpModule{cli: cli, guard: guard}
+	return starlark.StringDict{
+		"http": starlarkstruct.FromStringDict(starlarkstruct.Default, starlark.StringDict{
+			"get":     starlark.NewBuiltin("get", m.reqMethod("get")),
+			"put":     starlark.NewBuiltin("put", m.reqMethod("put")),
+			"post":    starlark.NewBuiltin("post", m.reqMethod("post")),
+			"delete":  starlark.NewBuiltin("delete", m.reqMethod("delete")),
+			"patch":   starlark.NewBuiltin("patch", m.reqMethod("patch")),
+			"options": starlark.NewBuiltin("options", m.reqMethod("options")),
+		}),
+	}
+}
+
+// reqMethod creates a starlark builtin that makes requests of an http method
+func (m *httpModule) reqMethod(method string) func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	return func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		var (
+			urlv     starlark.String
+			params   = &starlark.Dict{}
+			headers  = &starlark.Dict{}
+			formBody = &starlark.Dict{}
+			auth     starlark.Tuple
+			body     starlark.String
+			jsonBody starlark.Value
+		)
+		if err := starlark.UnpackArgs(method, args, kwargs, "url", &urlv, "params?", &params, "headers", &headers, "body", &body, "form_body", &formBody, "json_body", &jsonBody, "auth", &auth); err != nil {
+			return nil, err
+		}
+
+		rawurl, err := starhttp.AsString(urlv)
+		if err != nil {
+			return nil, err
+		}
+		if err = setQueryParams(&rawurl, params); err != nil {
+			return nil, err
+		}
+
+		req, err := http.NewRequest(strings.ToUpper(method), rawurl, nil)
+		if err != nil {
+			return nil, err
+		}
+		if err := m.guard.Allowed(req); err != nil {
+			return nil, err
+		}
+
+		if err = setHeaders(req, headers); err != nil {
+			return nil, err
+		}
+		if err = setAuth(req, auth); err != nil {
+			return nil, err
+		}
+		if err = setBody(req, body, formBody, jsonBody); err != nil {
+			return nil, err
+		}
+
+		res, err := m.cli.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		r := &starhttp.Response{Response: *res}
+		return r.Struct(), nil
+	}
+}
+
+// stringDictEach calls fn with each key & value of a dict of strings
+func stringDictEach(d *starlark.Dict, fn func(key, val string)) error {
+	for _, key := range d.Keys() {
+		keystr, err := starhttp.AsString(key)
+		if err != nil {
+			return err
+		}
+		val, _, err := d.Get(key)
+		if err != nil {
+			return err
+		}
+		if val.Type() != "string" {
+			return fmt.Errorf("expected param value for key '%s' to be a string. got: '%s'", key, val.Type())
+		}
+		valstr, err := starhttp.AsString(val)
+		if err != nil {
+			return err
+		}
+		fn(keystr, valstr)
+	}
+	return nil
+}
+
+func setQueryParams(rawurl *string, params *starlark.Dict) error {
+	if params.Len() == 0 {
+		return nil
+	}
+	u, err := url.Parse(*rawurl)
+	if err != nil {
+		return err
+	}
+	q := u.Query()
+	if err := stringDictEach(params, q.Set); err != nil {
+		return err
+	}
+	u.RawQuery = q.Encode()
+	*rawurl = u.String()
+	return nil
+}
+
+func setAuth(req *http.Request, auth starlark.Tuple) error {
+	if len(auth) == 0 {
+		return nil
+	} else if len(auth) != 2 {
+		return fmt.Errorf("expected two values for auth params tuple")
+	}
+	username, err := starhttp.AsString(auth[0])
+	if err != nil {
+		return fmt.Errorf("parsing auth username string: %s", err.Error())
+	}
+	password, err := starhttp.AsString(auth[1])
+	if err != nil {
+		return fmt.Errorf("parsing auth password string: %s", err.Error())
+	}
+	req.SetBasicAuth(username, password)
+	return nil
+}
+
+func setHeaders(req *http.Request, headers *starlark.Dict) error {
+	return stringDictEach(headers, req.Header.Add)
+}
+
+func setBody(req *http.Request, body starlark.String, formData *starlark.Dict, jsondata starlark.Value) error {
+	if !util.IsEmptyString(body) {
+		uq, err := strconv.Unquote(body.String())
+		if err != nil {
+			return err
+		}
+		req.Body = ioutil.NopCloser(strings.NewReader(uq))
+		return nil
+	}
+
+	if jsondata != nil && jsondata.String() != "" {
+		req.Header.Set("Content-Type", "application/json")
+		v, err := util.Unmarshal(jsondata)
+		if err != nil {
+			return err
+		}
+		data, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		req.Body = ioutil.NopCloser(bytes.NewBuffer(data))
+	}
+
+	if formData != nil && formData.Len() > 0 {
+		if req.Header.Get("Content-Type") == "" {
+			req.Header.Set("Content-Type", "multipart/form-data")
+		}
+		if req.Form == nil {
+			req.Form = url.Values{}
+		}
+		return stringDictEach(formData, req.Form.Add)
+	}
+	return nil
+}
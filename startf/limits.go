@@ -0,0 +1,228 @@
+package startf
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"go.starlark.net/starlark"
+)
+
+var (
+	// ErrStepLimit is returned when a script exceeds its step budget
+	ErrStepLimit = fmt.Errorf("transform exceeded step limit")
+	// ErrTimeout is returned when a script runs longer than its timeout
+	ErrTimeout = fmt.Errorf("transform timed out")
+	// ErrDownloadLimit is returned when a script downloads more data than
+	// allowed
+	ErrDownloadLimit = fmt.Errorf("transform exceeded download limit")
+	// ErrBodySizeLimit is returned when a script produces a body larger than
+	// allowed
+	ErrBodySizeLimit = fmt.Errorf("transform exceeded body size limit")
+)
+
+// Limits caps the resources a script can use. Zero values mean no limit
+type Limits struct {
+	// MaxSteps is the maximum number of execution steps of each starlark
+	// thread a script runs on, as counted by the starlark interpreter
+	MaxSteps uint64
+	// Timeout is the maximum wall-clock duration of script execution
+	Timeout time.Duration
+	// MaxDownloadBytes is the maximum number of bytes read from http
+	// responses during execution
+	MaxDownloadBytes int64
+	// MaxBodySize is the maximum size in bytes of the body a script produces
+	MaxBodySize int64
+}
+
+// SetLimits assigns resource limits for script execution
+func SetLimits(limits Limits) func(o *ExecOpts) {
+	return func(o *ExecOpts) {
+		o.Limits = limits
+	}
+}
+
+// budget tracks resource use of a single execution. The first limit a
+// script trips is recorded & cancels every thread running under the budget,
+// stopping the script at its next step
+type budget struct {
+	limits Limits
+
+	lk         sync.Mutex
+	threads    []*starlark.Thread
+	downloaded int64
+	err        error
+}
+
+func newBudget(limits Limits) *budget {
+	return &budget{limits: limits}
+}
+
+// thread prepares a starlark thread to run under the budget, capping its
+// execution steps
+func (b *budget) thread(thread *starlark.Thread) *starlark.Thread {
+	thread.SetMaxExecutionSteps(b.limits.MaxSteps)
+
+	b.lk.Lock()
+	defer b.lk.Unlock()
+	if b.err != nil {
+		thread.Cancel(b.err.Error())
+	}
+	b.threads = append(b.threads, thread)
+	return thread
+}
+
+// Err returns the limit error the budget has tripped, if any. The
+// interpreter cancels threads that run out of steps, which trips the step
+// limit
+func (b *budget) Err() error {
+	b.lk.Lock()
+	defer b.lk.Unlock()
+	if limit := b.limits.MaxSteps; b.err == nil && limit > 0 {
+		for _, thread := range b.threads {
+			if thread.ExecutionSteps() >= limit {
+				b.err = fmt.Errorf("%w of %d steps", ErrStepLimit, limit)
+				break
+			}
+		}
+	}
+	return b.err
+}
+
+// trip records a limit error, keeping the first one. Tripping cancels all
+// threads running under the budget
+func (b *budget) trip(err error) error {
+	b.lk.Lock()
+	defer b.lk.Unlock()
+	if b.err == nil {
+		b.err = err
+		for _, thread := range b.threads {
+			thread.Cancel(err.Error())
+		}
+	}
+	return b.err
+}
+
+// download counts bytes read from the network
+func (b *budget) download(n int) error {
+	b.lk.Lock()
+	b.downloaded += int64(n)
+	downloaded := b.downloaded
+	b.lk.Unlock()
+
+	if limit := b.limits.MaxDownloadBytes; limit > 0 && downloaded > limit {
+		return b.trip(fmt.Errorf("%w of %d bytes", ErrDownloadLimit, limit))
+	}
+	return b.Err()
+}
+
+// bodySize checks the size of a body as it's written against the body size
+// limit
+func (b *budget) bodySize(size int64) error {
+	if limit := b.limits.MaxBodySize; limit > 0 && size > limit {
+		return b.trip(fmt.Errorf("%w of %d bytes", ErrBodySizeLimit, limit))
+	}
+	return nil
+}
+
+// bodyWriter wraps a writer, checking the body size limit before each write
+// so an oversized body is never fully written
+func (b *budget) bodyWriter(w io.Writer) io.Writer {
+	return &sizeCheckedWriter{w: w, check: b.bodySize}
+}
+
+type sizeCheckedWriter struct {
+	w     io.Writer
+	size  int64
+	check func(size int64) error
+}
+
+// Write implements io.Writer
+func (w *sizeCheckedWriter) Write(p []byte) (int, error) {
+	if err := w.check(w.size + int64(len(p))); err != nil {
+		return 0, err
+	}
+	n, err := w.w.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+// watch trips the budget when ctx is done or the timeout elapses, returning
+// a context that ends with execution. Tripping cancels running threads, so
+// scripts stop even in loops that never call into go. Call stop when
+// execution is complete
+func (b *budget) watch(ctx context.Context) (execCtx context.Context, stop func()) {
+	cancel := func() {}
+	if b.limits.Timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, b.limits.Timeout)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		select {
+		case <-ctx.Done():
+			if ctx.Err() == context.DeadlineExceeded && b.limits.Timeout > 0 {
+				b.trip(fmt.Errorf("%w after %s", ErrTimeout, b.limits.Timeout))
+			} else {
+				b.trip(fmt.Errorf("transform cancelled: %w", ctx.Err()))
+			}
+		case <-done:
+		}
+	}()
+
+	return ctx, func() {
+		close(done)
+		cancel()
+	}
+}
+
+// httpClient creates an http client that stops requests when the budget is
+// tripped & counts response bytes against the download limit
+func (b *budget) httpClient(ctx context.Context, base *http.Client) *http.Client {
+	cli := *base
+	transport := cli.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	cli.Transport = &budgetTransport{ctx: ctx, b: b, base: transport}
+	return &cli
+}
+
+type budgetTransport struct {
+	ctx  context.Context
+	b    *budget
+	base http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *budgetTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := t.b.Err(); err != nil {
+		return nil, err
+	}
+	res, err := t.base.RoundTrip(req.WithContext(t.ctx))
+	if err != nil {
+		if limitErr := t.b.Err(); limitErr != nil {
+			return nil, limitErr
+		}
+		return nil, err
+	}
+	res.Body = &countedBody{ReadCloser: res.Body, b: t.b}
+	return res, nil
+}
+
+type countedBody struct {
+	io.ReadCloser
+	b *budget
+}
+
+// Read implements io.Reader
+func (r *countedBody) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	if limitErr := r.b.download(n); limitErr != nil {
+		return n, limitErr
+	}
+	return n, err
+}
@@ -0,0 +1,157 @@
+package startf
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/qfs"
+	"go.starlark.net/starlark"
+)
+
+func execLimited(ctx context.Context, script string, limits Limits, opts ...func(o *ExecOpts)) (*dataset.Dataset, error) {
+	ds := &dataset.Dataset{Transform: &dataset.Transform{}}
+	ds.Transform.SetScriptFile(qfs.NewMemfileBytes("tf.star", []byte(script)))
+	opts = append(opts, SetLimits(limits))
+	err := ExecScript(ctx, ds, nil, opts...)
+	return ds, err
+}
+
+func TestStepLimit(t *testing.T) {
+	ctx := context.Background()
+	script := `
+def transform(ds, ctx):
+  total = 0
+  for i in range(1000):
+    total += i
+  ds.set_body([total])`
+
+	if _, err := execLimited(ctx, script, Limits{MaxSteps: 500}); !errors.Is(err, ErrStepLimit) {
+		t.Errorf("expected step limit error. got: %v", err)
+	}
+
+	// the interpreter counts each instruction as a step, the loop takes about
+	// 10,000 steps
+	ds, err := execLimited(ctx, script, Limits{MaxSteps: 50000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, err := ioutil.ReadAll(ds.BodyFile())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(body) != "[499500]" {
+		t.Errorf("body mismatch. got: %s", body)
+	}
+
+	nested := `
+def transform(ds, ctx):
+  for i in range(1000000):
+    for j in range(1000000):
+      pass`
+	if _, err := execLimited(ctx, nested, Limits{MaxSteps: 10000}); !errors.Is(err, ErrStepLimit) {
+		t.Errorf("expected loops that never call a builtin to count steps. got: %v", err)
+	}
+}
+
+func TestTimeout(t *testing.T) {
+	script := `
+def transform(ds, ctx):
+  for i in range(1000000):
+    for j in range(1000000):
+      pass`
+
+	start := time.Now()
+	_, err := execLimited(context.Background(), script, Limits{Timeout: time.Millisecond * 50})
+	if !errors.Is(err, ErrTimeout) {
+		t.Errorf("expected timeout error. got: %v", err)
+	}
+	if time.Since(start) > time.Second*5 {
+		t.Errorf("expected timeout to stop script promptly. took: %s", time.Since(start))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(time.Millisecond * 50)
+		cancel()
+	}()
+	if _, err := execLimited(ctx, script, Limits{}); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context cancellation to stop script. got: %v", err)
+	}
+}
+
+func TestDownloadLimit(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`["` + strings.Repeat("a", 1024) + `"]`))
+	}))
+	defer s.Close()
+
+	script := `
+load("http.star", "http")
+def download(ctx):
+  return http.get(test_server_url).json()
+def transform(ds, ctx):
+  ds.set_body(ctx.download)`
+	setURL := func(o *ExecOpts) {
+		o.Globals["test_server_url"] = starlark.String(s.URL)
+	}
+
+	if _, err := execLimited(context.Background(), script, Limits{MaxDownloadBytes: 100}, setURL); !errors.Is(err, ErrDownloadLimit) {
+		t.Errorf("expected download limit error. got: %v", err)
+	}
+	if _, err := execLimited(context.Background(), script, Limits{MaxDownloadBytes: 2048}, setURL); err != nil {
+		t.Errorf("expected download under the limit to succeed. got: %s", err)
+	}
+}
+
+func TestBodySizeLimit(t *testing.T) {
+	script := `
+def transform(ds, ctx):
+  ds.set_body([[i, "row"] for i in range(100)])`
+
+	if _, err := execLimited(context.Background(), script, Limits{MaxBodySize: 100}); !errors.Is(err, ErrBodySizeLimit) {
+		t.Errorf("expected body size limit error. got: %v", err)
+	}
+
+	ds, err := execLimited(context.Background(), script, Limits{MaxBodySize: 10000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, err := ioutil.ReadAll(ds.BodyFile())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(body), `[[0,"row"]`) {
+		t.Errorf("expected body to be readable after the size check. got: %s", body)
+	}
+}
+
+func TestStreamedBodySizeLimit(t *testing.T) {
+	script := `
+def transform(ds, ctx):
+  w = ds.body_writer()
+  for i in range(100):
+    w.write([i, "row"])`
+
+	if _, err := execLimited(context.Background(), script, Limits{MaxBodySize: 100}); !errors.Is(err, ErrBodySizeLimit) {
+		t.Errorf("expected body size limit error. got: %v", err)
+	}
+
+	ds, err := execLimited(context.Background(), script, Limits{MaxBodySize: 10000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, err := ioutil.ReadAll(ds.BodyFile())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(body), `[[0,"row"]`) {
+		t.Errorf("expected streamed body to be readable after the size check. got: %s", body)
+	}
+}
@@ -1,11 +1,21 @@
+// Package qri exposes read-only access to a qri repo to starlark
 package qri
 
 import (
+	"bytes"
+	"context"
 	"fmt"
+	"io"
+	"strings"
 	"sync"
 
 	"github.com/qri-io/dataset"
+	"github.com/qri-io/deepdiff"
 	"github.com/qri-io/qri/repo"
+	reporef "github.com/qri-io/qri/repo/ref"
+	"github.com/qri-io/qri/sql/preprocess"
+	skyds "github.com/qri-io/qri/startf/ds"
+	"github.com/qri-io/qri/stats"
 	"go.starlark.net/starlark"
 	"go.starlark.net/starlarkstruct"
 )
@@ -14,6 +24,10 @@ import (
 // in starlark's load() function, eg: load('qri.star', 'qri')
 const ModuleName = "qri.star"
 
+// DefaultHistoryLimit is the number of versions history returns when no limit
+// is given
+const DefaultHistoryLimit = 25
+
 var (
 	once      sync.Once
 	qriModule starlark.StringDict
@@ -24,10 +38,59 @@ func NewModule(repo repo.Repo) *Module {
 	return &Module{repo: repo}
 }
 
-// Module encapsulates state for a qri starlark module
+// DatasetLoader loads a dataset by reference string
+type DatasetLoader func(refstr string) (*dataset.Dataset, error)
+
+// RefResolver resolves a dataset reference string to a version path without
+// loading the dataset
+type RefResolver func(refstr string) (path string, err error)
+
+// SQLService executes SQL queries against datasets, writing results to w in
+// outFormat. paths maps dataset references in the query to the versions to
+// read. *sql.Service satisfies SQLService
+type SQLService interface {
+	ExecAt(ctx context.Context, w io.Writer, outFormat, query string, paths map[string]string) error
+}
+
+// Module encapsulates state for a qri starlark module. All module builtins
+// are read-only, none modify the dataset being transformed or the repo
 type Module struct {
-	repo repo.Repo
-	ds   *dataset.Dataset
+	ctx      context.Context
+	repo     repo.Repo
+	ds       *dataset.Dataset
+	loader   DatasetLoader
+	resolver RefResolver
+	sql      SQLService
+}
+
+// SetContext sets the context builtins run with
+func (m *Module) SetContext(ctx context.Context) {
+	m.ctx = ctx
+}
+
+// SetDatasetLoader configures how the module loads datasets. transforms use
+// the loader to record loaded datasets as dependencies
+func (m *Module) SetDatasetLoader(l DatasetLoader) {
+	m.loader = l
+}
+
+// SetRefResolver configures how the module resolves datasets it doesn't
+// load, like those read by SQL queries. transforms use the resolver to record
+// resolved datasets as dependencies
+func (m *Module) SetRefResolver(r RefResolver) {
+	m.resolver = r
+}
+
+// SetSQLService provides a service for running SQL queries
+func (m *Module) SetSQLService(svc SQLService) {
+	m.sql = svc
+}
+
+func (m *Module) context() context.Context {
+	if m.ctx == nil {
+		return context.Background()
+	}
+	return m.ctx
 }
 
 // Namespace produces this module's exported namespace
@@ -45,17 +108,193 @@ func (m *Module) Struct() *starlarkstruct.Struct {
 // AddAllMethods augments a starlark.StringDict with all qri builtins. Should really only be used during "transform" step
 func (m *Module) AddAllMethods(sd starlark.StringDict) starlark.StringDict {
 	sd["list_datasets"] = starlark.NewBuiltin("list_datasets", m.ListDatasets)
+	sd["load_dataset"] = starlark.NewBuiltin("load_dataset", m.LoadDataset)
+	sd["get"] = starlark.NewBuiltin("get", m.Get)
+	sd["sql"] = starlark.NewBuiltin("sql", m.SQL)
+	sd["history"] = starlark.NewBuiltin("history", m.History)
+	sd["diff"] = starlark.NewBuiltin("diff", m.Diff)
+	sd["stats"] = starlark.NewBuiltin("stats", m.Stats)
 	return sd
 }
 
+// loadDataset loads a dataset by reference with the configured loader
+func (m *Module) loadDataset(refstr string) (*dataset.Dataset, error) {
+	if m.loader == nil {
+		return nil, fmt.Errorf("no qri repo available to load dataset: %s", refstr)
+	}
+	return m.loader(refstr)
+}
+
+// resolveRef resolves a dataset reference with the configured resolver
+func (m *Module) resolveRef(refstr string) (string, error) {
+	if m.resolver == nil {
+		return "", fmt.Errorf("no qri repo available to resolve dataset: %s", refstr)
+	}
+	return m.resolver(refstr)
+}
+
+// LoadDataset loads a dataset by reference
+func (m *Module) LoadDataset(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var refstr starlark.String
+	if err := starlark.UnpackArgs("load_dataset", args, kwargs, "ref", &refstr); err != nil {
+		return starlark.None, err
+	}
+
+	ds, err := m.loadDataset(refstr.GoString())
+	if err != nil {
+		return starlark.None, err
+	}
+	return skyds.NewDataset(ds, nil).Methods(), nil
+}
+
+// Get returns the value of a dataset at a dot.separated selector, eg:
+// qri.get("me/movies", "meta.title"). The first selector segment names a
+// component. An empty selector returns the dataset document, without body
+func (m *Module) Get(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var refstr, selector starlark.String
+	if err := starlark.UnpackArgs("get", args, kwargs, "ref", &refstr, "selector?", &selector); err != nil {
+		return starlark.None, err
+	}
+
+	ds, err := m.loadDataset(refstr.GoString())
+	if err != nil {
+		return starlark.None, err
+	}
+	v, err := selectValue(ds, selector.GoString())
+	if err != nil {
+		return starlark.None, err
+	}
+	return toStarlark(v)
+}
+
+// SQL runs a SELECT query against datasets, returning a list of dicts, one
+// per result row. Datasets the query reads are resolved once, the query reads
+// the resolved versions
+func (m *Module) SQL(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var query starlark.String
+	if err := starlark.UnpackArgs("sql", args, kwargs, "query", &query); err != nil {
+		return starlark.None, err
+	}
+	if m.sql == nil {
+		return starlark.None, fmt.Errorf("no sql service available to run queries")
+	}
+
+	_, sources, err := preprocess.Query(query.GoString())
+	if err != nil {
+		return starlark.None, err
+	}
+	paths := make(map[string]string, len(sources))
+	for _, refstr := range sources {
+		if paths[refstr], err = m.resolveRef(refstr); err != nil {
+			return starlark.None, err
+		}
+	}
+
+	buf := &bytes.Buffer{}
+	if err := m.sql.ExecAt(m.context(), buf, "json", query.GoString(), paths); err != nil {
+		return starlark.None, err
+	}
+	// queries without results only write a closing bracket
+	if out := strings.TrimSpace(buf.String()); out == "]" || out == "" {
+		return starlark.NewList(nil), nil
+	}
+	return jsonToStarlark(buf.Bytes())
+}
+
+// History lists versions of a dataset, most recent first
+func (m *Module) History(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var refstr starlark.String
+	limit := DefaultHistoryLimit
+	if err := starlark.UnpackArgs("history", args, kwargs, "ref", &refstr, "limit?", &limit); err != nil {
+		return starlark.None, err
+	}
+	if m.repo == nil {
+		return starlark.None, fmt.Errorf("no qri repo available to list history")
+	}
+	book := m.repo.Logbook()
+	if book == nil {
+		return starlark.None, fmt.Errorf("no logbook available to list history")
+	}
+
+	ref, err := repo.ParseDatasetRef(refstr.GoString())
+	if err != nil {
+		return starlark.None, err
+	}
+	if err = repo.CanonicalizeDatasetRef(m.repo, &ref); err != nil {
+		return starlark.None, err
+	}
+	items, err := book.Items(m.context(), reporef.ConvertToDsref(ref), 0, limit)
+	if err != nil {
+		return starlark.None, err
+	}
+	return toStarlark(items)
+}
+
+// Diff compares a component of two datasets, returning a list of deltas.
+// Compares bodies unless a component name is given
+func (m *Module) Diff(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var left, right starlark.String
+	comp := starlark.String("body")
+	if err := starlark.UnpackArgs("diff", args, kwargs, "a", &left, "b", &right, "component?", &comp); err != nil {
+		return starlark.None, err
+	}
+
+	values := make([]interface{}, 2)
+	for i, refstr := range []starlark.String{left, right} {
+		ds, err := m.loadDataset(refstr.GoString())
+		if err != nil {
+			return starlark.None, err
+		}
+		v, err := component(ds, comp.GoString())
+		if err != nil {
+			return starlark.None, err
+		}
+		if values[i], err = generic(v); err != nil {
+			return starlark.None, err
+		}
+	}
+
+	deltas, err := deepdiff.New().Diff(m.context(), values[0], values[1])
+	if err != nil {
+		return starlark.None, err
+	}
+	return toStarlark(deltaValues(deltas))
+}
+
+// Stats calculates statistics of a dataset body, returning a list with one
+// dict of stats per column
+func (m *Module) Stats(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var refstr starlark.String
+	if err := starlark.UnpackArgs("stats", args, kwargs, "ref", &refstr); err != nil {
+		return starlark.None, err
+	}
+
+	ds, err := m.loadDataset(refstr.GoString())
+	if err != nil {
+		return starlark.None, err
+	}
+	r, err := stats.New(nil).JSON(m.context(), ds)
+	if err != nil {
+		return starlark.None, err
+	}
+	buf := &bytes.Buffer{}
+	if _, err := buf.ReadFrom(r); err != nil {
+		return starlark.None, err
+	}
+	return jsonToStarlark(buf.Bytes())
+}
+
 // ListDatasets shows current local datasets
 func (m *Module) ListDatasets(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
 	if m.repo == nil {
 		return starlark.None, fmt.Errorf("no qri repo available to list datasets")
 	}
 
-	// TODO (b5) - remove hardcoded limit
-	refs, err := m.repo.References(0, 100000)
+	num, err := m.repo.RefCount()
+	if err != nil {
+		return starlark.None, err
+	}
+	refs, err := m.repo.References(0, num)
 	if err != nil {
 		return starlark.None, fmt.Errorf("error getting dataset list: %s", err.Error())
 	}
@@ -1,11 +1,16 @@
 package qri
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"testing"
 
 	"github.com/qri-io/dataset"
+	"github.com/qri-io/qfs"
+	"github.com/qri-io/starlib/testdata"
 	"go.starlark.net/starlark"
+	"go.starlark.net/starlarktest"
 )
 
 func TestNewModule(t *testing.T) {
@@ -39,3 +44,153 @@ func newLoader(ds *dataset.Dataset) func(thread *starlark.Thread, module string)
 		return nil, fmt.Errorf("invalid module")
 	}
 }
+
+type fakeSQLService struct {
+	query string
+	paths map[string]string
+	out   string
+}
+
+func (s *fakeSQLService) ExecAt(ctx context.Context, w io.Writer, outFormat, query string, paths map[string]string) error {
+	s.query = query
+	s.paths = paths
+	_, err := io.WriteString(w, s.out)
+	return err
+}
+
+func testDataset(title, body string) *dataset.Dataset {
+	ds := &dataset.Dataset{
+		Meta: &dataset.Meta{Title: title},
+		Structure: &dataset.Structure{
+			Format: "json",
+			Schema: dataset.BaseSchemaArray,
+		},
+	}
+	ds.SetBodyFile(qfs.NewMemfileBytes("body.json", []byte(body)))
+	return ds
+}
+
+func TestModuleBuiltins(t *testing.T) {
+	bodies := map[string]string{
+		"peer/a": `[["a",1],["b",2]]`,
+		"peer/b": `[["a",1],["b",3]]`,
+	}
+	loaded := []string{}
+	resolved := []string{}
+	m := NewModule(nil)
+	m.SetDatasetLoader(func(refstr string) (*dataset.Dataset, error) {
+		body, ok := bodies[refstr]
+		if !ok {
+			return nil, fmt.Errorf("not found: %s", refstr)
+		}
+		loaded = append(loaded, refstr)
+		return testDataset("dataset "+refstr, body), nil
+	})
+	m.SetRefResolver(func(refstr string) (string, error) {
+		if _, ok := bodies[refstr]; !ok {
+			return "", fmt.Errorf("not found: %s", refstr)
+		}
+		resolved = append(resolved, refstr)
+		return "/mem/" + refstr, nil
+	})
+	svc := &fakeSQLService{out: `[{"name":"a","count":1}` + "\n" + `,{"name":"b","count":2.5}` + "\n]"}
+	m.SetSQLService(svc)
+
+	script := `
+load('assert.star', 'assert')
+load('qri.star', 'qri')
+
+assert.eq(qri.get("peer/a", "meta.title"), "dataset peer/a")
+assert.eq(qri.get("peer/a", "body.1"), ["b", 2])
+assert.eq(qri.get("peer/a")["meta"]["title"], "dataset peer/a")
+def get_missing():
+	qri.get("peer/a", "meta.missing")
+
+def get_unknown():
+	qri.get("peer/a", "nope")
+
+assert.fails(get_missing, "not found")
+assert.fails(get_unknown, "unknown component")
+
+rows = qri.sql("select * from peer/b as b")
+assert.eq(rows[0], {"name": "a", "count": 1})
+assert.eq(str(rows[1]["count"]), "2.5")
+
+deltas = qri.diff("peer/a", "peer/b")
+assert.true(len(deltas) > 0)
+assert.eq(qri.diff("peer/a", "peer/a", "meta"), [
+	{"type": " ", "path": "qri", "value": "md:0"},
+	{"type": " ", "path": "title", "value": "dataset peer/a"},
+])
+changed = [d for d in deltas if "deltas" in d]
+assert.eq(len(changed), 1)
+assert.eq(changed[0]["path"], 1)
+assert.eq([d for d in changed[0]["deltas"] if d["type"] != " "], [
+	{"type": "-", "path": 1, "value": 2},
+	{"type": "+", "path": 1, "value": 3},
+])
+
+st = qri.stats("peer/a")
+assert.eq(len(st), 2)
+assert.eq(st[1]["count"], 2)
+`
+	thread := &starlark.Thread{Load: testLoader(t, m)}
+	if _, err := starlark.ExecFile(thread, "test.star", script, nil); err != nil {
+		if evalErr, ok := err.(*starlark.EvalError); ok {
+			t.Fatal(evalErr.Backtrace())
+		}
+		t.Fatal(err)
+	}
+
+	if svc.query != "select * from peer/b as b" {
+		t.Errorf("unexpected query: %q", svc.query)
+	}
+	// sql resolves queried datasets without loading them, the query reads the
+	// resolved versions
+	if len(resolved) != 1 || resolved[0] != "peer/b" {
+		t.Errorf("expected sql to resolve queried datasets. resolved: %v", resolved)
+	}
+	if svc.paths["peer/b"] != "/mem/peer/b" {
+		t.Errorf("expected query to read resolved versions. got: %v", svc.paths)
+	}
+}
+
+func TestModuleWithoutServices(t *testing.T) {
+	m := NewModule(nil)
+	script := `
+load('assert.star', 'assert')
+load('qri.star', 'qri')
+
+def sql():
+	qri.sql("select * from peer/a as a")
+
+def get():
+	qri.get("peer/a")
+
+def history():
+	qri.history("peer/a")
+
+assert.fails(sql, "no sql service")
+assert.fails(get, "no qri repo")
+assert.fails(history, "no qri repo")
+assert.fails(qri.list_datasets, "no qri repo")
+`
+	thread := &starlark.Thread{Load: testLoader(t, m)}
+	if _, err := starlark.ExecFile(thread, "test.star", script, nil); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func testLoader(t *testing.T, m *Module) func(thread *starlark.Thread, module string) (starlark.StringDict, error) {
+	assertLoader := testdata.NewLoader(nil, "")
+	return func(thread *starlark.Thread, module string) (starlark.StringDict, error) {
+		starlarktest.SetReporter(thread, t)
+		switch module {
+		case ModuleName:
+			return m.Namespace(), nil
+		case "assert.star":
+			return assertLoader(thread, module)
+		}
+		return nil, fmt.Errorf("invalid module")
+	}
+}
@@ -0,0 +1,196 @@
+package qri
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/dataset/dsio"
+	"github.com/qri-io/deepdiff"
+	"github.com/qri-io/starlib/util"
+	"go.starlark.net/starlark"
+)
+
+// toStarlark converts a go value to native starlark values by way of JSON.
+// whole numbers become ints, all other numbers floats
+func toStarlark(v interface{}) (starlark.Value, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return starlark.None, err
+	}
+	return jsonToStarlark(data)
+}
+
+// jsonToStarlark decodes JSON data into native starlark values
+func jsonToStarlark(data []byte) (starlark.Value, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		if err == io.EOF {
+			return starlark.None, nil
+		}
+		return starlark.None, err
+	}
+	return util.Marshal(convertNumbers(v))
+}
+
+// convertNumbers replaces json.Number values with int64 or float64
+func convertNumbers(v interface{}) interface{} {
+	switch x := v.(type) {
+	case json.Number:
+		if i, err := x.Int64(); err == nil {
+			return i
+		}
+		f, _ := x.Float64()
+		return f
+	case []interface{}:
+		for i, val := range x {
+			x[i] = convertNumbers(val)
+		}
+	case map[string]interface{}:
+		for key, val := range x {
+			x[key] = convertNumbers(val)
+		}
+	}
+	return v
+}
+
+// readBody reads the entire body of a dataset into a go value
+func readBody(ds *dataset.Dataset) (interface{}, error) {
+	if ds.BodyFile() == nil {
+		return nil, nil
+	}
+	if ds.Structure == nil {
+		return nil, fmt.Errorf("dataset has no structure")
+	}
+	tlt, err := dsio.GetTopLevelType(ds.Structure)
+	if err != nil {
+		return nil, err
+	}
+	rr, err := dsio.NewEntryReader(ds.Structure, ds.BodyFile())
+	if err != nil {
+		return nil, err
+	}
+	defer rr.Close()
+
+	if tlt == "object" {
+		obj := map[string]interface{}{}
+		err = dsio.EachEntry(rr, func(_ int, ent dsio.Entry, err error) error {
+			if err != nil {
+				return err
+			}
+			obj[ent.Key] = ent.Value
+			return nil
+		})
+		return obj, err
+	}
+
+	arr := []interface{}{}
+	err = dsio.EachEntry(rr, func(_ int, ent dsio.Entry, err error) error {
+		if err != nil {
+			return err
+		}
+		arr = append(arr, ent.Value)
+		return nil
+	})
+	return arr, err
+}
+
+// component returns a dataset component as a go value
+func component(ds *dataset.Dataset, name string) (interface{}, error) {
+	switch name {
+	case "body":
+		return readBody(ds)
+	case "commit":
+		return ds.Commit, nil
+	case "meta":
+		return ds.Meta, nil
+	case "readme":
+		return ds.Readme, nil
+	case "structure":
+		return ds.Structure, nil
+	case "transform":
+		return ds.Transform, nil
+	case "viz":
+		return ds.Viz, nil
+	}
+	return nil, fmt.Errorf("unknown component %q", name)
+}
+
+// selectValue gets the value of a dataset at a dot.separated.selector. the
+// first segment of the selector names a component, further segments select
+// object keys & array indexes within it. an empty selector selects the
+// dataset document, without its body
+func selectValue(ds *dataset.Dataset, selector string) (interface{}, error) {
+	if selector == "" {
+		return generic(ds)
+	}
+	path := strings.Split(selector, ".")
+	comp, err := component(ds, path[0])
+	if err != nil {
+		return nil, err
+	}
+	v, err := generic(comp)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, key := range path[1:] {
+		switch x := v.(type) {
+		case map[string]interface{}:
+			val, ok := x[key]
+			if !ok {
+				return nil, fmt.Errorf("invalid selector %q: %q not found", selector, strings.Join(path[:i+2], "."))
+			}
+			v = val
+		case []interface{}:
+			var idx int
+			if _, err := fmt.Sscanf(key, "%d", &idx); err != nil || idx < 0 || idx >= len(x) {
+				return nil, fmt.Errorf("invalid selector %q: index %q out of range", selector, key)
+			}
+			v = x[idx]
+		default:
+			return nil, fmt.Errorf("invalid selector %q: %q is not an object or array", selector, strings.Join(path[:i+1], "."))
+		}
+	}
+	return v, nil
+}
+
+// deltaValues converts deltas to dicts with "type", "path" & "value" keys.
+// changes within a delta are listed under "deltas", the prior value of
+// updates under "original"
+func deltaValues(deltas deepdiff.Deltas) []interface{} {
+	vals := make([]interface{}, 0, len(deltas))
+	for _, d := range deltas {
+		v := map[string]interface{}{
+			"type":  string(d.Type),
+			"value": d.Value,
+		}
+		if d.Path != nil {
+			v["path"] = d.Path.Value()
+		}
+		if d.SourceValue != nil {
+			v["original"] = d.SourceValue
+		}
+		if len(d.Deltas) > 0 {
+			v["deltas"] = deltaValues(d.Deltas)
+		}
+		vals = append(vals, v)
+	}
+	return vals
+}
+
+// generic converts a value to maps, slices & scalars by way of JSON
+func generic(v interface{}) (interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var g interface{}
+	err = json.Unmarshal(data, &g)
+	return g, err
+}
@@ -3,17 +3,15 @@ package startf
 import (
 	"fmt"
 	"net/http"
-
-	starhttp "github.com/qri-io/starlib/http"
 )
 
 var (
-	httpGuard = &HTTPGuard{}
 	// ErrNtwkDisabled is returned whenever a network call is attempted but h.NetworkEnabled is false
 	ErrNtwkDisabled = fmt.Errorf("network use is disabled. http can only be used during download step")
 )
 
-// HTTPGuard protects network requests, only allowing when network is enabled
+// HTTPGuard protects network requests, only allowing when network is enabled.
+// Each transform execution has its own guard
 type HTTPGuard struct {
 	NetworkEnabled bool
 }
@@ -35,8 +33,3 @@ func (h *HTTPGuard) EnableNtwk() {
 func (h *HTTPGuard) DisableNtwk() {
 	h.NetworkEnabled = false
 }
-
-func init() {
-	// connect httpGuard instance to starlib http guard
-	starhttp.Guard = httpGuard
-}
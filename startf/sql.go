@@ -0,0 +1,267 @@
+package startf
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/qfs"
+	"github.com/qri-io/qri/sql/preprocess"
+)
+
+// SQLSyntax is the syntax of transforms written as a single SQL SELECT
+// statement
+const SQLSyntax = "sql"
+
+// IsSQLTransform returns true if a transform is written in SQL, either by
+// syntax or by the extension of its script
+func IsSQLTransform(tf *dataset.Transform) bool {
+	if tf == nil {
+		return false
+	}
+	if tf.Syntax != "" {
+		return tf.Syntax == SQLSyntax
+	}
+	if f := tf.ScriptFile(); f != nil {
+		return filepath.Ext(f.FileName()) == ".sql"
+	}
+	return filepath.Ext(tf.ScriptPath) == ".sql"
+}
+
+// ExecSQL executes an SQL transform, setting the body of next to the query
+// results: a JSON array with one array of values per row in SELECT order, and
+// the structure to one inferred from the results. Datasets the query reads
+// are recorded as dependencies. SQL transforms require a repo & an SQLService option
+func ExecSQL(ctx context.Context, next, prev *dataset.Dataset, opts ...func(o *ExecOpts)) (err error) {
+	if next.Transform == nil || next.Transform.ScriptFile() == nil {
+		return fmt.Errorf("no script to execute")
+	}
+
+	o := &ExecOpts{}
+	DefaultExecOpts(o)
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.SQLService == nil {
+		return fmt.Errorf("no sql service available to run sql transforms")
+	}
+	if o.MutateFieldCheck != nil {
+		if err := o.MutateFieldCheck("body"); err != nil {
+			return fmt.Errorf("cannot use a sql transform and set the body of a dataset at the same time")
+		}
+		if err := o.MutateFieldCheck("structure"); err != nil {
+			return fmt.Errorf("cannot use a sql transform to set the body of a dataset and manually adjust structure at the same time")
+		}
+	}
+
+	b := newBudget(o.Limits)
+	ctx, stop := b.watch(ctx)
+	defer func() {
+		stop()
+		if limitErr := b.Err(); limitErr != nil {
+			err = limitErr
+		}
+	}()
+
+	next.Transform.Syntax = SQLSyntax
+	next.Transform.SyntaxVersion = Version
+	next.Transform.Resources = nil
+
+	script := next.Transform.ScriptFile()
+	data, err := ioutil.ReadAll(script)
+	if err != nil {
+		return err
+	}
+	// restore consumed script file
+	next.Transform.SetScriptFile(qfs.NewMemfileBytes(script.FileName(), data))
+	query := string(data)
+
+	t := &transform{
+		ctx:    ctx,
+		repo:   o.Repo,
+		next:   next,
+		prev:   prev,
+		stderr: o.ErrWriter,
+		budget: b,
+	}
+	t.print("🤖  running sql transform...\n")
+
+	_, sources, err := preprocess.Query(query)
+	if err != nil {
+		return err
+	}
+	// resolve sources once, the query reads the versions recorded as
+	// dependencies
+	paths := make(map[string]string, len(sources))
+	for _, refstr := range sources {
+		if paths[refstr], err = t.resolveDependency(refstr); err != nil {
+			return err
+		}
+	}
+
+	buf := &bytes.Buffer{}
+	if err := o.SQLService.ExecAt(ctx, b.bodyWriter(buf), "json", query, paths); err != nil {
+		return err
+	}
+	body, st, err := sqlResultBody(buf.Bytes())
+	if err != nil {
+		return err
+	}
+	if err := b.bodySize(int64(len(body))); err != nil {
+		return err
+	}
+
+	next.Structure = st
+	next.SetBodyFile(qfs.NewMemfileBytes("body.json", body))
+	return nil
+}
+
+// sqlResultBody normalizes SQL JSON output into a tabular body: an array of
+// rows, each an array of values in SELECT order, inferring a structure from
+// result rows
+func sqlResultBody(data []byte) ([]byte, *dataset.Structure, error) {
+	// queries without results only write a closing bracket
+	if trimmed := bytes.TrimSpace(data); len(trimmed) == 0 || bytes.Equal(trimmed, []byte("]")) {
+		data = []byte("[]")
+	}
+
+	cols, records, err := decodeSQLRows(data)
+	if err != nil {
+		return nil, nil, fmt.Errorf("reading sql results: %w", err)
+	}
+	rows := make([][]interface{}, len(records))
+	for i, rec := range records {
+		row := make([]interface{}, len(cols))
+		for j, col := range cols {
+			row[j] = rec[col]
+		}
+		rows[i] = row
+	}
+	body, err := json.Marshal(rows)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	st := &dataset.Structure{
+		Format: "json",
+		Schema: map[string]interface{}{
+			"type": "array",
+			"items": map[string]interface{}{
+				"type":  "array",
+				"items": inferColumnTypes(cols, rows),
+			},
+		},
+	}
+	return body, st, nil
+}
+
+// decodeSQLRows reads a JSON array of row objects, returning column names in
+// the order they first appear, which is the order of the SELECT statement
+func decodeSQLRows(data []byte) (cols []string, rows []map[string]interface{}, err error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := expectDelim(dec, '['); err != nil {
+		return nil, nil, err
+	}
+
+	seen := map[string]bool{}
+	cols = []string{}
+	rows = []map[string]interface{}{}
+	for dec.More() {
+		if err := expectDelim(dec, '{'); err != nil {
+			return nil, nil, err
+		}
+		row := map[string]interface{}{}
+		for dec.More() {
+			t, err := dec.Token()
+			if err != nil {
+				return nil, nil, err
+			}
+			col, ok := t.(string)
+			if !ok {
+				return nil, nil, fmt.Errorf("expected a column name. got: %v", t)
+			}
+			var val interface{}
+			if err := dec.Decode(&val); err != nil {
+				return nil, nil, err
+			}
+			if !seen[col] {
+				seen[col] = true
+				cols = append(cols, col)
+			}
+			row[col] = val
+		}
+		if err := expectDelim(dec, '}'); err != nil {
+			return nil, nil, err
+		}
+		rows = append(rows, row)
+	}
+	if err := expectDelim(dec, ']'); err != nil {
+		return nil, nil, err
+	}
+	return cols, rows, nil
+}
+
+func expectDelim(dec *json.Decoder, delim json.Delim) error {
+	t, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if t != delim {
+		return fmt.Errorf("expected '%s'. got: %v", delim, t)
+	}
+	return nil
+}
+
+// inferColumnTypes builds json schema items for tabular rows, one per column,
+// typing each column by its first non-null value. Integer columns with
+// non-integer numbers in later rows are widened to number
+func inferColumnTypes(cols []string, rows [][]interface{}) []interface{} {
+	types := make([]string, len(cols))
+	for _, row := range rows {
+		for i, val := range row {
+			vt := jsonSchemaType(val)
+			switch t := types[i]; {
+			case t == "" || t == "null":
+				types[i] = vt
+			case t == "integer" && vt == "number":
+				types[i] = "number"
+			}
+		}
+	}
+
+	items := make([]interface{}, len(cols))
+	for i, col := range cols {
+		t := types[i]
+		if t == "" {
+			t = "null"
+		}
+		items[i] = map[string]interface{}{"title": col, "type": t}
+	}
+	return items
+}
+
+func jsonSchemaType(v interface{}) string {
+	switch x := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case json.Number:
+		if _, err := x.Int64(); err == nil {
+			return "integer"
+		}
+		return "number"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return "string"
+}
@@ -0,0 +1,162 @@
+package startf
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/qfs"
+	"github.com/qri-io/qri/repo"
+)
+
+type fakeSQLService struct {
+	query  string
+	paths  map[string]string
+	output string
+}
+
+func (svc *fakeSQLService) ExecAt(ctx context.Context, w io.Writer, outFormat, query string, paths map[string]string) error {
+	svc.query = query
+	svc.paths = paths
+	_, err := fmt.Fprint(w, svc.output)
+	return err
+}
+
+func TestIsSQLTransform(t *testing.T) {
+	cases := []struct {
+		tf     *dataset.Transform
+		expect bool
+	}{
+		{nil, false},
+		{&dataset.Transform{Syntax: "starlark"}, false},
+		{&dataset.Transform{Syntax: "sql"}, true},
+		{&dataset.Transform{ScriptPath: "/path/to/transform.sql"}, true},
+		{&dataset.Transform{ScriptPath: "/path/to/transform.star"}, false},
+	}
+	for i, c := range cases {
+		if got := IsSQLTransform(c.tf); got != c.expect {
+			t.Errorf("case %d: expected %t. got: %t", i, c.expect, got)
+		}
+	}
+}
+
+func TestExecSQL(t *testing.T) {
+	svc := &fakeSQLService{output: `[{"title":"Spectre","duration":148,"rating":7.5}
+,{"title":"Avatar","duration":178,"rating":null}
+]`}
+	query := "SELECT 1 AS one"
+	ds := &dataset.Dataset{Transform: &dataset.Transform{}}
+	ds.Transform.SetScriptFile(qfs.NewMemfileBytes("transform.sql", []byte(query)))
+
+	if err := ExecSQL(context.Background(), ds, nil); err == nil {
+		t.Error("expected executing without an sql service to error")
+	}
+	if err := ExecSQL(context.Background(), ds, nil, SetSQLService(svc)); err != nil {
+		t.Fatal(err)
+	}
+
+	if svc.query != query {
+		t.Errorf("query mismatch. expected: %q, got: %q", query, svc.query)
+	}
+	if ds.Transform.Syntax != SQLSyntax {
+		t.Errorf("expected transform syntax to be %q. got: %q", SQLSyntax, ds.Transform.Syntax)
+	}
+	script, err := ioutil.ReadAll(ds.Transform.ScriptFile())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(script) != query {
+		t.Errorf("expected script file to be restored. got: %q", script)
+	}
+
+	body, err := ioutil.ReadAll(ds.BodyFile())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// rows keep the column order of the query
+	expectBody := `[["Spectre",148,7.5],["Avatar",178,null]]`
+	if diff := cmp.Diff(expectBody, string(body)); diff != "" {
+		t.Errorf("body (-want +got):\n%s", diff)
+	}
+
+	expectSchema := map[string]interface{}{
+		"type": "array",
+		"items": map[string]interface{}{
+			"type": "array",
+			"items": []interface{}{
+				map[string]interface{}{"title": "title", "type": "string"},
+				map[string]interface{}{"title": "duration", "type": "integer"},
+				map[string]interface{}{"title": "rating", "type": "number"},
+			},
+		},
+	}
+	if diff := cmp.Diff(expectSchema, ds.Structure.Schema); diff != "" {
+		t.Errorf("schema (-want +got):\n%s", diff)
+	}
+}
+
+func TestInferColumnTypes(t *testing.T) {
+	_, st, err := sqlResultBody([]byte(`[{"a":1,"b":null,"c":1}
+,{"a":2.5,"b":2,"c":"x"}
+]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := []interface{}{
+		map[string]interface{}{"title": "a", "type": "number"},
+		map[string]interface{}{"title": "b", "type": "integer"},
+		map[string]interface{}{"title": "c", "type": "integer"},
+	}
+	items := st.Schema["items"].(map[string]interface{})["items"]
+	if diff := cmp.Diff(expect, items); diff != "" {
+		t.Errorf("column types (-want +got):\n%s", diff)
+	}
+}
+
+func TestExecSQLNoResults(t *testing.T) {
+	ds := &dataset.Dataset{Transform: &dataset.Transform{}}
+	ds.Transform.SetScriptFile(qfs.NewMemfileBytes("transform.sql", []byte("SELECT 1 AS one")))
+	if err := ExecSQL(context.Background(), ds, nil, SetSQLService(&fakeSQLService{output: "]"})); err != nil {
+		t.Fatal(err)
+	}
+	body, err := ioutil.ReadAll(ds.BodyFile())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(body) != "[]" {
+		t.Errorf("expected an empty body. got: %s", body)
+	}
+}
+
+func TestExecSQLDependencies(t *testing.T) {
+	r := testRepo(t)
+	query := "SELECT m.title FROM peer/movies AS m"
+	ds := &dataset.Dataset{Transform: &dataset.Transform{}}
+	ds.Transform.SetScriptFile(qfs.NewMemfileBytes("transform.sql", []byte(query)))
+
+	svc := &fakeSQLService{output: `[{"m.title":"Spectre"}]`}
+	err := ExecSQL(context.Background(), ds, nil, AddQriRepo(r), SetSQLService(svc))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(ds.Transform.Resources) != 1 {
+		t.Fatalf("expected 1 recorded dependency. got: %v", ds.Transform.Resources)
+	}
+	for path, res := range ds.Transform.Resources {
+		ref, err := repo.ParseDatasetRef(res.Path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ref.AliasString() != "peer/movies" || ref.Path != path {
+			t.Errorf("expected a peer/movies dependency at %s. got: %s", path, res.Path)
+		}
+		if svc.paths["peer/movies"] != path {
+			t.Errorf("expected the query to read the recorded version %s. got: %v", path, svc.paths)
+		}
+	}
+}
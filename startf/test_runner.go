@@ -0,0 +1,486 @@
+package startf
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/qfs"
+	skyctx "github.com/qri-io/qri/startf/context"
+	skyds "github.com/qri-io/qri/startf/ds"
+	skyqri "github.com/qri-io/qri/startf/qri"
+	"github.com/qri-io/starlib/util"
+	"go.starlark.net/starlark"
+	"go.starlark.net/starlarkstruct"
+	"go.starlark.net/syntax"
+)
+
+// TestFuncPrefix is the name prefix of test functions in a transform test
+// script
+const TestFuncPrefix = "test_"
+
+// ErrNoTests is returned when a transform test script defines no test
+// functions
+var ErrNoTests = fmt.Errorf("no test functions found")
+
+// TestScriptPath returns the path of the companion test script for a
+// transform script, eg: transform.star -> transform_test.star
+func TestScriptPath(scriptPath string) string {
+	ext := filepath.Ext(scriptPath)
+	return strings.TrimSuffix(scriptPath, ext) + "_test" + ext
+}
+
+// TestResult is the outcome of a single transform test function
+type TestResult struct {
+	Name   string `json:"name"`
+	Passed bool   `json:"passed"`
+	// Error describes why a test failed
+	Error string `json:"error,omitempty"`
+	// Output is anything the test printed
+	Output   string        `json:"output,omitempty"`
+	Duration time.Duration `json:"duration"`
+}
+
+// TestReport is the outcome of running all test functions of a transform
+// test script
+type TestReport struct {
+	Script     string        `json:"script"`
+	TestScript string        `json:"testScript"`
+	Passed     int           `json:"passed"`
+	Failed     int           `json:"failed"`
+	Duration   time.Duration `json:"duration"`
+	Tests      []TestResult  `json:"tests"`
+}
+
+// RunTests runs each test_ function a test script defines against a
+// transform script, in name order. Test scripts can call any function the
+// transform script defines, and are given assertion, fixture & mock
+// context builtins. Network access is always disabled, including in
+// functions that normally allow it. Fixture paths are relative to the test
+// script
+func RunTests(ctx context.Context, scriptPath, testPath string, opts ...func(o *ExecOpts)) (*TestReport, error) {
+	o := &ExecOpts{}
+	DefaultExecOpts(o)
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	b := newBudget(o.Limits)
+	ctx, stop := b.watch(ctx)
+	defer stop()
+
+	r := &testRunner{
+		dir:      filepath.Dir(testPath),
+		fixtures: map[starlark.Value]*dataset.Dataset{},
+		t: &transform{
+			ctx:          ctx,
+			repo:         o.Repo,
+			skyqri:       skyqri.NewModule(o.Repo),
+			stderr:       ioutil.Discard,
+			moduleLoader: o.ModuleLoader,
+			// the guard's network is never enabled while testing
+			httpGuard: &HTTPGuard{},
+			budget:    b,
+		},
+	}
+	r.t.skyqri.SetContext(ctx)
+	r.t.skyqri.SetSQLService(o.SQLService)
+	r.t.skyqri.SetDatasetLoader(func(refstr string) (*dataset.Dataset, error) {
+		return r.t.loadDataset(ctx, refstr)
+	})
+	r.t.skyqri.SetRefResolver(r.t.resolveDependency)
+
+	predeclared := starlark.StringDict{
+		"error": starlark.NewBuiltin("error", Error),
+	}
+	for key, val := range o.Globals {
+		predeclared[key] = val
+	}
+	for key, val := range r.t.locals() {
+		predeclared[key] = val
+	}
+
+	var err error
+	if r.t.globals, err = r.exec(scriptPath, predeclared, o); err != nil {
+		return nil, err
+	}
+
+	// test scripts see everything the transform script defines
+	for key, val := range r.t.globals {
+		predeclared[key] = val
+	}
+	for key, val := range r.builtins() {
+		predeclared[key] = val
+	}
+	tests, err := r.exec(testPath, predeclared, o)
+	if err != nil {
+		return nil, err
+	}
+
+	names := []string{}
+	for name, val := range tests {
+		if _, ok := val.(*starlark.Function); ok && strings.HasPrefix(name, TestFuncPrefix) {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("%w in %s", ErrNoTests, testPath)
+	}
+	sort.Strings(names)
+
+	report := &TestReport{
+		Script:     scriptPath,
+		TestScript: testPath,
+		Tests:      make([]TestResult, 0, len(names)),
+	}
+	start := time.Now()
+	for _, name := range names {
+		res := r.runTest(name, tests[name].(*starlark.Function))
+		if res.Passed {
+			report.Passed++
+		} else {
+			report.Failed++
+		}
+		report.Tests = append(report.Tests, res)
+	}
+	report.Duration = time.Since(start)
+
+	if limitErr := b.Err(); limitErr != nil {
+		return report, limitErr
+	}
+	return report, nil
+}
+
+// testRunner holds state for running transform tests
+type testRunner struct {
+	t   *transform
+	dir string
+	// fixtures maps dataset values created by fixture() to the datasets they
+	// were loaded from
+	fixtures map[starlark.Value]*dataset.Dataset
+}
+
+// exec executes a script file, returning its globals
+func (r *testRunner) exec(path string, predeclared starlark.StringDict, o *ExecOpts) (starlark.StringDict, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	thread := r.t.budget.thread(&starlark.Thread{Load: r.t.ModuleLoader})
+	prog, err := compile(path, data, predeclared, o)
+	if err != nil {
+		return nil, err
+	}
+	globals, err := prog.Init(thread, predeclared)
+	if err != nil {
+		if evalErr, ok := err.(*starlark.EvalError); ok {
+			return nil, fmt.Errorf(evalErr.Backtrace())
+		}
+		return nil, err
+	}
+	globals.Freeze()
+	return globals, nil
+}
+
+// runTest calls a single test function, capturing printed output
+func (r *testRunner) runTest(name string, fn *starlark.Function) TestResult {
+	output := &bytes.Buffer{}
+	thread := r.t.budget.thread(&starlark.Thread{
+		Name: name,
+		Load: r.t.ModuleLoader,
+		Print: func(thread *starlark.Thread, msg string) {
+			output.WriteString(msg + "\n")
+		},
+	})
+
+	start := time.Now()
+	_, err := starlark.Call(thread, fn, nil, nil)
+	res := TestResult{
+		Name:     name,
+		Passed:   err == nil,
+		Output:   output.String(),
+		Duration: time.Since(start),
+	}
+	if evalErr, ok := err.(*starlark.EvalError); ok {
+		res.Error = evalErr.Backtrace()
+	} else if err != nil {
+		res.Error = err.Error()
+	}
+	return res
+}
+
+// builtins are the values test scripts are given in addition to the globals
+// of the transform script
+func (r *testRunner) builtins() starlark.StringDict {
+	return starlark.StringDict{
+		"assert": starlarkstruct.FromStringDict(starlark.String("assert"), starlark.StringDict{
+			"eq":       starlark.NewBuiltin("eq", assertEq),
+			"ne":       starlark.NewBuiltin("ne", assertNe),
+			"true":     starlark.NewBuiltin("true", assertTrue),
+			"false":    starlark.NewBuiltin("false", assertFalse),
+			"contains": starlark.NewBuiltin("contains", assertContains),
+			"fails":    starlark.NewBuiltin("fails", assertFails),
+		}),
+		"fixture":       starlark.NewBuiltin("fixture", r.Fixture),
+		"mock_ctx":      starlark.NewBuiltin("mock_ctx", MockContext),
+		"run_transform": starlark.NewBuiltin("run_transform", r.RunTransform),
+	}
+}
+
+// Fixture loads a read-only dataset from a JSON dataset document, for use as
+// the previous version of a dataset. An inline body is converted to a JSON
+// body file
+func (r *testRunner) Fixture(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var path starlark.String
+	if err := starlark.UnpackArgs("fixture", args, kwargs, "path", &path); err != nil {
+		return starlark.None, err
+	}
+
+	p := path.GoString()
+	if !filepath.IsAbs(p) {
+		p = filepath.Join(r.dir, p)
+	}
+	ds, err := loadFixture(p)
+	if err != nil {
+		return starlark.None, err
+	}
+
+	v := skyds.NewDataset(ds, nil).Methods()
+	r.fixtures[v] = ds
+	return v, nil
+}
+
+// RunTransform calls the transform function of the script under test,
+// returning the resulting dataset. prev must be a fixture, ctx defaults to an
+// empty mock context
+func (r *testRunner) RunTransform(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var prevx, ctxx starlark.Value
+	if err := starlark.UnpackArgs("run_transform", args, kwargs, "prev?", &prevx, "ctx?", &ctxx); err != nil {
+		return starlark.None, err
+	}
+
+	fn, err := r.t.globalFunc("transform")
+	if err == ErrNotDefined {
+		return starlark.None, fmt.Errorf("transform script doesn't define a transform function")
+	} else if err != nil {
+		return starlark.None, err
+	}
+
+	prev := &dataset.Dataset{}
+	if prevx != nil && prevx != starlark.None {
+		ds, ok := r.fixtures[prevx]
+		if !ok {
+			return starlark.None, fmt.Errorf("run_transform: prev must be a dataset created by fixture")
+		}
+		prev = ds
+	}
+	if ctxx == nil || ctxx == starlark.None {
+		ctxx = skyctx.NewContext(nil, nil).Struct()
+	}
+
+	d := skyds.NewDataset(prev, nil)
+	d.SetMutable(&dataset.Dataset{})
+	d.SetBodySizeCheck(r.t.budget.bodySize)
+	if _, err := starlark.Call(thread, fn, starlark.Tuple{d.Methods(), ctxx}, nil); err != nil {
+		d.DiscardBodyWriter()
+		return starlark.None, err
+	}
+	if err := d.CloseBodyWriter(); err != nil {
+		return starlark.None, err
+	}
+	return d.Methods(), nil
+}
+
+// MockContext creates a transform context with the given config & secrets.
+// download sets the value of ctx.download, standing in for the result of a
+// download function
+func MockContext(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var configx, secretsx, download starlark.Value
+	if err := starlark.UnpackArgs("mock_ctx", args, kwargs, "config?", &configx, "secrets?", &secretsx, "download?", &download); err != nil {
+		return starlark.None, err
+	}
+
+	config, err := mockContextMap("config", configx)
+	if err != nil {
+		return starlark.None, err
+	}
+	secrets, err := mockContextMap("secrets", secretsx)
+	if err != nil {
+		return starlark.None, err
+	}
+
+	c := skyctx.NewContext(config, secrets)
+	if download != nil {
+		c.SetResult("download", download)
+	}
+	return c.Struct(), nil
+}
+
+// mockContextMap converts an optional starlark dict to a go map
+func mockContextMap(name string, v starlark.Value) (map[string]interface{}, error) {
+	if v == nil || v == starlark.None {
+		return nil, nil
+	}
+	val, err := util.Unmarshal(v)
+	if err != nil {
+		return nil, err
+	}
+	m, ok := val.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("mock_ctx: %s must be a dict", name)
+	}
+	return m, nil
+}
+
+// loadFixture reads a dataset document from a JSON file
+func loadFixture(path string) (*dataset.Dataset, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	ds := &dataset.Dataset{}
+	if err := json.Unmarshal(data, ds); err != nil {
+		return nil, fmt.Errorf("reading fixture %s: %w", path, err)
+	}
+	if ds.Body == nil {
+		return ds, nil
+	}
+
+	body, err := json.Marshal(ds.Body)
+	if err != nil {
+		return nil, err
+	}
+	st := &dataset.Structure{Format: "json", Schema: dataset.BaseSchemaArray}
+	if _, ok := ds.Body.(map[string]interface{}); ok {
+		st.Schema = dataset.BaseSchemaObject
+	}
+	if ds.Structure != nil && ds.Structure.Schema != nil {
+		st.Schema = ds.Structure.Schema
+	}
+	ds.Structure = st
+	ds.Body = nil
+	ds.SetBodyFile(qfs.NewMemfileBytes("body.json", body))
+	return ds, nil
+}
+
+// assertMsg formats an assertion failure, prefixed by an optional message
+func assertMsg(msg starlark.String, format string, args ...interface{}) error {
+	err := fmt.Sprintf(format, args...)
+	if msg != "" {
+		err = msg.GoString() + ": " + err
+	}
+	return fmt.Errorf("%s", err)
+}
+
+func assertEq(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var (
+		a, b starlark.Value
+		msg  starlark.String
+	)
+	if err := starlark.UnpackArgs("eq", args, kwargs, "a", &a, "b", &b, "msg?", &msg); err != nil {
+		return starlark.None, err
+	}
+	eq, err := starlark.Equal(a, b)
+	if err != nil {
+		return starlark.None, err
+	}
+	if !eq {
+		return starlark.None, assertMsg(msg, "%s != %s", a, b)
+	}
+	return starlark.None, nil
+}
+
+func assertNe(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var (
+		a, b starlark.Value
+		msg  starlark.String
+	)
+	if err := starlark.UnpackArgs("ne", args, kwargs, "a", &a, "b", &b, "msg?", &msg); err != nil {
+		return starlark.None, err
+	}
+	eq, err := starlark.Equal(a, b)
+	if err != nil {
+		return starlark.None, err
+	}
+	if eq {
+		return starlark.None, assertMsg(msg, "%s == %s", a, b)
+	}
+	return starlark.None, nil
+}
+
+func assertTrue(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var (
+		cond starlark.Value
+		msg  starlark.String
+	)
+	if err := starlark.UnpackArgs("true", args, kwargs, "cond", &cond, "msg?", &msg); err != nil {
+		return starlark.None, err
+	}
+	if !cond.Truth() {
+		return starlark.None, assertMsg(msg, "expected %s to be true", cond)
+	}
+	return starlark.None, nil
+}
+
+func assertFalse(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var (
+		cond starlark.Value
+		msg  starlark.String
+	)
+	if err := starlark.UnpackArgs("false", args, kwargs, "cond", &cond, "msg?", &msg); err != nil {
+		return starlark.None, err
+	}
+	if cond.Truth() {
+		return starlark.None, assertMsg(msg, "expected %s to be false", cond)
+	}
+	return starlark.None, nil
+}
+
+func assertContains(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var (
+		container, x starlark.Value
+		msg          starlark.String
+	)
+	if err := starlark.UnpackArgs("contains", args, kwargs, "container", &container, "x", &x, "msg?", &msg); err != nil {
+		return starlark.None, err
+	}
+	in, err := starlark.Binary(syntax.IN, x, container)
+	if err != nil {
+		return starlark.None, err
+	}
+	if !in.Truth() {
+		return starlark.None, assertMsg(msg, "%s not in %s", x, container)
+	}
+	return starlark.None, nil
+}
+
+// assertFails calls a function, failing unless it errors. If a pattern is
+// given the error must contain it. Returns the error message
+func assertFails(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var (
+		fn      starlark.Callable
+		pattern starlark.String
+	)
+	if err := starlark.UnpackArgs("fails", args, kwargs, "fn", &fn, "pattern?", &pattern); err != nil {
+		return starlark.None, err
+	}
+	_, err := starlark.Call(thread, fn, nil, nil)
+	if err == nil {
+		return starlark.None, fmt.Errorf("expected %s to fail", fn.Name())
+	}
+	msg := err.Error()
+	if evalErr, ok := err.(*starlark.EvalError); ok {
+		msg = evalErr.Msg
+	}
+	if !strings.Contains(msg, pattern.GoString()) {
+		return starlark.None, fmt.Errorf("expected %s to fail with %q. got: %s", fn.Name(), pattern.GoString(), msg)
+	}
+	return starlark.String(msg), nil
+}
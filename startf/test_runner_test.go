@@ -0,0 +1,71 @@
+package startf
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTestScriptPath(t *testing.T) {
+	if got := TestScriptPath("dir/transform.star"); got != "dir/transform_test.star" {
+		t.Errorf("expected dir/transform_test.star. got: %s", got)
+	}
+}
+
+func TestRunTests(t *testing.T) {
+	script := "testdata/test_runner/transform.star"
+	report, err := RunTests(context.Background(), script, TestScriptPath(script))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expect := map[string]bool{
+		"test_failing":          false,
+		"test_network_disabled": true,
+		"test_secrets":          true,
+		"test_title_case":       true,
+		"test_transform":        true,
+	}
+	if len(report.Tests) != len(expect) {
+		t.Fatalf("expected %d tests. got: %d", len(expect), len(report.Tests))
+	}
+	for _, res := range report.Tests {
+		if passed, ok := expect[res.Name]; !ok || passed != res.Passed {
+			t.Errorf("%s: expected passed: %t. got: %t, error: %s", res.Name, passed, res.Passed, res.Error)
+		}
+	}
+	if report.Passed != 4 || report.Failed != 1 {
+		t.Errorf("expected 4 passed, 1 failed. got: %d passed, %d failed", report.Passed, report.Failed)
+	}
+
+	failed := report.Tests[0]
+	if failed.Name != "test_failing" {
+		t.Fatalf("expected tests to run in name order. got: %s first", failed.Name)
+	}
+	if !strings.Contains(failed.Error, "numbers: 1 != 2") {
+		t.Errorf("expected failure message. got: %s", failed.Error)
+	}
+	if failed.Output != "about to fail\n" {
+		t.Errorf("expected printed output to be captured. got: %q", failed.Output)
+	}
+}
+
+func TestRunTestsNoTests(t *testing.T) {
+	dir, err := ioutil.TempDir("", "test_runner")
+	if err != nil {
+		t.Fatal(err)
+	}
+	script := filepath.Join(dir, "transform.star")
+	if err := ioutil.WriteFile(script, []byte("def transform(ds, ctx):\n  pass\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(TestScriptPath(script), []byte("x = 1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := RunTests(context.Background(), script, TestScriptPath(script)); !errors.Is(err, ErrNoTests) {
+		t.Errorf("expected no tests error. got: %v", err)
+	}
+}
@@ -8,16 +8,20 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
+	"net/http"
+	"sync"
 
 	"github.com/qri-io/dataset"
 	"github.com/qri-io/qfs"
 	"github.com/qri-io/qri/base/dsfs"
 	"github.com/qri-io/qri/repo"
+	reporef "github.com/qri-io/qri/repo/ref"
 	skyctx "github.com/qri-io/qri/startf/context"
 	skyds "github.com/qri-io/qri/startf/ds"
 	skyqri "github.com/qri-io/qri/startf/qri"
 	"github.com/qri-io/qri/version"
 	"github.com/qri-io/starlib"
+	starhttp "github.com/qri-io/starlib/http"
 	"go.starlark.net/resolve"
 	"go.starlark.net/starlark"
 )
@@ -47,6 +51,14 @@ type ExecOpts struct {
 	ErrWriter io.Writer
 	// starlark module loader function
 	ModuleLoader ModuleLoader
+	// resource limits for script execution
+	Limits Limits
+	// record http requests made during execution into the transform config
+	RecordHTTP bool
+	// serve http requests from a recorded fixture instead of the network
+	ReplayHTTP bool
+	// service for running SQL queries from the 'qri' module
+	SQLService skyqri.SQLService
 }
 
 // AddQriRepo adds a qri repo to execution options, providing scripted access
@@ -89,6 +101,30 @@ func SetSecrets(secrets map[string]string) func(o *ExecOpts) {
 	}
 }
 
+// SetRecordHTTP records http requests & responses made during execution into
+// a fixture stored in the transform config
+func SetRecordHTTP(record bool) func(o *ExecOpts) {
+	return func(o *ExecOpts) {
+		o.RecordHTTP = record
+	}
+}
+
+// SetReplayHTTP serves http requests made during execution from the fixture
+// recorded in the transform config, falling back to the fixture of the
+// previous version
+func SetReplayHTTP(replay bool) func(o *ExecOpts) {
+	return func(o *ExecOpts) {
+		o.ReplayHTTP = replay
+	}
+}
+
+// SetSQLService provides a service for running SQL queries with qri.sql
+func SetSQLService(svc skyqri.SQLService) func(o *ExecOpts) {
+	return func(o *ExecOpts) {
+		o.SQLService = svc
+	}
+}
+
 // DefaultExecOpts applies default options to an ExecOpts pointer
 func DefaultExecOpts(o *ExecOpts) {
 	o.AllowFloat = true
@@ -110,6 +146,10 @@ type transform struct {
 	bodyFile     qfs.File
 	stderr       io.Writer
 	moduleLoader ModuleLoader
+	httpGuard    *HTTPGuard
+	budget       *budget
+	recorder     *recordTransport
+	replayer     *replayTransport
 
 	download starlark.Iterable
 }
@@ -127,8 +167,7 @@ var DefaultModuleLoader = func(thread *starlark.Thread, module string) (dict sta
 // will set transformation details, but starlark scripts can modify many parts of the dataset
 // pointer, including meta, structure, and transform. opts may provide more ways for output to
 // be produced from this function.
-func ExecScript(ctx context.Context, next, prev *dataset.Dataset, opts ...func(o *ExecOpts)) error {
-	var err error
+func ExecScript(ctx context.Context, next, prev *dataset.Dataset, opts ...func(o *ExecOpts)) (err error) {
 	if next.Transform == nil || next.Transform.ScriptFile() == nil {
 		return fmt.Errorf("no script to execute")
 	}
@@ -138,22 +177,27 @@ func ExecScript(ctx context.Context, next, prev *dataset.Dataset, opts ...func(o
 	for _, opt := range opts {
 		opt(o)
 	}
-
-	// hoist execution settings to resolve package settings
-	resolve.AllowFloat = o.AllowFloat
-	resolve.AllowSet = o.AllowSet
-	resolve.AllowLambda = o.AllowLambda
-	resolve.AllowNestedDef = o.AllowNestedDef
-
-	// add error func to starlark environment
-	starlark.Universe["error"] = starlark.NewBuiltin("error", Error)
-	for key, val := range o.Globals {
-		starlark.Universe[key] = val
+	if o.RecordHTTP && o.ReplayHTTP {
+		return fmt.Errorf("cannot both record and replay http")
 	}
 
+	// a tripped limit cancels the script at its next step, report the limit
+	// in place of the cancellation error
+	b := newBudget(o.Limits)
+	ctx, stop := b.watch(ctx)
+	defer func() {
+		stop()
+		if limitErr := b.Err(); limitErr != nil {
+			err = limitErr
+		}
+	}()
+
 	// set transform details
 	next.Transform.Syntax = "starlark"
 	next.Transform.SyntaxVersion = Version
+	// resources record the datasets loaded by this execution, drop any
+	// carried over from the transform a script was recalled from
+	next.Transform.Resources = nil
 
 	script := next.Transform.ScriptFile()
 	// "tee" the script reader to avoid losing script data, as starlark.ExecFile
@@ -171,20 +215,48 @@ func ExecScript(ctx context.Context, next, prev *dataset.Dataset, opts ...func(o
 		checkFunc:    o.MutateFieldCheck,
 		stderr:       o.ErrWriter,
 		moduleLoader: o.ModuleLoader,
+		httpGuard:    &HTTPGuard{},
+		budget:       b,
+	}
+	t.skyqri.SetContext(ctx)
+	t.skyqri.SetSQLService(o.SQLService)
+	t.skyqri.SetDatasetLoader(func(refstr string) (*dataset.Dataset, error) {
+		return t.loadDataset(t.ctx, refstr)
+	})
+	t.skyqri.SetRefResolver(t.resolveDependency)
+	if err = t.setupHTTPFixture(o); err != nil {
+		return err
 	}
 
-	skyCtx := skyctx.NewContext(next.Transform.Config, o.Secrets)
+	skyCtx := skyctx.NewContext(scriptConfig(next.Transform.Config), o.Secrets)
 
-	thread := &starlark.Thread{
+	thread := b.thread(&starlark.Thread{
 		Load: t.ModuleLoader,
 		Print: func(thread *starlark.Thread, msg string) {
 			// note we're ignoring a returned error here
 			_, _ = t.stderr.Write([]byte(msg))
 		},
+	})
+
+	// add error func & globals to the script environment. values are
+	// predeclared per-execution instead of modifying starlark.Universe, which
+	// is shared by all executions
+	predeclared := starlark.StringDict{
+		"error": starlark.NewBuiltin("error", Error),
+	}
+	for key, val := range o.Globals {
+		predeclared[key] = val
+	}
+	for key, val := range t.locals() {
+		predeclared[key] = val
 	}
 
 	// execute the transformation
-	t.globals, err = starlark.ExecFile(thread, pipeScript.FileName(), pipeScript, t.locals())
+	prog, err := compile(pipeScript.FileName(), pipeScript, predeclared, o)
+	if err == nil {
+		t.globals, err = prog.Init(thread, predeclared)
+		t.globals.Freeze()
+	}
 	if err != nil {
 		if evalErr, ok := err.(*starlark.EvalError); ok {
 			return fmt.Errorf(evalErr.Backtrace())
@@ -214,6 +286,9 @@ func ExecScript(ctx context.Context, next, prev *dataset.Dataset, opts ...func(o
 	if evalErr, ok := err.(*starlark.EvalError); ok {
 		return fmt.Errorf(evalErr.Backtrace())
 	}
+	if err == nil {
+		err = t.storeHTTPFixture()
+	}
 
 	// restore consumed script file
 	next.Transform.SetScriptFile(qfs.NewMemfileBytes("transform.star", buf.Bytes()))
@@ -221,6 +296,25 @@ func ExecScript(ctx context.Context, next, prev *dataset.Dataset, opts ...func(o
 	return err
 }
 
+// resolveMu guards the package-level dialect flags of the resolve package
+var resolveMu sync.Mutex
+
+// compile parses, resolves & compiles a script. The resolve package reads
+// dialect options from package-level flags, compile holds a lock while the
+// flags are set so executions with different options can run concurrently
+func compile(filename string, src interface{}, predeclared starlark.StringDict, o *ExecOpts) (*starlark.Program, error) {
+	resolveMu.Lock()
+	defer resolveMu.Unlock()
+
+	resolve.AllowFloat = o.AllowFloat
+	resolve.AllowSet = o.AllowSet
+	resolve.AllowLambda = o.AllowLambda
+	resolve.AllowNestedDef = o.AllowNestedDef
+
+	_, prog, err := starlark.SourceProgram(filename, src, predeclared.Has)
+	return prog, err
+}
+
 // Error halts program execution with an error
 func Error(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
 	var msg starlark.Value
@@ -270,8 +364,8 @@ func (t *transform) specialFuncs() (defined map[string]specialFunc, err error) {
 type specialFunc func(t *transform, thread *starlark.Thread, ctx *skyctx.Context) (result starlark.Value, err error)
 
 func callDownloadFunc(t *transform, thread *starlark.Thread, ctx *skyctx.Context) (result starlark.Value, err error) {
-	httpGuard.EnableNtwk()
-	defer httpGuard.DisableNtwk()
+	t.httpGuard.EnableNtwk()
+	defer t.httpGuard.DisableNtwk()
 	t.print("📡 running download...\n")
 
 	var download *starlark.Function
@@ -297,10 +391,12 @@ func callTransformFunc(t *transform, thread *starlark.Thread, ctx *skyctx.Contex
 
 	d := skyds.NewDataset(t.prev, t.checkFunc)
 	d.SetMutable(t.next)
+	d.SetBodySizeCheck(t.budget.bodySize)
 	if _, err = starlark.Call(thread, transform, starlark.Tuple{d.Methods(), ctx.Struct()}, nil); err != nil {
+		d.DiscardBodyWriter()
 		return err
 	}
-	return nil
+	return d.CloseBodyWriter()
 }
 
 // print writes output only if a node is specified
@@ -320,14 +416,105 @@ func (t *transform) ModuleLoader(thread *starlark.Thread, module string) (dict s
 		return t.skyqri.Namespace(), nil
 	}
 
+	// http modules are created per-execution, with a client bound to this
+	// execution's limits, fixtures & network guard
+	if module == starhttp.ModuleName {
+		return newHTTPModule(t.budget.httpClient(t.ctx, t.fixtureClient(http.DefaultClient)), t.httpGuard), nil
+	}
+
 	if t.moduleLoader == nil {
 		return nil, fmt.Errorf("couldn't load module: %s", module)
 	}
-
 	return t.moduleLoader(thread, module)
 }
 
-// LoadDataset is a function
+// setupHTTPFixture prepares recording or replay of http requests. Replayed
+// fixtures come from next's transform config, then prev's
+func (t *transform) setupHTTPFixture(o *ExecOpts) error {
+	if o.RecordHTTP {
+		t.recorder = &recordTransport{fixture: &HTTPFixture{}}
+		return nil
+	}
+	if !o.ReplayHTTP {
+		return nil
+	}
+
+	f, err := HTTPFixtureFromConfig(t.next.Transform.Config)
+	if err != nil {
+		return err
+	}
+	if f == nil && t.prev != nil && t.prev.Transform != nil {
+		if f, err = HTTPFixtureFromConfig(t.prev.Transform.Config); err != nil {
+			return err
+		}
+	}
+	if f == nil {
+		return ErrNoHTTPFixture
+	}
+	t.replayer = newReplayTransport(f)
+	return nil
+}
+
+// fixtureClient wraps the transport of an http client to record or replay
+// requests, returning the client unchanged if neither is configured
+func (t *transform) fixtureClient(base *http.Client) *http.Client {
+	if t.recorder == nil && t.replayer == nil {
+		return base
+	}
+
+	cli := *base
+	if t.replayer != nil {
+		cli.Transport = t.replayer
+		return &cli
+	}
+	t.recorder.base = cli.Transport
+	if t.recorder.base == nil {
+		t.recorder.base = http.DefaultTransport
+	}
+	cli.Transport = t.recorder
+	return &cli
+}
+
+// storeHTTPFixture writes the recorded or replayed fixture to next's
+// transform config, so the saved version carries the responses it was built
+// from
+func (t *transform) storeHTTPFixture() error {
+	var f *HTTPFixture
+	if t.recorder != nil {
+		f = t.recorder.fixture
+	} else if t.replayer != nil {
+		f = t.replayer.fixture
+	} else {
+		return nil
+	}
+
+	val, err := f.configValue()
+	if err != nil {
+		return err
+	}
+	if t.next.Transform.Config == nil {
+		t.next.Transform.Config = map[string]interface{}{}
+	}
+	t.next.Transform.Config[HTTPFixtureConfigKey] = val
+	return nil
+}
+
+// scriptConfig removes the http fixture from config exposed to scripts
+func scriptConfig(config map[string]interface{}) map[string]interface{} {
+	if _, ok := config[HTTPFixtureConfigKey]; !ok {
+		return config
+	}
+	cfg := make(map[string]interface{}, len(config))
+	for key, val := range config {
+		if key != HTTPFixtureConfigKey {
+			cfg[key] = val
+		}
+	}
+	return cfg
+}
+
+// LoadDataset loads a dataset from the qri repo, recording the loaded version
+// as a dependency of the transform
 func (t *transform) LoadDataset(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
 	var refstr starlark.String
 	if err := starlark.UnpackArgs("load_dataset", args, kwargs, "ref", &refstr); err != nil {
@@ -343,17 +530,10 @@ func (t *transform) LoadDataset(thread *starlark.Thread, _ *starlark.Builtin, ar
 }
 
 func (t *transform) loadDataset(ctx context.Context, refstr string) (*dataset.Dataset, error) {
-	if t.repo == nil {
-		return nil, fmt.Errorf("no qri repo available to load dataset: %s", refstr)
-	}
-
-	ref, err := repo.ParseDatasetRef(refstr)
+	ref, err := t.resolveRef(refstr)
 	if err != nil {
 		return nil, err
 	}
-	if err := repo.CanonicalizeDatasetRef(t.repo, &ref); err != nil {
-		return nil, err
-	}
 
 	ds, err := dsfs.LoadDataset(ctx, t.repo.Store(), ref.Path)
 	if err != nil {
@@ -366,12 +546,43 @@ func (t *transform) loadDataset(ctx context.Context, refstr string) (*dataset.Da
 		}
 	}
 
+	t.recordDependency(ref)
+	return ds, nil
+}
+
+// resolveRef resolves a dataset reference to a version in the repo
+func (t *transform) resolveRef(refstr string) (reporef.DatasetRef, error) {
+	if t.repo == nil {
+		return reporef.DatasetRef{}, fmt.Errorf("no qri repo available to load dataset: %s", refstr)
+	}
+
+	ref, err := repo.ParseDatasetRef(refstr)
+	if err != nil {
+		return ref, err
+	}
+	err = repo.CanonicalizeDatasetRef(t.repo, &ref)
+	return ref, err
+}
+
+// resolveDependency resolves a dataset reference without loading it,
+// recording the resolved version as a dependency. Datasets read by SQL
+// queries are resolved this way, the query reads the returned version path
+func (t *transform) resolveDependency(refstr string) (string, error) {
+	ref, err := t.resolveRef(refstr)
+	if err != nil {
+		return "", err
+	}
+	t.recordDependency(ref)
+	return ref.Path, nil
+}
+
+// recordDependency adds a loaded dataset version to the resources of the
+// transform, keyed by version path
+func (t *transform) recordDependency(ref reporef.DatasetRef) {
 	if t.next.Transform.Resources == nil {
 		t.next.Transform.Resources = map[string]*dataset.TransformResource{}
 	}
 	t.next.Transform.Resources[ref.Path] = &dataset.TransformResource{Path: ref.String()}
-
-	return ds, nil
 }
 
 // MutatedComponentsFunc returns a function for checking if a field has been
@@ -3,10 +3,14 @@ package startf
 import (
 	"bytes"
 	"context"
+	"fmt"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/qri-io/dataset"
@@ -152,6 +156,82 @@ func TestLoadDataset(t *testing.T) {
 	}
 }
 
+func TestQriModuleHistory(t *testing.T) {
+	ctx := context.Background()
+	r := testRepo(t)
+
+	script := `
+load("assert.star", "assert")
+load("qri.star", "qri")
+
+def transform(ds, ctx):
+	versions = qri.history("peer/movies", 1)
+	assert.eq(len(versions), 1)
+	assert.eq(versions[0]["name"], "movies")
+	ds.set_body([[versions[0]["path"]]])
+`
+	ds := &dataset.Dataset{
+		Transform: &dataset.Transform{},
+	}
+	ds.Transform.SetScriptFile(qfs.NewMemfileBytes("tf.star", []byte(script)))
+
+	err := ExecScript(ctx, ds, nil, func(o *ExecOpts) {
+		o.Repo = r
+		o.ModuleLoader = testModuleLoader(t)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadDatasetDependencies(t *testing.T) {
+	ctx := context.Background()
+	r := testRepo(t)
+
+	script := `
+load("qri.star", "qri")
+
+movies = load_dataset("peer/movies")
+
+def transform(ds, ctx):
+	cities = qri.load_dataset("peer/cities")
+	ds.set_body([[len(cities.get_body())]])
+`
+	ds := &dataset.Dataset{
+		Transform: &dataset.Transform{
+			// resources recalled from a previous version are replaced
+			Resources: map[string]*dataset.TransformResource{
+				"/map/stale": {Path: "peer/stale@/map/stale"},
+			},
+		},
+	}
+	ds.Transform.SetScriptFile(qfs.NewMemfileBytes("tf.star", []byte(script)))
+
+	err := ExecScript(ctx, ds, nil, func(o *ExecOpts) {
+		o.Repo = r
+		o.ModuleLoader = testModuleLoader(t)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	loaded := map[string]bool{}
+	for path, res := range ds.Transform.Resources {
+		ref, err := repo.ParseDatasetRef(res.Path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ref.Path != path {
+			t.Errorf("expected resource key %q to match ref path %q", path, ref.Path)
+		}
+		loaded[ref.AliasString()] = true
+	}
+	expect := map[string]bool{"peer/movies": true, "peer/cities": true}
+	if diff := cmp.Diff(expect, loaded); diff != "" {
+		t.Errorf("recorded dependencies mismatch (-want +got):\n%s", diff)
+	}
+}
+
 func TestGetMetaNilPrev(t *testing.T) {
 	ctx := context.Background()
 	ds := &dataset.Dataset{
@@ -248,3 +328,105 @@ func testModuleLoader(t *testing.T) func(thread *starlark.Thread, module string)
 		return starlib.Loader(thread, module)
 	}
 }
+
+func TestExecScriptConcurrent(t *testing.T) {
+	ctx := context.Background()
+
+	// downloads block until scripts that make requests outside the download
+	// step have finished, so those requests run while other executions have
+	// network access enabled
+	sneakyDone := make(chan struct{})
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-sneakyDone:
+		case <-time.After(time.Second * 5):
+		}
+		w.Write([]byte(`{"foo":["bar","baz","bat"]}`))
+	}))
+	defer s.Close()
+
+	execScript := func(script string, opts ...func(o *ExecOpts)) (*dataset.Dataset, error) {
+		ds := &dataset.Dataset{Transform: &dataset.Transform{}}
+		ds.Transform.SetScriptFile(qfs.NewMemfileBytes("tf.star", []byte(script)))
+		opts = append(opts, func(o *ExecOpts) {
+			o.Globals["test_server_url"] = starlark.String(s.URL)
+		})
+		err := ExecScript(ctx, ds, nil, opts...)
+		return ds, err
+	}
+
+	const n = 8
+	downloadErrs := make(chan error, n)
+	sneakyErrs := make(chan error, n)
+	dialectErrs := make(chan error, 2*n)
+
+	for i := 0; i < n; i++ {
+		go func() {
+			_, err := execScript(`
+load("http.star", "http")
+def download(ctx):
+  return http.get(test_server_url).json()['foo']
+def transform(ds, ctx):
+  ds.set_body(ctx.download)`)
+			downloadErrs <- err
+		}()
+	}
+
+	sneaky := sync.WaitGroup{}
+	for i := 0; i < n; i++ {
+		sneaky.Add(1)
+		go func() {
+			defer sneaky.Done()
+			_, err := execScript(`
+load("http.star", "http")
+def transform(ds, ctx):
+  ds.set_body(http.get(test_server_url).json()['foo'])`)
+			sneakyErrs <- err
+		}()
+	}
+
+	for i := 0; i < n; i++ {
+		allowFloat := i%2 == 0
+		name := fmt.Sprintf("exec_%d", i)
+		go func() {
+			ds, err := execScript(`
+def transform(ds, ctx):
+  ds.set_body([name, 1.5])`, func(o *ExecOpts) {
+				o.AllowFloat = allowFloat
+				o.Globals["name"] = starlark.String(name)
+			})
+			if allowFloat {
+				if err == nil {
+					var body []byte
+					if body, err = ioutil.ReadAll(ds.BodyFile()); err == nil && string(body) != fmt.Sprintf(`[%q,1.5]`, name) {
+						err = fmt.Errorf("%s: unexpected body %s", name, body)
+					}
+				}
+			} else if err == nil {
+				err = fmt.Errorf("%s: expected float literal to fail without AllowFloat", name)
+			} else {
+				err = nil
+			}
+			dialectErrs <- err
+		}()
+	}
+
+	sneaky.Wait()
+	close(sneakyDone)
+
+	for i := 0; i < n; i++ {
+		if err := <-downloadErrs; err != nil {
+			t.Errorf("download: %s", err)
+		}
+		if err := <-sneakyErrs; err == nil || !strings.Contains(err.Error(), ErrNtwkDisabled.Error()) {
+			t.Errorf("expected request outside download to fail with ErrNtwkDisabled. got: %v", err)
+		}
+		if err := <-dialectErrs; err != nil {
+			t.Error(err)
+		}
+	}
+
+	if _, ok := starlark.Universe["name"]; ok {
+		t.Error("expected globals to not be added to starlark.Universe")
+	}
+}
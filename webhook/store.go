@@ -0,0 +1,171 @@
+package webhook
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/qri-io/qri/event"
+)
+
+const (
+	queueFilename = "queue.json"
+	logFilename   = "deliveries.log"
+)
+
+// DeliveryStatus enumerates the outcomes of a delivery attempt
+type DeliveryStatus string
+
+const (
+	// StatusDelivered indicates the receiver accepted the delivery
+	StatusDelivered = DeliveryStatus("delivered")
+	// StatusRetrying indicates the attempt failed & will be retried
+	StatusRetrying = DeliveryStatus("retrying")
+	// StatusFailed indicates the attempt failed & the delivery was dropped
+	StatusFailed = DeliveryStatus("failed")
+)
+
+// LogEntry records a single delivery attempt
+type LogEntry struct {
+	DeliveryID  string         `json:"deliveryID"`
+	URL         string         `json:"url"`
+	Topic       event.Topic    `json:"topic"`
+	Attempt     int            `json:"attempt"`
+	Status      DeliveryStatus `json:"status"`
+	StatusCode  int            `json:"statusCode,omitempty"`
+	Error       string         `json:"error,omitempty"`
+	Timestamp   time.Time      `json:"timestamp"`
+	Duration    time.Duration  `json:"duration"`
+	NextAttempt time.Time      `json:"nextAttempt,omitempty"`
+}
+
+// store persists the delivery queue & log to a directory
+type store struct {
+	dir string
+	lk  sync.Mutex
+}
+
+func newStore(dir string) (*store, error) {
+	if dir == "" {
+		return nil, fmt.Errorf("webhook storage directory is required")
+	}
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return nil, err
+	}
+	return &store{dir: dir}, nil
+}
+
+func (s *store) readQueue() ([]*Delivery, error) {
+	s.lk.Lock()
+	defer s.lk.Unlock()
+
+	data, err := ioutil.ReadFile(filepath.Join(s.dir, queueFilename))
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	queue := []*Delivery{}
+	if err := json.Unmarshal(data, &queue); err != nil {
+		return nil, fmt.Errorf("reading webhook queue: %w", err)
+	}
+	return queue, nil
+}
+
+// writeQueue replaces the stored queue, writing to a temp file first so a
+// crash mid-write can't corrupt queued deliveries
+func (s *store) writeQueue(queue []*Delivery) error {
+	s.lk.Lock()
+	defer s.lk.Unlock()
+
+	data, err := json.Marshal(queue)
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(s.dir, queueFilename)
+	tmp := path + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+func (s *store) appendLog(e LogEntry) error {
+	s.lk.Lock()
+	defer s.lk.Unlock()
+
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(filepath.Join(s.dir, logFilename), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.Write(append(data, '\n'))
+	return err
+}
+
+func (s *store) readLog(offset, limit int) ([]LogEntry, error) {
+	s.lk.Lock()
+	defer s.lk.Unlock()
+	return readLogFile(filepath.Join(s.dir, logFilename), offset, limit)
+}
+
+// ReadLog reads delivery log entries stored in dir, most recent first. A
+// negative limit returns all entries after offset
+func ReadLog(dir string, offset, limit int) ([]LogEntry, error) {
+	return readLogFile(filepath.Join(dir, logFilename), offset, limit)
+}
+
+func readLogFile(path string, offset, limit int) ([]LogEntry, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return []LogEntry{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	entries := []LogEntry{}
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" {
+			continue
+		}
+		e := LogEntry{}
+		if err := json.Unmarshal([]byte(line), &e); err != nil {
+			return nil, fmt.Errorf("reading webhook delivery log: %w", err)
+		}
+		entries = append(entries, e)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+
+	// reverse to most-recent-first
+	for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+		entries[i], entries[j] = entries[j], entries[i]
+	}
+
+	if offset < 0 {
+		offset = 0
+	}
+	if offset >= len(entries) {
+		return []LogEntry{}, nil
+	}
+	entries = entries[offset:]
+	if limit >= 0 && limit < len(entries) {
+		entries = entries[:limit]
+	}
+	return entries, nil
+}
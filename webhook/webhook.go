@@ -0,0 +1,426 @@
+// Package webhook delivers qri events to configured HTTP endpoints. Events are
+// read from an event.Bus, matched against each configured hook's topics and
+// placed on a persistent on-disk queue. A delivery worker POSTs queued payloads
+// signed with HMAC-SHA256, retrying failed deliveries with exponential backoff.
+// Every attempt is recorded in an append-only delivery log
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	golog "github.com/ipfs/go-log"
+	"github.com/qri-io/qri/config"
+	"github.com/qri-io/qri/event"
+)
+
+var log = golog.Logger("webhook")
+
+const (
+	// SignatureHeader is the HTTP header that carries the hex-encoded
+	// HMAC-SHA256 signature of a delivery body, prefixed with "sha256="
+	SignatureHeader = "X-Qri-Signature"
+	// TopicHeader is the HTTP header that carries the event topic
+	TopicHeader = "X-Qri-Event"
+	// DeliveryHeader is the HTTP header that carries the unique delivery ID
+	DeliveryHeader = "X-Qri-Delivery"
+)
+
+var (
+	// nowFunc is the clock deliveries are scheduled with, package level for
+	// testing
+	nowFunc = time.Now
+	// BaseRetryDelay is the wait before the first retry of a failed delivery.
+	// each subsequent retry doubles the delay, up to MaxRetryDelay
+	BaseRetryDelay = time.Second * 10
+	// MaxRetryDelay caps the wait between retries
+	MaxRetryDelay = time.Hour
+	// pollInterval is the longest the worker waits before checking the queue
+	pollInterval = time.Minute
+)
+
+// Payload is the JSON body POSTed to a webhook URL
+type Payload struct {
+	ID        string          `json:"id"`
+	Topic     event.Topic     `json:"topic"`
+	Timestamp time.Time       `json:"timestamp"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// Delivery is a queued payload bound for a single webhook URL
+type Delivery struct {
+	ID          string          `json:"id"`
+	URL         string          `json:"url"`
+	Topic       event.Topic     `json:"topic"`
+	Body        json.RawMessage `json:"body"`
+	Attempts    int             `json:"attempts"`
+	NextAttempt time.Time       `json:"nextAttempt"`
+	Created     time.Time       `json:"created"`
+}
+
+// Dispatcher queues events from a bus & delivers them to webhooks
+type Dispatcher struct {
+	hooks       []*config.Webhook
+	maxAttempts int
+	client      *http.Client
+	store       *store
+
+	lk    sync.Mutex
+	queue []*Delivery
+	wake  chan struct{}
+	done  chan struct{}
+}
+
+// NewDispatcher creates a Dispatcher from configuration, storing queue & log
+// data in dir. Any deliveries left in the queue by a previous dispatcher are
+// loaded & resumed when the dispatcher is started
+func NewDispatcher(cfg *config.Webhooks, dir string) (*Dispatcher, error) {
+	if cfg == nil {
+		return nil, fmt.Errorf("webhook configuration is required")
+	}
+
+	st, err := newStore(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	queue, err := st.readQueue()
+	if err != nil {
+		return nil, err
+	}
+
+	maxAttempts := cfg.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = config.DefaultWebhookMaxAttempts
+	}
+
+	return &Dispatcher{
+		hooks:       cfg.Copy().Hooks,
+		maxAttempts: maxAttempts,
+		client:      &http.Client{Timeout: time.Second * 30},
+		store:       st,
+		queue:       queue,
+		wake:        make(chan struct{}, 1),
+		done:        make(chan struct{}),
+	}, nil
+}
+
+// Start subscribes the dispatcher to all bus events & begins delivering queued
+// payloads. Start returns immediately, work stops when ctx is cancelled
+func (d *Dispatcher) Start(ctx context.Context, bus event.Bus) {
+	wg := &sync.WaitGroup{}
+	wg.Add(2)
+	go func() {
+		wg.Wait()
+		close(d.done)
+	}()
+
+	events := bus.SubscribeAll()
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-ctx.Done():
+				bus.Unsubscribe(events)
+				return
+			case e := <-events:
+				if err := d.Enqueue(e.Topic, e.Payload); err != nil {
+					log.Errorf("enqueuing %s event: %s", e.Topic, err)
+				}
+			}
+		}
+	}()
+
+	go func() {
+		defer wg.Done()
+		d.deliverLoop(ctx)
+	}()
+}
+
+// Done returns a channel that closes once a started dispatcher has stopped
+func (d *Dispatcher) Done() <-chan struct{} {
+	return d.done
+}
+
+// Enqueue adds a delivery to the queue for each hook that matches topic
+func (d *Dispatcher) Enqueue(topic event.Topic, data interface{}) error {
+	var matches []*config.Webhook
+	for _, h := range d.hooks {
+		if MatchTopic(h.Topics, topic) {
+			matches = append(matches, h)
+		}
+	}
+	if len(matches) == 0 {
+		return nil
+	}
+
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	now := nowFunc()
+	d.lk.Lock()
+	for _, h := range matches {
+		id := newID()
+		body, err := json.Marshal(Payload{
+			ID:        id,
+			Topic:     topic,
+			Timestamp: now,
+			Data:      raw,
+		})
+		if err != nil {
+			d.lk.Unlock()
+			return err
+		}
+		d.queue = append(d.queue, &Delivery{
+			ID:          id,
+			URL:         h.URL,
+			Topic:       topic,
+			Body:        body,
+			NextAttempt: now,
+			Created:     now,
+		})
+	}
+	err = d.store.writeQueue(d.queue)
+	d.lk.Unlock()
+
+	d.notify()
+	return err
+}
+
+// Queue returns a copy of the pending delivery queue
+func (d *Dispatcher) Queue() []Delivery {
+	d.lk.Lock()
+	defer d.lk.Unlock()
+	res := make([]Delivery, len(d.queue))
+	for i, dl := range d.queue {
+		res[i] = *dl
+	}
+	return res
+}
+
+// Log returns entries from the delivery log, most recent first
+func (d *Dispatcher) Log(offset, limit int) ([]LogEntry, error) {
+	return d.store.readLog(offset, limit)
+}
+
+func (d *Dispatcher) notify() {
+	select {
+	case d.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (d *Dispatcher) deliverLoop(ctx context.Context) {
+	for {
+		wait := d.DeliverDue(ctx)
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-d.wake:
+			timer.Stop()
+		case <-timer.C:
+		}
+	}
+}
+
+// DeliverDue attempts every delivery scheduled at or before the current time,
+// returning the duration until the next scheduled attempt
+func (d *Dispatcher) DeliverDue(ctx context.Context) time.Duration {
+	now := nowFunc()
+	d.lk.Lock()
+	var due []*Delivery
+	for _, dl := range d.queue {
+		if !dl.NextAttempt.After(now) {
+			due = append(due, dl)
+		}
+	}
+	d.lk.Unlock()
+
+	for _, dl := range due {
+		if ctx.Err() != nil {
+			break
+		}
+		d.attempt(ctx, dl)
+	}
+
+	d.lk.Lock()
+	defer d.lk.Unlock()
+	if err := d.store.writeQueue(d.queue); err != nil {
+		log.Errorf("writing webhook queue: %s", err)
+	}
+
+	wait := pollInterval
+	now = nowFunc()
+	for _, dl := range d.queue {
+		if until := dl.NextAttempt.Sub(now); until < wait {
+			wait = until
+		}
+	}
+	if wait < 0 {
+		wait = 0
+	}
+	return wait
+}
+
+func (d *Dispatcher) attempt(ctx context.Context, dl *Delivery) {
+	start := nowFunc()
+	status, err := d.send(ctx, dl)
+
+	d.lk.Lock()
+	defer d.lk.Unlock()
+	dl.Attempts++
+	entry := LogEntry{
+		DeliveryID: dl.ID,
+		URL:        dl.URL,
+		Topic:      dl.Topic,
+		Attempt:    dl.Attempts,
+		StatusCode: status,
+		Timestamp:  start,
+		Duration:   nowFunc().Sub(start),
+	}
+
+	switch {
+	case err == nil:
+		entry.Status = StatusDelivered
+		d.remove(dl)
+	case dl.Attempts >= d.maxAttempts:
+		entry.Status = StatusFailed
+		entry.Error = err.Error()
+		d.remove(dl)
+	default:
+		entry.Status = StatusRetrying
+		entry.Error = err.Error()
+		dl.NextAttempt = nowFunc().Add(Backoff(dl.Attempts))
+		entry.NextAttempt = dl.NextAttempt
+	}
+
+	if err := d.store.appendLog(entry); err != nil {
+		log.Errorf("writing webhook delivery log: %s", err)
+	}
+}
+
+// remove drops a delivery from the queue. caller must hold the lock
+func (d *Dispatcher) remove(dl *Delivery) {
+	for i, q := range d.queue {
+		if q == dl {
+			d.queue = append(d.queue[:i:i], d.queue[i+1:]...)
+			return
+		}
+	}
+}
+
+func (d *Dispatcher) send(ctx context.Context, dl *Delivery) (status int, err error) {
+	hook := d.hook(dl.URL)
+	if hook == nil {
+		return 0, fmt.Errorf("no webhook is configured for %s", dl.URL)
+	}
+
+	req, err := http.NewRequest("POST", dl.URL, bytes.NewReader(dl.Body))
+	if err != nil {
+		return 0, err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(TopicHeader, string(dl.Topic))
+	req.Header.Set(DeliveryHeader, dl.ID)
+	if hook.Secret != "" {
+		req.Header.Set(SignatureHeader, "sha256="+Sign(hook.Secret, dl.Body))
+	}
+
+	res, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+	io.Copy(ioutil.Discard, res.Body)
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return res.StatusCode, fmt.Errorf("unexpected response status: %d", res.StatusCode)
+	}
+	return res.StatusCode, nil
+}
+
+func (d *Dispatcher) hook(url string) *config.Webhook {
+	for _, h := range d.hooks {
+		if h.URL == url {
+			return h
+		}
+	}
+	return nil
+}
+
+// MatchTopic reports whether topic is included in a list of topic patterns. A
+// pattern ending in "*" matches any topic with the preceding prefix. An empty
+// list of patterns matches all topics
+func MatchTopic(patterns []string, topic event.Topic) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	t := string(topic)
+	for _, p := range patterns {
+		if strings.HasSuffix(p, "*") {
+			if strings.HasPrefix(t, strings.TrimSuffix(p, "*")) {
+				return true
+			}
+		} else if p == t {
+			return true
+		}
+	}
+	return false
+}
+
+// Backoff returns the wait before retrying a delivery that has failed attempts
+// times
+func Backoff(attempts int) time.Duration {
+	delay := BaseRetryDelay
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= MaxRetryDelay {
+			return MaxRetryDelay
+		}
+	}
+	return delay
+}
+
+// Sign returns the hex-encoded HMAC-SHA256 of body keyed with secret
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks a signature header value against a body & secret. Receivers
+// of webhook deliveries can use Verify to authenticate requests
+func Verify(secret string, body []byte, signature string) bool {
+	sig, err := hex.DecodeString(strings.TrimPrefix(signature, "sha256="))
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hmac.Equal(sig, mac.Sum(nil))
+}
+
+func newID() string {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return fmt.Sprintf("%d", nowFunc().UnixNano())
+	}
+	return hex.EncodeToString(buf)
+}
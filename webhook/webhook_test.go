@@ -0,0 +1,281 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/qri-io/qri/config"
+	"github.com/qri-io/qri/dsref"
+	"github.com/qri-io/qri/event"
+)
+
+func TestDispatcherDeliver(t *testing.T) {
+	dir, err := ioutil.TempDir("", "TestDispatcherDeliver")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	received := make(chan Payload, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			t.Error(err)
+		}
+		if !Verify("secret", body, r.Header.Get(SignatureHeader)) {
+			t.Errorf("signature verification failed")
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.Header.Get(TopicHeader) != string(event.ETDatasetSaveEvent) {
+			t.Errorf("topic header mismatch. expected: %q, got: %q", event.ETDatasetSaveEvent, r.Header.Get(TopicHeader))
+		}
+		p := Payload{}
+		if err := json.Unmarshal(body, &p); err != nil {
+			t.Error(err)
+		}
+		received <- p
+	}))
+	defer srv.Close()
+
+	cfg := &config.Webhooks{
+		Enabled: true,
+		Hooks: []*config.Webhook{
+			{URL: srv.URL, Topics: []string{"dataset:*"}, Secret: "secret"},
+		},
+	}
+	d, err := NewDispatcher(cfg, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer func() {
+		cancel()
+		<-d.Done()
+	}()
+	bus := event.NewBus(ctx)
+	d.Start(ctx, bus)
+
+	// unmatched topics shouldn't be delivered
+	bus.Publish(event.ETPeerConnectEvent, event.PeerEvent{})
+	bus.Publish(event.ETDatasetSaveEvent, event.DatasetSaveEvent{Ref: dsref.Ref{Username: "peer", Name: "movies"}})
+
+	select {
+	case p := <-received:
+		if p.Topic != event.ETDatasetSaveEvent {
+			t.Errorf("payload topic mismatch. expected: %q, got: %q", event.ETDatasetSaveEvent, p.Topic)
+		}
+		data := event.DatasetSaveEvent{}
+		if err := json.Unmarshal(p.Data, &data); err != nil {
+			t.Fatal(err)
+		}
+		if data.Ref.Name != "movies" {
+			t.Errorf("payload data mismatch. expected name: %q, got: %q", "movies", data.Ref.Name)
+		}
+	case <-time.After(time.Second * 2):
+		t.Fatal("timed out waiting for delivery")
+	}
+}
+
+func TestDispatcherRetry(t *testing.T) {
+	dir, err := ioutil.TempDir("", "TestDispatcherRetry")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	prevNow := nowFunc
+	defer func() { nowFunc = prevNow }()
+	now := time.Date(2001, 1, 1, 1, 1, 1, 0, time.UTC)
+	nowFunc = func() time.Time { return now }
+
+	var (
+		lk    sync.Mutex
+		calls int
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lk.Lock()
+		defer lk.Unlock()
+		calls++
+		if calls < 3 {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer srv.Close()
+	numCalls := func() int {
+		lk.Lock()
+		defer lk.Unlock()
+		return calls
+	}
+
+	cfg := &config.Webhooks{
+		Enabled:     true,
+		MaxAttempts: 5,
+		Hooks:       []*config.Webhook{{URL: srv.URL}},
+	}
+	d, err := NewDispatcher(cfg, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	if err := d.Enqueue(event.ETDatasetSaveEvent, event.DatasetSaveEvent{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if wait := d.DeliverDue(ctx); wait != Backoff(1) {
+		t.Errorf("wait mismatch after first failure. expected: %s, got: %s", Backoff(1), wait)
+	}
+	// nothing is due yet, no attempt should be made
+	d.DeliverDue(ctx)
+	if n := numCalls(); n != 1 {
+		t.Errorf("expected 1 call before backoff elapsed, got: %d", n)
+	}
+
+	// queue should survive a restart
+	d, err = NewDispatcher(cfg, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q := d.Queue(); len(q) != 1 || q[0].Attempts != 1 {
+		t.Fatalf("expected reloaded queue to have 1 delivery with 1 attempt, got: %v", q)
+	}
+
+	now = now.Add(Backoff(1))
+	if wait := d.DeliverDue(ctx); wait != Backoff(2) {
+		t.Errorf("wait mismatch after second failure. expected: %s, got: %s", Backoff(2), wait)
+	}
+	now = now.Add(Backoff(2))
+	d.DeliverDue(ctx)
+
+	if n := numCalls(); n != 3 {
+		t.Errorf("expected 3 calls, got: %d", n)
+	}
+	if q := d.Queue(); len(q) != 0 {
+		t.Errorf("expected empty queue after delivery, got %d deliveries", len(q))
+	}
+
+	entries, err := ReadLog(dir, 0, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := []DeliveryStatus{StatusDelivered, StatusRetrying, StatusRetrying}
+	if len(entries) != len(expect) {
+		t.Fatalf("log length mismatch. expected: %d, got: %d", len(expect), len(entries))
+	}
+	for i, s := range expect {
+		if entries[i].Status != s {
+			t.Errorf("log entry %d status mismatch. expected: %q, got: %q", i, s, entries[i].Status)
+		}
+	}
+	if entries[2].StatusCode != http.StatusInternalServerError {
+		t.Errorf("expected first attempt status code %d, got: %d", http.StatusInternalServerError, entries[2].StatusCode)
+	}
+
+	entries, err = ReadLog(dir, 1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Attempt != 2 {
+		t.Errorf("expected paginated log to return second attempt, got: %v", entries)
+	}
+}
+
+func TestDispatcherMaxAttempts(t *testing.T) {
+	dir, err := ioutil.TempDir("", "TestDispatcherMaxAttempts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	cfg := &config.Webhooks{
+		Enabled:     true,
+		MaxAttempts: 1,
+		Hooks:       []*config.Webhook{{URL: srv.URL}},
+	}
+	d, err := NewDispatcher(cfg, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Enqueue(event.ETDatasetSaveEvent, event.DatasetSaveEvent{}); err != nil {
+		t.Fatal(err)
+	}
+	d.DeliverDue(context.Background())
+
+	if q := d.Queue(); len(q) != 0 {
+		t.Errorf("expected failed delivery to be dropped, got %d deliveries", len(q))
+	}
+	entries, err := d.Log(0, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Status != StatusFailed {
+		t.Errorf("expected a single failed log entry, got: %v", entries)
+	}
+}
+
+func TestMatchTopic(t *testing.T) {
+	cases := []struct {
+		patterns []string
+		topic    event.Topic
+		expect   bool
+	}{
+		{nil, "dataset:saveEvent", true},
+		{[]string{"dataset:saveEvent"}, "dataset:saveEvent", true},
+		{[]string{"dataset:saveEvent"}, "dataset:removeEvent", false},
+		{[]string{"dataset:*"}, "dataset:removeEvent", true},
+		{[]string{"remote:*"}, "dataset:removeEvent", false},
+		{[]string{"remote:*", "*"}, "dataset:removeEvent", true},
+	}
+
+	for i, c := range cases {
+		if got := MatchTopic(c.patterns, c.topic); got != c.expect {
+			t.Errorf("case %d %v %q mismatch. expected: %t, got: %t", i, c.patterns, c.topic, c.expect, got)
+		}
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	cases := []struct {
+		attempts int
+		expect   time.Duration
+	}{
+		{1, BaseRetryDelay},
+		{2, BaseRetryDelay * 2},
+		{3, BaseRetryDelay * 4},
+		{100, MaxRetryDelay},
+	}
+
+	for _, c := range cases {
+		if got := Backoff(c.attempts); got != c.expect {
+			t.Errorf("attempts %d mismatch. expected: %s, got: %s", c.attempts, c.expect, got)
+		}
+	}
+}
+
+func TestSignVerify(t *testing.T) {
+	body := []byte(`{"id":"foo"}`)
+	sig := "sha256=" + Sign("secret", body)
+	if !Verify("secret", body, sig) {
+		t.Error("expected signature to verify")
+	}
+	if Verify("wrong", body, sig) {
+		t.Error("expected signature with wrong secret to fail")
+	}
+	if Verify("secret", []byte(`{"id":"bar"}`), sig) {
+		t.Error("expected signature for altered body to fail")
+	}
+}